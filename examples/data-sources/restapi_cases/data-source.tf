@@ -0,0 +1,4 @@
+data "restapi_cases" "pending_approvals" {
+  state      = "open"
+  object_oid = "00000000-0000-0000-0000-000000000002"
+}
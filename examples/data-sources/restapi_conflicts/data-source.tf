@@ -0,0 +1,8 @@
+data "restapi_conflicts" "jsmith" {
+  path = "/users"
+  name = "jsmith"
+  unique_keys = {
+    emailAddress = "jsmith@example.com"
+  }
+  fail_on_conflict = true
+}
@@ -0,0 +1,17 @@
+data "restapi_midpoint_import_ids" "business_roles" {
+  type = "roles"
+  filter = jsonencode({
+    equal = { path = "subtype", value = "business" }
+  })
+  import_options = {
+    read_results_key = "role"
+  }
+}
+
+# Bring every business role under Terraform; generate the configuration with
+# terraform plan -generate-config-out=roles.tf
+import {
+  for_each = data.restapi_midpoint_import_ids.business_roles.import_ids
+  to       = restapi_object.business_role[each.key]
+  id       = each.value
+}
@@ -0,0 +1,23 @@
+data "restapi_midpoint_objects" "employees" {
+  type = "users"
+  filter = jsonencode({
+    equal = { path = "subtype", value = "employee" }
+  })
+  order_by = "name"
+}
+
+data "restapi_midpoint_objects" "business_roles" {
+  type  = "roles"
+  query = "archetypeRef matches (oid = \"00000000-0000-0000-0000-000000000321\")"
+}
+
+data "restapi_midpoint_role" "auditor" {
+  name = "Auditor"
+}
+
+# Every employee gets the auditor role
+resource "restapi_midpoint_assignment" "auditor" {
+  for_each   = data.restapi_midpoint_objects.employees.objects_by_oid
+  object_oid = each.key
+  target_oid = data.restapi_midpoint_role.auditor.oid
+}
@@ -0,0 +1,16 @@
+data "restapi_midpoint_resource_schema" "ldap" {
+  oid             = "ef2bc95b-76e0-48e2-86d6-3d4f02d3e1a2"
+  object_class    = "inetOrgPerson"
+  test_connection = true
+}
+
+locals {
+  ldap_attributes = {
+    for attribute in data.restapi_midpoint_resource_schema.ldap.object_classes[0].attributes : attribute.name => attribute
+  }
+}
+
+# Fails the plan when the mapped attribute is not in the schema
+output "mail_is_updatable" {
+  value = local.ldap_attributes["mail"].updatable
+}
@@ -0,0 +1,14 @@
+data "restapi_midpoint_role" "auditor" {
+  name = "Auditor"
+}
+
+# A business role with a given identifier
+data "restapi_midpoint_role" "approver" {
+  identifier    = "APR"
+  archetype_oid = "00000000-0000-0000-0000-000000000321"
+}
+
+resource "restapi_midpoint_assignment" "auditor" {
+  object_oid = "c0c010c0-d34d-b33f-f00d-111111111111"
+  target_oid = data.restapi_midpoint_role.auditor.oid
+}
@@ -0,0 +1,14 @@
+data "restapi_midpoint_system_status" "this" {
+  minimum_version = "4.7"
+}
+
+# Marks only exist in midPoint 4.7 and newer
+resource "restapi_midpoint_mark" "reviewed" {
+  count         = data.restapi_midpoint_system_status.this.meets_minimum_version ? 1 : 0
+  name          = "Reviewed"
+  display_label = "Reviewed"
+}
+
+output "midpoint_version" {
+  value = data.restapi_midpoint_system_status.this.version
+}
@@ -0,0 +1,10 @@
+data "restapi_midpoint_task_status" "ldap_reconciliation" {
+  oid = "7d7ad5a0-6a7b-4a53-8d0e-6d7cd5f1d4a2"
+}
+
+check "ldap_reconciliation_healthy" {
+  assert {
+    condition     = data.restapi_midpoint_task_status.ldap_reconciliation.healthy
+    error_message = "The LDAP reconciliation is ${data.restapi_midpoint_task_status.ldap_reconciliation.execution_state} with result ${data.restapi_midpoint_task_status.ldap_reconciliation.result_status}: ${data.restapi_midpoint_task_status.ldap_reconciliation.result_message}"
+  }
+}
@@ -0,0 +1,19 @@
+data "restapi_midpoint_user" "administrator" {
+  name = "administrator"
+}
+
+data "restapi_midpoint_user" "by_employee_number" {
+  filter = jsonencode({
+    equal = { path = "employeeNumber", value = "4711" }
+  })
+}
+
+# The user gets a role from this configuration only
+resource "restapi_midpoint_assignment" "approver" {
+  object_oid = data.restapi_midpoint_user.by_employee_number.oid
+  target_oid = restapi_midpoint_role.approver.oid
+}
+
+output "administrator_roles" {
+  value = [for membership in data.restapi_midpoint_user.administrator.role_membership : membership.target_oid]
+}
@@ -0,0 +1,17 @@
+data "restapi_objects" "auditors" {
+  path          = "/users"
+  search_path   = "/users/search"
+  search_method = "POST"
+  results_key   = "object/object"
+  search_data = jsonencode({
+    query = {
+      filter = {
+        equal = { path = "subtype", value = "auditor" }
+      }
+    }
+  })
+}
+
+output "auditor_names" {
+  value = { for oid, user in data.restapi_objects.auditors.objects_by_id : oid => jsondecode(user).name }
+}
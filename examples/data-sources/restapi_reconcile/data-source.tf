@@ -0,0 +1,11 @@
+data "restapi_reconcile" "nightly" {
+  object {
+    path      = "/users"
+    object_id = "00000000-0000-0000-0000-000000000002"
+    data = jsonencode({
+      name        = "administrator"
+      description = "Built-in administrator"
+    })
+    ignore_changes_to = ["*.@metadata", "metadata", "operationExecution", "iteration", "iterationToken"]
+  }
+}
@@ -0,0 +1,3 @@
+data "restapi_work_items" "my_approvals" {
+  assignee_oid = "00000000-0000-0000-0000-000000000002"
+}
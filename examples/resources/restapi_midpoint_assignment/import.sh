@@ -0,0 +1,5 @@
+# identifier: <object oid>/<target oid>/<relation>, optionally preceded by the object_path
+
+# Examples:
+terraform import restapi_midpoint_assignment.jdoe_auditor 3b3a7d9c-2f4e-4c1a-9d7e-5a1b2c3d4e5f/7d2c1f5e-8a3b-4e6d-9c0f-1b2a3c4d5e6f/default
+terraform import restapi_midpoint_assignment.meta_role /roles/7d2c1f5e-8a3b-4e6d-9c0f-1b2a3c4d5e6f/00000000-0000-0000-0000-000000000321/default
@@ -0,0 +1,17 @@
+# Each team assigns its own roles to the same user
+resource "restapi_midpoint_assignment" "jdoe_auditor" {
+  object_oid = restapi_midpoint_user.jdoe.oid
+  target_oid = restapi_midpoint_role.auditor.oid
+
+  activation {
+    valid_to = "2027-06-30T00:00:00Z"
+  }
+}
+
+resource "restapi_midpoint_assignment" "jdoe_manager" {
+  object_oid  = restapi_midpoint_user.jdoe.oid
+  target_oid  = "00000000-0000-0000-0000-000000000001"
+  target_type = "OrgType"
+  relation    = "manager"
+  description = "Head of the department"
+}
@@ -0,0 +1,2 @@
+# identifier: <oid of the mark>
+terraform import restapi_midpoint_mark.orphaned 5a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d
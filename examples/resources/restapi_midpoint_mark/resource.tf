@@ -0,0 +1,16 @@
+resource "restapi_midpoint_mark" "orphaned" {
+  name          = "Orphaned account"
+  description   = "Accounts without an owner"
+  display_label = "Orphaned"
+  display_color = "#dd4b39"
+
+  # The built-in object mark archetype
+  archetype_oids = ["00000000-0000-0000-0000-000000000700"]
+
+  policy_rule {
+    name = "orphaned"
+    policy_constraints = jsonencode({
+      situation = [{ situation = "unmatched" }]
+    })
+  }
+}
@@ -0,0 +1,2 @@
+# identifier: <type>/<name of the transport>
+terraform import restapi_midpoint_notification_transport.smtp mail/smtp
@@ -0,0 +1,27 @@
+variable "smtp_host" {}
+variable "smtp_password" {
+  sensitive = true
+}
+
+resource "restapi_midpoint_notification_transport" "smtp" {
+  name         = "smtp"
+  default_from = "midpoint@example.com"
+
+  mail_server {
+    host               = var.smtp_host
+    port               = 587
+    username           = "midpoint"
+    password           = var.smtp_password
+    transport_security = "starttlsRequired"
+  }
+}
+
+# Test environments write the messages to a file instead
+resource "restapi_midpoint_notification_transport" "sms" {
+  name             = "sms-gateway"
+  type             = "sms"
+  redirect_to_file = "/opt/midpoint/var/sms.log"
+  configuration = jsonencode({
+    gateway = [{ method = "post", urlExpression = { script = { code = "'https://sms.example.com/send'" } } }]
+  })
+}
@@ -0,0 +1,2 @@
+# identifier: <name of the global policy rule>
+terraform import restapi_midpoint_policy.sod sod-approver-requester
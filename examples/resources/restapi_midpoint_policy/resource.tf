@@ -0,0 +1,14 @@
+# Users may not have both roles at once
+resource "restapi_midpoint_policy" "sod" {
+  name       = "sod-approver-requester"
+  focus_type = "UserType"
+
+  policy_constraints = jsonencode({
+    exclusion = [{
+      targetRef = { oid = restapi_midpoint_role.approver.oid, type = "RoleType" }
+    }]
+  })
+  policy_actions = jsonencode({
+    enforcement = {}
+  })
+}
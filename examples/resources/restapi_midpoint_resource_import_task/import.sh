@@ -0,0 +1,2 @@
+# identifier: <oid of the task>
+terraform import restapi_midpoint_resource_import_task.ldap_accounts 7d7ad5a0-6a7b-4a53-8d0e-6d7cd5f1d4a2
@@ -0,0 +1,20 @@
+resource "restapi_object" "ldap" {
+  path          = "/resources"
+  data          = file("${path.module}/ldap-resource.json")
+  test_on_apply = true
+}
+
+resource "restapi_midpoint_resource_import_task" "ldap_accounts" {
+  resource_oid        = restapi_object.ldap.id
+  kind                = "account"
+  intent              = "default"
+  wait_for_completion = true
+
+  timeouts {
+    create = "1h"
+  }
+}
+
+output "imported_accounts" {
+  value = restapi_midpoint_resource_import_task.ldap_accounts.imported_count
+}
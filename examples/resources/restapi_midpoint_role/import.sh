@@ -0,0 +1,2 @@
+# identifier: <oid of the role>
+terraform import restapi_midpoint_role.auditor 7d2c1f5e-8a3b-4e6d-9c0f-1b2a3c4d5e6f
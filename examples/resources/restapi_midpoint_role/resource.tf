@@ -0,0 +1,23 @@
+resource "restapi_midpoint_role" "auditor" {
+  name         = "auditor"
+  display_name = "Auditor"
+  description  = "Reads users and roles"
+
+  archetype_oids = ["00000000-0000-0000-0000-000000000321"]
+
+  inducement {
+    target_oid = "00000000-0000-0000-0000-000000000008"
+  }
+
+  authorization {
+    name         = "read-identities"
+    actions      = ["http://midpoint.evolveum.com/xml/ns/public/security/authorization-model-3#read"]
+    object_types = ["UserType", "RoleType"]
+  }
+
+  authorization {
+    name     = "gui-access"
+    decision = "allow"
+    actions  = ["http://midpoint.evolveum.com/xml/ns/public/security/authorization-ui-3#users"]
+  }
+}
@@ -0,0 +1,2 @@
+# identifier: <oid of the user>
+terraform import restapi_midpoint_user.jdoe 3b3a7d9c-2f4e-4c1a-9d7e-5a1b2c3d4e5f
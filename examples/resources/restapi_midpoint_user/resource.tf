@@ -0,0 +1,26 @@
+resource "restapi_midpoint_user" "jdoe" {
+  name        = "jdoe"
+  given_name  = "John"
+  family_name = "Doe"
+  email       = "john.doe@example.com"
+
+  activation {
+    administrative_status = "enabled"
+    valid_to              = "2027-12-31T23:59:59Z"
+  }
+
+  assignment {
+    target_oid = "00000000-0000-0000-0000-000000000008"
+  }
+
+  assignment {
+    target_oid  = "00000000-0000-0000-0000-000000000001"
+    target_type = "OrgType"
+    relation    = "manager"
+  }
+
+  extension_namespace = "http://example.com/xml/ns/mySchema"
+  extension = {
+    costCenter = "CC-1234"
+  }
+}
@@ -0,0 +1,2 @@
+# identifier: <oid of the user>
+terraform import restapi_midpoint_user_password.jdoe 0c4b9d7e-3a1f-4c8e-9b2d-5f6a7e8c9d01
@@ -0,0 +1,9 @@
+resource "random_password" "jdoe" {
+  length = 24
+}
+
+resource "restapi_midpoint_user_password" "jdoe" {
+  user_oid     = restapi_midpoint_user.jdoe.id
+  password     = random_password.jdoe.result
+  force_change = true
+}
@@ -3,3 +3,25 @@
 # Examples:
 terraform import restapi_object.objects /api/objects
 terraform import restapi_object.object /api/objects/123
+
+# Options the resource configuration sets can follow as query parameters, so the
+# imported data leaves out the fields it ignores and the first plan is empty.
+# Supported: representation, ignore_changes_to (comma-separated), read_results_key,
+# and, for objects behind nonstandard endpoints, read_path, read_method, read_data,
+# id_attribute, query_string, read_query_string and read_search.<key>. Escape & and = in values as %26 and %3D.
+terraform import restapi_object.user '/ws/rest/users/1234?read_results_key=user&ignore_changes_to=metadata,operationExecution'
+terraform import restapi_object.legacy '/users/1234?read_path=/legacy/users/view/{id}&id_attribute=user/uid&query_string=options%3Draw'
+
+# Objects can also be imported by one of their items instead of their oid, as
+# <path>:<item>=<value> or <path>?<item>=<value>. The object is looked up with a
+# midPoint search at /<path>/search; import options can follow.
+terraform import restapi_object.jdoe 'users:name=jdoe'
+terraform import restapi_object.approver 'roles?name=Finance Approver&read_results_key=role'
+
+# A singleton, such as a fixed-oid midPoint object or a one-per-tenant configuration
+# document, is imported by its literal path with the singleton option.
+terraform import restapi_object.system_configuration '/systemConfigurations/00000000-0000-0000-0000-000000000001?singleton=true'
+
+# The imported data is the whole object as read (without the fields ignore_changes_to
+# ignores), so an import block can generate its configuration:
+#   terraform plan -generate-config-out=generated.tf
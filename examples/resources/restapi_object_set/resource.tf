@@ -0,0 +1,23 @@
+locals {
+  business_roles = {
+    finance = "Finance department"
+    sales   = "Sales team"
+    support = "Customer support"
+  }
+}
+
+# Manage a whole catalog of business roles with one resource
+resource "restapi_object_set" "business_roles" {
+  path      = "/roles"
+  page_size = 200
+
+  objects = {
+    for key, description in local.business_roles : key => jsonencode({
+      role = {
+        name        = key
+        description = description
+        subtype     = ["business"]
+      }
+    })
+  }
+}
@@ -0,0 +1,14 @@
+# Clear midPoint's caches whenever the system configuration changes
+resource "restapi_raw_request" "clear_caches" {
+  path = "/rpc/executeScript"
+  body = jsonencode({
+    executeScript = {
+      action = { type = "execute-script", parameter = [{ name = "script", value = "midpoint.clearCaches()" }] }
+    }
+  })
+  expected_status = [200, 204]
+
+  triggers = {
+    system_configuration = restapi_object.system_configuration.api_response
+  }
+}
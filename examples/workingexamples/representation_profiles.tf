@@ -0,0 +1,44 @@
+# Representation profiles example
+# The same module manages roles strictly in prod and loosely in dev:
+# the workspace only chooses which profile the resources use
+
+variable "representation" {
+  type    = string
+  default = "loose"
+}
+
+provider "restapi" {
+  uri                  = "http://midpoint-server:8080/midpoint/api"
+  write_returns_object = true
+  username             = "administrator"
+  password             = "5ecr3t"
+
+  # Manage everything in data, ignoring only server bookkeeping
+  representation {
+    name              = "strict"
+    ignore_changes_to = ["*.@metadata", "metadata", "iteration", "iterationToken"]
+    update_strategy   = "midpoint-patch"
+  }
+
+  # Manage only the names and inducements, leave the rest to other tooling
+  representation {
+    name              = "loose"
+    fields            = ["name", "displayName", "inducement"]
+    ignore_changes_to = ["re:/.*Timestamp$/"]
+    update_strategy   = "midpoint-patch"
+  }
+}
+
+resource "restapi_object" "role_example" {
+  path           = "/roles"
+  id_attribute   = "oid"
+  representation = var.representation
+
+  data = jsonencode({
+    name        = "Example Role"
+    displayName = "Example Role"
+    inducement = [
+      { targetRef = { oid = "00000000-0000-0000-0000-000000000008", type = "RoleType" } }
+    ]
+  })
+}
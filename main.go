@@ -16,4 +16,7 @@ func main() {
 			return restapi.Provider()
 		},
 	})
+
+	// Terraform stopped the provider; log what its requests cost
+	restapi.LogRequestMetrics()
 }
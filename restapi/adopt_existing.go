@@ -0,0 +1,91 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+/*
+createConflicted reports whether a create failed because the object already
+
+	exists: midPoint answers with 409 Conflict or, for some object types, with
+	an ObjectAlreadyExistsException whose message says so.
+*/
+func createConflicted(err error) bool {
+	return isConflictError(err) || (err != nil && strings.Contains(strings.ToLower(err.Error()), "already exists"))
+}
+
+/*
+adoptExistingObject takes over the object a create conflicted with: it looks
+
+	for the object having the value of data at key with a midPoint search at
+	searchPath, takes its oid and sends data to it as an update.
+*/
+func (obj *APIObject) adoptExistingObject(key string, searchPath string) error {
+	value, err := GetObjectAtKey(midpointObjectOf(obj.data), key, obj.debug)
+	if err != nil {
+		return fmt.Errorf("cannot look up the existing object: data has no '%s' to search by", key)
+	}
+	want := polyStringValue(value)
+	if want == "" {
+		want = fmt.Sprintf("%v", value)
+	}
+
+	oid, err := searchOIDByItem(obj.apiClient, searchPath, key, want, obj.debug)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[WARN] adopt_existing.go: Adopting the existing object '%s' with %s '%s' instead of creating it", oid, key, want)
+	obj.id = oid
+	if err := obj.updateObject(); err != nil {
+		return fmt.Errorf("failed to update the adopted object '%s': %w", obj.id, err)
+	}
+	return obj.readObject()
+}
+
+/*
+searchOIDByItem returns the oid of the one object having value at the item
+
+	path key, found with a midPoint search at searchPath. Values are compared
+	like conflicts are, so PolyStrings match by their orig.
+*/
+func searchOIDByItem(client *APIClient, searchPath string, key string, value string, debug bool) (string, error) {
+	search, err := json.Marshal(conflictQuery(map[string]string{key: value}))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the search for the existing object to JSON: %v", err)
+	}
+	resultString, err := client.sendRequest("POST", searchPath, string(search))
+	if err != nil {
+		return "", fmt.Errorf("failed to search the existing object at '%s': %v", searchPath, err)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(resultString), &result); err != nil {
+		return "", fmt.Errorf("failed to parse the search response from '%s': %v", searchPath, err)
+	}
+
+	/* midPoint leaves out the list entirely when nothing matched */
+	items, _ := GetObjectAtKey(result, "object/object", debug)
+	list, ok := asValueList(items)
+	if !ok && items != nil {
+		list = []interface{}{items}
+	}
+	oids := make([]string, 0)
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			object = midpointObjectOf(object)
+			if len(conflictingKeys(object, map[string]string{key: value})) > 0 {
+				oids = append(oids, stringAtKey(object, "oid"))
+			}
+		}
+	}
+	switch len(oids) {
+	case 0:
+		return "", fmt.Errorf("no existing object with %s '%s' was found at '%s'", key, value, searchPath)
+	case 1:
+		return oids[0], nil
+	}
+	return "", fmt.Errorf("%d existing objects have %s '%s' (%s); cannot tell which one is meant", len(oids), key, value, strings.Join(oids, ", "))
+}
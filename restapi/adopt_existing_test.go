@@ -0,0 +1,71 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAdoptExisting(t *testing.T) {
+	searchResponse := `{"object": {"object": [{"user": {"oid": "user-1", "name": {"orig": "jsmith", "norm": "jsmith"}}}]}}`
+	updated := ""
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/users":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error": "Object already exists"}`))
+		case r.Method == "POST" && r.URL.Path == "/users/search":
+			if !strings.Contains(string(body), `"path":"name","value":"jsmith"`) {
+				t.Errorf("adopt_existing_test.go: Unexpected search %s", body)
+			}
+			w.Write([]byte(searchResponse))
+		case r.Method == "PUT" && r.URL.Path == "/users/user-1":
+			updated = string(body)
+		case r.Method == "GET" && r.URL.Path == "/users/user-1":
+			w.Write([]byte(`{"user": {"oid": "user-1", "name": "jsmith", "fullName": "John Smith"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", writeReturnsObject: true})
+	if err != nil {
+		t.Fatalf("adopt_existing_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(adopt bool) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":           "/users",
+			"data":           `{"user": {"name": "jsmith", "fullName": "John Smith"}}`,
+			"adopt_existing": adopt,
+		})
+	}
+
+	if err := resourceRestAPICreate(newResourceData(false), client); err == nil || !strings.Contains(err.Error(), "409") {
+		t.Fatalf("adopt_existing_test.go: Expected the conflict without adopt_existing, got %v", err)
+	}
+
+	d := newResourceData(true)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("adopt_existing_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "user-1" || !strings.Contains(updated, "John Smith") {
+		t.Fatalf("adopt_existing_test.go: Expected the existing object to be adopted and updated, got '%s' and '%s'", d.Id(), updated)
+	}
+
+	/* Nothing or several objects to adopt keep the create failing */
+	for _, response := range []string{
+		`{"object": {}}`,
+		`{"object": {"object": [{"user": {"oid": "user-1", "name": "jsmith"}}, {"user": {"oid": "user-2", "name": "JSmith"}}]}}`,
+	} {
+		searchResponse = response
+		if err := resourceRestAPICreate(newResourceData(true), client); err == nil || !strings.Contains(err.Error(), "adopting the existing object failed") {
+			t.Fatalf("adopt_existing_test.go: Expected the create to fail with %s, got %v", response, err)
+		}
+	}
+}
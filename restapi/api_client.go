@@ -7,9 +7,9 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"math"
+	"mime"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -23,63 +23,113 @@ import (
 )
 
 type apiClientOpt struct {
-	uri                 string
-	insecure            bool
-	username            string
-	password            string
-	headers             map[string]string
-	timeout             int
-	idAttribute         string
-	createMethod        string
-	readMethod          string
-	readData            string
-	updateMethod        string
-	updateData          string
-	destroyMethod       string
-	destroyData         string
-	copyKeys            []string
-	writeReturnsObject  bool
-	createReturnsObject bool
-	xssiPrefix          string
-	useCookies          bool
-	rateLimit           float64
-	oauthClientID       string
-	oauthClientSecret   string
-	oauthScopes         []string
-	oauthTokenURL       string
-	oauthEndpointParams url.Values
-	certFile            string
-	keyFile             string
-	rootCAFile          string
-	certString          string
-	keyString           string
-	rootCAString        string
-	debug               bool
+	uri                   string
+	insecure              bool
+	username              string
+	password              string
+	headers               map[string]string
+	timeout               int
+	idAttribute           string
+	createMethod          string
+	readMethod            string
+	readData              string
+	updateMethod          string
+	updateStrategy        string
+	updateData            string
+	destroyMethod         string
+	destroyData           string
+	copyKeys              []string
+	defaultData           map[string]interface{}
+	correlationHeader     string
+	auditLogPath          string
+	slowRequest           time.Duration
+	largePayload          int
+	readCacheTTL          time.Duration
+	bulkRefresh           bool
+	maxResponseSize       int64
+	maxParallel           int
+	writeReturnsObject    bool
+	createReturnsObject   bool
+	xssiPrefix            string
+	accept                string
+	expectContentType     string
+	useCookies            bool
+	rateLimit             float64
+	conflictRetries       int
+	throttleRetries       int
+	concurrencyRetries    int
+	midpointPatchPerDelta bool
+	deltaListPath         string
+	createConcurrency     int
+	createJournalFile     string
+	mode                  string
+	representations       map[string]*representationProfile
+	midpointMode          bool
+	oauthClientID         string
+	oauthClientSecret     string
+	oauthScopes           []string
+	oauthTokenURL         string
+	oauthEndpointParams   url.Values
+	certFile              string
+	keyFile               string
+	rootCAFile            string
+	certString            string
+	keyString             string
+	rootCAString          string
+	debug                 bool
 }
 
 /*APIClient is a HTTP client with additional controlling fields*/
 type APIClient struct {
-	httpClient          *http.Client
-	uri                 string
-	insecure            bool
-	username            string
-	password            string
-	headers             map[string]string
-	idAttribute         string
-	createMethod        string
-	readMethod          string
-	readData            string
-	updateMethod        string
-	updateData          string
-	destroyMethod       string
-	destroyData         string
-	copyKeys            []string
-	writeReturnsObject  bool
-	createReturnsObject bool
-	xssiPrefix          string
-	rateLimiter         *rate.Limiter
-	debug               bool
-	oauthConfig         *clientcredentials.Config
+	httpClient            *http.Client
+	uri                   string
+	insecure              bool
+	username              string
+	password              string
+	headers               map[string]string
+	idAttribute           string
+	createMethod          string
+	readMethod            string
+	readData              string
+	updateMethod          string
+	updateStrategy        string
+	updateData            string
+	destroyMethod         string
+	destroyData           string
+	copyKeys              []string
+	defaultData           map[string]interface{}
+	correlationHeader     string
+	writeReturnsObject    bool
+	createReturnsObject   bool
+	xssiPrefix            string
+	accept                string
+	expectContentType     string
+	rateLimiter           *rate.Limiter
+	conflictRetries       int
+	throttleRetries       int
+	concurrencyRetries    int
+	throttle              *throttleDeferral
+	retry                 *retryPolicy
+	metrics               *requestMetrics
+	correlationID         string
+	auditLog              *auditLog
+	slowRequest           time.Duration
+	largePayload          int
+	perfWarnings          *resultWarnings
+	readCache             *readCache
+	bulkRefresh           *bulkRefresher
+	maxResponseSize       int64
+	limiter               *requestLimiter
+	midpointPatchPerDelta bool
+	deltaListPath         string
+	createScheduler       *createScheduler
+	createJournal         *createJournal
+	representations       map[string]*representationProfile
+	midpointMode          bool
+	debug                 bool
+	oauthConfig           *clientcredentials.Config
+	payloadFormat         string
+	resultWarnings        *resultWarnings
 }
 
 // NewAPIClient makes a new api client for RESTful calls
@@ -114,6 +164,12 @@ func NewAPIClient(opt *apiClientOpt) (*APIClient, error) {
 		opt.destroyMethod = "DELETE"
 	}
 
+	if opt.updateStrategy != "" {
+		if _, err := resolveUpdateStrategy(opt.updateStrategy, opt.updateMethod); err != nil {
+			return nil, err
+		}
+	}
+
 	tlsConfig := &tls.Config{
 		/* Disable TLS verification if requested */
 		InsecureSkipVerify: opt.insecure,
@@ -184,25 +240,73 @@ func NewAPIClient(opt *apiClientOpt) (*APIClient, error) {
 			Transport: tr,
 			Jar:       cookieJar,
 		},
-		rateLimiter:         rateLimiter,
-		uri:                 opt.uri,
-		insecure:            opt.insecure,
-		username:            opt.username,
-		password:            opt.password,
-		headers:             opt.headers,
-		idAttribute:         opt.idAttribute,
-		createMethod:        opt.createMethod,
-		readMethod:          opt.readMethod,
-		readData:            opt.readData,
-		updateMethod:        opt.updateMethod,
-		updateData:          opt.updateData,
-		destroyMethod:       opt.destroyMethod,
-		destroyData:         opt.destroyData,
-		copyKeys:            opt.copyKeys,
-		writeReturnsObject:  opt.writeReturnsObject,
-		createReturnsObject: opt.createReturnsObject,
-		xssiPrefix:          opt.xssiPrefix,
-		debug:               opt.debug,
+		rateLimiter:           rateLimiter,
+		uri:                   opt.uri,
+		insecure:              opt.insecure,
+		username:              opt.username,
+		password:              opt.password,
+		headers:               opt.headers,
+		idAttribute:           opt.idAttribute,
+		createMethod:          opt.createMethod,
+		readMethod:            opt.readMethod,
+		readData:              opt.readData,
+		updateMethod:          opt.updateMethod,
+		updateStrategy:        opt.updateStrategy,
+		updateData:            opt.updateData,
+		destroyMethod:         opt.destroyMethod,
+		destroyData:           opt.destroyData,
+		copyKeys:              opt.copyKeys,
+		defaultData:           opt.defaultData,
+		correlationHeader:     opt.correlationHeader,
+		slowRequest:           opt.slowRequest,
+		largePayload:          opt.largePayload,
+		readCache:             newReadCache(opt.readCacheTTL),
+		bulkRefresh:           newBulkRefresher(opt.bulkRefresh),
+		maxResponseSize:       opt.maxResponseSize,
+		limiter:               newRequestLimiter(opt.maxParallel),
+		writeReturnsObject:    opt.writeReturnsObject,
+		createReturnsObject:   opt.createReturnsObject,
+		xssiPrefix:            opt.xssiPrefix,
+		accept:                opt.accept,
+		expectContentType:     opt.expectContentType,
+		conflictRetries:       opt.conflictRetries,
+		throttleRetries:       opt.throttleRetries,
+		concurrencyRetries:    opt.concurrencyRetries,
+		throttle:              newThrottleDeferral(),
+		metrics:               newRequestMetrics(opt.uri),
+		midpointPatchPerDelta: opt.midpointPatchPerDelta,
+		deltaListPath:         opt.deltaListPath,
+		createScheduler:       newCreateScheduler(opt.createConcurrency),
+		representations:       opt.representations,
+		midpointMode:          opt.midpointMode,
+		debug:                 opt.debug,
+	}
+
+	if opt.auditLogPath != "" {
+		audit, err := newAuditLog(opt.auditLogPath)
+		if err != nil {
+			return nil, err
+		}
+		client.auditLog = audit
+	}
+
+	if opt.createJournalFile != "" {
+		journal, err := newCreateJournal(opt.createJournalFile)
+		if err != nil {
+			return nil, err
+		}
+		client.createJournal = journal
+	}
+
+	switch opt.mode {
+	case "", clientModeHTTP:
+	case clientModeMemory:
+		/* Nothing leaves the process, so there is no server to authenticate with */
+		log.Printf("api_client.go: Memory mode, requests are answered by an in-process object store")
+		client.httpClient.Transport = newMemoryStore(client.idAttribute)
+		return &client, nil
+	default:
+		return nil, fmt.Errorf("unknown mode '%s'; must be one of: %s, %s", opt.mode, clientModeHTTP, clientModeMemory)
 	}
 
 	if opt.oauthClientID != "" && opt.oauthClientSecret != "" && opt.oauthTokenURL != "" {
@@ -221,6 +325,55 @@ func NewAPIClient(opt *apiClientOpt) (*APIClient, error) {
 	return &client, nil
 }
 
+/*
+withTokenScope returns a copy of the client whose OAuth tokens are requested
+for audience (sent as the `audience` token endpoint parameter) and scopes
+instead of the ones configured in the provider, when they are set. Tokens
+are obtained from the same client credentials and token endpoint.
+*/
+func (client *APIClient) withTokenScope(audience string, scopes []string) (*APIClient, error) {
+	if client.oauthConfig == nil {
+		return nil, errors.New("token_audience and token_scopes require oauth_client_credentials to be configured in the provider")
+	}
+
+	config := *client.oauthConfig
+	if len(scopes) > 0 {
+		config.Scopes = scopes
+	}
+	if audience != "" {
+		config.EndpointParams = url.Values{}
+		for k, v := range client.oauthConfig.EndpointParams {
+			config.EndpointParams[k] = v
+		}
+		config.EndpointParams.Set("audience", audience)
+	}
+
+	scoped := *client
+	scoped.oauthConfig = &config
+	return &scoped, nil
+}
+
+/* tokenScopeKey returns the audience and scopes the client's requests are authorized with */
+func (client *APIClient) tokenScopeKey() string {
+	if client.oauthConfig == nil {
+		return ""
+	}
+	return client.oauthConfig.EndpointParams.Get("audience") + " " + strings.Join(client.oauthConfig.Scopes, " ")
+}
+
+/*
+withPayloadFormat returns a copy of the client that sends JSON request bodies
+
+	in format (see content_type), such as midPoint XML, and converts responses
+	in that format back to JSON. Payloads with their own content type, such
+	as JSON Patch documents, are sent as they are.
+*/
+func (client *APIClient) withPayloadFormat(format string) *APIClient {
+	formatClient := *client
+	formatClient.payloadFormat = format
+	return &formatClient
+}
+
 // Convert the important bits about this object to string representation
 // This is useful for debugging.
 func (client *APIClient) toString() string {
@@ -248,6 +401,119 @@ Helper function that handles sending/receiving and handling
 	of HTTP data in and out.
 */
 func (client *APIClient) sendRequest(method string, path string, data string) (string, error) {
+	return client.sendRequestWithContentType(method, path, data, "")
+}
+
+/*
+Same as sendRequest, but when contentType is set it is used for the request
+
+	body regardless of any Content-Type configured in the provider headers.
+	This is needed for payloads such as JSON Patch that have their own media type.
+*/
+func (client *APIClient) sendRequestWithContentType(method string, path string, data string, contentType string) (string, error) {
+	return client.sendRequestWithHeaders(method, path, data, contentType, nil)
+}
+
+/*
+Same as sendRequestWithContentType, but also sets extraHeaders on the request
+
+	after the provider headers, for values that are specific to one request.
+*/
+func (client *APIClient) sendRequestWithHeaders(method string, path string, data string, contentType string, extraHeaders map[string]string) (string, error) {
+	body, _, err := client.sendRequestReturningHeaders(method, path, data, contentType, extraHeaders)
+	return body, err
+}
+
+/*
+Same as sendRequestWithHeaders, but also returns the response headers, for
+
+	callers that need information such as pagination links from them.
+	Requests answered with 429 Too Many Requests are deferred and retried
+	up to throttle_retries times (see throttleDeferral).
+*/
+func (client *APIClient) sendRequestReturningHeaders(method string, path string, data string, contentType string, extraHeaders map[string]string) (string, http.Header, error) {
+	body, headers, _, err := client.sendRequestReturningStatus(method, path, data, contentType, extraHeaders)
+	return body, headers, err
+}
+
+/*
+Same as sendRequestReturningHeaders, but also returns the status code of the
+
+	response, or 0 when no response was received, for callers that check it.
+	With read_cache_ttl, GETs may be answered by the readCache.
+*/
+func (client *APIClient) sendRequestReturningStatus(method string, path string, data string, contentType string, extraHeaders map[string]string) (string, http.Header, int, error) {
+	if client.readCache != nil {
+		if method == "GET" {
+			key := readCacheKey(method, path, data, contentType, extraHeaders, client.correlationHeader, client.tokenScopeKey())
+			return client.readCache.get(key, func() (string, http.Header, int, error) {
+				return client.sendRequestRetrying(method, path, data, contentType, extraHeaders)
+			})
+		}
+		if isMutatingRequest(method, path) {
+			defer client.readCache.invalidate()
+		}
+	}
+	return client.sendRequestRetrying(method, path, data, contentType, extraHeaders)
+}
+
+/*
+sendRequestRetrying sends a request for sendRequestReturningStatus, retrying
+
+	it on throttling and midPoint concurrency errors.
+*/
+func (client *APIClient) sendRequestRetrying(method string, path string, data string, contentType string, extraHeaders map[string]string) (string, http.Header, int, error) {
+	extraHeaders, correlationID := client.correlationHeaders(extraHeaders)
+	finish := func(status int, err error) error {
+		if auditErr := client.auditLog.record(method, path, data, status, correlationID, err); auditErr != nil {
+			log.Printf("[WARN] api_client.go: Could not record %s %s in the audit log: %v", method, path, auditErr)
+		}
+		return client.logCorrelation(method, path, correlationID, status, err)
+	}
+	concurrencyAttempt := 0
+	for attempt := 0; ; attempt++ {
+		client.limiter.acquire(method, path)
+		client.throttle.begin()
+		started := time.Now()
+		body, headers, status, err := client.sendRequestOnce(method, path, data, contentType, extraHeaders)
+		latency := time.Since(started)
+		client.throttle.end()
+		client.limiter.release()
+		client.metrics.record(method, path, status, len(data), len(body), latency)
+		client.checkPerformance(method, path, len(data), len(body), latency)
+
+		/* Concurrency errors have their own count of retries */
+		if isConcurrencyError(err) && concurrencyAttempt < client.concurrencyRetries {
+			delay := client.retryDelay(concurrencyAttempt, concurrencyDelay(concurrencyAttempt), false)
+			if expired := client.retryExpired(delay); expired != nil {
+				return body, headers, status, finish(status, fmt.Errorf("%v; %v", err, expired))
+			}
+			concurrencyAttempt++
+			log.Printf("api_client.go: %s %s failed with a concurrency error (attempt %d of %d), sending it again in %s: %v",
+				method, path, concurrencyAttempt, client.concurrencyRetries+1, delay, err)
+			client.metrics.retried()
+			time.Sleep(delay)
+			attempt--
+			continue
+		}
+		if !isThrottledError(err) || attempt >= client.throttleRetries {
+			err = client.resultAsWarning(method, path, body, status, err)
+			return body, headers, status, finish(status, err)
+		}
+
+		delay := client.retryDelay(attempt, throttleDelay(attempt, headers), headers.Get("Retry-After") != "")
+		if expired := client.retryExpired(delay); expired != nil {
+			return body, headers, status, finish(status, fmt.Errorf("%v; %v", err, expired))
+		}
+		log.Printf("api_client.go: %s %s was throttled (attempt %d of %d), deferring it for %s and until other requests are done",
+			method, path, attempt+1, client.throttleRetries+1, delay)
+		client.metrics.retried()
+		client.throttle.wait(delay)
+	}
+}
+
+/* sendRequestOnce sends a single request for sendRequestReturningStatus */
+func (client *APIClient) sendRequestOnce(method string, path string, data string, contentType string, extraHeaders map[string]string) (string, http.Header, int, error) {
 	fullURI := client.uri + path
 	var req *http.Request
 	var err error
@@ -256,6 +522,13 @@ func (client *APIClient) sendRequest(method string, path string, data string) (s
 		log.Printf("api_client.go: method='%s', path='%s', full uri (derived)='%s', data='%s'\n", method, path, fullURI, data)
 	}
 
+	converts := client.payloadFormat != "" && data != "" && contentType == ""
+	if converts {
+		if data, err = encodePayload(client.payloadFormat, data); err != nil {
+			return "", nil, 0, err
+		}
+	}
+
 	buffer := bytes.NewBuffer([]byte(data))
 
 	if data == "" {
@@ -271,7 +544,7 @@ func (client *APIClient) sendRequest(method string, path string, data string) (s
 
 	if err != nil {
 		log.Fatal(err)
-		return "", err
+		return "", nil, 0, err
 	}
 
 	if client.debug {
@@ -285,6 +558,25 @@ func (client *APIClient) sendRequest(method string, path string, data string) (s
 		}
 	}
 
+	if client.accept != "" {
+		req.Header.Set("Accept", client.accept)
+	}
+
+	for n, v := range extraHeaders {
+		req.Header.Set(n, v)
+	}
+
+	if contentType != "" && data != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if client.payloadFormat != "" {
+		if converts {
+			req.Header.Set("Content-Type", payloadMediaTypes[client.payloadFormat])
+		}
+		req.Header.Set("Accept", payloadMediaTypes[client.payloadFormat])
+	}
+
 	if client.debug {
 		log.Printf("api_client.go: Request headers:")
 		for name, values := range req.Header {
@@ -299,7 +591,7 @@ func (client *APIClient) sendRequest(method string, path string, data string) (s
 		tokenSource := client.oauthConfig.TokenSource(ctx)
 		token, err := tokenSource.Token()
 		if err != nil {
-			return "", err
+			return "", nil, 0, err
 		}
 		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
 	}
@@ -337,7 +629,7 @@ func (client *APIClient) sendRequest(method string, path string, data string) (s
 
 	if err != nil {
 		//log.Printf("api_client.go: Error detected: %s\n", err)
-		return "", err
+		return "", nil, 0, err
 	}
 
 	if client.debug {
@@ -350,25 +642,104 @@ func (client *APIClient) sendRequest(method string, path string, data string) (s
 		}
 	}
 
-	bodyBytes, err2 := io.ReadAll(resp.Body)
+	rawBody, err2 := readResponseBody(resp.Body, resp.ContentLength, client.maxResponseSize)
 	resp.Body.Close()
 
 	if err2 != nil {
-		return "", err2
+		return "", resp.Header, resp.StatusCode, fmt.Errorf("%s %s: %v", method, path, err2)
 	}
-	body := strings.TrimPrefix(string(bodyBytes), client.xssiPrefix)
+	body := strings.TrimPrefix(rawBody, client.xssiPrefix)
 	if client.debug {
 		log.Printf("api_client.go: BODY:\n%s\n", body)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return body, fmt.Errorf("unexpected response code '%d': %s", resp.StatusCode, body)
+		/* Error bodies in the payload format are described as JSON, others, such as the HTML page of a proxy, as they are */
+		if client.payloadFormat != "" {
+			if decoded, err := decodePayload(client.payloadFormat, body); err == nil {
+				body = decoded
+			}
+		}
+		return body, resp.Header, resp.StatusCode, fmt.Errorf("unexpected response code '%d': %s", resp.StatusCode, describeErrorBody(body))
+	}
+
+	if err := client.checkContentType(method, fullURI, resp, body); err != nil {
+		return body, resp.Header, resp.StatusCode, err
+	}
+
+	if client.payloadFormat != "" {
+		if body, err = decodePayload(client.payloadFormat, body); err != nil {
+			return "", resp.Header, resp.StatusCode, err
+		}
 	}
 
 	if body == "" {
-		return "{}", nil
+		return "{}", resp.Header, resp.StatusCode, nil
+	}
+
+	return body, resp.Header, resp.StatusCode, nil
+
+}
+
+/*
+checkContentType fails when a successful response body is not of expect_content_type,
+
+	or, when that is not set, of the media type of the payload format,
+	naming what came back instead.
+	Proxies answering with HTML login or error pages otherwise surface as
+	JSON parse errors far from the request.
+*/
+func (client *APIClient) checkContentType(method string, uri string, resp *http.Response, body string) error {
+	expected := client.expectContentType
+	if expected == "" && client.payloadFormat != "" {
+		expected = payloadMediaTypes[client.payloadFormat]
+	}
+	if expected == "" || body == "" {
+		return nil
+	}
+
+	actual := resp.Header.Get("Content-Type")
+	if mediaTypeMatches(actual, expected) {
+		return nil
 	}
 
-	return body, nil
+	excerpt := body
+	if len(excerpt) > 200 {
+		excerpt = excerpt[:200] + "..."
+	}
+	hint := ""
+	if mediaTypeMatches(actual, "text/html") {
+		hint = " An HTML page usually means a proxy or single sign-on gateway answered instead of the API, for example with a login page; check the uri and the credentials."
+	}
+	return fmt.Errorf("unexpected content type '%s' in the response code '%d' to %s %s, expected '%s'.%s Response: %s", actual, resp.StatusCode, method, uri, expected, hint, excerpt)
+}
+
+/*
+mediaTypeMatches reports whether the Content-Type header actual is of the
+
+	media type expected, ignoring parameters such as charset. Structured
+	syntax suffixes match too, so application/problem+json is JSON.
+*/
+func mediaTypeMatches(actual string, expected string) bool {
+	actualType, _, err := mime.ParseMediaType(actual)
+	if err != nil {
+		return false
+	}
+	expectedType, _, err := mime.ParseMediaType(expected)
+	if err != nil {
+		return false
+	}
+	if actualType == expectedType {
+		return true
+	}
+	i := strings.Index(expectedType, "/")
+	return i > 0 && strings.HasPrefix(actualType, expectedType[:i+1]) && strings.HasSuffix(actualType, "+"+expectedType[i+1:])
+}
+
+/* Wait between conflict retries; grows linearly with the attempt number */
+var conflictRetryDelay = 500 * time.Millisecond
 
+// isConflictError reports whether err was caused by the server answering 409 Conflict
+func isConflictError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unexpected response code '409'")
 }
@@ -8,11 +8,15 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"log"
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -240,3 +244,121 @@ func generateCertificates() {
 	rootCAPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootCABytes})
 	_ = os.WriteFile(rootCAFilePath, rootCAPEM, 0644)
 }
+
+func TestAPIClientTokenScope(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			/* Hand out tokens naming what they were requested for */
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"%s|%s","token_type":"bearer"}`, r.Form.Get("audience"), r.Form.Get("scope"))
+		default:
+			w.Write([]byte(r.Header.Get("Authorization")))
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{
+		uri:                 svr.URL,
+		timeout:             2,
+		oauthClientID:       "terraform",
+		oauthClientSecret:   "secret",
+		oauthTokenURL:       svr.URL + "/token",
+		oauthScopes:         []string{"midpoint"},
+		oauthEndpointParams: url.Values{"audience": []string{"midpoint"}},
+	})
+	if err != nil {
+		t.Fatalf("api_client_test.go: Failed to create API client: %s", err)
+	}
+
+	scoped, err := client.withTokenScope("scim-gateway", []string{"scim.read", "scim.write"})
+	if err != nil {
+		t.Fatalf("api_client_test.go: Failed to scope the client: %s", err)
+	}
+
+	res, err := scoped.sendRequest("GET", "/Users", "")
+	if err != nil {
+		t.Fatalf("api_client_test.go: %s", err)
+	}
+	if res != "Bearer scim-gateway|scim.read scim.write" {
+		t.Fatalf("api_client_test.go: Expected a token for the SCIM gateway, got '%s'", res)
+	}
+
+	/* The provider's client keeps requesting the tokens it was configured with */
+	res, err = client.sendRequest("GET", "/users", "")
+	if err != nil {
+		t.Fatalf("api_client_test.go: %s", err)
+	}
+	if res != "Bearer midpoint|midpoint" {
+		t.Fatalf("api_client_test.go: Expected a token for midPoint, got '%s'", res)
+	}
+
+	noOAuth, _ := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 2})
+	if _, err := noOAuth.withTokenScope("scim-gateway", nil); err == nil {
+		t.Fatalf("api_client_test.go: Expected scoping a client without OAuth to fail")
+	}
+}
+
+func TestAPIClientContentType(t *testing.T) {
+	var lastAccept string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastAccept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><body>Please sign in</body></html>"))
+		case "/users/gone":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<html><body>Not Found</body></html>"))
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.Write([]byte(`{"title": "Not Found"}`))
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"user": {"name": "jsmith"}}`))
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{
+		uri:               svr.URL,
+		timeout:           2,
+		headers:           map[string]string{"Accept": "*/*"},
+		accept:            "application/json",
+		expectContentType: "application/json",
+	})
+	if err != nil {
+		t.Fatalf("api_client_test.go: Failed to create API client: %s", err)
+	}
+
+	for _, path := range []string{"/users/1", "/problem", "/empty"} {
+		if _, err := client.sendRequest("GET", path, ""); err != nil {
+			t.Fatalf("api_client_test.go: Expected the response to %s to be accepted, got %s", path, err)
+		}
+	}
+	if lastAccept != "application/json" {
+		t.Fatalf("api_client_test.go: Expected accept to override the Accept header, got '%s'", lastAccept)
+	}
+
+	_, err = client.sendRequest("GET", "/login", "")
+	if err == nil || !strings.Contains(err.Error(), "unexpected content type 'text/html; charset=utf-8'") || !strings.Contains(err.Error(), "login page") || !strings.Contains(err.Error(), "Please sign in") {
+		t.Fatalf("api_client_test.go: Expected the HTML login page to be reported, got %v", err)
+	}
+
+	/* Only successful responses are checked, so an HTML 404 page still means the object is gone */
+	_, err = client.sendRequest("GET", "/users/gone", "")
+	if err == nil || !strings.Contains(err.Error(), "unexpected response code '404'") {
+		t.Fatalf("api_client_test.go: Expected the response code of an HTML error page to be reported, got %v", err)
+	}
+	obj, err := NewAPIObject(client, &apiObjectOpts{path: "/users", id: "gone"})
+	if err != nil {
+		t.Fatalf("api_client_test.go: Failed to create API object: %s", err)
+	}
+	if err := obj.readObject(); err != nil || obj.id != "" {
+		t.Fatalf("api_client_test.go: Expected an object answered with an HTML 404 page to be gone, got id '%s' (%v)", obj.id, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"reflect"
 	"strings"
@@ -14,53 +15,128 @@ import (
 )
 
 type apiObjectOpts struct {
-	path          string
-	getPath       string
-	postPath      string
-	putPath       string
-	createMethod  string
-	readMethod    string
-	readData      string
-	updateMethod  string
-	updateData    string
-	destroyMethod string
-	destroyData   string
-	deletePath    string
-	searchPath    string
-	queryString   string
-	debug         bool
-	readSearch    map[string]string
-	id            string
-	idAttribute   string
-	data          string
+	path           string
+	getPath        string
+	postPath       string
+	putPath        string
+	patchPath      string
+	createMethod   string
+	readMethod     string
+	readData       string
+	updateMethod   string
+	updateStrategy string
+	updateData     string
+	destroyMethod  string
+	destroyData    string
+	previousData   string
+	deletePath     string
+	searchPath     string
+	queryString    string
+	queryStrings   map[string]string
+	debug          bool
+	readSearch     map[string]string
+	readResultsKey string
+	wrapperKey     string
+	requestWrapper string
+	readFieldPaths map[string]string
+	listMergeKeys  map[string]string
+	pathNamespaces map[string]string
+	pagination     *paginationOpts
+	shallowRead    *shallowReadOpts
+	binaryFields   []binaryField
+	normalize      *normalizeRules
+	protectedPaths []string
+	managedFields  []string
+	updateOptions  []string
+	precondition   string
+	recompute      bool
+	postCreate     []postCreateRequest
+	testOnApply    bool
+	upsert         bool
+	warnPartial    bool
+	copyKeys       []string
+	retry          *retryPolicy
+	readInclude    []string
+	readExclude    []string
+	singleton      bool
+	waitForTask    bool
+	taskInterval   time.Duration
+	tasksPath      string
+	taskTimeout    time.Duration
+	destroyOptions []string
+	tokenAudience  string
+	tokenScopes    []string
+	contentType    string
+	createPriority int
+	id             string
+	idAttribute    string
+	data           string
 }
 
 /*APIObject is the state holding struct for a restapi_object resource*/
 type APIObject struct {
-	apiClient     *APIClient
-	getPath       string
-	postPath      string
-	putPath       string
-	createMethod  string
-	readMethod    string
-	updateMethod  string
-	destroyMethod string
-	deletePath    string
-	searchPath    string
-	queryString   string
-	debug         bool
-	readSearch    map[string]string
-	id            string
-	idAttribute   string
+	apiClient      *APIClient
+	getPath        string
+	postPath       string
+	putPath        string
+	patchPath      string
+	createMethod   string
+	readMethod     string
+	updateMethod   string
+	updateStrategy string
+	destroyMethod  string
+	deletePath     string
+	searchPath     string
+	queryString    string
+	queryStrings   map[string]string
+	debug          bool
+	readSearch     map[string]string
+	readResultsKey string
+	wrapperKey     string
+	requestWrapper string
+	readFieldPaths map[string]string
+	listMergeKeys  map[string]string
+	pathNamespaces map[string]string
+	pagination     *paginationOpts
+	shallowRead    *shallowReadOpts
+	binaryFields   []binaryField
+	normalize      *normalizeRules
+	protectedPaths []string
+	managedFields  []string /* Paths Terraform manages; everything else is left out of comparisons and writes */
+	updateOptions  []string
+	precondition   string
+	recompute      bool
+	postCreate     []postCreateRequest
+	testOnApply    bool
+	upsert         bool
+	warnings       *resultWarnings
+	perfWarnings   *resultWarnings
+	readInclude    []string
+	readExclude    []string
+	copyKeys       []string
+	waitForTask    bool
+	taskInterval   time.Duration
+	tasksPath      string
+	taskTimeout    time.Duration
+	destroyOptions []string
+	tokenAudience  string
+	tokenScopes    []string
+	contentType    string
+	createPriority int
+	id             string
+	idAttribute    string
 
 	/* Set internally */
 	data            map[string]interface{} /* Data as managed by the user */
 	readData        map[string]interface{} /* Read data as managed by the user */
 	updateData      map[string]interface{} /* Update data as managed by the user */
 	destroyData     map[string]interface{} /* Destroy data as managed by the user */
+	previousData    map[string]interface{} /* Data of the previous apply, whose keys patches may remove */
 	apiData         map[string]interface{} /* Data as available from the API */
 	apiResponse     string
+	appliedDeltas   []string /* Modifications sent by the last update, as JSON */
 	ignoreChangesTo []string /* Fields to ignore when detecting changes */
+	objectType      string   /* midPoint type of the objects at path, for ObjectDeltaListType requests */
 }
 
 // NewAPIObject makes an APIobject to manage a RESTful object in an API
@@ -93,6 +169,9 @@ func NewAPIObject(iClient *APIClient, opts *apiObjectOpts) (*APIObject, error) {
 	if opts.updateData == "" {
 		opts.updateData = iClient.updateData
 	}
+	if opts.updateStrategy == "" {
+		opts.updateStrategy = iClient.updateStrategy
+	}
 	if opts.destroyMethod == "" {
 		opts.destroyMethod = iClient.destroyMethod
 	}
@@ -102,40 +181,160 @@ func NewAPIObject(iClient *APIClient, opts *apiObjectOpts) (*APIObject, error) {
 	if opts.postPath == "" {
 		opts.postPath = opts.path
 	}
+	/* A singleton is the one object at path, so every request goes to path itself */
+	if opts.singleton {
+		for _, p := range []*string{&opts.getPath, &opts.putPath, &opts.deletePath} {
+			if *p == "" {
+				*p = opts.path
+			}
+		}
+	}
 	if opts.getPath == "" {
 		opts.getPath = opts.path + "/{id}"
 	}
 	if opts.putPath == "" {
 		opts.putPath = opts.path + "/{id}"
 	}
+	if opts.patchPath == "" {
+		opts.patchPath = opts.putPath
+	}
 	if opts.deletePath == "" {
 		opts.deletePath = opts.path + "/{id}"
 	}
 	if opts.searchPath == "" {
 		opts.searchPath = opts.path
 	}
+	if opts.tasksPath == "" {
+		opts.tasksPath = "/tasks"
+	}
+	/* Responses hold the object in the envelope requests put it in */
+	if opts.wrapperKey == "" {
+		opts.wrapperKey = opts.requestWrapper
+	}
+	if opts.taskInterval == 0 {
+		opts.taskInterval = taskPollInterval
+	}
+	if opts.taskTimeout == 0 {
+		opts.taskTimeout = 30 * time.Minute
+	}
+
+	/* An upsert creates and updates with a PUT overwriting the object at its id */
+	if opts.upsert {
+		opts.createMethod = "PUT"
+		opts.postPath = opts.putPath
+		opts.updateMethod = "PUT"
+		opts.updateStrategy = updateStrategyPut
+		if !containsString(opts.updateOptions, "overwrite") {
+			opts.updateOptions = append(opts.updateOptions, "overwrite")
+		}
+	}
+
+	updateStrategy, err := resolveUpdateStrategy(opts.updateStrategy, opts.updateMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	/* A resource calling a service that needs a differently scoped token
+	   gets its own copy of the client requesting such tokens */
+	if opts.tokenAudience != "" || len(opts.tokenScopes) > 0 {
+		iClient, err = iClient.withTokenScope(opts.tokenAudience, opts.tokenScopes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	/* Operation results that only partially failed are collected as warnings */
+	var warnings *resultWarnings
+	if opts.warnPartial {
+		iClient, warnings = iClient.withResultWarnings()
+	}
+
+	/* Slow requests and large payloads are reported with the object */
+	var perfWarnings *resultWarnings
+	iClient, perfWarnings = iClient.withPerformanceWarnings()
+
+	/* All requests of the operation share a correlation id */
+	iClient = iClient.withCorrelationID()
+
+	/* The resource's retry block replaces the retries of the provider */
+	if opts.retry != nil {
+		iClient = iClient.withRetryPolicy(opts.retry)
+	}
+
+	/* The resource's copy_keys replace the ones of the provider */
+	copyKeys := opts.copyKeys
+	if len(copyKeys) == 0 {
+		copyKeys = iClient.copyKeys
+	}
+
+	switch opts.contentType {
+	case "", contentTypeJSONPayload:
+	case contentTypeXMLPayload, contentTypeYAMLPayload:
+		iClient = iClient.withPayloadFormat(opts.contentType)
+	default:
+		return nil, fmt.Errorf("unknown content_type '%s'; must be one of: %s", opts.contentType, strings.Join(payloadFormats, ", "))
+	}
+
+	/* Objects at paths of no known midPoint type cannot be sent to delta_list_path */
+	objectType, _ := objectTypeForPath(opts.path)
 
 	obj := APIObject{
-		apiClient:     iClient,
-		getPath:       opts.getPath,
-		postPath:      opts.postPath,
-		putPath:       opts.putPath,
-		createMethod:  opts.createMethod,
-		readMethod:    opts.readMethod,
-		updateMethod:  opts.updateMethod,
-		destroyMethod: opts.destroyMethod,
-		deletePath:    opts.deletePath,
-		searchPath:    opts.searchPath,
-		queryString:   opts.queryString,
-		debug:         opts.debug,
-		readSearch:    opts.readSearch,
-		id:            opts.id,
-		idAttribute:   opts.idAttribute,
-		data:          make(map[string]interface{}),
-		readData:      make(map[string]interface{}),
-		updateData:    make(map[string]interface{}),
-		destroyData:   make(map[string]interface{}),
-		apiData:       make(map[string]interface{}),
+		apiClient:      iClient,
+		getPath:        opts.getPath,
+		postPath:       opts.postPath,
+		putPath:        opts.putPath,
+		patchPath:      opts.patchPath,
+		createMethod:   opts.createMethod,
+		readMethod:     opts.readMethod,
+		updateMethod:   opts.updateMethod,
+		updateStrategy: updateStrategy,
+		destroyMethod:  opts.destroyMethod,
+		deletePath:     opts.deletePath,
+		searchPath:     opts.searchPath,
+		queryString:    opts.queryString,
+		queryStrings:   opts.queryStrings,
+		debug:          opts.debug,
+		readSearch:     opts.readSearch,
+		readResultsKey: opts.readResultsKey,
+		wrapperKey:     opts.wrapperKey,
+		requestWrapper: opts.requestWrapper,
+		readFieldPaths: opts.readFieldPaths,
+		listMergeKeys:  opts.listMergeKeys,
+		pathNamespaces: opts.pathNamespaces,
+		pagination:     opts.pagination,
+		shallowRead:    opts.shallowRead,
+		binaryFields:   opts.binaryFields,
+		normalize:      opts.normalize,
+		protectedPaths: opts.protectedPaths,
+		managedFields:  opts.managedFields,
+		updateOptions:  opts.updateOptions,
+		precondition:   opts.precondition,
+		recompute:      opts.recompute,
+		postCreate:     opts.postCreate,
+		testOnApply:    opts.testOnApply,
+		upsert:         opts.upsert,
+		warnings:       warnings,
+		perfWarnings:   perfWarnings,
+		readInclude:    opts.readInclude,
+		readExclude:    opts.readExclude,
+		copyKeys:       copyKeys,
+		waitForTask:    opts.waitForTask,
+		taskInterval:   opts.taskInterval,
+		tasksPath:      opts.tasksPath,
+		taskTimeout:    opts.taskTimeout,
+		destroyOptions: opts.destroyOptions,
+		tokenAudience:  opts.tokenAudience,
+		tokenScopes:    opts.tokenScopes,
+		contentType:    opts.contentType,
+		createPriority: opts.createPriority,
+		objectType:     objectType,
+		id:             opts.id,
+		idAttribute:    opts.idAttribute,
+		data:           make(map[string]interface{}),
+		readData:       make(map[string]interface{}),
+		updateData:     make(map[string]interface{}),
+		destroyData:    make(map[string]interface{}),
+		apiData:        make(map[string]interface{}),
 	}
 
 	if opts.data != "" {
@@ -143,10 +342,13 @@ func NewAPIObject(iClient *APIClient, opts *apiObjectOpts) (*APIObject, error) {
 			log.Printf("api_object.go: Parsing data: '%s'", opts.data)
 		}
 
-		err := json.Unmarshal([]byte(opts.data), &obj.data)
+		err := unmarshalJSON([]byte(opts.data), &obj.data)
 		if err != nil {
 			return &obj, fmt.Errorf("api_object.go: error parsing data provided: %v", err.Error())
 		}
+		if len(iClient.defaultData) > 0 {
+			mergeDefaultData(obj.data, iClient.defaultData)
+		}
 
 		/* Opportunistically set the object's ID if it is provided in the data.
 		   If it is not set, we will get it later in synchronize_state */
@@ -166,12 +368,26 @@ func NewAPIObject(iClient *APIClient, opts *apiObjectOpts) (*APIObject, error) {
 		}
 	}
 
+	if err := obj.expandPathTemplates(); err != nil {
+		return &obj, err
+	}
+
+	/* Without an id in its data, a singleton is known by its path */
+	if obj.id == "" && opts.singleton {
+		obj.id = opts.path
+	}
+
+	/* Binary properties are configured apart from data so they stay out of state */
+	if err := insertBinaryFields(obj.data, obj.binaryFields, obj.debug); err != nil {
+		return &obj, err
+	}
+
 	if opts.readData != "" {
 		if opts.debug {
 			log.Printf("api_object.go: Parsing read data: '%s'", opts.readData)
 		}
 
-		err := json.Unmarshal([]byte(opts.readData), &obj.readData)
+		err := unmarshalJSON([]byte(opts.readData), &obj.readData)
 		if err != nil {
 			return &obj, fmt.Errorf("api_object.go: error parsing read data provided: %v", err.Error())
 		}
@@ -182,7 +398,7 @@ func NewAPIObject(iClient *APIClient, opts *apiObjectOpts) (*APIObject, error) {
 			log.Printf("api_object.go: Parsing update data: '%s'", opts.updateData)
 		}
 
-		err := json.Unmarshal([]byte(opts.updateData), &obj.updateData)
+		err := unmarshalJSON([]byte(opts.updateData), &obj.updateData)
 		if err != nil {
 			return &obj, fmt.Errorf("api_object.go: error parsing update data provided: %v", err.Error())
 		}
@@ -193,12 +409,20 @@ func NewAPIObject(iClient *APIClient, opts *apiObjectOpts) (*APIObject, error) {
 			log.Printf("api_object.go: Parsing destroy data: '%s'", opts.destroyData)
 		}
 
-		err := json.Unmarshal([]byte(opts.destroyData), &obj.destroyData)
+		err := unmarshalJSON([]byte(opts.destroyData), &obj.destroyData)
 		if err != nil {
 			return &obj, fmt.Errorf("api_object.go: error parsing destroy data provided: %v", err.Error())
 		}
 	}
 
+	/* Without valid previous data, the patch strategies remove nothing */
+	if opts.previousData != "" {
+		if err := unmarshalJSON([]byte(opts.previousData), &obj.previousData); err != nil {
+			log.Printf("api_object.go: Ignoring the previous data, which is invalid: %v", err)
+			obj.previousData = nil
+		}
+	}
+
 	if opts.debug {
 		log.Printf("api_object.go: Constructed object: %s", obj.toString())
 	}
@@ -213,14 +437,43 @@ func (obj *APIObject) toString() string {
 	buffer.WriteString(fmt.Sprintf("get_path: %s\n", obj.getPath))
 	buffer.WriteString(fmt.Sprintf("post_path: %s\n", obj.postPath))
 	buffer.WriteString(fmt.Sprintf("put_path: %s\n", obj.putPath))
+	buffer.WriteString(fmt.Sprintf("patch_path: %s\n", obj.patchPath))
 	buffer.WriteString(fmt.Sprintf("delete_path: %s\n", obj.deletePath))
 	buffer.WriteString(fmt.Sprintf("query_string: %s\n", obj.queryString))
+	buffer.WriteString(fmt.Sprintf("operation_query_strings: %v\n", obj.queryStrings))
 	buffer.WriteString(fmt.Sprintf("create_method: %s\n", obj.createMethod))
 	buffer.WriteString(fmt.Sprintf("read_method: %s\n", obj.readMethod))
 	buffer.WriteString(fmt.Sprintf("update_method: %s\n", obj.updateMethod))
+	buffer.WriteString(fmt.Sprintf("update_strategy: %s\n", obj.updateStrategy))
 	buffer.WriteString(fmt.Sprintf("destroy_method: %s\n", obj.destroyMethod))
 	buffer.WriteString(fmt.Sprintf("debug: %t\n", obj.debug))
 	buffer.WriteString(fmt.Sprintf("read_search: %s\n", spew.Sdump(obj.readSearch)))
+	buffer.WriteString(fmt.Sprintf("read_results_key: %s\n", obj.readResultsKey))
+	buffer.WriteString(fmt.Sprintf("response_wrapper_key: %s\n", obj.wrapperKey))
+	buffer.WriteString(fmt.Sprintf("request_wrapper_key: %s\n", obj.requestWrapper))
+	buffer.WriteString(fmt.Sprintf("read_field_paths: %s\n", spew.Sdump(obj.readFieldPaths)))
+	buffer.WriteString(fmt.Sprintf("list_merge_keys: %s\n", spew.Sdump(obj.listMergeKeys)))
+	buffer.WriteString(fmt.Sprintf("path_namespaces: %s\n", spew.Sdump(obj.pathNamespaces)))
+	buffer.WriteString(fmt.Sprintf("shallow_read: %s\n", spew.Sdump(obj.shallowRead)))
+	buffer.WriteString(fmt.Sprintf("normalize: %s\n", spew.Sdump(obj.normalize)))
+	buffer.WriteString(fmt.Sprintf("protected_fields: %s\n", strings.Join(obj.protectedPaths, ", ")))
+	buffer.WriteString(fmt.Sprintf("managed_fields: %s\n", strings.Join(obj.managedFields, ", ")))
+	buffer.WriteString(fmt.Sprintf("update_options: %s\n", strings.Join(obj.updateOptions, ", ")))
+	buffer.WriteString(fmt.Sprintf("update_precondition: %s\n", obj.precondition))
+	buffer.WriteString(fmt.Sprintf("recompute_after_write: %t\n", obj.recompute))
+	buffer.WriteString(fmt.Sprintf("post_create_requests: %d\n", len(obj.postCreate)))
+	buffer.WriteString(fmt.Sprintf("test_on_apply: %t\n", obj.testOnApply))
+	buffer.WriteString(fmt.Sprintf("upsert: %t\n", obj.upsert))
+	buffer.WriteString(fmt.Sprintf("warn_on_partial_error: %t\n", obj.warnings != nil))
+	buffer.WriteString(fmt.Sprintf("copy_keys: %v\n", obj.copyKeys))
+	buffer.WriteString(fmt.Sprintf("read_include: %v\n", obj.readInclude))
+	buffer.WriteString(fmt.Sprintf("read_exclude: %v\n", obj.readExclude))
+	buffer.WriteString(fmt.Sprintf("wait_for_task: %t\n", obj.waitForTask))
+	buffer.WriteString(fmt.Sprintf("destroy_options: %s\n", strings.Join(obj.destroyOptions, ", ")))
+	buffer.WriteString(fmt.Sprintf("token_audience: %s\n", obj.tokenAudience))
+	buffer.WriteString(fmt.Sprintf("token_scopes: %s\n", strings.Join(obj.tokenScopes, ", ")))
+	buffer.WriteString(fmt.Sprintf("content_type: %s\n", obj.contentType))
+	buffer.WriteString(fmt.Sprintf("binary_fields: %s\n", strings.Join(binaryPaths(obj.binaryFields), ", ")))
 	buffer.WriteString(fmt.Sprintf("data: %s\n", spew.Sdump(obj.data)))
 	buffer.WriteString(fmt.Sprintf("read_data: %s\n", spew.Sdump(obj.readData)))
 	buffer.WriteString(fmt.Sprintf("update_data: %s\n", spew.Sdump(obj.updateData)))
@@ -240,12 +493,8 @@ func (obj *APIObject) updateState(state string) error {
 		log.Printf("api_object.go: Updating API object state to '%s'\n", state)
 	}
 
-	/* Other option - Decode as JSON Numbers instead of golang datatypes
-	d := json.NewDecoder(strings.NewReader(res_str))
-	d.UseNumber()
-	err = d.Decode(&obj.api_data)
-	*/
-	err := json.Unmarshal([]byte(state), &obj.apiData)
+	/* Numbers are decoded as json.Number, so they are stored as the server wrote them */
+	err := unmarshalJSON([]byte(state), &obj.apiData)
 	if err != nil {
 		return err
 	}
@@ -253,6 +502,13 @@ func (obj *APIObject) updateState(state string) error {
 	/* Store response body for parsing via jsondecode() */
 	obj.apiResponse = state
 
+	/* The object is compared without the envelope of the response */
+	if obj.wrapperKey != "" {
+		if obj.apiData, err = obj.unwrapResponse(obj.apiData); err != nil {
+			return err
+		}
+	}
+
 	/* A usable ID was not passed (in constructor or here),
 	   so we have to guess what it is from the data structure */
 	if obj.id == "" {
@@ -266,12 +522,12 @@ func (obj *APIObject) updateState(state string) error {
 	}
 
 	/* Any keys that come from the data we want to copy are done here */
-	if len(obj.apiClient.copyKeys) > 0 {
-		for _, key := range obj.apiClient.copyKeys {
+	if len(obj.copyKeys) > 0 {
+		for _, key := range obj.copyKeys {
 			if obj.debug {
-				log.Printf("api_object.go: Copying key '%s' from api_data (%v) to data (%v)\n", key, obj.apiData[key], obj.data[key])
+				log.Printf("api_object.go: Copying key '%s' from api_data to data\n", key)
 			}
-			obj.data[key] = obj.apiData[key]
+			copyKey(obj.data, obj.apiData, key)
 		}
 	} else if obj.debug {
 		log.Printf("api_object.go: copy_keys is empty - not attempting to copy data")
@@ -284,6 +540,9 @@ func (obj *APIObject) updateState(state string) error {
 }
 
 func (obj *APIObject) createObject() error {
+	if obj.id == "" && obj.upsert {
+		return fmt.Errorf("upsert needs the id of the object to PUT it; include it in the object's data or set generate_oid")
+	}
 	/* Failsafe: The constructor should prevent this situation, but
 	   protect here also. If no id is set, and the API does not respond
 	   with the id of whatever gets created, we have no way to know what
@@ -292,27 +551,52 @@ func (obj *APIObject) createObject() error {
 		return fmt.Errorf("provided object does not have an id set and the client is not configured to read the object from a POST or PUT response; please set write_returns_object to true, or include an id in the object's data")
 	}
 
-	// Filter ignored fields from the data before sending
-	dataToSend := obj.data
+	// Filter ignored and unmanaged fields from the data before sending
+	dataToSend := obj.managedOnly(obj.data)
 	if len(obj.ignoreChangesTo) > 0 {
-		dataToSend = filterIgnoredFields(obj.data, obj.ignoreChangesTo)
+		dataToSend = filterIgnoredFields(dataToSend, obj.ignoreChangesTo)
 		if obj.debug {
 			log.Printf("api_object.go: Filtered ignored fields for CREATE operation")
 		}
 	}
 
-	b, _ := json.Marshal(dataToSend)
+	b, _ := json.Marshal(obj.wrapRequest(dataToSend))
 
-	postPath := obj.postPath
-	if obj.queryString != "" {
-		if obj.debug {
-			log.Printf("api_object.go: Adding query string '%s'", obj.queryString)
+	/* Wait for creates of lower priority (parents) to complete first */
+	obj.apiClient.createScheduler.acquire(obj.createPriority)
+	defer obj.apiClient.createScheduler.release(obj.createPriority)
+
+	postPath := obj.withQueryString(obj.postPath, "create")
+
+	if obj.upsert {
+		postPath = withExecuteOptions(postPath, []string{"overwrite"})
+	}
+
+	postPath = strings.Replace(postPath, "{id}", obj.id, -1)
+
+	var resultString string
+	var err error
+	if obj.apiClient.createJournal != nil {
+		adopted, err := obj.adoptJournaledCreate(postPath, string(b))
+		if err != nil || adopted {
+			return err
+		}
+		resultString, err = obj.sendJournaledCreate(postPath, string(b))
+		if err != nil {
+			return err
+		}
+	} else {
+		resultString, err = obj.apiClient.sendRequest(obj.createMethod, postPath, string(b))
+		if err != nil {
+			return err
 		}
-		postPath = fmt.Sprintf("%s?%s", obj.postPath, obj.queryString)
 	}
 
-	resultString, err := obj.apiClient.sendRequest(obj.createMethod, strings.Replace(postPath, "{id}", obj.id, -1), string(b))
-	if err != nil {
+	/* A create handed to an approval process did not create anything yet */
+	if caseOID := pendingApprovalCase(resultString); caseOID != "" {
+		return &approvalPendingError{id: obj.id, caseOID: caseOID}
+	}
+	if err := obj.waitForSpawnedTask(resultString); err != nil {
 		return err
 	}
 
@@ -323,6 +607,9 @@ func (obj *APIObject) createObject() error {
 				obj.apiClient.writeReturnsObject, obj.apiClient.createReturnsObject)
 		}
 		err = obj.updateState(resultString)
+		if err == nil && obj.id != "" {
+			err = obj.readFieldGroups()
+		}
 		/* Yet another failsafe. In case something terrible went wrong internally,
 		   bail out so the user at least knows that the ID did not get set. */
 		if obj.id == "" {
@@ -338,23 +625,86 @@ func (obj *APIObject) createObject() error {
 	return err
 }
 
+/*
+adoptJournaledCreate checks whether the create journal holds an unfinished
+
+	create of the same payload from an earlier run. If so, the object is looked
+	up by its id or with read_search and, when found, adopted instead of being
+	created again.
+*/
+func (obj *APIObject) adoptJournaledCreate(postPath string, payload string) (bool, error) {
+	journal := obj.apiClient.createJournal
+	entry, pending := journal.pending(postPath, payload)
+	if !pending {
+		return false, nil
+	}
+
+	log.Printf("api_object.go: Found unfinished create at '%s' from %s in the create journal; looking for an existing object", postPath, entry.Started)
+
+	if obj.id == "" {
+		searchKey := obj.readSearch["search_key"]
+		searchValue := obj.readSearch["search_value"]
+		if searchKey == "" || searchValue == "" {
+			log.Printf("api_object.go: Cannot look up the object without an id or read_search; sending the create again")
+			return false, nil
+		}
+		if _, err := obj.findObject(obj.readSearch["query_string"], searchKey, searchValue, obj.readSearch["results_key"], ""); err != nil {
+			log.Printf("api_object.go: Object was not found (%v); sending the create again", err)
+			obj.id = ""
+			return false, nil
+		}
+	}
+
+	id := obj.id
+	if err := obj.readObject(); err != nil {
+		return false, err
+	}
+	if obj.id == "" {
+		log.Printf("api_object.go: Object '%s' does not exist; sending the create again", id)
+		obj.id = id
+		return false, nil
+	}
+
+	log.Printf("api_object.go: Adopting object '%s' created by an interrupted run", obj.id)
+	return true, journal.complete(entry)
+}
+
+/*
+sendJournaledCreate sends a create recorded in the create journal. The entry
+
+	is removed once the server answered; it is only left behind when the
+	outcome is unknown, such as on a timeout or a crash.
+*/
+func (obj *APIObject) sendJournaledCreate(postPath string, payload string) (string, error) {
+	journal := obj.apiClient.createJournal
+	previous, _ := journal.pending(postPath, payload)
+	entry, err := journal.begin(postPath, payload, previous.IdempotencyKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to record create in the create journal: %v", err)
+	}
+
+	resultString, err := obj.apiClient.sendRequestWithHeaders(obj.createMethod, postPath, payload, "", map[string]string{idempotencyKeyHeader: entry.IdempotencyKey})
+	if err != nil && !strings.Contains(err.Error(), "unexpected response code") {
+		return resultString, err
+	}
+
+	if jerr := journal.complete(entry); jerr != nil {
+		log.Printf("api_object.go: WARNING! Failed to update the create journal: %v", jerr)
+	}
+	return resultString, err
+}
+
 func (obj *APIObject) readObject() error {
 	if obj.id == "" {
 		return fmt.Errorf("cannot read an object unless the ID has been set")
 	}
 
-	getPath := obj.getPath
-	if obj.queryString != "" {
-		if obj.debug {
-			log.Printf("api_object.go: Adding query string '%s'", obj.queryString)
-		}
-		getPath = fmt.Sprintf("%s?%s", obj.getPath, obj.queryString)
-	}
+	getPath := withRetrieveOptions(obj.withQueryString(obj.getPath, "read"), obj.readInclude, obj.readExclude)
 
 	send := ""
 	if len(obj.readData) > 0 {
 		readData, _ := json.Marshal(obj.readData)
-		send = string(readData)
+		send = strings.Replace(string(readData), "{id}", obj.id, -1)
 		if obj.debug {
 			log.Printf("api_object.go: Using read data '%s'", send)
 		}
@@ -370,6 +720,16 @@ func (obj *APIObject) readObject() error {
 		return err
 	}
 
+	resultString, found, err := obj.readSearchResult(resultString)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Printf("api_object.go: Read search at '%s' did not return the object '%s'. Removing from state.", obj.getPath, obj.id)
+		obj.id = ""
+		return nil
+	}
+
 	searchKey := obj.readSearch["search_key"]
 	searchValue := obj.readSearch["search_value"]
 
@@ -378,11 +738,11 @@ func (obj *APIObject) readObject() error {
 		obj.searchPath = strings.Replace(obj.getPath, "{id}", obj.id, -1)
 
 		queryString := obj.readSearch["query_string"]
-		if obj.queryString != "" {
+		if readQueryString := obj.operationQueryString("read"); readQueryString != "" {
 			if obj.debug {
-				log.Printf("api_object.go: Adding query string '%s'", obj.queryString)
+				log.Printf("api_object.go: Adding query string '%s'", readQueryString)
 			}
-			queryString = fmt.Sprintf("%s&%s", obj.readSearch["query_string"], obj.queryString)
+			queryString = fmt.Sprintf("%s&%s", obj.readSearch["query_string"], readQueryString)
 		}
 		searchData := ""
 		if len(obj.readSearch["search_data"]) > 0 {
@@ -401,16 +761,116 @@ func (obj *APIObject) readObject() error {
 			return nil
 		}
 		objFoundString, _ := json.Marshal(objFound)
-		return obj.updateState(string(objFoundString))
+		resultString = string(objFoundString)
 	}
 
-	return obj.updateState(resultString)
+	err = obj.updateState(resultString)
+	if err != nil {
+		return err
+	}
+	return obj.readFieldGroups()
+}
+
+/*
+readSearchResult picks the object out of the response of a read that is a
+
+	search (such as a POST to midPoint's /users/search), which returns a list
+	of results instead of the object. The list is found at read_results_key,
+	or is the response itself when it is an array, and the object is the
+	result whose id_attribute matches the object's id. When read_results_key
+	holds a single object instead, as in envelopes such as {"object": {...}},
+	that object is returned. Plain reads are returned unchanged.
+*/
+func (obj *APIObject) readSearchResult(resultString string) (string, bool, error) {
+	var result interface{}
+	if obj.readResultsKey == "" {
+		if !strings.HasPrefix(strings.TrimSpace(resultString), "[") {
+			return resultString, true, nil
+		}
+	}
+	if err := unmarshalJSON([]byte(resultString), &result); err != nil {
+		return "", false, fmt.Errorf("api_object.go: Failed to parse the read search response: %v", err)
+	}
+
+	if obj.readResultsKey != "" {
+		hash, ok := result.(map[string]interface{})
+		if !ok {
+			return "", false, fmt.Errorf("api_object.go: The read search response is not a hash. Cannot locate read_results_key '%s'", obj.readResultsKey)
+		}
+		tmp, err := GetObjectAtKey(hash, obj.readResultsKey, obj.debug)
+		if err != nil {
+			/* Searches such as midPoint's leave the results out when nothing matched */
+			log.Printf("api_object.go: No results at read_results_key '%s': %v", obj.readResultsKey, err)
+			return "", false, nil
+		}
+		/* A GET by id wrapped in an envelope holds the object itself */
+		if hash, ok := tmp.(map[string]interface{}); ok {
+			b, err := json.Marshal(hash)
+			return string(b), true, err
+		}
+		result = tmp
+	}
+
+	results, ok := result.([]interface{})
+	if !ok {
+		return "", false, fmt.Errorf("api_object.go: The read search results are not an array. They are a '%s'", reflect.TypeOf(result))
+	}
+	for _, item := range results {
+		hash, ok := item.(map[string]interface{})
+		if !ok {
+			return "", false, fmt.Errorf("api_object.go: The read search results are not a map of key value pairs")
+		}
+		if id, err := GetStringAtKey(hash, obj.idAttribute, obj.debug); err == nil && id == obj.id {
+			b, err := json.Marshal(hash)
+			return string(b), true, err
+		}
+	}
+	return "", false, nil
+}
+
+/*
+readFieldGroups issues a read to every path in read_field_paths and merges
+
+	each response into apiData at the configured key. This lets objects whose
+	representation is split across several endpoints (such as a base object
+	and its membership) be compared and patched as a whole.
+*/
+func (obj *APIObject) readFieldGroups() error {
+	for _, key := range sortedStringKeys(obj.readFieldPaths) {
+		path := strings.Replace(obj.readFieldPaths[key], "{id}", obj.id, -1)
+		if obj.debug {
+			log.Printf("api_object.go: Reading field group '%s' from '%s'", key, path)
+		}
+
+		resultString, err := obj.apiClient.sendRequest(obj.readMethod, path, "")
+		if err != nil {
+			return fmt.Errorf("failed to read field group '%s' from '%s': %v", key, path, err)
+		}
+
+		var value interface{}
+		if err := unmarshalJSON([]byte(resultString), &value); err != nil {
+			return fmt.Errorf("failed to parse field group '%s' from '%s': %v", key, path, err)
+		}
+
+		if err := SetObjectAtKey(obj.apiData, key, value, obj.debug); err != nil {
+			return fmt.Errorf("failed to merge field group '%s': %v", key, err)
+		}
+	}
+	return nil
 }
 
 func (obj *APIObject) updateObject() error {
 	if obj.id == "" {
 		return fmt.Errorf("cannot update an object unless the ID has been set")
 	}
+	obj.appliedDeltas = make([]string, 0)
+
+	/* Nothing is sent unless the object on the server allows it */
+	if obj.precondition != "" {
+		if err := obj.checkPrecondition(); err != nil {
+			return err
+		}
+	}
 
 	// Write debug log
 	debugFile := "/tmp/midpoint-patch-debug.log"
@@ -422,8 +882,13 @@ func (obj *APIObject) updateObject() error {
 		f.Close()
 	}
 
+	switch obj.updateStrategy {
+	case updateStrategyJSONPatch, updateStrategyMergePatch:
+		return obj.patchWithConflictRetry(obj.patchGenericObject)
+	}
+
 	// For Midpoint integration, send the object via PATCH
-	if obj.updateMethod == "PATCH" {
+	if obj.updateStrategy == updateStrategyMidpointPatch {
 		// Write debug log
 		f, _ := os.OpenFile(debugFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if f != nil {
@@ -432,23 +897,10 @@ func (obj *APIObject) updateObject() error {
 			f.Close()
 		}
 
-		// First, fetch current state to compare with desired state
-		err := obj.readObject()
-		if err != nil {
-			return fmt.Errorf("failed to read object for PATCH operation: %v", err)
-		}
-
-		// Write debug log
-		f, _ = os.OpenFile(debugFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if f != nil {
-			f.WriteString("readObject() completed successfully\n")
-			f.WriteString("Calling patchMidpointObject()...\n")
-			f.Close()
+		if obj.apiClient.deltaListPath != "" {
+			return obj.patchWithConflictRetry(obj.patchMidpointDeltaList)
 		}
-
-		// We have apiData (current) and obj.data (desired)
-		// Now calculate what changed and form appropriate PATCH requests
-		return obj.patchMidpointObject()
+		return obj.patchWithConflictRetry(obj.patchMidpointObject)
 	}
 
 	// Original PUT behavior
@@ -460,36 +912,40 @@ func (obj *APIObject) updateObject() error {
 			log.Printf("api_object.go: Using update data '%s'", send)
 		}
 	} else {
-		// Filter ignored fields from the data before sending
-		dataToSend := obj.data
+		// Filter ignored and unmanaged fields from the data before sending
+		dataToSend := obj.managedOnly(obj.data)
 		if len(obj.ignoreChangesTo) > 0 {
-			dataToSend = filterIgnoredFields(obj.data, obj.ignoreChangesTo)
+			dataToSend = filterIgnoredFields(dataToSend, obj.ignoreChangesTo)
 			if obj.debug {
 				log.Printf("api_object.go: Filtered ignored fields for UPDATE operation")
 			}
 		}
-		b, _ := json.Marshal(dataToSend)
+		b, _ := json.Marshal(obj.wrapRequest(dataToSend))
 		send = string(b)
 	}
 
-	putPath := obj.putPath
-	if obj.queryString != "" {
-		if obj.debug {
-			log.Printf("api_object.go: Adding query string '%s'", obj.queryString)
-		}
-		putPath = fmt.Sprintf("%s?%s", obj.putPath, obj.queryString)
-	}
+	putPath := obj.withQueryString(obj.putPath, "update")
+	putPath = withExecuteOptions(putPath, obj.updateOptions)
 
 	resultString, err := obj.apiClient.sendRequest(obj.updateMethod, strings.Replace(putPath, "{id}", obj.id, -1), send)
 	if err != nil {
 		return err
 	}
+	if caseOID := pendingApprovalCase(resultString); caseOID != "" {
+		return &approvalPendingError{id: obj.id, caseOID: caseOID}
+	}
+	if err := obj.waitForSpawnedTask(resultString); err != nil {
+		return err
+	}
 
 	if obj.apiClient.writeReturnsObject {
 		if obj.debug {
 			log.Printf("api_object.go: Parsing response from PUT to update internal structures (write_returns_object=true)...\n")
 		}
 		err = obj.updateState(resultString)
+		if err == nil {
+			err = obj.readFieldGroups()
+		}
 	} else {
 		if obj.debug {
 			log.Printf("api_object.go: Requesting updated object from API (write_returns_object=false)...\n")
@@ -499,19 +955,112 @@ func (obj *APIObject) updateObject() error {
 	return err
 }
 
+/*
+patchWithConflictRetry reads the current state of the object and calls patch
+
+	to send the deltas needed to reach the desired state. If the server rejects
+	a modification with 409 Conflict (typically a midPoint background recompute
+	racing with us), the object is re-read and the deltas are recomputed against
+	the fresh data, up to the client's conflictRetries times.
+*/
+func (obj *APIObject) patchWithConflictRetry(patch func() error) error {
+	for attempt := 0; ; attempt++ {
+		// First, fetch current state to compare with desired state
+		err := obj.readObject()
+		if err != nil {
+			return fmt.Errorf("failed to read object for PATCH operation: %v", err)
+		}
+
+		if obj.debug {
+			log.Printf("api_object.go: Read '%s' for patching (attempt %d), computing the deltas", obj.id, attempt+1)
+		}
+
+		// We have apiData (current) and obj.data (desired)
+		// Now calculate what changed and form appropriate PATCH requests
+		err = patch()
+		if err == nil || !isConflictError(err) || attempt >= obj.apiClient.conflictRetries {
+			return err
+		}
+
+		log.Printf("api_object.go: Conflict while patching '%s' (attempt %d of %d), re-reading object and recomputing deltas: %v",
+			obj.id, attempt+1, obj.apiClient.conflictRetries+1, err)
+		obj.apiClient.metrics.retried()
+		time.Sleep(time.Duration(attempt+1) * conflictRetryDelay)
+	}
+}
+
+/*
+operationQueryString returns the query string of the create, read, update or
+
+	destroy requests: its <operation>_query_string, or else query_string.
+*/
+func (obj *APIObject) operationQueryString(operation string) string {
+	if queryString := obj.queryStrings[operation]; queryString != "" {
+		return queryString
+	}
+	return obj.queryString
+}
+
+/* withQueryString appends the query string of the operation to path */
+func (obj *APIObject) withQueryString(path string, operation string) string {
+	queryString := obj.operationQueryString(operation)
+	if queryString == "" {
+		return path
+	}
+	if obj.debug {
+		log.Printf("api_object.go: Adding query string '%s'", queryString)
+	}
+	return fmt.Sprintf("%s?%s", path, queryString)
+}
+
+/*
+withRetrieveOptions appends midPoint's include and exclude retrieve options,
+
+	the paths of items to read that are not returned by default (such as
+	jpegPhoto) and of items not to read, to path.
+*/
+func withRetrieveOptions(path string, include []string, exclude []string) string {
+	if len(include) == 0 && len(exclude) == 0 {
+		return path
+	}
+	values := url.Values{}
+	for _, item := range include {
+		values.Add("include", item)
+	}
+	for _, item := range exclude {
+		values.Add("exclude", item)
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + values.Encode()
+}
+
+/*
+withExecuteOptions appends midPoint's execute options (such as raw, reconcile
+
+	or force) to path as repeated options query parameters.
+*/
+func withExecuteOptions(path string, options []string) string {
+	if len(options) == 0 {
+		return path
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + url.Values{"options": options}.Encode()
+}
+
 func (obj *APIObject) deleteObject() error {
 	if obj.id == "" {
 		log.Printf("WARNING: Attempting to delete an object that has no id set. Assuming this is OK.\n")
 		return nil
 	}
 
-	deletePath := obj.deletePath
-	if obj.queryString != "" {
-		if obj.debug {
-			log.Printf("api_object.go: Adding query string '%s'", obj.queryString)
-		}
-		deletePath = fmt.Sprintf("%s?%s", obj.deletePath, obj.queryString)
-	}
+	deletePath := obj.withQueryString(obj.deletePath, "destroy")
+	deletePath = withExecuteOptions(deletePath, obj.destroyOptions)
 
 	send := ""
 	if len(obj.destroyData) > 0 {
@@ -591,31 +1140,85 @@ func (obj *APIObject) patchMidpointObject() error {
 		log.Printf("api_object.go: Calculating differences for PATCH operation")
 	}
 
+	deltas := obj.midpointDeltas()
+	if len(deltas) == 0 {
+		if obj.debug {
+			log.Printf("api_object.go: No differences found, not sending PATCH")
+		}
+		return nil
+	}
+
+	if obj.apiClient.midpointPatchPerDelta {
+		// Legacy mode: one PATCH request per modification
+		for _, delta := range deltas {
+			err := obj.sendMidpointPatch(delta.modificationType, delta.path, delta.value)
+			if err != nil {
+				return fmt.Errorf("failed to %s attribute '%s': %w", delta.modificationType, delta.path, err)
+			}
+			applied, _ := marshalModifications([]interface{}{obj.buildItemDelta(delta.modificationType, delta.path, delta.value)})
+			obj.appliedDeltas = append(obj.appliedDeltas, applied...)
+		}
+	} else {
+		// Send all modifications in a single ObjectModificationType so midPoint applies them atomically
+		itemDeltas := make([]interface{}, len(deltas))
+		for i, delta := range deltas {
+			itemDeltas[i] = obj.buildItemDelta(delta.modificationType, delta.path, delta.value)
+		}
+		err := obj.sendObjectModification(itemDeltas)
+		if err != nil {
+			return fmt.Errorf("failed to apply %d modification(s): %w", len(deltas), err)
+		}
+		obj.appliedDeltas, _ = marshalModifications(itemDeltas)
+	}
+
+	// After sending patches, Terraform will call Read() to refresh the state
+	// So we don't need to explicitly read here
+	return nil
+}
+
+/*
+patchMidpointDeltaList sends the deltas of the object as an ObjectDeltaListType
+
+	to delta_list_path, failing unless its result tells it was applied.
+*/
+func (obj *APIObject) patchMidpointDeltaList() error {
+	if obj.objectType == "" {
+		return fmt.Errorf("cannot send '%s' to delta_list_path: no midPoint object type is known for its path", obj.id)
+	}
+	failures, err := sendObjectDeltaList(obj.apiClient, obj.apiClient.deltaListPath, []objectDelta{{obj: obj, objectType: obj.objectType}})
+	if err != nil {
+		return err
+	}
+	if message, ok := failures[obj.id]; ok {
+		return fmt.Errorf("failed to apply the modifications of '%s': %s", obj.id, message)
+	}
+	return nil
+}
+
+/*
+midpointDeltas computes the itemDeltas that turn apiData into data, in the
+
+	order they are sent by patchMidpointObject.
+*/
+func (obj *APIObject) midpointDeltas() []midpointItemDelta {
 	// For Midpoint REST API, data often has a wrapper key (e.g., {"role": {...}})
 	// When PATCH-ing to /roles/{id}, we need to patch the fields inside the role, not the wrapper itself
-	// So if there's a single top-level key, unwrap it
-	workingData := obj.data
-	workingApiData := obj.apiData
-
-	if len(obj.data) == 1 && len(obj.apiData) == 1 {
-		// Get the single key from both maps
-		var dataKey, apiKey string
-		for k := range obj.data {
-			dataKey = k
-		}
-		for k := range obj.apiData {
-			apiKey = k
-		}
-
-		// If both have the same single key and it's a map, unwrap it
-		if dataKey == apiKey {
-			if dataMap, ok := obj.data[dataKey].(map[string]interface{}); ok {
-				if apiMap, ok := obj.apiData[apiKey].(map[string]interface{}); ok {
-					workingData = dataMap
-					workingApiData = apiMap
-					if obj.debug {
-						log.Printf("api_object.go: Unwrapped data from '%s' key for patching", dataKey)
-					}
+	// So the object is unwrapped from the key patchWrapper finds
+	// Managed fields are given with the wrapper key (e.g. "role.displayName"), so they apply before unwrapping
+	workingData := obj.managedOnly(obj.data)
+	workingApiData := obj.managedOnly(obj.apiData)
+	ignoreList := obj.ignoreChangesTo
+	wrapper := ""
+
+	// If both have the wrapper key and it's a map, unwrap it
+	if key := obj.patchWrapper(); key != "" {
+		if dataMap, ok := workingData[key].(map[string]interface{}); ok {
+			if apiMap, ok := workingApiData[key].(map[string]interface{}); ok {
+				workingData = dataMap
+				workingApiData = apiMap
+				wrapper = key
+				if obj.debug {
+					log.Printf("api_object.go: Unwrapped data from '%s' key for patching", key)
 				}
 			}
 		}
@@ -629,37 +1232,51 @@ func (obj *APIObject) patchMidpointObject() error {
 	}
 
 	// Recursively merge ignored fields from API data into desired data
-	if len(obj.ignoreChangesTo) > 0 {
-		desiredData = mergeIgnoredFields(desiredData, workingApiData, obj.ignoreChangesTo, obj.debug)
+	if len(ignoreList) > 0 {
+		desiredData = mergeIgnoredFields(desiredData, workingApiData, ignoreList, obj.debug)
 	}
 
 	// Process each top-level key in the desired state
-	for key, desiredValue := range desiredData {
-
+	deltas := make([]midpointItemDelta, 0)
+	for _, key := range sortedKeys(desiredData) {
+		desiredValue := desiredData[key]
 		currentValue, exists := workingApiData[key]
 
 		// Handle additions and modifications
 		if !exists {
 			// Key doesn't exist in current state - add it
 			log.Printf("api_object.go: *** PATCH OPERATION: Adding new attribute '%s'", key)
+			deltas = append(deltas, midpointItemDelta{modificationType: "add", path: key, value: desiredValue})
+		} else if !obj.normalize.equal(currentValue, desiredValue) {
+			// Extension attributes need namespace-qualified paths and are patched one by one
+			if extDeltas, ok := obj.extensionDeltas(key, currentValue, desiredValue, ignoreList); ok {
+				log.Printf("api_object.go: *** PATCH OPERATION: Modifying items of '%s'", key)
+				deltas = append(deltas, extDeltas...)
+				continue
+			}
 
-			err := obj.sendMidpointPatch("add", key, desiredValue)
-			if err != nil {
-				return fmt.Errorf("failed to add attribute '%s': %v", key, err)
+			// Container values that already carry midPoint ids are modified in place
+			if idDeltas, ok := obj.containerIDDeltas(key, currentValue, desiredValue, ignoreList); ok {
+				log.Printf("api_object.go: *** PATCH OPERATION: Matching values of attribute '%s' by container id", key)
+				deltas = append(deltas, idDeltas...)
+				continue
 			}
-		} else if !reflect.DeepEqual(currentValue, desiredValue) {
-			// Key exists but value is different - replace it
-			log.Printf("api_object.go: *** PATCH OPERATION: Replacing attribute '%s'", key)
 
-			err := obj.sendMidpointPatch("replace", key, desiredValue)
-			if err != nil {
-				return fmt.Errorf("failed to replace attribute '%s': %v", key, err)
+			// Multi-value containers with a merge key get precise add/delete deltas
+			if listDeltas, ok := obj.listMergeDeltas(key, currentValue, desiredValue, ignoreList); ok {
+				log.Printf("api_object.go: *** PATCH OPERATION: Merging values of attribute '%s' by '%s'", key, obj.listMergeKeys[key])
+				deltas = append(deltas, listDeltas...)
+				continue
 			}
+
+			// Key exists but value is different - replace it
+			log.Printf("api_object.go: *** PATCH OPERATION: Replacing attribute '%s'", key)
+			deltas = append(deltas, midpointItemDelta{modificationType: "replace", path: key, value: desiredValue})
 		}
 	}
 
 	// Check for deletions - keys that exist in current state but not in desired state
-	for key := range workingApiData {
+	for _, key := range sortedKeys(workingApiData) {
 		if _, exists := desiredData[key]; !exists {
 			// Skip the ID attribute - we don't want to delete that
 			if key == obj.idAttribute {
@@ -667,7 +1284,7 @@ func (obj *APIObject) patchMidpointObject() error {
 			}
 
 			// Skip fields in the ignore list - these are server-managed and shouldn't be deleted
-			if matchesIgnorePattern(key, obj.ignoreChangesTo) {
+			if matchesIgnorePattern(key, ignoreList) {
 				if obj.debug {
 					log.Printf("api_object.go: Skipping deletion of ignored attribute '%s'", key)
 				}
@@ -677,49 +1294,130 @@ func (obj *APIObject) patchMidpointObject() error {
 			if obj.debug {
 				log.Printf("api_object.go: Deleting attribute '%s'", key)
 			}
-
-			err := obj.sendMidpointPatch("delete", key, nil)
-			if err != nil {
-				return fmt.Errorf("failed to delete attribute '%s': %v", key, err)
-			}
+			deltas = append(deltas, midpointItemDelta{modificationType: "delete", path: key})
 		}
 	}
 
-	// After sending patches, Terraform will call Read() to refresh the state
-	// So we don't need to explicitly read here
-	return nil
+	return withoutProtectedDeletions(deltas, wrapper, obj.protectedPaths)
 }
 
-// sendMidpointPatch sends a single PATCH request for the specified modification
-func (obj *APIObject) sendMidpointPatch(modificationType string, path string, value interface{}) error {
-	// Build the ObjectModificationType payload
-	// Midpoint expects: { "objectModification": { "itemDelta": { "modificationType": "...", "path": "...", "value": ... } } }
+/*
+listMergeDeltas returns the add/delete deltas for an attribute configured in
+
+	list_merge_keys. ok is false when the attribute has no merge key or its
+	values cannot be matched, in which case the whole value is replaced.
+*/
+func (obj *APIObject) listMergeDeltas(key string, currentValue interface{}, desiredValue interface{}, ignoreList []string) ([]midpointItemDelta, bool) {
+	mergeKey, ok := obj.listMergeKeys[key]
+	if !ok {
+		return nil, false
+	}
+
+	current, okCurrent := asValueList(currentValue)
+	desired, okDesired := asValueList(desiredValue)
+	if !okCurrent || !okDesired {
+		return nil, false
+	}
+
+	return diffListByMergeKey(key, current, desired, mergeKey, _descendIgnoreList(key, ignoreList), obj.debug)
+}
+
+/*
+extensionDeltas returns the per-item deltas for the extension container when
+
+	path_namespaces is configured. ok is false for any other attribute.
+*/
+func (obj *APIObject) extensionDeltas(key string, currentValue interface{}, desiredValue interface{}, ignoreList []string) ([]midpointItemDelta, bool) {
+	if key != extensionContainer || len(obj.pathNamespaces) == 0 {
+		return nil, false
+	}
+
+	current, okCurrent := currentValue.(map[string]interface{})
+	desired, okDesired := desiredValue.(map[string]interface{})
+	if !okCurrent || !okDesired {
+		return nil, false
+	}
 
+	return extensionDeltas(key, current, desired, obj.pathNamespaces, _descendIgnoreList(key, ignoreList), obj.debug), true
+}
+
+/*
+containerIDDeltas returns the deltas for an attribute whose current values
+
+	are containers with midPoint ids, reusing those ids so unchanged values
+	are left alone. ok is false when the values are not identified containers.
+*/
+func (obj *APIObject) containerIDDeltas(key string, currentValue interface{}, desiredValue interface{}, ignoreList []string) ([]midpointItemDelta, bool) {
+	current, okCurrent := asValueList(currentValue)
+	desired, okDesired := asValueList(desiredValue)
+	if !okCurrent || !okDesired || len(current) == 0 {
+		return nil, false
+	}
+
+	return diffContainersByID(key, current, desired, obj.listMergeKeys[key], _descendIgnoreList(key, ignoreList), obj.debug)
+}
+
+/* midPoint serializes a container with a single value as an object rather than a list */
+func asValueList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case map[string]interface{}:
+		return []interface{}{v}, true
+	}
+	return nil, false
+}
+
+/*midpointItemDelta is a single modification computed by patchMidpointObject*/
+type midpointItemDelta struct {
+	modificationType string
+	path             string
+	value            interface{}
+}
+
+// buildItemDelta builds a single ItemDeltaType element of an ObjectModificationType
+func (obj *APIObject) buildItemDelta(modificationType string, path string, value interface{}) map[string]interface{} {
 	itemDelta := make(map[string]interface{})
 	itemDelta["modificationType"] = modificationType
-	itemDelta["path"] = path
+	itemDelta["path"] = qualifyItemPath(path, obj.pathNamespaces)
 
-	// Add value for add and replace operations
-	if modificationType != "delete" && value != nil {
+	// Add value for add and replace operations, and for deletes of specific container values
+	if value != nil {
 		// Filter out ignored fields from the value before sending
 		// This prevents sending server-managed fields like @metadata, @ns, etc.
+		ignoreList := obj.ignoreChangesTo
 		if mapValue, ok := value.(map[string]interface{}); ok {
-			value = filterIgnoredFields(mapValue, obj.ignoreChangesTo)
+			value = filterIgnoredFields(mapValue, ignoreList)
 		} else if sliceValue, ok := value.([]interface{}); ok {
 			// Handle arrays by filtering each element
 			filteredSlice := make([]interface{}, len(sliceValue))
 			for i, elem := range sliceValue {
 				if mapElem, ok := elem.(map[string]interface{}); ok {
-					filteredSlice[i] = filterIgnoredFields(mapElem, obj.ignoreChangesTo)
+					filteredSlice[i] = filterIgnoredFields(mapElem, ignoreList)
 				} else {
 					filteredSlice[i] = elem
 				}
 			}
 			value = filteredSlice
 		}
-		itemDelta["value"] = value
+		itemDelta["value"] = withoutPolyStringNorms(value)
 	}
 
+	return itemDelta
+}
+
+// sendMidpointPatch sends a single PATCH request for the specified modification
+func (obj *APIObject) sendMidpointPatch(modificationType string, path string, value interface{}) error {
+	// Midpoint expects: { "objectModification": { "itemDelta": { "modificationType": "...", "path": "...", "value": ... } } }
+	return obj.sendObjectModification(obj.buildItemDelta(modificationType, path, value))
+}
+
+/*
+sendObjectModification sends one PATCH request carrying an ObjectModificationType.
+
+	itemDelta is either a single ItemDeltaType map or a list of them.
+*/
+func (obj *APIObject) sendObjectModification(itemDelta interface{}) error {
 	// Wrap in objectModification as required by Midpoint's ObjectModificationType
 	modification := map[string]interface{}{
 		"objectModification": map[string]interface{}{
@@ -736,8 +1434,14 @@ func (obj *APIObject) sendMidpointPatch(modificationType string, path string, va
 	// Construct the PATCH path
 	// NOTE: We don't include query_string for PATCH operations because options like
 	// "isImport", "overwrite", "noFetch" are for create/import operations and cause
-	// Midpoint to expect a full object (e.g., RoleType) instead of ObjectModificationType
-	patchPath := obj.putPath
+	// Midpoint to expect a full object (e.g., RoleType) instead of ObjectModificationType.
+	// update_query_string is meant for updates, so it is sent, as are update_options
+	// (such as raw or reconcile)
+	patchPath := obj.patchPath
+	if updateQueryString := obj.queryStrings["update"]; updateQueryString != "" {
+		patchPath = fmt.Sprintf("%s?%s", patchPath, updateQueryString)
+	}
+	patchPath = withExecuteOptions(patchPath, obj.updateOptions)
 	fullPath := strings.Replace(patchPath, "{id}", obj.id, -1)
 
 	// Write debug info to file for inspection
@@ -765,6 +1469,12 @@ func (obj *APIObject) sendMidpointPatch(modificationType string, path string, va
 	if err != nil {
 		return err
 	}
+	if caseOID := pendingApprovalCase(resultString); caseOID != "" {
+		return &approvalPendingError{id: obj.id, caseOID: caseOID}
+	}
+	if err := obj.waitForSpawnedTask(resultString); err != nil {
+		return err
+	}
 
 	// Update internal state if the API returns the updated object
 	if obj.apiClient.writeReturnsObject {
@@ -778,10 +1488,6 @@ func (obj *APIObject) sendMidpointPatch(modificationType string, path string, va
 }
 
 func (obj *APIObject) findObject(queryString string, searchKey string, searchValue string, resultsKey string, searchData string) (map[string]interface{}, error) {
-	var objFound map[string]interface{}
-	var dataArray []interface{}
-	var ok bool
-
 	/*
 	   Issue a GET to the base path and expect results to come back
 	*/
@@ -793,26 +1499,56 @@ func (obj *APIObject) findObject(queryString string, searchKey string, searchVal
 		searchPath = fmt.Sprintf("%s?%s", obj.searchPath, queryString)
 	}
 
-	if obj.debug {
-		log.Printf("api_object.go: Calling API on path '%s'", searchPath)
-	}
-	resultString, err := obj.apiClient.sendRequest(obj.apiClient.readMethod, searchPath, searchData)
-	if err != nil {
-		return objFound, err
-	}
+	pages := newPager(obj.pagination, obj.apiClient.uri, searchPath)
+	for {
+		pagePath := pages.path()
+		if obj.debug {
+			log.Printf("api_object.go: Calling API on path '%s'", pagePath)
+		}
+		resultString, headers, err := obj.apiClient.sendRequestReturningHeaders(obj.apiClient.readMethod, pagePath, searchData, "", nil)
+		if err != nil {
+			return nil, err
+		}
 
-	/*
-	   Parse it seeking JSON data
-	*/
-	if obj.debug {
-		log.Printf("api_object.go: Response received... parsing")
-	}
-	var result interface{}
-	err = json.Unmarshal([]byte(resultString), &result)
-	if err != nil {
-		return objFound, err
+		/*
+		   Parse it seeking JSON data
+		*/
+		if obj.debug {
+			log.Printf("api_object.go: Response received... parsing")
+		}
+		var result interface{}
+		err = decodeJSON(resultString, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		dataArray, err := obj.searchResults(result, pagePath, resultsKey)
+		if err != nil {
+			return nil, err
+		}
+
+		objFound, err := obj.searchRecord(dataArray, searchKey, searchValue, resultsKey)
+		if err != nil || objFound != nil {
+			return objFound, err
+		}
+
+		more, err := pages.advance(result, headers, len(dataArray))
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
 	}
 
+	return nil, fmt.Errorf("failed to find an object with the '%s' key = '%s' at %s", searchKey, searchValue, searchPath)
+}
+
+/* searchResults locates the array of results in a search response */
+func (obj *APIObject) searchResults(result interface{}, searchPath string, resultsKey string) ([]interface{}, error) {
+	var dataArray []interface{}
+	var ok bool
+
 	if resultsKey != "" {
 		var tmp interface{}
 
@@ -822,31 +1558,34 @@ func (obj *APIObject) findObject(queryString string, searchKey string, searchVal
 
 		/* First verify the data we got back is a hash */
 		if _, ok = result.(map[string]interface{}); !ok {
-			return objFound, fmt.Errorf("api_object.go: The results of a GET to '%s' did not return a hash. Cannot search within for results_key '%s'", searchPath, resultsKey)
+			return nil, fmt.Errorf("api_object.go: The results of a GET to '%s' did not return a hash. Cannot search within for results_key '%s'", searchPath, resultsKey)
 		}
 
-		tmp, err = GetObjectAtKey(result.(map[string]interface{}), resultsKey, obj.debug)
+		tmp, err := GetObjectAtKey(result.(map[string]interface{}), resultsKey, obj.debug)
 		if err != nil {
-			return objFound, fmt.Errorf("api_object.go: Error finding results_key: %s", err)
+			return nil, fmt.Errorf("api_object.go: Error finding results_key: %s", err)
 		}
 		if dataArray, ok = tmp.([]interface{}); !ok {
-			return objFound, fmt.Errorf("api_object.go: The data at results_key location '%s' is not an array. It is a '%s'", resultsKey, reflect.TypeOf(tmp))
+			return nil, fmt.Errorf("api_object.go: The data at results_key location '%s' is not an array. It is a '%s'", resultsKey, reflect.TypeOf(tmp))
 		}
 	} else {
 		if obj.debug {
 			log.Printf("api_object.go: results_key is not set - coaxing data to array of interfaces")
 		}
 		if dataArray, ok = result.([]interface{}); !ok {
-			return objFound, fmt.Errorf("api_object.go: The results of a GET to '%s' did not return an array. It is a '%s'. Perhaps you meant to add a results_key?", searchPath, reflect.TypeOf(result))
+			return nil, fmt.Errorf("api_object.go: The results of a GET to '%s' did not return an array. It is a '%s'. Perhaps you meant to add a results_key?", searchPath, reflect.TypeOf(result))
 		}
 	}
+	return dataArray, nil
+}
 
+/* searchRecord returns the record of dataArray whose searchKey equals searchValue and sets the object's id from it */
+func (obj *APIObject) searchRecord(dataArray []interface{}, searchKey string, searchValue string, resultsKey string) (map[string]interface{}, error) {
 	/* Loop through all of the results seeking the specific record */
 	for _, item := range dataArray {
-		var hash map[string]interface{}
-
-		if hash, ok = item.(map[string]interface{}); !ok {
-			return objFound, fmt.Errorf("api_object.go: The elements being searched for data are not a map of key value pairs")
+		hash, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("api_object.go: The elements being searched for data are not a map of key value pairs")
 		}
 
 		if obj.debug {
@@ -856,15 +1595,14 @@ func (obj *APIObject) findObject(queryString string, searchKey string, searchVal
 
 		tmp, err := GetStringAtKey(hash, searchKey, obj.debug)
 		if err != nil {
-			return objFound, fmt.Errorf("failed to get the value of '%s' in the results array at '%s': %s", searchKey, resultsKey, err)
+			return nil, fmt.Errorf("failed to get the value of '%s' in the results array at '%s': %s", searchKey, resultsKey, err)
 		}
 
 		/* We found our record */
 		if tmp == searchValue {
-			objFound = hash
 			obj.id, err = GetStringAtKey(hash, obj.idAttribute, obj.debug)
 			if err != nil {
-				return objFound, fmt.Errorf("failed to find id_attribute '%s' in the record: %s", obj.idAttribute, err)
+				return hash, fmt.Errorf("failed to find id_attribute '%s' in the record: %s", obj.idAttribute, err)
 			}
 
 			if obj.debug {
@@ -873,15 +1611,68 @@ func (obj *APIObject) findObject(queryString string, searchKey string, searchVal
 
 			/* But there is no id attribute??? */
 			if obj.id == "" {
-				return objFound, fmt.Errorf("the object for '%s'='%s' did not have the id attribute '%s', or the value was empty", searchKey, searchValue, obj.idAttribute)
+				return hash, fmt.Errorf("the object for '%s'='%s' did not have the id attribute '%s', or the value was empty", searchKey, searchValue, obj.idAttribute)
 			}
-			break
+			return hash, nil
 		}
 	}
+	return nil, nil
+}
 
-	if obj.id == "" {
-		return objFound, fmt.Errorf("failed to find an object with the '%s' key = '%s' at %s", searchKey, searchValue, searchPath)
+/*
+listObjects returns every record of a search, following the pages of the
+
+	object's pagination. Unlike findObject, a results_key missing from the
+	response is no error, as midPoint leaves it out when nothing matched.
+*/
+func (obj *APIObject) listObjects(method string, queryString string, resultsKey string, searchData string) ([]interface{}, error) {
+	searchPath := obj.searchPath
+	if queryString != "" {
+		searchPath = fmt.Sprintf("%s?%s", obj.searchPath, queryString)
 	}
 
-	return objFound, nil
+	records := make([]interface{}, 0)
+	pages := newPager(obj.pagination, obj.apiClient.uri, searchPath)
+	for {
+		pagePath := pages.path()
+		if obj.debug {
+			log.Printf("api_object.go: Listing objects at '%s'", pagePath)
+		}
+		resultString, headers, err := obj.apiClient.sendRequestReturningHeaders(method, pagePath, searchData, "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result interface{}
+		if err := decodeJSON(resultString, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse the search response from '%s': %v", pagePath, err)
+		}
+
+		page := result
+		if resultsKey != "" {
+			hash, ok := result.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("the search response from '%s' is not a hash; cannot locate results_key '%s'", pagePath, resultsKey)
+			}
+			if page, err = GetObjectAtKey(hash, resultsKey, obj.debug); err != nil {
+				if obj.debug {
+					log.Printf("api_object.go: No results at results_key '%s': %v", resultsKey, err)
+				}
+				page = []interface{}{}
+			}
+		}
+		dataArray, ok := asValueList(page)
+		if !ok {
+			return nil, fmt.Errorf("the results of the search at '%s' are not an array, it is a '%s'. Perhaps you meant to set results_key?", pagePath, reflect.TypeOf(page))
+		}
+		records = append(records, dataArray...)
+
+		more, err := pages.advance(result, headers, len(dataArray))
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return records, nil
+		}
+	}
 }
@@ -3,7 +3,12 @@ package restapi
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Mastercard/terraform-provider-restapi/fakeserver"
@@ -215,6 +220,28 @@ func TestAPIObject(t *testing.T) {
 		}
 	})
 
+	/* Merge a second endpoint into the object's api_data */
+	t.Run("read_object_with_field_paths", func(t *testing.T) {
+		if testDebug {
+			log.Printf("api_object_test.go: Testing read_object() with read_field_paths")
+		}
+		obj := testingObjects["normal"]
+		obj.readFieldPaths = map[string]string{"related/minimal": "/api/objects/2"}
+		defer func() { obj.readFieldPaths = nil }()
+
+		err := obj.readObject()
+		if err != nil {
+			t.Fatalf("api_object_test.go: Failed to read data with field paths: %s", err)
+		}
+		res, err := GetStringAtKey(obj.apiData, "related/minimal/Thing", testDebug)
+		if err != nil || res != "fork" {
+			t.Fatalf("api_object_test.go: Expected 'related/minimal/Thing' to be merged as 'fork', got '%s' (%v)", res, err)
+		}
+		if obj.apiData["Thing"] != "potato" {
+			t.Fatalf("api_object_test.go: Base object data was lost when merging field paths: %+v", obj.apiData)
+		}
+	})
+
 	/* Go ahead and update one of our objects */
 	t.Run("update_object", func(t *testing.T) {
 		if testDebug {
@@ -331,3 +358,142 @@ func TestAPIObject(t *testing.T) {
 		log.Println("api_object_test.go: Done")
 	}
 }
+
+func TestAPIObjectReadSearch(t *testing.T) {
+	var body string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		if r.Method != "POST" || r.URL.Path != "/users/search" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.Contains(body, "gone") {
+			w.Write([]byte(`{"object": {}}`))
+			return
+		}
+		w.Write([]byte(`{"object": {"object": [{"oid": "other", "name": "other"}, {"oid": "1234", "name": "jsmith"}]}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("api_object_test.go: Failed to create API client: %s", err)
+	}
+
+	read := func(id string) *APIObject {
+		obj, err := NewAPIObject(client, &apiObjectOpts{
+			path:           "/users",
+			getPath:        "/users/search",
+			readMethod:     "POST",
+			readData:       `{"query": {"filter": {"text": "oid = \"{id}\""}}}`,
+			readResultsKey: "object/object",
+			idAttribute:    "oid",
+			id:             id,
+			data:           `{"name": "jsmith"}`,
+		})
+		if err != nil {
+			t.Fatalf("api_object_test.go: Failed to create API object: %s", err)
+		}
+		if err := obj.readObject(); err != nil {
+			t.Fatalf("api_object_test.go: Read failed: %s", err)
+		}
+		return obj
+	}
+
+	obj := read("1234")
+	if !strings.Contains(body, `oid = \"1234\"`) {
+		t.Fatalf("api_object_test.go: Expected {id} to be replaced in the read data, sent %s", body)
+	}
+	if obj.id != "1234" || obj.apiData["name"] != "jsmith" {
+		t.Fatalf("api_object_test.go: Expected the matching search result to be read, got %v", obj.apiData)
+	}
+
+	if obj := read("gone"); obj.id != "" {
+		t.Fatalf("api_object_test.go: Expected an object missing from the search results to be removed from state")
+	}
+}
+
+func TestAPIObjectReadEnvelope(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": {"oid": "1234", "name": "jsmith"}, "status": "success"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("api_object_test.go: Failed to create API client: %s", err)
+	}
+
+	obj, err := NewAPIObject(client, &apiObjectOpts{
+		path:           "/users",
+		readResultsKey: "result",
+		idAttribute:    "oid",
+		id:             "1234",
+		data:           `{"name": "jsmith"}`,
+	})
+	if err != nil {
+		t.Fatalf("api_object_test.go: Failed to create API object: %s", err)
+	}
+	if err := obj.readObject(); err != nil {
+		t.Fatalf("api_object_test.go: Read failed: %s", err)
+	}
+
+	expected := map[string]interface{}{"oid": "1234", "name": "jsmith"}
+	if obj.id != "1234" || !reflect.DeepEqual(obj.apiData, expected) {
+		t.Fatalf("api_object_test.go: Expected the object to be unwrapped from its envelope, got %v", obj.apiData)
+	}
+}
+
+func TestAPIObjectOperationQueryStrings(t *testing.T) {
+	requests := make(map[string]string)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method] = r.URL.RequestURI()
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"id": "1", "name": "old"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("api_object_test.go: Failed to create API client: %s", err)
+	}
+	obj, err := NewAPIObject(client, &apiObjectOpts{
+		path:           "/users",
+		updateStrategy: updateStrategyMidpointPatch,
+		queryString:    "options=noFetch",
+		queryStrings: map[string]string{
+			"create": "options=isImport&options=overwrite",
+			"read":   "options=resolveNames",
+			"update": "options=reconcile",
+		},
+		data: `{"id": "1", "name": "new"}`,
+	})
+	if err != nil {
+		t.Fatalf("api_object_test.go: Failed to create API object: %s", err)
+	}
+	if err := obj.createObject(); err != nil {
+		t.Fatalf("api_object_test.go: Create failed: %s", err)
+	}
+	if err := obj.updateObject(); err != nil {
+		t.Fatalf("api_object_test.go: Update failed: %s", err)
+	}
+	if err := obj.deleteObject(); err != nil {
+		t.Fatalf("api_object_test.go: Delete failed: %s", err)
+	}
+
+	/* Destroys fall back to query_string */
+	expected := map[string]string{
+		"POST":   "/users?options=isImport&options=overwrite",
+		"GET":    "/users/1?options=resolveNames",
+		"PATCH":  "/users/1?options=reconcile",
+		"DELETE": "/users/1?options=noFetch",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("api_object_test.go: Expected each operation to use its own query string: expected %v, got %v", expected, requests)
+	}
+}
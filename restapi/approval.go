@@ -0,0 +1,181 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* What a create or update does when midPoint sends it for approval */
+const (
+	approvalActionFail   = "fail"
+	approvalActionWait   = "wait"
+	approvalActionRecord = "record"
+)
+
+var approvalActions = []string{approvalActionFail, approvalActionWait, approvalActionRecord}
+
+/* The prefix of the asynchronousOperationReference of operation results waiting on a case */
+const caseReferencePrefix = "case:"
+
+/* How often a case is read while waiting for its approval; tests shorten it */
+var casePollInterval = 5 * time.Second
+
+/*
+approvalPendingError is returned by writes that midPoint did not apply but
+
+	handed to an approval process, answering with an in_progress operation
+	result that refers to the case deciding on them.
+*/
+type approvalPendingError struct {
+	id      string
+	caseOID string
+}
+
+func (e *approvalPendingError) Error() string {
+	return fmt.Sprintf("the write of '%s' was not applied yet: it awaits the approval of the case '%s'; see approval_action", e.id, e.caseOID)
+}
+
+/*
+pendingApprovalCase returns the oid of the case a write waits on when
+
+	resultString is an in_progress operation result, or an empty string.
+*/
+func pendingApprovalCase(resultString string) string {
+	result := operationResultOf(resultString)
+	if scalarStringAt(result, "status") != "in_progress" {
+		return ""
+	}
+	return asynchronousReference(result, caseReferencePrefix)
+}
+
+/* operationResultOf returns the operation result midPoint answered a write with, or nil */
+func operationResultOf(resultString string) map[string]interface{} {
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(resultString), &response); err != nil {
+		return nil
+	}
+	result, ok := response["object"].(map[string]interface{})
+	if !ok {
+		result = midpointObjectOf(response)
+	}
+	return result
+}
+
+/*
+asynchronousReference looks for an asynchronousOperationReference with the
+
+	prefix in an operation result and its partial results, returning it
+	without the prefix.
+*/
+func asynchronousReference(result map[string]interface{}, prefix string) string {
+	if reference := scalarStringAt(result, "asynchronousOperationReference"); strings.HasPrefix(reference, prefix) {
+		return strings.TrimPrefix(reference, prefix)
+	}
+	partials, _ := asValueList(result["partialResults"])
+	for _, partial := range partials {
+		if partial, ok := partial.(map[string]interface{}); ok {
+			if reference := asynchronousReference(partial, prefix); reference != "" {
+				return reference
+			}
+		}
+	}
+	return ""
+}
+
+/*
+readApprovalCase returns whether the case is closed and, if so, whether its
+
+	outcome approved the write. midPoint reports outcomes as URIs ending in
+	#approve or #reject.
+*/
+func readApprovalCase(client *APIClient, casesPath string, caseOID string) (closed bool, approved bool, err error) {
+	resultString, err := client.sendRequest("GET", fmt.Sprintf("%s/%s", strings.TrimSuffix(casesPath, "/"), caseOID), "")
+	if err != nil {
+		return false, false, fmt.Errorf("failed to read the approval case '%s': %v", caseOID, err)
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(resultString), &response); err != nil {
+		return false, false, fmt.Errorf("failed to parse the approval case '%s': %v", caseOID, err)
+	}
+	c := midpointObjectOf(response)
+	if !caseStateMatches(scalarStringAt(c, "state"), "closed") {
+		return false, false, nil
+	}
+	return true, caseStateMatches(scalarStringAt(c, "outcome"), "approve"), nil
+}
+
+/* waitForApproval reads the case until it is closed, failing unless it approved the write */
+func waitForApproval(client *APIClient, casesPath string, pending *approvalPendingError, timeout time.Duration, debug bool) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		closed, approved, err := readApprovalCase(client, casesPath, pending.caseOID)
+		if err != nil {
+			return err
+		}
+		if closed {
+			if !approved {
+				return fmt.Errorf("the write of '%s' was rejected by the approval case '%s'", pending.id, pending.caseOID)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the approval case '%s' of the write of '%s' was not closed within %s", pending.caseOID, pending.id, timeout)
+		}
+		if debug {
+			log.Printf("approval.go: Waiting for the approval case '%s' of '%s'", pending.caseOID, pending.id)
+		}
+		time.Sleep(casePollInterval)
+	}
+}
+
+/*
+handlePendingApproval applies the approval_action of the resource to a write
+
+	that awaits approval. When waiting, the object is read once the case
+	approved the write. recorded is true when the case was recorded in
+	pending_case_oid instead, in which case there is nothing to read yet.
+*/
+func handlePendingApproval(d *schema.ResourceData, obj *APIObject, pending *approvalPendingError, timeout time.Duration) (recorded bool, err error) {
+	switch d.Get("approval_action").(string) {
+	case approvalActionWait:
+		if err := waitForApproval(obj.apiClient, d.Get("cases_path").(string), pending, timeout, obj.debug); err != nil {
+			return false, err
+		}
+		return false, obj.readObject()
+	case approvalActionRecord:
+		if pending.id == "" {
+			return false, fmt.Errorf("%v; recording it requires the id of the object in data", pending)
+		}
+		log.Printf("[WARN] approval.go: %v. Recording the case in pending_case_oid.", pending)
+		return true, d.Set("pending_case_oid", pending.caseOID)
+	}
+	return false, pending
+}
+
+/*
+pendingApprovalOpen checks the case recorded in pending_case_oid. While it is
+
+	open, the state is kept as it is; once it is closed, the case is forgotten
+	and the object is read again, so a rejected write shows up as drift.
+*/
+func pendingApprovalOpen(d *schema.ResourceData, client *APIClient) (bool, error) {
+	caseOID := d.Get("pending_case_oid").(string)
+	if caseOID == "" {
+		return false, nil
+	}
+	closed, _, err := readApprovalCase(client, d.Get("cases_path").(string), caseOID)
+	if err != nil {
+		return false, err
+	}
+	if closed {
+		d.Set("pending_case_oid", "")
+		return false, nil
+	}
+	log.Printf("approval.go: The write of '%s' still awaits the approval case '%s', keeping the state", d.Id(), caseOID)
+	return true, nil
+}
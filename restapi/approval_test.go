@@ -0,0 +1,99 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestApprovalAction(t *testing.T) {
+	casePollInterval = 10 * time.Millisecond
+	created := false
+	caseReads := 0
+	outcome := ""
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" || r.Method == "PUT":
+			/* Every write is handed to an approval process */
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"object": {"operation": "executeChanges", "status": "in_progress", "partialResults": [{"operation": "approval", "status": "in_progress", "asynchronousOperationReference": "case:case-1"}]}}`))
+		case r.URL.Path == "/cases/case-1":
+			caseReads++
+			if outcome == "" || caseReads < 2 {
+				w.Write([]byte(`{"case": {"oid": "case-1", "state": "open"}}`))
+				return
+			}
+			created = outcome == "approve"
+			w.Write([]byte(`{"case": {"oid": "case-1", "state": "closed", "outcome": "http://midpoint.evolveum.com/xml/ns/public/model/approval/outcome#` + outcome + `"}}`))
+		case r.URL.Path == "/users/1" && created:
+			w.Write([]byte(`{"id": "1", "name": "jsmith"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id"})
+	if err != nil {
+		t.Fatalf("approval_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(action string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":            "/users",
+			"data":            `{"id": "1", "name": "jsmith"}`,
+			"approval_action": action,
+		})
+	}
+
+	/* fail */
+	if err := resourceRestAPICreate(newResourceData(approvalActionFail), client); err == nil || !strings.Contains(err.Error(), "awaits the approval of the case 'case-1'") {
+		t.Fatalf("approval_test.go: Expected the create to fail with the case, got %v", err)
+	}
+
+	/* record, then refresh while the case is open and after it was rejected */
+	d := newResourceData(approvalActionRecord)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("approval_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "1" || d.Get("pending_case_oid") != "case-1" {
+		t.Fatalf("approval_test.go: Expected the case to be recorded, got %v", d.State())
+	}
+	caseReads = 0
+	if err := resourceRestAPIRead(d, client); err != nil || d.Id() != "1" || d.Get("pending_case_oid") != "case-1" {
+		t.Fatalf("approval_test.go: Expected the state to be kept while the case is open, got %v, %v", err, d.State())
+	}
+	outcome = "reject"
+	if err := resourceRestAPIRead(d, client); err != nil || d.Id() != "" || d.Get("pending_case_oid") != "" {
+		t.Fatalf("approval_test.go: Expected the rejected object to be removed from state, got %v, %v", err, d.State())
+	}
+
+	/* wait, rejected and approved */
+	caseReads = 0
+	if err := resourceRestAPICreate(newResourceData(approvalActionWait), client); err == nil || !strings.Contains(err.Error(), "rejected by the approval case 'case-1'") {
+		t.Fatalf("approval_test.go: Expected the create to fail as rejected, got %v", err)
+	}
+	caseReads = 0
+	outcome = "approve"
+	d = newResourceData(approvalActionWait)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("approval_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "1" || caseReads != 2 {
+		t.Fatalf("approval_test.go: Expected the create to wait for the approval, got %d case reads and %v", caseReads, d.State())
+	}
+
+	/* An update is recorded the same way */
+	d = newResourceData(approvalActionRecord)
+	d.SetId("1")
+	if err := resourceRestAPIUpdate(d, client); err != nil || d.Get("pending_case_oid") != "case-1" {
+		t.Fatalf("approval_test.go: Expected the update to be recorded, got %v, %v", err, d.State())
+	}
+
+	if caseOID := pendingApprovalCase(`{"object": {"status": "success"}}`); caseOID != "" {
+		t.Fatalf("approval_test.go: Expected no case for a successful result, got '%s'", caseOID)
+	}
+}
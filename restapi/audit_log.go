@@ -0,0 +1,165 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* The value written to the audit log in place of sensitive values */
+const auditRedacted = "(redacted)"
+
+/* Parts of the names of items whose values are not written to the audit log */
+var auditSensitiveNames = []string{"password", "secret", "token", "credential", "privatekey", "clearvalue"}
+
+/*
+An append-only journal of the requests that change objects on the server,
+
+	one JSON object per line, for deployments that need an operator-side
+	record of changes.
+*/
+type auditLog struct {
+	file  string
+	mutex sync.Mutex
+}
+
+type auditEntry struct {
+	Time          string        `json:"time"`
+	Method        string        `json:"method"`
+	Path          string        `json:"path"`
+	Status        int           `json:"status"`
+	Failed        bool          `json:"failed,omitempty"`
+	CorrelationID string        `json:"correlation_id,omitempty"`
+	Changes       []auditChange `json:"changes,omitempty"`
+}
+
+/* One change of a request: an item delta, a JSON Patch operation or an item of a written object */
+type auditChange struct {
+	Operation string      `json:"operation"`
+	Path      string      `json:"path"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+func newAuditLog(file string) (*auditLog, error) {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log '%s': %v", file, err)
+	}
+	f.Close()
+	return &auditLog{file: file}, nil
+}
+
+/* isMutatingRequest reports whether a request changes objects; midPoint searches are POSTs that do not */
+func isMutatingRequest(method string, path string) bool {
+	switch method {
+	case "POST":
+		return !strings.HasSuffix(strings.SplitN(path, "?", 2)[0], "/search")
+	case "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+/* record appends the outcome of a request to the audit log, if it changes objects */
+func (a *auditLog) record(method string, path string, data string, status int, correlationID string, err error) error {
+	if a == nil || !isMutatingRequest(method, path) {
+		return nil
+	}
+	encoded, marshalErr := json.Marshal(auditEntry{
+		Time:          time.Now().UTC().Format(time.RFC3339Nano),
+		Method:        method,
+		Path:          path,
+		Status:        status,
+		Failed:        err != nil,
+		CorrelationID: correlationID,
+		Changes:       auditChanges(data),
+	})
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	f, openErr := os.OpenFile(a.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if openErr != nil {
+		return fmt.Errorf("could not open audit log '%s': %v", a.file, openErr)
+	}
+	defer f.Close()
+	_, writeErr := f.Write(append(encoded, '\n'))
+	return writeErr
+}
+
+/*
+auditChanges summarizes the body of a request: the item deltas of a midPoint
+
+	objectModification, the operations of a JSON Patch or else the items of
+	the written object, with sensitive values redacted.
+*/
+func auditChanges(data string) []auditChange {
+	var body interface{}
+	if data == "" || json.Unmarshal([]byte(data), &body) != nil {
+		return nil
+	}
+
+	changes := make([]auditChange, 0)
+	switch b := body.(type) {
+	case []interface{}:
+		for _, raw := range b {
+			if op, ok := raw.(map[string]interface{}); ok {
+				path := stringAtKey(op, "path")
+				changes = append(changes, auditChange{Operation: stringAtKey(op, "op"), Path: path, Value: redactAuditValue(path, op["value"])})
+			}
+		}
+	case map[string]interface{}:
+		if modification, ok := b["objectModification"].(map[string]interface{}); ok {
+			deltas, _ := asValueList(modification["itemDelta"])
+			for _, raw := range deltas {
+				if delta, ok := raw.(map[string]interface{}); ok {
+					path := stringAtKey(delta, "path")
+					changes = append(changes, auditChange{Operation: stringAtKey(delta, "modificationType"), Path: path, Value: redactAuditValue(path, delta["value"])})
+				}
+			}
+			return changes
+		}
+		object := midpointObjectOf(b)
+		for _, key := range sortedKeys(object) {
+			changes = append(changes, auditChange{Operation: "set", Path: key, Value: redactAuditValue(key, object[key])})
+		}
+	}
+	return changes
+}
+
+/* redactAuditValue returns value with protected strings and the values of sensitive items redacted */
+func redactAuditValue(name string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	lower := strings.ToLower(name)
+	for _, sensitive := range auditSensitiveNames {
+		if strings.Contains(lower, sensitive) {
+			return auditRedacted
+		}
+	}
+	if _, ok := protectedString(value); ok {
+		return auditRedacted
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, inner := range v {
+			redacted[key] = redactAuditValue(key, inner)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, inner := range v {
+			redacted[i] = redactAuditValue("", inner)
+		}
+		return redacted
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package restapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", mode: clientModeMemory, idAttribute: "oid", auditLogPath: file})
+	if err != nil {
+		t.Fatalf("audit_log_test.go: Failed to create API client: %s", err)
+	}
+
+	requests := []struct{ method, path, data string }{
+		{"POST", "/users", `{"oid": "user-1", "name": "jsmith", "credentials": {"password": {"value": {"clearValue": "secret"}}}, "extension": {"apiKey": {"clearValue": "key"}}}`},
+		{"GET", "/users/user-1", ""},
+		{"POST", "/users/search", `{"query": {}}`},
+		{"PATCH", "/users/user-1", `{"objectModification": {"itemDelta": {"modificationType": "replace", "path": "description", "value": "Accountant"}}}`},
+		{"DELETE", "/users/user-1", ""},
+	}
+	for _, r := range requests {
+		client.sendRequest(r.method, r.path, r.data)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("audit_log_test.go: Failed to read the audit log: %s", err)
+	}
+	if strings.Contains(string(content), "secret") || strings.Contains(string(content), `"key"`) {
+		t.Fatalf("audit_log_test.go: Expected the password to be redacted, got %s", content)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("audit_log_test.go: Expected only the three changes in the audit log, got %s", content)
+	}
+	entries := make([]auditEntry, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i]); err != nil {
+			t.Fatalf("audit_log_test.go: Expected JSON lines, got %s", line)
+		}
+	}
+	if entries[0].Method != "POST" || len(entries[0].Changes) != 4 || entries[0].Changes[0].Path != "credentials" || entries[0].Changes[0].Value != auditRedacted || entries[0].Changes[1].Value.(map[string]interface{})["apiKey"] != auditRedacted {
+		t.Fatalf("audit_log_test.go: Unexpected create entry %+v", entries[0])
+	}
+	if entries[1].Method != "PATCH" || entries[1].Status != 204 && entries[1].Status != 200 || len(entries[1].Changes) != 1 || entries[1].Changes[0].Operation != "replace" || entries[1].Changes[0].Value != "Accountant" {
+		t.Fatalf("audit_log_test.go: Unexpected modification entry %+v", entries[1])
+	}
+	if entries[2].Method != "DELETE" || entries[2].Path != "/users/user-1" || entries[2].Failed {
+		t.Fatalf("audit_log_test.go: Unexpected delete entry %+v", entries[2])
+	}
+}
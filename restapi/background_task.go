@@ -0,0 +1,47 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+)
+
+/* The prefix of the asynchronousOperationReference of operation results handed to a background task */
+const taskReferencePrefix = "task:"
+
+/* spawnedTask returns the oid of the background task a write started, or an empty string */
+func spawnedTask(resultString string) string {
+	return asynchronousReference(operationResultOf(resultString), taskReferencePrefix)
+}
+
+/*
+waitForSpawnedTask waits for the background task a write started, when
+
+	wait_for_task is set, so the write only succeeds once the task closed
+	with a successful result.
+*/
+func (obj *APIObject) waitForSpawnedTask(resultString string) error {
+	if !obj.waitForTask {
+		return nil
+	}
+	taskOID := spawnedTask(resultString)
+	if taskOID == "" {
+		return nil
+	}
+
+	task, err := NewAPIObject(obj.apiClient, &apiObjectOpts{
+		path:        obj.tasksPath,
+		id:          taskOID,
+		idAttribute: "task/oid",
+		debug:       obj.debug,
+	})
+	if err != nil {
+		return err
+	}
+	if obj.debug {
+		log.Printf("background_task.go: The write of '%s' started the task '%s', waiting for it", obj.id, taskOID)
+	}
+	if err := pollMidpointTask(task, obj.taskTimeout, obj.taskInterval); err != nil {
+		return fmt.Errorf("the write of '%s' started a task that did not succeed: %v", obj.id, err)
+	}
+	return nil
+}
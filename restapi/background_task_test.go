@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWaitForTask(t *testing.T) {
+	taskReads := 0
+	taskResult := `{"status": "success"}`
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" || r.Method == "PUT":
+			/* Every write is handed to a background task */
+			w.Write([]byte(`{"object": {"operation": "executeChanges", "status": "in_progress", "partialResults": [{"operation": "execute", "status": "in_progress", "asynchronousOperationReference": "task:task-1"}]}}`))
+		case r.URL.Path == "/tasks/task-1":
+			taskReads++
+			if taskReads < 2 {
+				w.Write([]byte(`{"task": {"oid": "task-1", "executionState": "running"}}`))
+				return
+			}
+			w.Write([]byte(`{"task": {"oid": "task-1", "executionState": "closed", "result": ` + taskResult + `}}`))
+		case r.URL.Path == "/users/1":
+			w.Write([]byte(`{"id": "1", "name": "jsmith"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id"})
+	if err != nil {
+		t.Fatalf("background_task_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(wait bool) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":               "/users",
+			"data":               `{"id": "1", "name": "jsmith"}`,
+			"wait_for_task":      wait,
+			"task_poll_interval": 1,
+		})
+	}
+
+	/* Without wait_for_task, the task is not read */
+	if err := resourceRestAPICreate(newResourceData(false), client); err != nil || taskReads != 0 {
+		t.Fatalf("background_task_test.go: Expected the create not to wait, got %v and %d task reads", err, taskReads)
+	}
+
+	d := newResourceData(true)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("background_task_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "1" || taskReads != 2 {
+		t.Fatalf("background_task_test.go: Expected the create to wait for the task, got %d task reads and %v", taskReads, d.State())
+	}
+
+	/* A failed task fails the update with its result */
+	taskReads = 0
+	taskResult = `{"operation": "execute", "status": "fatal_error", "message": "Policy violated", "partialResults": [{"operation": "projector", "status": "fatal_error", "message": "No password"}]}`
+	d.Set("data", `{"id": "1", "name": "jsmith", "fullName": "John Smith"}`)
+	err = resourceRestAPIUpdate(d, client)
+	if err == nil || !strings.Contains(err.Error(), "ended closed with the result fatal_error: Policy violated") || !strings.Contains(err.Error(), "- projector: fatal_error: No password") {
+		t.Fatalf("background_task_test.go: Expected the update to fail with the task result, got %v", err)
+	}
+
+	if oid := spawnedTask(`{"object": {"status": "success"}}`); oid != "" {
+		t.Fatalf("background_task_test.go: Expected no task for a result without a reference, got '%s'", oid)
+	}
+}
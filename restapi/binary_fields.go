@@ -0,0 +1,278 @@
+package restapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* Prefix of the hashes binary values are compared by */
+const binaryHashPrefix = "sha256:"
+
+/*
+binaryField is a binary property of the object, such as midPoint's jpegPhoto,
+
+	sent base64 encoded at path. Its content comes from file or from
+	contentBase64; the latter holds only a hash when it is read back from
+	state, in which case the content is unknown and the property is left
+	as it is on the server.
+*/
+type binaryField struct {
+	path          string
+	file          string
+	contentBase64 string
+}
+
+func binaryFieldSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Binary properties of the object, such as midPoint's `jpegPhoto`. Their content is sent base64 encoded at `path`, is left out of `data`, `api_data` and `api_response`, and is compared by its SHA-256 hash (see `binary_hashes`) instead of as text.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"path": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The dotted path of the property in the object, such as `jpegPhoto` or `user.jpegPhoto`.",
+				},
+				"file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "A file holding the content. It is read at every plan, so changes to the file are detected.",
+				},
+				"content_base64": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "The base64 encoded content, for example from `filebase64()`. Only its hash is stored in state.",
+					StateFunc:   hashBinaryState,
+				},
+			},
+		},
+	}
+}
+
+/* hashBinaryState stores the hash of content_base64 in state instead of the content */
+func hashBinaryState(v interface{}) string {
+	content := v.(string)
+	if content == "" || strings.HasPrefix(content, binaryHashPrefix) {
+		return content
+	}
+	return hashBase64(content)
+}
+
+func expandBinaryFields(v []interface{}) []binaryField {
+	fields := make([]binaryField, 0, len(v))
+	for _, raw := range v {
+		if raw == nil {
+			continue
+		}
+		f := raw.(map[string]interface{})
+		fields = append(fields, binaryField{
+			path:          f["path"].(string),
+			file:          f["file"].(string),
+			contentBase64: f["content_base64"].(string),
+		})
+	}
+	return fields
+}
+
+/*
+withConfiguredContents replaces the hashes read back from state with the
+
+	contents in the raw configuration, which is available when planning and
+	applying but not when refreshing.
+*/
+func withConfiguredContents(fields []binaryField, config cty.Value) []binaryField {
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute("binary_field") {
+		return fields
+	}
+	blocks := config.GetAttr("binary_field")
+	if blocks.IsNull() || !blocks.IsKnown() {
+		return fields
+	}
+
+	contents := make(map[string]string)
+	for it := blocks.ElementIterator(); it.Next(); {
+		_, block := it.Element()
+		if block.IsNull() || !block.IsKnown() {
+			continue
+		}
+		path, content := block.GetAttr("path"), block.GetAttr("content_base64")
+		if path.IsKnown() && !path.IsNull() && content.IsKnown() && !content.IsNull() {
+			contents[path.AsString()] = content.AsString()
+		}
+	}
+
+	for i, f := range fields {
+		if content, ok := contents[f.path]; ok {
+			fields[i].contentBase64 = content
+		}
+	}
+	return fields
+}
+
+/* content returns the base64 encoded content of the field. known is false when only its hash is available */
+func (f binaryField) content() (content string, known bool, err error) {
+	if f.file != "" {
+		b, err := os.ReadFile(f.file)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read binary_field '%s' from '%s': %v", f.path, f.file, err)
+		}
+		return base64.StdEncoding.EncodeToString(b), true, nil
+	}
+	if f.contentBase64 == "" || strings.HasPrefix(f.contentBase64, binaryHashPrefix) {
+		return "", false, nil
+	}
+	return f.contentBase64, true, nil
+}
+
+/* hash returns the hash of the field's content */
+func (f binaryField) hash() (string, error) {
+	if f.file == "" && strings.HasPrefix(f.contentBase64, binaryHashPrefix) {
+		return f.contentBase64, nil
+	}
+	content, known, err := f.content()
+	if err != nil || !known {
+		return "", err
+	}
+	return hashBase64(content), nil
+}
+
+/* keyPath returns the field's path in the '/'-delimited form of GetObjectAtKey */
+func (f binaryField) keyPath() string {
+	return strings.ReplaceAll(f.path, ".", "/")
+}
+
+/* hashBase64 hashes the decoded content, or the text itself when it is not valid base64 */
+func hashBase64(content string) string {
+	b, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		b = []byte(content)
+	}
+	sum := sha256.Sum256(b)
+	return binaryHashPrefix + hex.EncodeToString(sum[:])
+}
+
+/* insertBinaryFields sets the known contents of the binary fields in data */
+func insertBinaryFields(data map[string]interface{}, fields []binaryField, debug bool) error {
+	for _, f := range fields {
+		content, known, err := f.content()
+		if err != nil {
+			return err
+		}
+		if !known {
+			continue
+		}
+		if err := SetObjectAtKey(data, f.keyPath(), content, debug); err != nil {
+			return fmt.Errorf("failed to set binary_field '%s': %v", f.path, err)
+		}
+	}
+	return nil
+}
+
+/* unknownBinaryPaths returns the paths of the binary fields whose content is not known */
+func unknownBinaryPaths(fields []binaryField) []string {
+	paths := make([]string, 0)
+	for _, f := range fields {
+		if _, known, err := f.content(); err == nil && !known {
+			paths = append(paths, f.path)
+		}
+	}
+	return paths
+}
+
+func binaryPaths(fields []binaryField) []string {
+	paths := make([]string, 0, len(fields))
+	for _, f := range fields {
+		paths = append(paths, f.path)
+	}
+	return paths
+}
+
+/*
+hashBinaryValues returns a copy of data whose binary fields are replaced by
+
+	their hashes, along with those hashes by path.
+*/
+func hashBinaryValues(data map[string]interface{}, fields []binaryField) (map[string]interface{}, map[string]string) {
+	hashes := make(map[string]string)
+	if len(fields) == 0 {
+		return data, hashes
+	}
+
+	hashed := copyMap(data)
+	for _, f := range fields {
+		value, err := GetObjectAtKey(hashed, f.keyPath(), false)
+		if err != nil {
+			continue
+		}
+		content, ok := value.(string)
+		if !ok {
+			continue
+		}
+		hashes[f.path] = hashBase64(content)
+		SetObjectAtKey(hashed, f.keyPath(), hashes[f.path], false)
+	}
+	return hashed, hashes
+}
+
+/* copyMap copies the maps along the way so values can be replaced without changing data */
+func copyMap(data map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = copyMap(m)
+		}
+		copied[k] = v
+	}
+	return copied
+}
+
+/* desiredBinaryHashes returns the hashes of the configured contents by path */
+func desiredBinaryHashes(fields []binaryField) (map[string]interface{}, error) {
+	hashes := make(map[string]interface{})
+	for _, f := range fields {
+		hash, err := f.hash()
+		if err != nil {
+			return nil, err
+		}
+		if hash != "" {
+			hashes[f.path] = hash
+		}
+	}
+	return hashes, nil
+}
+
+/*
+customizeDiffBinaryHashes plans binary_hashes with the hashes of the
+
+	configured contents, so a binary property whose content differs from
+	the server's (including a changed file) is updated.
+*/
+func customizeDiffBinaryHashes(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	raw, ok := d.Get("binary_field").([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	if !d.NewValueKnown("binary_field") {
+		return d.SetNewComputed("binary_hashes")
+	}
+
+	desired, err := desiredBinaryHashes(withConfiguredContents(expandBinaryFields(raw), d.GetRawConfig()))
+	if err != nil {
+		return err
+	}
+	if current, ok := d.Get("binary_hashes").(map[string]interface{}); ok && reflect.DeepEqual(current, desired) {
+		return nil
+	}
+	return d.SetNew("binary_hashes", desired)
+}
@@ -0,0 +1,130 @@
+package restapi
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestBinaryFields(t *testing.T) {
+	photo := base64.StdEncoding.EncodeToString([]byte{0xff, 0xd8, 0xff, 0xe0})
+	hash := hashBase64(photo)
+
+	if hashBinaryState(photo) != hash || hashBinaryState(hash) != hash {
+		t.Fatalf("binary_fields_test.go: Expected the content and its hash to be stored as the hash")
+	}
+
+	fields := []binaryField{{path: "user.jpegPhoto", contentBase64: photo}, {path: "user.thumbnail", contentBase64: hash}}
+	data := MapAny{"user": MapAny{"name": "jsmith"}}
+	if err := insertBinaryFields(data, fields, false); err != nil {
+		t.Fatalf("binary_fields_test.go: Failed to insert binary fields: %s", err)
+	}
+	expected := MapAny{"user": MapAny{"name": "jsmith", "jpegPhoto": photo}}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("binary_fields_test.go: Expected only the known content to be inserted: expected %v, got %v", expected, data)
+	}
+	if unknown := unknownBinaryPaths(fields); !reflect.DeepEqual(unknown, []string{"user.thumbnail"}) {
+		t.Fatalf("binary_fields_test.go: Expected user.thumbnail to be unknown, got %v", unknown)
+	}
+
+	hashed, hashes := hashBinaryValues(data, fields)
+	if hashed["user"].(MapAny)["jpegPhoto"] != hash || !reflect.DeepEqual(hashes, map[string]string{"user.jpegPhoto": hash}) {
+		t.Fatalf("binary_fields_test.go: Expected the content to be replaced by its hash, got %v and %v", hashed, hashes)
+	}
+	if data["user"].(MapAny)["jpegPhoto"] != photo {
+		t.Fatalf("binary_fields_test.go: Expected hashing to leave the data unchanged")
+	}
+}
+
+func TestBinaryFieldsDiff(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1})
+	if err != nil {
+		t.Fatalf("binary_fields_test.go: Failed to create API client: %s", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(file, []byte("first"), 0o600); err != nil {
+		t.Fatalf("binary_fields_test.go: Failed to write photo: %s", err)
+	}
+	firstHash := hashBase64(base64.StdEncoding.EncodeToString([]byte("first")))
+
+	state := &terraform.InstanceState{
+		ID: "1",
+		Attributes: map[string]string{
+			"id":                      "1",
+			"path":                    "/users",
+			"data":                    `{"id": "1", "name": "jsmith"}`,
+			"binary_field.#":          "1",
+			"binary_field.0.path":     "jpegPhoto",
+			"binary_field.0.file":     file,
+			"binary_hashes.%":         "1",
+			"binary_hashes.jpegPhoto": firstHash,
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"id": "1", "name": "jsmith"}`,
+		"binary_field": []interface{}{map[string]interface{}{"path": "jpegPhoto", "file": file}},
+	})
+
+	diff, err := resourceRestAPI().Diff(context.TODO(), state, config, client)
+	if err != nil {
+		t.Fatalf("binary_fields_test.go: Diff failed: %s", err)
+	}
+	if diff != nil && diff.Attributes["binary_hashes.jpegPhoto"] != nil {
+		t.Fatalf("binary_fields_test.go: Expected no diff for an unchanged file, got %v", diff.Attributes["binary_hashes.jpegPhoto"])
+	}
+
+	if err := os.WriteFile(file, []byte("second"), 0o600); err != nil {
+		t.Fatalf("binary_fields_test.go: Failed to write photo: %s", err)
+	}
+	diff, err = resourceRestAPI().Diff(context.TODO(), state, config, client)
+	if err != nil {
+		t.Fatalf("binary_fields_test.go: Diff failed: %s", err)
+	}
+	secondHash := hashBase64(base64.StdEncoding.EncodeToString([]byte("second")))
+	if diff == nil || diff.Attributes["binary_hashes.jpegPhoto"] == nil || diff.Attributes["binary_hashes.jpegPhoto"].New != secondHash {
+		t.Fatalf("binary_fields_test.go: Expected a changed file to change its hash to %s, got %v", secondHash, diff)
+	}
+}
+
+func TestBinaryFieldsContentDiff(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1})
+	if err != nil {
+		t.Fatalf("binary_fields_test.go: Failed to create API client: %s", err)
+	}
+
+	photo := base64.StdEncoding.EncodeToString([]byte("photo"))
+	state := &terraform.InstanceState{
+		ID: "1",
+		Attributes: map[string]string{
+			"id":                            "1",
+			"path":                          "/users",
+			"data":                          `{"id": "1"}`,
+			"binary_field.#":                "1",
+			"binary_field.0.path":           "jpegPhoto",
+			"binary_field.0.content_base64": hashBase64(photo),
+			"binary_hashes.%":               "1",
+			"binary_hashes.jpegPhoto":       hashBase64(photo),
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"id": "1"}`,
+		"binary_field": []interface{}{map[string]interface{}{"path": "jpegPhoto", "content_base64": photo}},
+	})
+	diff, err := resourceRestAPI().Diff(context.TODO(), state, config, client)
+	if err != nil {
+		t.Fatalf("binary_fields_test.go: Diff failed: %s", err)
+	}
+	for _, key := range []string{"binary_field.0.content_base64", "binary_hashes.jpegPhoto"} {
+		if diff != nil && diff.Attributes[key] != nil {
+			t.Fatalf("binary_fields_test.go: Expected the content to be compared by its hash, got a diff of %s: %v", key, diff.Attributes[key])
+		}
+	}
+}
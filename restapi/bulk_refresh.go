@@ -0,0 +1,152 @@
+package restapi
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+/* How long a refresh waits for the refreshes of other objects at the same path to join its search */
+var bulkRefreshWindow = 50 * time.Millisecond
+
+/* The most objects read by one search */
+const bulkRefreshBatchSize = 100
+
+/*
+bulkRefresher reads the objects refreshed at about the same time at one path
+
+	with one inOid search, instead of a GET per object.
+*/
+type bulkRefresher struct {
+	mutex   sync.Mutex
+	batches map[string]*bulkRefreshBatch
+}
+
+/* The objects of a path waiting for one search; done is closed once found is filled */
+type bulkRefreshBatch struct {
+	oids  []string
+	done  chan struct{}
+	found map[string]map[string]interface{}
+	err   error
+}
+
+func newBulkRefresher(enabled bool) *bulkRefresher {
+	if !enabled {
+		return nil
+	}
+	return &bulkRefresher{batches: make(map[string]*bulkRefreshBatch)}
+}
+
+/*
+read returns the object with the oid at path, as found by the search of the
+
+	batch it joined, or nil when the search did not return it.
+*/
+func (b *bulkRefresher) read(client *APIClient, path string, oid string) (map[string]interface{}, error) {
+	b.mutex.Lock()
+	batch, ok := b.batches[path]
+	if !ok {
+		batch = &bulkRefreshBatch{done: make(chan struct{})}
+		b.batches[path] = batch
+		go func() {
+			time.Sleep(bulkRefreshWindow)
+			b.mutex.Lock()
+			if b.batches[path] == batch {
+				delete(b.batches, path)
+			}
+			b.mutex.Unlock()
+			batch.found, batch.err = searchByOid(client, path, batch.oids)
+			close(batch.done)
+		}()
+	}
+	batch.oids = append(batch.oids, oid)
+	if len(batch.oids) >= bulkRefreshBatchSize {
+		/* Full; later refreshes start the next batch */
+		delete(b.batches, path)
+	}
+	b.mutex.Unlock()
+
+	<-batch.done
+	return batch.found[oid], batch.err
+}
+
+/* searchByOid reads the objects with the oids at path with one midPoint search, returning them unwrapped by oid */
+func searchByOid(client *APIClient, path string, oids []string) (map[string]map[string]interface{}, error) {
+	log.Printf("bulk_refresh.go: Reading %d objects at '%s' with one search", len(oids), path)
+	search, _ := json.Marshal(map[string]interface{}{"query": map[string]interface{}{
+		"filter": map[string]interface{}{"inOid": map[string]interface{}{"value": oids}},
+		"paging": map[string]interface{}{"maxSize": len(oids)},
+	}})
+	body, err := client.sendRequest("POST", path+"/search", string(search))
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := decodeJSON(body, &result); err != nil {
+		return nil, err
+	}
+	records, err := GetObjectAtKey(result, "object/object", false)
+	if err != nil {
+		return nil, err
+	}
+
+	list, _ := asValueList(records)
+	found := make(map[string]map[string]interface{}, len(list))
+	for _, record := range list {
+		if hash, ok := record.(map[string]interface{}); ok {
+			object := midpointObjectOf(hash)
+			found[stringAtKey(object, "oid")] = object
+		}
+	}
+	return found, nil
+}
+
+/*
+bulkRefreshable reports whether the object is read by a plain GET of
+
+	path/{id}, whose response a search of path can stand in for.
+*/
+func (obj *APIObject) bulkRefreshable() bool {
+	return obj.apiClient.bulkRefresh != nil && obj.id != "" &&
+		obj.getPath == obj.postPath+"/{id}" && obj.readMethod == "GET" &&
+		len(obj.readData) == 0 && len(obj.readSearch) == 0 &&
+		obj.operationQueryString("read") == "" && obj.wrapperKey == "" &&
+		len(obj.readInclude) == 0 && len(obj.readExclude) == 0
+}
+
+/*
+refreshObject reads the object for a refresh: with bulk_refresh, from the
+
+	search of a batch of objects at its path, or else like readObject. An
+	object the search did not return, or one whose search failed, is read
+	on its own, so missing objects are handled as always.
+*/
+func (obj *APIObject) refreshObject() error {
+	if !obj.bulkRefreshable() {
+		return obj.readObject()
+	}
+	object, err := obj.apiClient.bulkRefresh.read(obj.apiClient, obj.postPath, obj.id)
+	if err != nil || object == nil {
+		if err != nil {
+			log.Printf("bulk_refresh.go: The search for '%s' failed, reading it on its own: %v", obj.id, err)
+		}
+		return obj.readObject()
+	}
+
+	/* Searches name the type of every object, which a read of the object does not */
+	hydrated := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		if key != "@type" {
+			hydrated[key] = value
+		}
+	}
+	encoded, err := json.Marshal(withWrapperOf(obj.data, hydrated))
+	if err != nil {
+		return err
+	}
+	if err := obj.updateState(string(encoded)); err != nil {
+		return err
+	}
+	return obj.readFieldGroups()
+}
@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* countingTransport counts the requests by method and path */
+type countingTransport struct {
+	next     http.RoundTripper
+	mutex    sync.Mutex
+	requests []string
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mutex.Lock()
+	t.requests = append(t.requests, req.Method+" "+req.URL.Path)
+	t.mutex.Unlock()
+	return t.next.RoundTrip(req)
+}
+
+func TestBulkRefresh(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", mode: clientModeMemory, idAttribute: "oid", bulkRefresh: true})
+	if err != nil {
+		t.Fatalf("bulk_refresh_test.go: Failed to create API client: %s", err)
+	}
+	resources := make([]*schema.ResourceData, 5)
+	for i := range resources {
+		resources[i] = schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path": "/users",
+			"data": fmt.Sprintf(`{"oid": "user-%d", "name": "user%d"}`, i, i),
+		})
+		if err := resourceRestAPICreate(resources[i], client); err != nil {
+			t.Fatalf("bulk_refresh_test.go: Create failed: %s", err)
+		}
+	}
+	if _, err := client.sendRequest("PUT", "/users/user-3", `{"oid": "user-3", "name": "changed"}`); err != nil {
+		t.Fatalf("bulk_refresh_test.go: Failed to change the object: %s", err)
+	}
+	if _, err := client.sendRequest("DELETE", "/users/user-4", ""); err != nil {
+		t.Fatalf("bulk_refresh_test.go: Failed to delete the object: %s", err)
+	}
+
+	transport := &countingTransport{next: client.httpClient.Transport}
+	client.httpClient.Transport = transport
+	var wg sync.WaitGroup
+	for _, d := range resources {
+		wg.Add(1)
+		go func(d *schema.ResourceData) {
+			defer wg.Done()
+			if err := resourceRestAPIRead(d, client); err != nil {
+				t.Errorf("bulk_refresh_test.go: Read failed: %s", err)
+			}
+		}(d)
+	}
+	wg.Wait()
+
+	/* One search, and a GET of the object it did not return */
+	if strings.Join(transport.requests, ", ") != "POST /users/search, GET /users/user-4" {
+		t.Fatalf("bulk_refresh_test.go: Expected one search for all objects, got %v", transport.requests)
+	}
+	if !strings.Contains(resources[3].Get("data").(string), "changed") || resources[0].Get("data") != `{"name":"user0","oid":"user-0"}` {
+		t.Fatalf("bulk_refresh_test.go: Expected the objects from the search in state, got %s and %s", resources[3].Get("data"), resources[0].Get("data"))
+	}
+	if resources[4].Id() != "" {
+		t.Fatalf("bulk_refresh_test.go: Expected the deleted object to be removed from state, got '%s'", resources[4].Id())
+	}
+}
@@ -0,0 +1,54 @@
+package restapi
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+/*
+canonicalJSON encodes v the way Terraform's jsonencode() does: keys sorted,
+
+	no whitespace and the same escaping. Numbers keep the literal they were
+	decoded from (see unmarshalJSON), and other numbers are written without
+	an exponent, so a value read back from the server encodes to the same
+	text as the configuration instead of showing up as a change.
+*/
+func canonicalJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(canonicalValue(v))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+/* canonicalValue returns v with its float64 numbers replaced by their literals */
+func canonicalValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		canonical := make(map[string]interface{}, len(value))
+		for k, elem := range value {
+			canonical[k] = canonicalValue(elem)
+		}
+		return canonical
+	case []interface{}:
+		canonical := make([]interface{}, len(value))
+		for i, elem := range value {
+			canonical[i] = canonicalValue(elem)
+		}
+		return canonical
+	case float64:
+		return json.Number(strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return v
+}
+
+/* sameCanonicalJSON reports whether the JSON documents a and b encode to the same canonical text */
+func sameCanonicalJSON(a string, b string) bool {
+	var decodedA, decodedB interface{}
+	if unmarshalJSON([]byte(a), &decodedA) != nil || unmarshalJSON([]byte(b), &decodedB) != nil {
+		return false
+	}
+	canonicalA, errA := canonicalJSON(decodedA)
+	canonicalB, errB := canonicalJSON(decodedB)
+	return errA == nil && errB == nil && canonicalA == canonicalB
+}
@@ -0,0 +1,25 @@
+package restapi
+
+import "testing"
+
+func TestCanonicalJSON(t *testing.T) {
+	/* Keys are sorted and numbers keep their literals, like jsonencode() */
+	var data map[string]interface{}
+	unmarshalJSON([]byte(`{ "name": "a<b", "limit": 1000000000, "id": 12345678901234567890, "nested": {"z": 1.50, "a": [2, 1]} }`), &data)
+	encoded, err := canonicalJSON(data)
+	if err != nil || encoded != `{"id":12345678901234567890,"limit":1000000000,"name":"a\u003cb","nested":{"a":[2,1],"z":1.50}}` {
+		t.Fatalf("canonical_json_test.go: Unexpected encoding %s, %v", encoded, err)
+	}
+
+	/* Numbers from other sources are written without an exponent */
+	if encoded, _ := canonicalJSON(map[string]interface{}{"limit": 1e9, "ratio": 0.25}); encoded != `{"limit":1000000000,"ratio":0.25}` {
+		t.Fatalf("canonical_json_test.go: Unexpected encoding of float64 numbers %s", encoded)
+	}
+
+	if !sameCanonicalJSON(`{"b": 1, "a": {"c": true}}`, "{\n  \"a\": {\"c\": true},\n  \"b\": 1\n}") {
+		t.Fatalf("canonical_json_test.go: Expected documents differing in key order and whitespace to be the same")
+	}
+	if sameCanonicalJSON(`{"a": 1}`, `{"a": 2}`) || sameCanonicalJSON(`{"a": 1}`, `not json`) {
+		t.Fatalf("canonical_json_test.go: Expected different documents to differ")
+	}
+}
@@ -1,9 +1,11 @@
 package restapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,12 +14,36 @@ import (
 
 // After any operation that returns API data, we'll stuff all the k,v pairs into the api_data map so users can consume the values elsewhere if they'd like
 func setResourceState(obj *APIObject, d *schema.ResourceData) {
+	/* Binary properties are only exposed by their hashes */
+	hashedData, binaryHashes := hashBinaryValues(obj.apiData, obj.binaryFields)
+
 	apiData := make(map[string]string)
-	for k, v := range obj.apiData {
+	for k, v := range hashedData {
 		apiData[k] = fmt.Sprintf("%v", v)
 	}
 	d.Set("api_data", apiData)
-	d.Set("api_response", obj.apiResponse)
+
+	apiResponse := obj.apiResponse
+	if len(binaryHashes) > 0 {
+		if b, err := json.Marshal(hashedData); err == nil {
+			apiResponse = string(b)
+		}
+	}
+	d.Set("api_response", apiResponse)
+
+	/* A binary property the server does not return keeps its last known hash */
+	if len(obj.binaryFields) > 0 {
+		previous, _ := d.Get("binary_hashes").(map[string]interface{})
+		hashes := make(map[string]interface{})
+		for _, path := range binaryPaths(obj.binaryFields) {
+			if hash, ok := binaryHashes[path]; ok {
+				hashes[path] = hash
+			} else if hash, ok := previous[path]; ok {
+				hashes[path] = hash
+			}
+		}
+		d.Set("binary_hashes", hashes)
+	}
 }
 
 // GetStringAtKey uses GetObjectAtKey to verify the resulting object is either a JSON string or Number and returns it as a string
@@ -33,10 +59,12 @@ func GetStringAtKey(data map[string]interface{}, path string, debug bool) (strin
 		return tmp, nil
 	case float64:
 		return strconv.FormatFloat(tmp, 'f', -1, 64), nil
+	case json.Number:
+		return tmp.String(), nil
 	case bool:
 		return fmt.Sprintf("%v", res), nil
 	default:
-		return "", fmt.Errorf("object at path '%s' is not a JSON string or number (float64) - the go fmt package says it is '%T'", path, res)
+		return "", fmt.Errorf("object at path '%s' is not a JSON string or number - the go fmt package says it is '%T'", path, res)
 	}
 }
 
@@ -128,6 +156,41 @@ func GetObjectAtKey(data map[string]interface{}, path string, debug bool) (inter
 	return hash[part], nil
 }
 
+// SetObjectAtKey is the counterpart of GetObjectAtKey: it stores value at the '/'-delimited path, creating intermediate maps as needed
+func SetObjectAtKey(data map[string]interface{}, path string, value interface{}, debug bool) error {
+	hash := data
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	seen := ""
+
+	for _, part := range parts[:len(parts)-1] {
+		/* Protect against double slashes by mistake */
+		if part == "" {
+			continue
+		}
+		seen += "/" + part
+
+		next, ok := hash[part]
+		if !ok || next == nil {
+			if debug {
+				log.Printf("common.go:SetObjectAtKey:  %s - creating map", part)
+			}
+			tmp := make(map[string]interface{})
+			hash[part] = tmp
+			hash = tmp
+			continue
+		}
+
+		tmp, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("SetObjectAtKey: Object at '%s' is not a map. Is this the right path?", seen)
+		}
+		hash = tmp
+	}
+
+	hash[parts[len(parts)-1]] = value
+	return nil
+}
+
 // GetKeys is a handy helper to just dump the keys of a map into a slice
 func GetKeys(hash map[string]interface{}) []string {
 	keys := make([]string, 0)
@@ -146,6 +209,16 @@ func GetEnvOrDefault(k string, defaultvalue string) string {
 	return v
 }
 
+// sortedStringKeys returns the keys of a string map in a stable order
+func sortedStringKeys(hash map[string]string) []string {
+	keys := make([]string, 0, len(hash))
+	for k := range hash {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func expandStringSet(configured []interface{}) []string {
 	return expandStringList(configured)
 }
@@ -171,3 +171,32 @@ func TestGetListStringAtKey(t *testing.T) {
 		t.Fatalf("Error: Expected '2', but got %s", res)
 	}
 }
+
+func TestSetObjectAtKey(t *testing.T) {
+	debug := false
+	testObj := map[string]interface{}{
+		"rootFoo": "bar",
+		"top":     map[string]interface{}{"foo": "bar"},
+	}
+
+	if err := SetObjectAtKey(testObj, "top/members", []interface{}{"a", "b"}, debug); err != nil {
+		t.Fatalf("Error setting 'top/members': %s", err)
+	}
+	if res, _ := GetObjectAtKey(testObj, "top/members", debug); len(res.([]interface{})) != 2 {
+		t.Fatalf("Error: Expected 'top/members' to hold 2 items, but got %v", res)
+	}
+	if res, _ := GetStringAtKey(testObj, "top/foo", debug); res != "bar" {
+		t.Fatalf("Error: Expected sibling 'top/foo' to be kept, but got %s", res)
+	}
+
+	if err := SetObjectAtKey(testObj, "new/nested/key", "value", debug); err != nil {
+		t.Fatalf("Error setting 'new/nested/key': %s", err)
+	}
+	if res, _ := GetStringAtKey(testObj, "new/nested/key", debug); res != "value" {
+		t.Fatalf("Error: Expected 'value', but got %s", res)
+	}
+
+	if err := SetObjectAtKey(testObj, "rootFoo/child", "value", debug); err == nil {
+		t.Fatalf("Error expected when setting a key below the string 'rootFoo'")
+	}
+}
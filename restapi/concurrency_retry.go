@@ -0,0 +1,62 @@
+package restapi
+
+import (
+	"strings"
+	"time"
+)
+
+/*
+Parts of the messages of failed midPoint responses caused by concurrent work on
+
+	the same objects or by the repository giving up on a transaction, which
+	succeed when sent again.
+*/
+var midpointConcurrencyMessages = []string{
+	"concurrent modification",
+	"concurrencyexception",
+	"could not serialize access",
+	"serialization failure",
+	"serializationfailure",
+	"deadlock detected",
+	"deadlock found",
+	"lock wait timeout",
+	"optimisticlockexception",
+	"pessimisticlockexception",
+}
+
+/* Wait before the first retry of a request that failed with a concurrency error; doubles with every attempt */
+var concurrencyRetryDelay = 500 * time.Millisecond
+
+var concurrencyMaxDelay = 10 * time.Second
+
+/*
+isConcurrencyError reports whether err was caused by a failed response whose
+
+	body tells of a concurrency or locking error, whatever its status code.
+	409 Conflict is left to update_conflict_retries, which recomputes the
+	deltas against the object as it is now instead of sending them again.
+*/
+func isConcurrencyError(err error) bool {
+	if err == nil || !strings.Contains(err.Error(), "unexpected response code") || isConflictError(err) {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, part := range midpointConcurrencyMessages {
+		if strings.Contains(message, part) {
+			return true
+		}
+	}
+	return false
+}
+
+/* concurrencyDelay returns how long to wait before sending a request again after its attempt'th concurrency error */
+func concurrencyDelay(attempt int) time.Duration {
+	delay := concurrencyRetryDelay
+	for i := 0; i < attempt && delay < concurrencyMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > concurrencyMaxDelay {
+		return concurrencyMaxDelay
+	}
+	return delay
+}
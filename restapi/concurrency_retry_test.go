@@ -0,0 +1,52 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyRetries(t *testing.T) {
+	defer func(delay time.Duration) { concurrencyRetryDelay = delay }(concurrencyRetryDelay)
+	concurrencyRetryDelay = time.Millisecond
+
+	attempts := 0
+	failures := 2
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status": "fatal_error", "message": "Concurrent modification of object 00000000-0000-0000-0000-000000000002"}`))
+			return
+		}
+		w.Write([]byte(`{"oid": "user-1"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, concurrencyRetries: 3})
+	if err != nil {
+		t.Fatalf("concurrency_retry_test.go: Failed to create API client: %s", err)
+	}
+	if _, err := client.sendRequest("PATCH", "/users/user-1", "{}"); err != nil || attempts != 3 {
+		t.Fatalf("concurrency_retry_test.go: Expected the request to succeed on the third attempt, got %v after %d attempts", err, attempts)
+	}
+
+	/* Retries run out */
+	attempts = 0
+	failures = 10
+	if _, err := client.sendRequest("PATCH", "/users/user-1", "{}"); err == nil || attempts != 4 {
+		t.Fatalf("concurrency_retry_test.go: Expected the request to fail after 4 attempts, got %v after %d attempts", err, attempts)
+	}
+
+	/* Other errors are not retried */
+	svr.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message": "Schema violation"}`))
+	})
+	attempts = 0
+	if _, err := client.sendRequest("PATCH", "/users/user-1", "{}"); err == nil || attempts != 1 {
+		t.Fatalf("concurrency_retry_test.go: Expected other errors to fail at once, got %v after %d attempts", err, attempts)
+	}
+}
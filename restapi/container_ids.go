@@ -0,0 +1,172 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+)
+
+/* Keys midPoint uses for the identifier of a container value */
+var containerIDKeys = []string{"@id", "id"}
+
+/*
+diffContainersByID compares two values of a multi-value container whose
+
+	current values carry midPoint container ids (@id or id). Desired values
+	are matched to current ones by their own id, by mergeKey when one is
+	configured, or otherwise by being equal apart from the id. Matched values
+	keep their id and only the items that changed inside them are modified,
+	using paths such as assignment/[3]/description. Current values without a
+	match are deleted by id and desired values without a match are added.
+
+	If a current value has no id the containers cannot be matched and ok is
+	false, so the caller should fall back to other strategies.
+*/
+func diffContainersByID(path string, current []interface{}, desired []interface{}, mergeKey string, ignoreList []string, debug bool) (deltas []midpointItemDelta, ok bool) {
+	currentByID := make(map[string]map[string]interface{}, len(current))
+	currentIDs := make([]string, 0, len(current))
+	for _, elem := range current {
+		hash, isMap := elem.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+		id, hasID := containerIDString(hash)
+		if !hasID {
+			return nil, false
+		}
+		if _, duplicate := currentByID[id]; duplicate {
+			return nil, false
+		}
+		currentByID[id] = hash
+		currentIDs = append(currentIDs, id)
+	}
+
+	/* Ids never count as a change when comparing values */
+	compareIgnoreList := append(append([]string{}, ignoreList...), containerIDKeys...)
+
+	matched := make(map[string]bool, len(current))
+	modifications := make([]midpointItemDelta, 0)
+	toAdd := make([]interface{}, 0)
+
+	for _, elem := range desired {
+		hash, isMap := elem.(map[string]interface{})
+		if !isMap {
+			return nil, false
+		}
+
+		id := matchContainerValue(hash, currentIDs, currentByID, matched, mergeKey, compareIgnoreList, debug)
+		if id == "" {
+			toAdd = append(toAdd, elem)
+			continue
+		}
+		matched[id] = true
+
+		if listElementChanged(currentByID[id], hash, compareIgnoreList) {
+			valuePath := fmt.Sprintf("%s/[%s]", path, id)
+			if debug {
+				log.Printf("container_ids.go: Modifying container value '%s' in place", valuePath)
+			}
+			modifications = append(modifications, containerValueDeltas(valuePath, currentByID[id], hash, ignoreList)...)
+		}
+	}
+
+	toDelete := make([]interface{}, 0)
+	for _, id := range currentIDs {
+		if matched[id] {
+			continue
+		}
+		idKey, idValue, _ := containerIDOf(currentByID[id])
+		toDelete = append(toDelete, map[string]interface{}{idKey: idValue})
+	}
+
+	if debug {
+		log.Printf("container_ids.go: '%s' matched by container id: %d value(s) to add, %d value(s) to delete, %d item(s) to modify", path, len(toAdd), len(toDelete), len(modifications))
+	}
+
+	deltas = make([]midpointItemDelta, 0, len(modifications)+2)
+	if len(toDelete) > 0 {
+		deltas = append(deltas, midpointItemDelta{modificationType: "delete", path: path, value: toDelete})
+	}
+	deltas = append(deltas, modifications...)
+	if len(toAdd) > 0 {
+		deltas = append(deltas, midpointItemDelta{modificationType: "add", path: path, value: toAdd})
+	}
+	return deltas, true
+}
+
+/* matchContainerValue returns the id of the unmatched current value that corresponds to desired, or "" */
+func matchContainerValue(desired map[string]interface{}, currentIDs []string, currentByID map[string]map[string]interface{}, matched map[string]bool, mergeKey string, ignoreList []string, debug bool) string {
+	if id, hasID := containerIDString(desired); hasID {
+		if _, exists := currentByID[id]; exists && !matched[id] {
+			return id
+		}
+		return ""
+	}
+
+	if mergeKey != "" {
+		key, ok := mergeKeyOf(desired, mergeKey, debug)
+		if !ok {
+			return ""
+		}
+		for _, id := range currentIDs {
+			if currentKey, ok := mergeKeyOf(currentByID[id], mergeKey, debug); ok && !matched[id] && currentKey == key {
+				return id
+			}
+		}
+		return ""
+	}
+
+	for _, id := range currentIDs {
+		if !matched[id] && !listElementChanged(currentByID[id], desired, ignoreList) {
+			return id
+		}
+	}
+	return ""
+}
+
+/* containerValueDeltas returns the deltas that turn one container value into another, item by item */
+func containerValueDeltas(valuePath string, current map[string]interface{}, desired map[string]interface{}, ignoreList []string) []midpointItemDelta {
+	deltas := make([]midpointItemDelta, 0)
+
+	for _, key := range sortedKeys(desired) {
+		if contains(containerIDKeys, key) || matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		itemPath := fmt.Sprintf("%s/%s", valuePath, key)
+		currentValue, exists := current[key]
+		if !exists {
+			deltas = append(deltas, midpointItemDelta{modificationType: "add", path: itemPath, value: desired[key]})
+		} else if listElementChanged(currentValue, desired[key], _descendIgnoreList(key, ignoreList)) {
+			deltas = append(deltas, midpointItemDelta{modificationType: "replace", path: itemPath, value: desired[key]})
+		}
+	}
+
+	for _, key := range sortedKeys(current) {
+		if contains(containerIDKeys, key) || matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		if _, exists := desired[key]; !exists {
+			deltas = append(deltas, midpointItemDelta{modificationType: "delete", path: fmt.Sprintf("%s/%s", valuePath, key)})
+		}
+	}
+
+	return deltas
+}
+
+/* containerIDOf returns the key and raw value of a container value's id */
+func containerIDOf(value map[string]interface{}) (string, interface{}, bool) {
+	for _, key := range containerIDKeys {
+		if id, ok := value[key]; ok && id != nil {
+			return key, id, true
+		}
+	}
+	return "", nil, false
+}
+
+/* containerIDString returns a container value's id in the form used in item paths */
+func containerIDString(value map[string]interface{}) (string, bool) {
+	_, id, ok := containerIDOf(value)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(id), true
+}
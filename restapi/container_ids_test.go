@@ -0,0 +1,90 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffContainersByID(t *testing.T) {
+	current := []interface{}{
+		MapAny{"@id": float64(1), "targetRef": MapAny{"oid": "end-user"}},
+		MapAny{"@id": float64(2), "targetRef": MapAny{"oid": "approver"}, "description": "old"},
+		MapAny{"@id": float64(3), "targetRef": MapAny{"oid": "superuser"}},
+	}
+
+	t.Run("match_by_value", func(t *testing.T) {
+		/* Unchanged values are matched even though the configuration carries no ids */
+		desired := []interface{}{
+			MapAny{"targetRef": MapAny{"oid": "end-user"}},
+			MapAny{"targetRef": MapAny{"oid": "approver"}, "description": "old"},
+			MapAny{"targetRef": MapAny{"oid": "new-role"}},
+		}
+		deltas, ok := diffContainersByID("assignment", current, desired, "", nil, false)
+		if !ok {
+			t.Fatalf("container_ids_test.go: Expected containers to be matched by id")
+		}
+		expected := []midpointItemDelta{
+			{modificationType: "delete", path: "assignment", value: []interface{}{MapAny{"@id": float64(3)}}},
+			{modificationType: "add", path: "assignment", value: []interface{}{MapAny{"targetRef": MapAny{"oid": "new-role"}}}},
+		}
+		if !reflect.DeepEqual(expected, deltas) {
+			t.Fatalf("container_ids_test.go: Unexpected deltas: expected %v but got %v", expected, deltas)
+		}
+	})
+
+	t.Run("match_by_merge_key", func(t *testing.T) {
+		desired := []interface{}{
+			MapAny{"targetRef": MapAny{"oid": "end-user"}},
+			MapAny{"targetRef": MapAny{"oid": "approver"}, "activation": MapAny{"administrativeStatus": "disabled"}},
+			MapAny{"targetRef": MapAny{"oid": "superuser"}},
+		}
+		deltas, ok := diffContainersByID("assignment", current, desired, "targetRef/oid", nil, false)
+		if !ok {
+			t.Fatalf("container_ids_test.go: Expected containers to be matched by id")
+		}
+		expected := []midpointItemDelta{
+			{modificationType: "add", path: "assignment/[2]/activation", value: MapAny{"administrativeStatus": "disabled"}},
+			{modificationType: "delete", path: "assignment/[2]/description"},
+		}
+		if !reflect.DeepEqual(expected, deltas) {
+			t.Fatalf("container_ids_test.go: Unexpected deltas: expected %v but got %v", expected, deltas)
+		}
+	})
+
+	t.Run("match_by_explicit_id", func(t *testing.T) {
+		desired := []interface{}{
+			MapAny{"@id": 1, "targetRef": MapAny{"oid": "end-user"}},
+			MapAny{"@id": 2, "targetRef": MapAny{"oid": "approver"}, "description": "new"},
+			MapAny{"@id": 3, "targetRef": MapAny{"oid": "superuser"}},
+		}
+		deltas, ok := diffContainersByID("assignment", current, desired, "", nil, false)
+		if !ok {
+			t.Fatalf("container_ids_test.go: Expected containers to be matched by id")
+		}
+		expected := []midpointItemDelta{
+			{modificationType: "replace", path: "assignment/[2]/description", value: "new"},
+		}
+		if !reflect.DeepEqual(expected, deltas) {
+			t.Fatalf("container_ids_test.go: Unexpected deltas: expected %v but got %v", expected, deltas)
+		}
+	})
+
+	t.Run("ignored_items", func(t *testing.T) {
+		withMetadata := []interface{}{MapAny{"id": "7", "targetRef": MapAny{"oid": "end-user"}, "metadata": MapAny{"createTimestamp": "now"}}}
+		desired := []interface{}{MapAny{"targetRef": MapAny{"oid": "end-user"}}}
+		deltas, ok := diffContainersByID("assignment", withMetadata, desired, "", []string{"metadata"}, false)
+		if !ok || len(deltas) != 0 {
+			t.Fatalf("container_ids_test.go: Expected no deltas when only ids and ignored items differ, got %v", deltas)
+		}
+	})
+
+	t.Run("values_without_ids", func(t *testing.T) {
+		withoutIDs := []interface{}{MapAny{"targetRef": MapAny{"oid": "end-user"}}}
+		if _, ok := diffContainersByID("assignment", withoutIDs, withoutIDs, "", nil, false); ok {
+			t.Fatalf("container_ids_test.go: Expected matching to fail when current values have no id")
+		}
+		if _, ok := diffContainersByID("roleMembershipRef", []interface{}{"a", "b"}, []interface{}{"a"}, "", nil, false); ok {
+			t.Fatalf("container_ids_test.go: Expected matching to fail for non-container values")
+		}
+	})
+}
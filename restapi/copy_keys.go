@@ -0,0 +1,85 @@
+package restapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+copyKey copies the item at the dotted path key, such as
+
+	`metadata.createTimestamp`, from apiData into data. A `*` segment
+	matches every key of a map and every element of a list; elements of
+	lists are only copied into lists data already has. Top level keys are
+	copied even if the server does not have them, as copy_keys always did.
+*/
+func copyKey(data map[string]interface{}, apiData map[string]interface{}, key string) {
+	segments := strings.Split(key, ".")
+	if len(segments) == 1 {
+		data[key] = apiData[key]
+		return
+	}
+	copyKeySegments(data, apiData, segments)
+}
+
+/*
+copyKeySegments copies the items of source at segments into target,
+
+	returning target with them and whether any item was found. Maps on the
+	way are only added to target when they lead to an item.
+*/
+func copyKeySegments(target interface{}, source interface{}, segments []string) (interface{}, bool) {
+	if len(segments) == 0 {
+		return source, true
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch s := source.(type) {
+	case map[string]interface{}:
+		t, ok := target.(map[string]interface{})
+		if !ok {
+			t = make(map[string]interface{})
+		}
+		found := false
+		keys := []string{segment}
+		if segment == "*" {
+			keys = sortedKeys(s)
+		}
+		for _, k := range keys {
+			if value, ok := s[k]; ok {
+				if copied, ok := copyKeySegments(t[k], value, rest); ok {
+					t[k] = copied
+					found = true
+				}
+			}
+		}
+		if !found {
+			return target, false
+		}
+		return t, true
+	case []interface{}:
+		t, ok := target.([]interface{})
+		if !ok {
+			return target, false
+		}
+		found := false
+		indexes := []int{}
+		if segment == "*" {
+			for i := range s {
+				indexes = append(indexes, i)
+			}
+		} else if i, err := strconv.Atoi(segment); err == nil {
+			indexes = append(indexes, i)
+		}
+		for _, i := range indexes {
+			if i >= 0 && i < len(s) && i < len(t) {
+				if copied, ok := copyKeySegments(t[i], s[i], rest); ok {
+					t[i] = copied
+					found = true
+				}
+			}
+		}
+		return t, found
+	}
+	return target, false
+}
@@ -0,0 +1,44 @@
+package restapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCopyKey(t *testing.T) {
+	apiData := map[string]interface{}{
+		"revision": "4",
+		"metadata": map[string]interface{}{"createTimestamp": "2024-01-01T00:00:00Z", "creatorRef": map[string]interface{}{"oid": "admin"}},
+		"role":     map[string]interface{}{"oid": "role-1", "name": "Finance"},
+		"assignment": []interface{}{
+			map[string]interface{}{"id": 1.0, "targetRef": map[string]interface{}{"oid": "role-1"}},
+			map[string]interface{}{"id": 2.0, "targetRef": map[string]interface{}{"oid": "role-2"}},
+		},
+	}
+	data := map[string]interface{}{
+		"role":       map[string]interface{}{"name": "Finance"},
+		"assignment": []interface{}{map[string]interface{}{"targetRef": map[string]interface{}{"oid": "role-1"}}},
+	}
+
+	for _, key := range []string{"revision", "metadata.createTimestamp", "role.oid", "assignment.*.id", "extension.missing", "missing"} {
+		copyKey(data, apiData, key)
+	}
+	var expected map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"revision": "4",
+		"metadata": {"createTimestamp": "2024-01-01T00:00:00Z"},
+		"role": {"oid": "role-1", "name": "Finance"},
+		"assignment": [{"id": 1, "targetRef": {"oid": "role-1"}}],
+		"missing": null
+	}`), &expected)
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("copy_keys_test.go: Expected the nested keys to be copied, got %v", data)
+	}
+
+	data = map[string]interface{}{}
+	copyKey(data, apiData, "metadata.*.oid")
+	if !reflect.DeepEqual(data, map[string]interface{}{"metadata": map[string]interface{}{"creatorRef": map[string]interface{}{"oid": "admin"}}}) {
+		t.Fatalf("copy_keys_test.go: Expected a wildcard to match only the keys having the item, got %v", data)
+	}
+}
@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+)
+
+/*
+withCorrelationID returns a copy of the client sending one correlation id,
+
+	generated here, with every request of an operation on an object.
+*/
+func (client *APIClient) withCorrelationID() *APIClient {
+	if client.correlationHeader == "" {
+		return client
+	}
+	id, err := generateOID()
+	if err != nil {
+		return client
+	}
+	correlatedClient := *client
+	correlatedClient.correlationID = id
+	return &correlatedClient
+}
+
+/*
+correlationHeaders returns extraHeaders with the correlation id header and
+
+	the id, which is the operation's or else a new one for the request alone.
+	Without a correlation_id_header, extraHeaders are returned as they are.
+*/
+func (client *APIClient) correlationHeaders(extraHeaders map[string]string) (map[string]string, string) {
+	if client.correlationHeader == "" {
+		return extraHeaders, ""
+	}
+	id := client.correlationID
+	if id == "" {
+		generated, err := generateOID()
+		if err != nil {
+			return extraHeaders, ""
+		}
+		id = generated
+	}
+	headers := make(map[string]string, len(extraHeaders)+1)
+	for name, value := range extraHeaders {
+		headers[name] = value
+	}
+	headers[client.correlationHeader] = id
+	return headers, id
+}
+
+/* logCorrelation logs the outcome of a request with its correlation id, which also ends up in errors */
+func (client *APIClient) logCorrelation(method string, path string, id string, status int, err error) error {
+	if id == "" {
+		return err
+	}
+	if err != nil {
+		log.Printf("[WARN] api_client.go: %s %s failed (%s: %s): %v", method, path, client.correlationHeader, id, err)
+		return fmt.Errorf("%v (%s: %s)", err, client.correlationHeader, id)
+	}
+	log.Printf("[INFO] api_client.go: %s %s answered %d (%s: %s)", method, path, status, client.correlationHeader, id)
+	return nil
+}
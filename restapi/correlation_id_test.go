@@ -0,0 +1,54 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCorrelationID(t *testing.T) {
+	var ids []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Correlation-ID"))
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id": "1", "name": "jsmith"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id", correlationHeader: "X-Correlation-ID"})
+	if err != nil {
+		t.Fatalf("correlation_id_test.go: Failed to create API client: %s", err)
+	}
+
+	/* Requests of one operation share the id */
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path": "/users",
+		"data": `{"id": "1", "name": "jsmith"}`,
+	})
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("correlation_id_test.go: Create failed: %s", err)
+	}
+	if len(ids) < 2 || ids[0] == "" || ids[len(ids)-1] != ids[0] {
+		t.Fatalf("correlation_id_test.go: Expected the requests of the create to share an id, got %v", ids)
+	}
+	created := ids[0]
+
+	/* Other operations and requests get their own */
+	ids = nil
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("correlation_id_test.go: Read failed: %s", err)
+	}
+	_, err = client.sendRequest("GET", "/missing", "")
+	if len(ids) != 2 || ids[0] == created || ids[1] == ids[0] || ids[1] == "" {
+		t.Fatalf("correlation_id_test.go: Expected new ids for other operations, got %v", ids)
+	}
+	if err == nil || !strings.Contains(err.Error(), "X-Correlation-ID: "+ids[1]) {
+		t.Fatalf("correlation_id_test.go: Expected the id in the error, got %v", err)
+	}
+}
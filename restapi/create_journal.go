@@ -0,0 +1,145 @@
+package restapi
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*
+createJournal records creates that were sent but whose outcome is not yet
+
+	known, so that a run interrupted by a crash can tell on the next apply
+	that an object may already exist. Entries are keyed by the create path and
+	a hash of the submitted payload and are kept in a small JSON file that is
+	rewritten atomically on every change.
+*/
+type createJournal struct {
+	mu      sync.Mutex
+	file    string
+	entries map[string]createJournalEntry
+}
+
+type createJournalEntry struct {
+	Path           string    `json:"path"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	PayloadHash    string    `json:"payload_hash"`
+	Started        time.Time `json:"started"`
+}
+
+/* Header carrying the idempotency key of a journaled create */
+const idempotencyKeyHeader = "Idempotency-Key"
+
+func newCreateJournal(file string) (*createJournal, error) {
+	j := &createJournal{
+		file:    file,
+		entries: make(map[string]createJournalEntry),
+	}
+
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read create journal '%s': %v", file, err)
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &j.entries); err != nil {
+			return nil, fmt.Errorf("could not parse create journal '%s': %v", file, err)
+		}
+	}
+	return j, nil
+}
+
+func createJournalKey(path string, payloadHash string) string {
+	return path + " " + payloadHash
+}
+
+func hashPayload(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+/* pending returns the entry left behind by an earlier, unfinished create of the same payload */
+func (j *createJournal) pending(path string, payload string) (createJournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[createJournalKey(path, hashPayload(payload))]
+	return entry, ok
+}
+
+/*
+begin records a create that is about to be sent. If idempotencyKey is empty a
+
+	new one is generated. The returned entry must be passed to complete.
+*/
+func (j *createJournal) begin(path string, payload string, idempotencyKey string) (createJournalEntry, error) {
+	if idempotencyKey == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return createJournalEntry{}, err
+		}
+		idempotencyKey = hex.EncodeToString(b)
+	}
+
+	entry := createJournalEntry{
+		Path:           path,
+		IdempotencyKey: idempotencyKey,
+		PayloadHash:    hashPayload(payload),
+		Started:        time.Now().UTC(),
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries[createJournalKey(entry.Path, entry.PayloadHash)] = entry
+	return entry, j.save()
+}
+
+/* complete removes the entry of a create whose outcome is known */
+func (j *createJournal) complete(entry createJournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key := createJournalKey(entry.Path, entry.PayloadHash)
+	if _, ok := j.entries[key]; !ok {
+		return nil
+	}
+	delete(j.entries, key)
+	return j.save()
+}
+
+func (j *createJournal) save() error {
+	if len(j.entries) == 0 {
+		if err := os.Remove(j.file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove create journal '%s': %v", j.file, err)
+		}
+		return nil
+	}
+
+	b, err := json.MarshalIndent(j.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.file), filepath.Base(j.file)+".*")
+	if err != nil {
+		return fmt.Errorf("could not write create journal '%s': %v", j.file, err)
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write create journal '%s': %v", j.file, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), j.file)
+}
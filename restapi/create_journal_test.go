@@ -0,0 +1,109 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateJournal(t *testing.T) {
+	journalFile := filepath.Join(t.TempDir(), "creates.json")
+
+	var posts int
+	var lastIdempotencyKey string
+	exists := false
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			posts++
+			lastIdempotencyKey = r.Header.Get(idempotencyKeyHeader)
+			exists = true
+			w.Write([]byte(`{"id": "1", "name": "foo"}`))
+		case "GET":
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"id": "1", "name": "foo"}`))
+		}
+	}))
+	defer svr.Close()
+
+	newObject := func() *APIObject {
+		client, err := NewAPIClient(&apiClientOpt{
+			uri:               svr.URL,
+			timeout:           5,
+			createJournalFile: journalFile,
+		})
+		if err != nil {
+			t.Fatalf("create_journal_test.go: Failed to create API client: %s", err)
+		}
+		obj, err := NewAPIObject(client, &apiObjectOpts{
+			path: "/api/objects",
+			data: `{"id": "1", "name": "foo"}`,
+		})
+		if err != nil {
+			t.Fatalf("create_journal_test.go: Failed to create API object: %s", err)
+		}
+		return obj
+	}
+	payload := `{"id":"1","name":"foo"}`
+
+	t.Run("create_is_journaled", func(t *testing.T) {
+		obj := newObject()
+		if err := obj.createObject(); err != nil {
+			t.Fatalf("create_journal_test.go: Create failed: %s", err)
+		}
+		if posts != 1 || lastIdempotencyKey == "" {
+			t.Fatalf("create_journal_test.go: Expected one POST with an idempotency key, got %d POST(s) and key '%s'", posts, lastIdempotencyKey)
+		}
+		if _, err := os.Stat(journalFile); !os.IsNotExist(err) {
+			t.Fatalf("create_journal_test.go: Expected the journal to be empty after a completed create")
+		}
+	})
+
+	t.Run("interrupted_create_is_adopted", func(t *testing.T) {
+		/* Simulate a crash after the create was sent */
+		journal, _ := newCreateJournal(journalFile)
+		if _, err := journal.begin("/api/objects", payload, ""); err != nil {
+			t.Fatalf("create_journal_test.go: Failed to write journal: %s", err)
+		}
+
+		posts = 0
+		obj := newObject()
+		if err := obj.createObject(); err != nil {
+			t.Fatalf("create_journal_test.go: Create failed: %s", err)
+		}
+		if posts != 0 {
+			t.Fatalf("create_journal_test.go: Expected the existing object to be adopted, but it was created again")
+		}
+		if obj.id != "1" || obj.apiData["name"] != "foo" {
+			t.Fatalf("create_journal_test.go: Expected the adopted object to be read, got id '%s' and %v", obj.id, obj.apiData)
+		}
+		if _, err := os.Stat(journalFile); !os.IsNotExist(err) {
+			t.Fatalf("create_journal_test.go: Expected the journal entry to be removed after adoption")
+		}
+	})
+
+	t.Run("missing_object_is_created_with_same_key", func(t *testing.T) {
+		journal, _ := newCreateJournal(journalFile)
+		entry, err := journal.begin("/api/objects", payload, "")
+		if err != nil {
+			t.Fatalf("create_journal_test.go: Failed to write journal: %s", err)
+		}
+
+		posts, exists = 0, false
+		obj := newObject()
+		if err := obj.createObject(); err != nil {
+			t.Fatalf("create_journal_test.go: Create failed: %s", err)
+		}
+		if posts != 1 {
+			t.Fatalf("create_journal_test.go: Expected the create to be sent again, got %d POST(s)", posts)
+		}
+		if lastIdempotencyKey != entry.IdempotencyKey {
+			t.Fatalf("create_journal_test.go: Expected idempotency key '%s' to be reused, got '%s'", entry.IdempotencyKey, lastIdempotencyKey)
+		}
+	})
+}
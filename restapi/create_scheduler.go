@@ -0,0 +1,75 @@
+package restapi
+
+import (
+	"sync"
+)
+
+/*
+createScheduler orders and throttles object creation across all resources
+
+	handled by one provider instance. Terraform happily creates independent
+	resources in parallel, which makes midPoint answer with 409 storms when
+	dependents (users, assignments) race with the parents they reference
+	(orgs, roles). A create only starts once no create with a lower
+	create_priority is waiting or running, and at most limit creates run at
+	once (0 means unlimited).
+
+	The ordering is best-effort: the provider does not see the plan, so it
+	only knows of the creates Terraform has already handed to it. A create
+	that reaches the provider before a create with a lower priority does is
+	not held back, which happens when Terraform starts a dependent before its
+	parent. Only depends_on, or a reference, guarantees the order.
+*/
+type createScheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	active  int
+	pending map[int]int /* Number of creates waiting or running per priority */
+}
+
+func newCreateScheduler(limit int) *createScheduler {
+	s := &createScheduler{
+		limit:   limit,
+		pending: make(map[int]int),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+/* acquire blocks until a create with the given priority may be sent */
+func (s *createScheduler) acquire(priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[priority]++
+	for s.mustWait(priority) {
+		s.cond.Wait()
+	}
+	s.active++
+}
+
+/* release marks a create obtained with acquire as finished */
+func (s *createScheduler) release(priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active--
+	s.pending[priority]--
+	if s.pending[priority] == 0 {
+		delete(s.pending, priority)
+	}
+	s.cond.Broadcast()
+}
+
+func (s *createScheduler) mustWait(priority int) bool {
+	if s.limit > 0 && s.active >= s.limit {
+		return true
+	}
+	for p := range s.pending {
+		if p < priority {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCreateSchedulerOrdering(t *testing.T) {
+	s := newCreateScheduler(0)
+
+	var mu sync.Mutex
+	order := make([]int, 0)
+	var wg sync.WaitGroup
+
+	/* Hold a parent create open so every dependent has to queue up behind it */
+	s.acquire(0)
+
+	for _, priority := range []int{2, 1, 2, 1} {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			s.acquire(p)
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			s.release(p)
+		}(priority)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	if len(order) != 0 {
+		t.Fatalf("create_scheduler_test.go: Dependents started before the parent create finished: %v", order)
+	}
+	mu.Unlock()
+	s.release(0)
+
+	wg.Wait()
+	expected := []int{1, 1, 2, 2}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("create_scheduler_test.go: Expected creates in order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestCreateSchedulerConcurrency(t *testing.T) {
+	s := newCreateScheduler(2)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.acquire(0)
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			s.release(0)
+		}()
+	}
+
+	wg.Wait()
+	if maxRunning > 2 {
+		t.Fatalf("create_scheduler_test.go: Expected at most 2 concurrent creates, saw %d", maxRunning)
+	}
+}
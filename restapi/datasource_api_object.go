@@ -2,6 +2,7 @@ package restapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -44,19 +45,26 @@ func dataSourceRestAPI() *schema.Resource {
 			},
 			"search_key": {
 				Type:        schema.TypeString,
-				Description: "When reading search results from the API, this key is used to identify the specific record to read. This should be a unique record such as 'name'. Similar to results_key, the value may be in the format of 'field/field/field' to search for data deeper in the returned object.",
-				Required:    true,
+				Description: "When reading search results from the API, this key is used to identify the specific record to read. This should be a unique record such as 'name'. Similar to results_key, the value may be in the format of 'field/field/field' to search for data deeper in the returned object. Required unless `object_id` is set.",
+				Optional:    true,
 			},
 			"search_value": {
 				Type:        schema.TypeString,
-				Description: "The value of 'search_key' will be compared to this value to determine if the correct object was found. Example: if 'search_key' is 'name' and 'search_value' is 'foo', the record in the array returned by the API with name=foo will be used.",
-				Required:    true,
+				Description: "The value of 'search_key' will be compared to this value to determine if the correct object was found. Example: if 'search_key' is 'name' and 'search_value' is 'foo', the record in the array returned by the API with name=foo will be used. Required unless `object_id` is set.",
+				Optional:    true,
+			},
+			"object_id": {
+				Type:          schema.TypeString,
+				Description:   "The id of the object to read from `path/{id}` directly, instead of searching for it with `search_key` and `search_value`.",
+				Optional:      true,
+				ConflictsWith: []string{"search_key", "search_value"},
 			},
 			"results_key": {
 				Type:        schema.TypeString,
 				Description: "When issuing a GET to the path, this JSON key is used to locate the results array. The format is 'field/field/field'. Example: 'results/values'. If omitted, it is assumed the results coming back are already an array and are to be used exactly as-is.",
 				Optional:    true,
 			},
+			"pagination": paginationSchema(),
 			"id_attribute": {
 				Type:        schema.TypeString,
 				Description: "Defaults to `id_attribute` set on the provider. Allows per-resource override of `id_attribute` (see `id_attribute` provider config documentation)",
@@ -123,6 +131,7 @@ func dataSourceRestAPIRead(d *schema.ResourceData, meta interface{}) error {
 		debug:       debug,
 		queryString: readQueryString,
 		idAttribute: idAttribute,
+		pagination:  expandPagination(d.Get("pagination").([]interface{})),
 	}
 
 	obj, err := NewAPIObject(client, opts)
@@ -130,6 +139,22 @@ func dataSourceRestAPIRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	if objectID := d.Get("object_id").(string); objectID != "" {
+		obj.id = objectID
+		if err := obj.readObject(); err != nil {
+			return err
+		}
+		if obj.id == "" {
+			return fmt.Errorf("no object with the id '%s' at %s", objectID, path)
+		}
+		d.SetId(obj.id)
+		setResourceState(obj, d)
+		return nil
+	}
+	if searchKey == "" || searchValue == "" {
+		return fmt.Errorf("either object_id or both search_key and search_value must be set")
+	}
+
 	if _, err := obj.findObject(queryString, searchKey, searchValue, resultsKey, send); err != nil {
 		return err
 	}
@@ -0,0 +1,179 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRestAPIObjects() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceRestAPIObjectsRead,
+		Description: "Runs a search and returns every matching object as JSON, so the results can be used with `for_each` and `jsondecode`.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The API path on top of the base URL set in the provider that represents objects of this type on the API server.",
+				Required:    true,
+			},
+			"search_path": {
+				Type:        schema.TypeString,
+				Description: "The API path on top of the base URL set in the provider to search for objects of this type, such as `/users/search` for midPoint. If not set, defaults to the value of path.",
+				Optional:    true,
+			},
+			"search_method": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `read_method` set on the provider. The HTTP method of the search, such as `POST` for midPoint searches.",
+				Optional:    true,
+			},
+			"query_string": {
+				Type:        schema.TypeString,
+				Description: "An optional query string to send when performing the search.",
+				Optional:    true,
+			},
+			"search_data": {
+				Type:        schema.TypeString,
+				Description: "Valid JSON object sent as body of the search request, such as a midPoint query filter to narrow the search on the server.",
+				Optional:    true,
+			},
+			"results_key": {
+				Type:        schema.TypeString,
+				Description: "The '/'-delimited location of the results array in the search response, such as `object/object` for midPoint. If omitted, the response must already be an array. A response without it has no results.",
+				Optional:    true,
+			},
+			"search_key": {
+				Type:         schema.TypeString,
+				Description:  "When set with `search_value`, only the results whose value at this '/'-delimited key equals `search_value` are returned.",
+				Optional:     true,
+				RequiredWith: []string{"search_value"},
+			},
+			"search_value": {
+				Type:         schema.TypeString,
+				Description:  "The value `search_key` must have in the returned results.",
+				Optional:     true,
+				RequiredWith: []string{"search_key"},
+			},
+			"pagination": paginationSchema(),
+			"id_attribute": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `id_attribute` set on the provider. The '/'-delimited location of the id of each result, looked up below the key midPoint wraps objects in when it is not found at the top.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"objects": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Every object found, in the order of the search response, as JSON to be read with `jsondecode`.",
+				Computed:    true,
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The id of every object in `objects` that has one.",
+				Computed:    true,
+			},
+			"objects_by_id": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The objects that have an id, as JSON by id. Use it with `for_each`.",
+				Computed:    true,
+			},
+		}, /* End schema */
+	}
+}
+
+func dataSourceRestAPIObjectsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*APIClient)
+	debug := d.Get("debug").(bool)
+
+	opts := &apiObjectOpts{
+		path:        d.Get("path").(string),
+		searchPath:  d.Get("search_path").(string),
+		debug:       debug,
+		idAttribute: d.Get("id_attribute").(string),
+		pagination:  expandPagination(d.Get("pagination").([]interface{})),
+	}
+	obj, err := NewAPIObject(client, opts)
+	if err != nil {
+		return err
+	}
+
+	method := d.Get("search_method").(string)
+	if method == "" {
+		method = client.readMethod
+	}
+	records, err := obj.listObjects(method, d.Get("query_string").(string), d.Get("results_key").(string), d.Get("search_data").(string))
+	if err != nil {
+		return err
+	}
+
+	searchKey := d.Get("search_key").(string)
+	searchValue := d.Get("search_value").(string)
+
+	objects := make([]string, 0, len(records))
+	ids := make([]string, 0, len(records))
+	byID := make(map[string]string)
+	for _, record := range records {
+		hash, ok := record.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("the objects in the search response from '%s' are not a map of key value pairs", obj.searchPath)
+		}
+		if searchKey != "" {
+			if value, err := GetStringAtKey(hash, searchKey, debug); err != nil || value != searchValue {
+				continue
+			}
+		}
+
+		b, err := json.Marshal(hash)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, string(b))
+
+		id := recordID(hash, obj.idAttribute, debug)
+		if id == "" {
+			if debug {
+				log.Printf("datasource_api_objects.go: No id_attribute '%s' in %s", obj.idAttribute, string(b))
+			}
+			continue
+		}
+		if _, ok := byID[id]; ok {
+			return fmt.Errorf("the search at '%s' returned more than one object with the id '%s'", obj.searchPath, id)
+		}
+		ids = append(ids, id)
+		byID[id] = string(b)
+	}
+
+	if debug {
+		log.Printf("datasource_api_objects.go: Found %d objects at '%s'", len(objects), obj.searchPath)
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("objects", objects)
+	d.Set("ids", ids)
+	return d.Set("objects_by_id", byID)
+}
+
+/* recordID returns the id of a search result, looking below the key midPoint may wrap it in */
+func recordID(record map[string]interface{}, idAttribute string, debug bool) string {
+	if id, err := GetStringAtKey(record, idAttribute, debug); err == nil && id != "" {
+		return id
+	}
+	if len(record) == 1 {
+		for _, inner := range record {
+			if innerMap, ok := inner.(map[string]interface{}); ok {
+				id, _ := GetStringAtKey(innerMap, idAttribute, debug)
+				return id
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceObjects(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", writeReturnsObject: true, mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_api_objects_test.go: Failed to create API client: %s", err)
+	}
+	for _, user := range []string{
+		`{"user": {"oid": "u1", "name": "jsmith", "locality": "Brno"}}`,
+		`{"user": {"oid": "u2", "name": "jdoe", "locality": "Brno"}}`,
+		`{"user": {"oid": "u3", "name": "mroe", "locality": "Bratislava"}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/users", user); err != nil {
+			t.Fatalf("datasource_api_objects_test.go: Create failed: %s", err)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceRestAPIObjects().Schema, map[string]interface{}{
+		"path":          "/users",
+		"search_path":   "/users/search",
+		"search_method": "POST",
+		"search_data":   `{"query": {"filter": {"equal": {"path": "locality", "value": "Brno"}}}}`,
+		"results_key":   "object/object",
+	})
+	if err := dataSourceRestAPIObjectsRead(d, client); err != nil {
+		t.Fatalf("datasource_api_objects_test.go: Read failed: %s", err)
+	}
+	if ids := d.Get("ids").([]interface{}); !reflect.DeepEqual(ids, []interface{}{"u1", "u2"}) {
+		t.Fatalf("datasource_api_objects_test.go: Expected the users in Brno, got %v", ids)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Get("objects_by_id").(map[string]interface{})["u2"].(string)), &decoded); err != nil {
+		t.Fatalf("datasource_api_objects_test.go: Expected objects as JSON: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, MapAny{"user": MapAny{"oid": "u2", "name": "jdoe", "locality": "Brno"}}) {
+		t.Fatalf("datasource_api_objects_test.go: Unexpected object %v", decoded)
+	}
+
+	/* midPoint leaves out the results when nothing matched; search_key filters the results */
+	d = schema.TestResourceDataRaw(t, dataSourceRestAPIObjects().Schema, map[string]interface{}{
+		"path":          "/users",
+		"search_path":   "/users/search",
+		"search_method": "POST",
+		"search_data":   `{"query": {"filter": {"equal": {"path": "locality", "value": "Praha"}}}}`,
+		"results_key":   "object/object",
+	})
+	if err := dataSourceRestAPIObjectsRead(d, client); err != nil || len(d.Get("objects").([]interface{})) != 0 {
+		t.Fatalf("datasource_api_objects_test.go: Expected no objects, got %v (%v)", d.Get("objects"), err)
+	}
+
+	/* restapi_object reads a single object by its id */
+	d = schema.TestResourceDataRaw(t, dataSourceRestAPI().Schema, map[string]interface{}{"path": "/users", "object_id": "u3"})
+	if err := dataSourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("datasource_api_objects_test.go: Read by id failed: %s", err)
+	}
+	if d.Id() != "u3" || d.Get("api_response").(string) == "" {
+		t.Fatalf("datasource_api_objects_test.go: Expected the object u3, got '%s'", d.Id())
+	}
+	d = schema.TestResourceDataRaw(t, dataSourceRestAPI().Schema, map[string]interface{}{"path": "/users", "object_id": "u4"})
+	if err := dataSourceRestAPIRead(d, client); err == nil {
+		t.Fatalf("datasource_api_objects_test.go: Expected reading a missing object to fail")
+	}
+}
+
+func TestDataSourceObjectsPagination(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		results := []string{}
+		for i := offset; i < offset+2 && i < 5; i++ {
+			results = append(results, fmt.Sprintf(`{"id": "%d", "team": "%s"}`, i, []string{"a", "b"}[i%2]))
+		}
+		fmt.Fprintf(w, `{"results": [%s]}`, strings.Join(results, ","))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id", readMethod: "GET"})
+	if err != nil {
+		t.Fatalf("datasource_api_objects_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceRestAPIObjects().Schema, map[string]interface{}{
+		"path":         "/objects",
+		"results_key":  "results",
+		"search_key":   "team",
+		"search_value": "a",
+		"pagination":   []interface{}{map[string]interface{}{"type": "offset", "page_size": 2}},
+	})
+	if err := dataSourceRestAPIObjectsRead(d, client); err != nil {
+		t.Fatalf("datasource_api_objects_test.go: Read failed: %s", err)
+	}
+	if ids := d.Get("ids").([]interface{}); !reflect.DeepEqual(ids, []interface{}{"0", "2", "4"}) {
+		t.Fatalf("datasource_api_objects_test.go: Expected the objects of every page, got %v", ids)
+	}
+}
@@ -0,0 +1,358 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* Attributes shared by the data sources that search midPoint cases */
+func caseSearchSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"path": {
+			Type:        schema.TypeString,
+			Description: "Defaults to `/cases`. The API path on top of the base URL set in the provider that represents midPoint CaseType objects.",
+			Optional:    true,
+			Default:     "/cases",
+		},
+		"search_path": {
+			Type:        schema.TypeString,
+			Description: "Defaults to `path/search`. The API path used to search for cases.",
+			Optional:    true,
+		},
+		"search_method": {
+			Type:        schema.TypeString,
+			Description: "Defaults to `POST`. The HTTP method used to search for cases.",
+			Optional:    true,
+			Default:     "POST",
+		},
+		"search_data": {
+			Type:        schema.TypeString,
+			Description: "Defaults to an empty midPoint query. Valid JSON object sent as body of the search request; use it to narrow the search on the server with a midPoint query filter.",
+			Optional:    true,
+			Default:     `{"query":{}}`,
+		},
+		"results_key": {
+			Type:        schema.TypeString,
+			Description: "Defaults to `object/object`. The '/'-delimited location of the array of cases in the search response. Set to an empty string if the response is already an array.",
+			Optional:    true,
+			Default:     "object/object",
+		},
+		"state": {
+			Type:        schema.TypeString,
+			Description: "Only return cases in this state (for example `open` or `closed`). Both the short name and the full state URI are accepted.",
+			Optional:    true,
+		},
+		"object_oid": {
+			Type:        schema.TypeString,
+			Description: "Only return cases whose `objectRef` points to the object with this oid.",
+			Optional:    true,
+		},
+		"debug": {
+			Type:        schema.TypeBool,
+			Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+			Optional:    true,
+		},
+	}
+}
+
+func dataSourceRestAPICases() *schema.Resource {
+	s := caseSearchSchema()
+	s["cases"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "The cases that matched the filters.",
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"oid":             {Type: schema.TypeString, Computed: true, Description: "The oid of the case."},
+				"name":            {Type: schema.TypeString, Computed: true, Description: "The name of the case."},
+				"state":           {Type: schema.TypeString, Computed: true, Description: "The state of the case."},
+				"object_oid":      {Type: schema.TypeString, Computed: true, Description: "The oid of the object the case is about."},
+				"target_oid":      {Type: schema.TypeString, Computed: true, Description: "The oid of the target of the case, such as the requested role."},
+				"requestor_oid":   {Type: schema.TypeString, Computed: true, Description: "The oid of the user that requested the case."},
+				"open_work_items": {Type: schema.TypeInt, Computed: true, Description: "The number of work items of the case that are not closed."},
+				"api_response":    {Type: schema.TypeString, Computed: true, Description: "The case as returned by the API server, as JSON."},
+			},
+		},
+	}
+	s["oids"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "The oid of every case in `cases`.",
+		Computed:    true,
+	}
+
+	return &schema.Resource{
+		Read:        dataSourceRestAPICasesRead,
+		Description: "Searches midPoint cases (CaseType objects), such as approval cases. Use it to block or alert in a pipeline when open cases exist for the objects it is about to modify.",
+		Schema:      s,
+	}
+}
+
+func dataSourceRestAPIWorkItems() *schema.Resource {
+	s := caseSearchSchema()
+	s["assignee_oid"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Only return work items assigned to the user with this oid.",
+		Optional:    true,
+	}
+	s["include_closed"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "When true, work items that were already completed are returned too. Default: false",
+		Optional:    true,
+		Default:     false,
+	}
+	s["work_items"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "The work items that matched the filters.",
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"case_oid":      {Type: schema.TypeString, Computed: true, Description: "The oid of the case the work item belongs to."},
+				"case_name":     {Type: schema.TypeString, Computed: true, Description: "The name of the case the work item belongs to."},
+				"id":            {Type: schema.TypeString, Computed: true, Description: "The container id of the work item within its case."},
+				"name":          {Type: schema.TypeString, Computed: true, Description: "The name of the work item."},
+				"assignee_oids": {Type: schema.TypeList, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true, Description: "The oids of the users the work item is assigned to."},
+				"stage_number":  {Type: schema.TypeInt, Computed: true, Description: "The approval stage of the work item."},
+				"deadline":      {Type: schema.TypeString, Computed: true, Description: "The deadline of the work item, if any."},
+				"closed":        {Type: schema.TypeBool, Computed: true, Description: "Whether the work item was already completed."},
+			},
+		},
+	}
+
+	return &schema.Resource{
+		Read:        dataSourceRestAPIWorkItemsRead,
+		Description: "Lists the work items (such as approvals) of midPoint cases, optionally only those assigned to a given user.",
+		Schema:      s,
+	}
+}
+
+func dataSourceRestAPICasesRead(d *schema.ResourceData, meta interface{}) error {
+	cases, err := searchCases(d, meta.(*APIClient))
+	if err != nil {
+		return err
+	}
+
+	result := make([]interface{}, 0, len(cases))
+	oids := make([]string, 0, len(cases))
+	for _, c := range cases {
+		openWorkItems := 0
+		for _, workItem := range caseWorkItems(c) {
+			if !workItemClosed(workItem) {
+				openWorkItems++
+			}
+		}
+		encoded, _ := json.Marshal(c)
+
+		oid := stringAtKey(c, "oid")
+		result = append(result, map[string]interface{}{
+			"oid":             oid,
+			"name":            polyStringValue(c["name"]),
+			"state":           stringAtKey(c, "state"),
+			"object_oid":      stringAtKey(c, "objectRef/oid"),
+			"target_oid":      stringAtKey(c, "targetRef/oid"),
+			"requestor_oid":   stringAtKey(c, "requestorRef/oid"),
+			"open_work_items": openWorkItems,
+			"api_response":    string(encoded),
+		})
+		oids = append(oids, oid)
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("oids", oids)
+	return d.Set("cases", result)
+}
+
+func dataSourceRestAPIWorkItemsRead(d *schema.ResourceData, meta interface{}) error {
+	cases, err := searchCases(d, meta.(*APIClient))
+	if err != nil {
+		return err
+	}
+
+	assigneeOid := d.Get("assignee_oid").(string)
+	includeClosed := d.Get("include_closed").(bool)
+
+	result := make([]interface{}, 0)
+	for _, c := range cases {
+		for _, workItem := range caseWorkItems(c) {
+			closed := workItemClosed(workItem)
+			if closed && !includeClosed {
+				continue
+			}
+
+			assignees := refOids(workItem["assigneeRef"])
+			if assigneeOid != "" && !containsString(assignees, assigneeOid) {
+				continue
+			}
+
+			id, _ := containerIDString(workItem)
+			stage := 0
+			if v, ok := workItem["stageNumber"].(float64); ok {
+				stage = int(v)
+			}
+
+			result = append(result, map[string]interface{}{
+				"case_oid":      stringAtKey(c, "oid"),
+				"case_name":     polyStringValue(c["name"]),
+				"id":            id,
+				"name":          polyStringValue(workItem["name"]),
+				"assignee_oids": assignees,
+				"stage_number":  stage,
+				"deadline":      stringAtKey(workItem, "deadline"),
+				"closed":        closed,
+			})
+		}
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	return d.Set("work_items", result)
+}
+
+/*
+searchCases sends the configured case search and returns the cases that
+
+	match the state and object_oid filters. midPoint may wrap each result in
+	a single "case" key, which is removed.
+*/
+func searchCases(d *schema.ResourceData, client *APIClient) ([]map[string]interface{}, error) {
+	path := d.Get("path").(string)
+	searchPath := d.Get("search_path").(string)
+	if searchPath == "" {
+		searchPath = path + "/search"
+	}
+	resultsKey := d.Get("results_key").(string)
+	state := d.Get("state").(string)
+	objectOid := d.Get("object_oid").(string)
+	debug := d.Get("debug").(bool)
+
+	if debug {
+		log.Printf("datasource_cases.go: Searching cases at '%s' (state='%s', object_oid='%s')", searchPath, state, objectOid)
+	}
+
+	resultString, err := client.sendRequest(d.Get("search_method").(string), searchPath, d.Get("search_data").(string))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search cases at '%s': %v", searchPath, err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(resultString), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse case search response from '%s': %v", searchPath, err)
+	}
+
+	if resultsKey != "" {
+		hash, ok := result.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the case search response from '%s' is not a hash; cannot locate results_key '%s'", searchPath, resultsKey)
+		}
+		if result, err = GetObjectAtKey(hash, resultsKey, debug); err != nil {
+			/* midPoint leaves out the list entirely when nothing matched */
+			if debug {
+				log.Printf("datasource_cases.go: No cases at results_key '%s': %v", resultsKey, err)
+			}
+			return []map[string]interface{}{}, nil
+		}
+	}
+
+	items, ok := asValueList(result)
+	if !ok {
+		return nil, fmt.Errorf("the cases in the search response from '%s' are not an array, it is a '%s'", searchPath, reflect.TypeOf(result))
+	}
+
+	cases := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		c, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the cases in the search response from '%s' are not a map of key value pairs", searchPath)
+		}
+		if inner, ok := c["case"].(map[string]interface{}); ok && len(c) == 1 {
+			c = inner
+		}
+
+		if state != "" && !caseStateMatches(stringAtKey(c, "state"), state) {
+			continue
+		}
+		if objectOid != "" && stringAtKey(c, "objectRef/oid") != objectOid {
+			continue
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+/* midPoint reports case states either as a short name or as a URI ending in #<name> */
+func caseStateMatches(actual string, wanted string) bool {
+	if actual == wanted {
+		return true
+	}
+	if i := strings.LastIndex(actual, "#"); i >= 0 {
+		return actual[i+1:] == wanted
+	}
+	return false
+}
+
+func caseWorkItems(c map[string]interface{}) []map[string]interface{} {
+	values, _ := asValueList(c["workItem"])
+	workItems := make([]map[string]interface{}, 0, len(values))
+	for _, value := range values {
+		if workItem, ok := value.(map[string]interface{}); ok {
+			workItems = append(workItems, workItem)
+		}
+	}
+	return workItems
+}
+
+/* A work item is completed once midPoint has set its closeTimestamp */
+func workItemClosed(workItem map[string]interface{}) bool {
+	_, closed := workItem["closeTimestamp"]
+	return closed
+}
+
+/* refOids returns the oids of a single- or multi-valued ObjectReferenceType item */
+func refOids(value interface{}) []string {
+	refs, _ := asValueList(value)
+	oids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if hash, ok := ref.(map[string]interface{}); ok {
+			if oid := stringAtKey(hash, "oid"); oid != "" {
+				oids = append(oids, oid)
+			}
+		}
+	}
+	return oids
+}
+
+/* polyStringValue returns the original value of a PolyString, which may also be serialized as a plain string */
+func polyStringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if orig, ok := v["orig"].(string); ok {
+			return orig
+		}
+	}
+	return ""
+}
+
+/* stringAtKey is GetStringAtKey for optional values, returning an empty string when missing */
+func stringAtKey(data map[string]interface{}, path string) string {
+	value, err := GetStringAtKey(data, path, false)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
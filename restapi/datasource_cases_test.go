@@ -0,0 +1,123 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const casesSearchResponse = `{
+  "object": {
+    "object": [
+      {
+        "oid": "case-1",
+        "name": {"orig": "Approving assignment of Admin to jsmith", "norm": "approving assignment of admin to jsmith"},
+        "state": "open",
+        "objectRef": {"oid": "user-jsmith", "type": "UserType"},
+        "targetRef": {"oid": "role-admin", "type": "RoleType"},
+        "workItem": [
+          {"@id": 3, "name": "Approve Admin", "stageNumber": 1, "assigneeRef": {"oid": "user-boss"}},
+          {"@id": 4, "name": "Approve Admin", "stageNumber": 1, "assigneeRef": [{"oid": "user-cfo"}], "closeTimestamp": "2026-01-01T00:00:00Z"}
+        ]
+      },
+      {
+        "case": {
+          "oid": "case-2",
+          "name": "Closed case",
+          "state": "http://midpoint.evolveum.com/xml/ns/public/model/case/state#closed",
+          "objectRef": {"oid": "user-other"}
+        }
+      }
+    ]
+  }
+}`
+
+func TestDataSourceCases(t *testing.T) {
+	var lastMethod, lastPath, lastBody string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lastMethod, lastPath, lastBody = r.Method, r.URL.Path, string(b)
+		w.Write([]byte(casesSearchResponse))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("datasource_cases_test.go: Failed to create API client: %s", err)
+	}
+
+	t.Run("cases", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceRestAPICases().Schema, map[string]interface{}{"state": "open"})
+		if err := dataSourceRestAPICasesRead(d, client); err != nil {
+			t.Fatalf("datasource_cases_test.go: Read failed: %s", err)
+		}
+
+		if lastMethod != "POST" || lastPath != "/cases/search" || lastBody != `{"query":{}}` {
+			t.Fatalf("datasource_cases_test.go: Unexpected search request %s %s '%s'", lastMethod, lastPath, lastBody)
+		}
+
+		cases := d.Get("cases").([]interface{})
+		if len(cases) != 1 {
+			t.Fatalf("datasource_cases_test.go: Expected 1 open case, got %d", len(cases))
+		}
+		c := cases[0].(map[string]interface{})
+		if c["oid"] != "case-1" || c["object_oid"] != "user-jsmith" || c["target_oid"] != "role-admin" {
+			t.Fatalf("datasource_cases_test.go: Unexpected case %v", c)
+		}
+		if c["name"] != "Approving assignment of Admin to jsmith" {
+			t.Fatalf("datasource_cases_test.go: Expected PolyString name to be flattened, got '%v'", c["name"])
+		}
+		if c["open_work_items"] != 1 {
+			t.Fatalf("datasource_cases_test.go: Expected 1 open work item, got %v", c["open_work_items"])
+		}
+	})
+
+	t.Run("state_uri_and_object_filter", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceRestAPICases().Schema, map[string]interface{}{
+			"state":      "closed",
+			"object_oid": "user-other",
+		})
+		if err := dataSourceRestAPICasesRead(d, client); err != nil {
+			t.Fatalf("datasource_cases_test.go: Read failed: %s", err)
+		}
+
+		oids := d.Get("oids").([]interface{})
+		if len(oids) != 1 || oids[0] != "case-2" {
+			t.Fatalf("datasource_cases_test.go: Expected only case-2, got %v", oids)
+		}
+	})
+
+	t.Run("work_items", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceRestAPIWorkItems().Schema, map[string]interface{}{"assignee_oid": "user-boss"})
+		if err := dataSourceRestAPIWorkItemsRead(d, client); err != nil {
+			t.Fatalf("datasource_cases_test.go: Read failed: %s", err)
+		}
+
+		workItems := d.Get("work_items").([]interface{})
+		if len(workItems) != 1 {
+			t.Fatalf("datasource_cases_test.go: Expected 1 work item, got %d", len(workItems))
+		}
+		w := workItems[0].(map[string]interface{})
+		if w["case_oid"] != "case-1" || w["id"] != "3" || w["stage_number"] != 1 || w["closed"] != false {
+			t.Fatalf("datasource_cases_test.go: Unexpected work item %v", w)
+		}
+	})
+
+	t.Run("closed_work_items", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceRestAPIWorkItems().Schema, map[string]interface{}{
+			"assignee_oid":   "user-cfo",
+			"include_closed": true,
+		})
+		if err := dataSourceRestAPIWorkItemsRead(d, client); err != nil {
+			t.Fatalf("datasource_cases_test.go: Read failed: %s", err)
+		}
+
+		workItems := d.Get("work_items").([]interface{})
+		if len(workItems) != 1 || workItems[0].(map[string]interface{})["closed"] != true {
+			t.Fatalf("datasource_cases_test.go: Expected the closed work item, got %v", workItems)
+		}
+	})
+}
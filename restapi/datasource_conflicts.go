@@ -0,0 +1,259 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRestAPIConflicts() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceRestAPIConflictsRead,
+		Description: "Searches the server for existing objects that have the same value as a planned object for one of its unique keys, such as its `name`. Use it to fail fast during plan with a clear message instead of a generic 409 Conflict during apply.",
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The API path on top of the base URL set in the provider that represents objects of the planned object's type, such as `/users`.",
+				Required:    true,
+			},
+			"search_path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `path/search`. The API path used to search for conflicting objects.",
+				Optional:    true,
+			},
+			"search_method": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `POST`. The HTTP method used to search for conflicting objects.",
+				Optional:    true,
+				Default:     "POST",
+			},
+			"results_key": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `object/object`. The '/'-delimited location of the array of objects in the search response. Set to an empty string if the response is already an array.",
+				Optional:    true,
+				Default:     "object/object",
+			},
+			"object_type": {
+				Type:        schema.TypeString,
+				Description: "The midPoint type of the planned object (such as `UserType`), used in the conflict message. Derived from `path` when not set.",
+				Optional:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the planned object. A shorthand for a `name` entry in `unique_keys`.",
+				Optional:    true,
+			},
+			"unique_keys": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The values of the planned object that must be unique, by the '/'-delimited item path they are stored at (for example `emailAddress` or `extension/employeeNumber`). Objects having any of these values are conflicts.",
+				Optional:    true,
+			},
+			"exclude_oids": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Objects that are not conflicts, such as the planned object itself once it exists.",
+				Optional:    true,
+			},
+			"fail_on_conflict": {
+				Type:        schema.TypeBool,
+				Description: "When true, reading the data source fails with `message` when there are conflicts, which stops the plan. Default: false",
+				Optional:    true,
+				Default:     false,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"conflicts": {
+				Type:        schema.TypeList,
+				Description: "The existing objects that conflict with the planned object.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"oid":  {Type: schema.TypeString, Computed: true, Description: "The oid of the conflicting object."},
+						"name": {Type: schema.TypeString, Computed: true, Description: "The name of the conflicting object."},
+						"keys": {Type: schema.TypeList, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true, Description: "The unique keys the conflicting object has the same value for."},
+					},
+				},
+			},
+			"has_conflicts": {
+				Type:        schema.TypeBool,
+				Description: "Whether any existing object conflicts with the planned object.",
+				Computed:    true,
+			},
+			"message": {
+				Type:        schema.TypeString,
+				Description: "A description of the conflicts, suitable for a precondition's `error_message`. Empty when there are none.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceRestAPIConflictsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*APIClient)
+	path := d.Get("path").(string)
+	searchPath := d.Get("search_path").(string)
+	if searchPath == "" {
+		searchPath = path + "/search"
+	}
+	resultsKey := d.Get("results_key").(string)
+	debug := d.Get("debug").(bool)
+
+	uniqueKeys := expandReadSearch(d.Get("unique_keys").(map[string]interface{}))
+	if name := d.Get("name").(string); name != "" {
+		uniqueKeys["name"] = name
+	}
+	if len(uniqueKeys) == 0 {
+		return fmt.Errorf("either name or unique_keys must be set to search for conflicts")
+	}
+
+	objectType := d.Get("object_type").(string)
+	if objectType == "" {
+		/* Only used in the message, so fall back to a generic description */
+		if objectType, _ = objectTypeForPath(path); objectType == "" {
+			objectType = "object"
+		}
+	}
+
+	search, err := json.Marshal(conflictQuery(uniqueKeys))
+	if err != nil {
+		return fmt.Errorf("failed to marshal the conflict search to JSON: %v", err)
+	}
+	if debug {
+		log.Printf("datasource_conflicts.go: Searching conflicts at '%s': %s", searchPath, string(search))
+	}
+
+	resultString, err := client.sendRequest(d.Get("search_method").(string), searchPath, string(search))
+	if err != nil {
+		return fmt.Errorf("failed to search conflicts at '%s': %v", searchPath, err)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(resultString), &result); err != nil {
+		return fmt.Errorf("failed to parse the conflict search response from '%s': %v", searchPath, err)
+	}
+
+	var items []interface{}
+	if resultsKey != "" {
+		hash, ok := result.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("the conflict search response from '%s' is not a hash; cannot locate results_key '%s'", searchPath, resultsKey)
+		}
+		/* midPoint leaves out the list entirely when nothing matched */
+		result, err = GetObjectAtKey(hash, resultsKey, debug)
+	}
+	if err == nil {
+		var ok bool
+		if items, ok = asValueList(result); !ok {
+			return fmt.Errorf("the objects in the conflict search response from '%s' are not an array, it is a '%s'", searchPath, reflect.TypeOf(result))
+		}
+	}
+
+	excluded := expandStringList(d.Get("exclude_oids").([]interface{}))
+	conflicts := make([]interface{}, 0)
+	descriptions := make([]string, 0)
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("the objects in the conflict search response from '%s' are not a map of key value pairs", searchPath)
+		}
+		/* midPoint may wrap each result in a single key naming its type */
+		if len(object) == 1 {
+			for _, inner := range object {
+				if innerMap, ok := inner.(map[string]interface{}); ok {
+					object = innerMap
+				}
+			}
+		}
+
+		oid := stringAtKey(object, "oid")
+		if containsString(excluded, oid) {
+			continue
+		}
+		keys := conflictingKeys(object, uniqueKeys)
+		name := polyStringValue(object["name"])
+
+		conflicts = append(conflicts, map[string]interface{}{
+			"oid":  oid,
+			"name": name,
+			"keys": keys,
+		})
+		descriptions = append(descriptions, fmt.Sprintf("'%s' (oid %s, same %s)", name, oid, strings.Join(keys, ", ")))
+	}
+
+	message := ""
+	if len(conflicts) > 0 {
+		message = fmt.Sprintf("%d existing %s conflicts with the planned object: %s", len(conflicts), objectType, strings.Join(descriptions, "; "))
+		if d.Get("fail_on_conflict").(bool) {
+			return fmt.Errorf("%s", message)
+		}
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("has_conflicts", len(conflicts) > 0)
+	d.Set("message", message)
+	return d.Set("conflicts", conflicts)
+}
+
+/* conflictQuery returns a midPoint query matching objects with any of the unique key values */
+func conflictQuery(uniqueKeys map[string]string) map[string]interface{} {
+	paths := make([]string, 0, len(uniqueKeys))
+	for path := range uniqueKeys {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	equals := make([]interface{}, 0, len(paths))
+	for _, path := range paths {
+		equals = append(equals, map[string]interface{}{"path": path, "value": uniqueKeys[path]})
+	}
+
+	var filter map[string]interface{}
+	if len(equals) == 1 {
+		filter = map[string]interface{}{"equal": equals[0]}
+	} else {
+		filter = map[string]interface{}{"or": map[string]interface{}{"equal": equals}}
+	}
+	return map[string]interface{}{"query": map[string]interface{}{"filter": filter}}
+}
+
+/*
+conflictingKeys returns the unique keys object has the planned value for.
+
+	Values are compared ignoring case, as midPoint matches PolyStrings such
+	as names by their normalized form.
+*/
+func conflictingKeys(object map[string]interface{}, uniqueKeys map[string]string) []string {
+	keys := make([]string, 0)
+	for path, want := range uniqueKeys {
+		value, err := GetObjectAtKey(object, path, false)
+		if err != nil {
+			continue
+		}
+		values, ok := asValueList(value)
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			have := polyStringValue(v)
+			if have == "" && v != nil {
+				have = fmt.Sprintf("%v", v)
+			}
+			if strings.EqualFold(have, want) {
+				keys = append(keys, path)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const conflictsSearchResponse = `{
+  "object": {
+    "object": [
+      {"user": {"oid": "user-1", "name": {"orig": "JSmith", "norm": "jsmith"}, "emailAddress": "john@example.com"}},
+      {"oid": "user-2", "name": "jdoe", "emailAddress": "jsmith@example.com"},
+      {"oid": "user-3", "name": "planned", "emailAddress": "planned@example.com"}
+    ]
+  }
+}`
+
+func TestDataSourceConflicts(t *testing.T) {
+	var lastMethod, lastPath, lastBody string
+	response := conflictsSearchResponse
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lastMethod, lastPath, lastBody = r.Method, r.URL.Path, string(b)
+		w.Write([]byte(response))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("datasource_conflicts_test.go: Failed to create API client: %s", err)
+	}
+
+	config := map[string]interface{}{
+		"path":         "/users",
+		"name":         "jsmith",
+		"unique_keys":  map[string]interface{}{"emailAddress": "jsmith@example.com"},
+		"exclude_oids": []interface{}{"user-3"},
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceRestAPIConflicts().Schema, config)
+	if err := dataSourceRestAPIConflictsRead(d, client); err != nil {
+		t.Fatalf("datasource_conflicts_test.go: Read failed: %s", err)
+	}
+
+	expectedBody := `{"query":{"filter":{"or":{"equal":[{"path":"emailAddress","value":"jsmith@example.com"},{"path":"name","value":"jsmith"}]}}}}`
+	if lastMethod != "POST" || lastPath != "/users/search" || lastBody != expectedBody {
+		t.Fatalf("datasource_conflicts_test.go: Unexpected search request %s %s '%s'", lastMethod, lastPath, lastBody)
+	}
+
+	conflicts := d.Get("conflicts").([]interface{})
+	if len(conflicts) != 2 || !d.Get("has_conflicts").(bool) {
+		t.Fatalf("datasource_conflicts_test.go: Expected 2 conflicts, got %v", conflicts)
+	}
+	first := conflicts[0].(map[string]interface{})
+	if first["oid"] != "user-1" || first["name"] != "JSmith" || !reflect.DeepEqual(first["keys"], []interface{}{"name"}) {
+		t.Fatalf("datasource_conflicts_test.go: Unexpected first conflict %v", first)
+	}
+	second := conflicts[1].(map[string]interface{})
+	if second["oid"] != "user-2" || !reflect.DeepEqual(second["keys"], []interface{}{"emailAddress"}) {
+		t.Fatalf("datasource_conflicts_test.go: Unexpected second conflict %v", second)
+	}
+	if message := d.Get("message").(string); !strings.HasPrefix(message, "2 existing UserType conflicts") || !strings.Contains(message, "'jdoe' (oid user-2, same emailAddress)") {
+		t.Fatalf("datasource_conflicts_test.go: Unexpected message '%s'", message)
+	}
+
+	config["fail_on_conflict"] = true
+	d = schema.TestResourceDataRaw(t, dataSourceRestAPIConflicts().Schema, config)
+	if err := dataSourceRestAPIConflictsRead(d, client); err == nil || !strings.Contains(err.Error(), "conflicts with the planned object") {
+		t.Fatalf("datasource_conflicts_test.go: Expected the read to fail on conflicts, got %v", err)
+	}
+
+	/* midPoint leaves out the results entirely when nothing matched */
+	response = `{"object": {}}`
+	d = schema.TestResourceDataRaw(t, dataSourceRestAPIConflicts().Schema, config)
+	if err := dataSourceRestAPIConflictsRead(d, client); err != nil {
+		t.Fatalf("datasource_conflicts_test.go: Read failed without conflicts: %s", err)
+	}
+	if d.Get("has_conflicts").(bool) || d.Get("message").(string) != "" {
+		t.Fatalf("datasource_conflicts_test.go: Expected no conflicts, got '%s'", d.Get("message"))
+	}
+}
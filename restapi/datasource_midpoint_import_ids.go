@@ -0,0 +1,129 @@
+package restapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceMidpointImportIDs() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointImportIDsRead,
+		Description: "Lists the midPoint objects of a type, optionally filtered, and returns the `restapi_object` import ID of each by name, so an existing midPoint's roles or orgs can be brought under Terraform in one pass with `import` blocks and `for_each`.",
+
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:         schema.TypeString,
+				Description:  "The collection of the objects to list, such as `roles` or `orgs`.",
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(midpointObjectCollections, false),
+			},
+			"filter": {
+				Type:          schema.TypeString,
+				Description:   "A midPoint query filter as JSON, such as `jsonencode({ equal = { path = \"subtype\", value = \"business\" } })`. Not set lists every object.",
+				Optional:      true,
+				ValidateFunc:  validation.StringIsJSON,
+				ConflictsWith: []string{"query"},
+			},
+			"query": {
+				Type:        schema.TypeString,
+				Description: "A filter in the midPoint query language, such as `subtype = \"business\"`. It needs midPoint 4.4 or newer.",
+				Optional:    true,
+			},
+			"order_by": {
+				Type:        schema.TypeString,
+				Description: "The item path the objects are listed by, such as `name`.",
+				Optional:    true,
+			},
+			"order_direction": {
+				Type:         schema.TypeString,
+				Description:  "One of `ascending` or `descending`. Default: `ascending`",
+				Optional:     true,
+				Default:      "ascending",
+				ValidateFunc: validation.StringInSlice([]string{"ascending", "descending"}, false),
+			},
+			"page_size": {
+				Type:         schema.TypeInt,
+				Description:  "The number of objects requested per search request. Default: 100",
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"max_results": {
+				Type:         schema.TypeInt,
+				Description:  "The most objects to list. Not set lists every match.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"import_options": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Import options added to every import ID as query parameters, such as `{ read_results_key = \"role\" }`; see the import of `restapi_object`.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"import_ids": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The import ID (`/<type>/<oid>`, followed by `import_options`) of every object by its name. Use it as the `for_each` of an `import` block.",
+				Computed:    true,
+			},
+			"oids": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The oid of every object by its name.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceMidpointImportIDsRead(d *schema.ResourceData, meta interface{}) error {
+	options := url.Values{}
+	for name, value := range d.Get("import_options").(map[string]interface{}) {
+		if !isImportOption(name) {
+			return fmt.Errorf("unknown import option '%s'; must be one of: %s", name, strings.Join(importOptions, ", "))
+		}
+		options.Set(name, value.(string))
+	}
+	suffix := ""
+	if len(options) > 0 {
+		suffix = "?" + options.Encode()
+	}
+
+	objects, err := searchMidpointObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := "/" + d.Get("type").(string)
+	importIDs := make(map[string]string, len(objects))
+	oids := make(map[string]string, len(objects))
+	for _, object := range objects {
+		oid := stringAtKey(object, "oid")
+		name := scalarStringAt(object, "name")
+		if name == "" {
+			return fmt.Errorf("the object '%s' at '%s' has no name to list it by", oid, path)
+		}
+		if other, ok := oids[name]; ok {
+			duplicates := []string{other, oid}
+			sort.Strings(duplicates)
+			return fmt.Errorf("the objects %s at '%s' have the same name '%s'; narrow the filter to list them by name", strings.Join(duplicates, " and "), path, name)
+		}
+		oids[name] = oid
+		importIDs[name] = path + "/" + oid + suffix
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("oids", oids)
+	return d.Set("import_ids", importIDs)
+}
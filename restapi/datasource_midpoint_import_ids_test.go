@@ -0,0 +1,50 @@
+package restapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceMidpointImportIDs(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "role/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_import_ids_test.go: Failed to create API client: %s", err)
+	}
+	for _, role := range []string{
+		`{"role": {"oid": "role-1", "name": {"orig": "Finance Approver", "norm": "finance approver"}, "subtype": "business"}}`,
+		`{"role": {"oid": "role-2", "name": "Sales", "subtype": "business"}}`,
+		`{"role": {"oid": "role-3", "name": "Sales", "subtype": "application"}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/roles", role); err != nil {
+			t.Fatalf("datasource_midpoint_import_ids_test.go: Failed to create a role: %s", err)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceMidpointImportIDs().Schema, map[string]interface{}{
+		"type":           "roles",
+		"filter":         `{"equal": {"path": "subtype", "value": "business"}}`,
+		"import_options": map[string]interface{}{"read_results_key": "role"},
+	})
+	if err := dataSourceMidpointImportIDsRead(d, client); err != nil {
+		t.Fatalf("datasource_midpoint_import_ids_test.go: Read failed: %s", err)
+	}
+	expected := map[string]interface{}{
+		"Finance Approver": "/roles/role-1?read_results_key=role",
+		"Sales":            "/roles/role-2?read_results_key=role",
+	}
+	if importIDs := d.Get("import_ids").(map[string]interface{}); !reflect.DeepEqual(importIDs, expected) {
+		t.Fatalf("datasource_midpoint_import_ids_test.go: Expected %v, got %v", expected, importIDs)
+	}
+	if oid := d.Get("oids.Sales"); oid != "role-2" {
+		t.Fatalf("datasource_midpoint_import_ids_test.go: Expected the oid of Sales to be role-2, got %v", oid)
+	}
+
+	/* Objects that cannot be told apart by name fail the read */
+	d = schema.TestResourceDataRaw(t, dataSourceMidpointImportIDs().Schema, map[string]interface{}{"type": "roles"})
+	if err := dataSourceMidpointImportIDsRead(d, client); err == nil || !strings.Contains(err.Error(), "role-2 and role-3") {
+		t.Fatalf("datasource_midpoint_import_ids_test.go: Expected the duplicate names to fail the read, got %v", err)
+	}
+}
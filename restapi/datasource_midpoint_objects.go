@@ -0,0 +1,212 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/* The collections of midPoint's REST API, by which the objects of a type are searched */
+var midpointObjectCollections = []string{
+	"accessCertificationCampaigns", "accessCertificationDefinitions", "archetypes", "cases",
+	"connectorHosts", "connectors", "dashboards", "forms", "functionLibraries", "lookupTables",
+	"marks", "messageTemplates", "nodes", "objectCollections", "objectTemplates", "orgs",
+	"reportData", "reports", "resources", "roles", "securityPolicies", "sequences", "services",
+	"shadows", "systemConfigurations", "tasks", "users", "valuePolicies",
+}
+
+func dataSourceMidpointObjects() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointObjectsRead,
+		Description: "Searches the midPoint objects of a type with a native query filter, page by page, and returns every match, so that assignments or reports can be generated from live data with `for_each`.",
+
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:         schema.TypeString,
+				Description:  "The collection of the objects to search, such as `users`, `roles`, `orgs` or `resources`.",
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(midpointObjectCollections, false),
+			},
+			"filter": {
+				Type:          schema.TypeString,
+				Description:   "A midPoint query filter as JSON, such as `jsonencode({ substring = { path = \"name\", value = \"adm\" } })`. Not set matches every object.",
+				Optional:      true,
+				ValidateFunc:  validation.StringIsJSON,
+				ConflictsWith: []string{"query"},
+			},
+			"query": {
+				Type:        schema.TypeString,
+				Description: "A filter in the midPoint query language, such as `name startsWith \"adm\"`. It needs midPoint 4.4 or newer.",
+				Optional:    true,
+			},
+			"order_by": {
+				Type:        schema.TypeString,
+				Description: "The item path the results are ordered by, such as `name`.",
+				Optional:    true,
+			},
+			"order_direction": {
+				Type:         schema.TypeString,
+				Description:  "One of `ascending` or `descending`. Default: `ascending`",
+				Optional:     true,
+				Default:      "ascending",
+				ValidateFunc: validation.StringInSlice([]string{"ascending", "descending"}, false),
+			},
+			"page_size": {
+				Type:         schema.TypeInt,
+				Description:  "The number of objects requested per search request. Default: 100",
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"max_results": {
+				Type:         schema.TypeInt,
+				Description:  "The most objects to return. Not set returns every match.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"objects": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Every object found, in the order of the search, as JSON to be read with `jsondecode`.",
+				Computed:    true,
+			},
+			"oids": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The oid of every object in `objects`.",
+				Computed:    true,
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The name of every object in `objects`.",
+				Computed:    true,
+			},
+			"objects_by_oid": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The objects as JSON by oid. Use it with `for_each`.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* midpointSearchQuery returns the query of the filter or query attribute, without paging */
+func midpointSearchQuery(d *schema.ResourceData) (map[string]interface{}, error) {
+	query := make(map[string]interface{})
+	if filter := d.Get("filter").(string); filter != "" {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(filter), &parsed); err != nil {
+			return nil, fmt.Errorf("filter is not a JSON object: %v", err)
+		}
+		query["filter"] = parsed
+	}
+	if text := d.Get("query").(string); text != "" {
+		query["filter"] = map[string]interface{}{"text": text}
+	}
+	return query, nil
+}
+
+/*
+searchMidpointObjects runs the search of the type, filter, query, order_by,
+
+	order_direction, page_size and max_results attributes page by page and
+	returns the objects found, unwrapped from the key naming their type.
+*/
+func searchMidpointObjects(d *schema.ResourceData, meta interface{}) ([]map[string]interface{}, error) {
+	debug := d.Get("debug").(bool)
+	path := "/" + d.Get("type").(string)
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:       path,
+		searchPath: path + "/search",
+		debug:      debug,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query, err := midpointSearchQuery(d)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := d.Get("page_size").(int)
+	maxResults := d.Get("max_results").(int)
+	records := make([]interface{}, 0)
+	for {
+		size := pageSize
+		if maxResults > 0 && maxResults-len(records) < size {
+			size = maxResults - len(records)
+		}
+		paging := map[string]interface{}{"offset": len(records), "maxSize": size}
+		if orderBy := d.Get("order_by").(string); orderBy != "" {
+			paging["orderBy"] = orderBy
+			paging["orderDirection"] = d.Get("order_direction").(string)
+		}
+		query["paging"] = paging
+
+		search, _ := json.Marshal(map[string]interface{}{"query": query})
+		if debug {
+			log.Printf("datasource_midpoint_objects.go: Searching '%s' for %s", obj.searchPath, string(search))
+		}
+		page, err := obj.listObjects("POST", "", "object/object", string(search))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+		if len(page) < size || maxResults > 0 && len(records) >= maxResults {
+			break
+		}
+	}
+
+	objects := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		hash, ok := record.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the objects found at '%s' are not a map of key value pairs", path)
+		}
+		objects = append(objects, midpointObjectOf(hash))
+	}
+	if debug {
+		log.Printf("datasource_midpoint_objects.go: Found %d objects at '%s'", len(objects), path)
+	}
+	return objects, nil
+}
+
+func dataSourceMidpointObjectsRead(d *schema.ResourceData, meta interface{}) error {
+	records, err := searchMidpointObjects(d, meta)
+	if err != nil {
+		return err
+	}
+
+	objects := make([]string, 0, len(records))
+	oids := make([]string, 0, len(records))
+	names := make([]string, 0, len(records))
+	byOid := make(map[string]string, len(records))
+	for _, object := range records {
+		b, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		oid := stringAtKey(object, "oid")
+		objects = append(objects, string(b))
+		oids = append(oids, oid)
+		names = append(names, scalarStringAt(object, "name"))
+		byOid[oid] = string(b)
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("objects", objects)
+	d.Set("oids", oids)
+	d.Set("names", names)
+	return d.Set("objects_by_oid", byOid)
+}
@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceMidpointObjects(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_objects_test.go: Failed to create API client: %s", err)
+	}
+	for _, user := range []string{
+		`{"user": {"oid": "user-1", "name": {"orig": "dave", "norm": "dave"}, "subtype": "employee"}}`,
+		`{"user": {"oid": "user-2", "name": "carol", "subtype": "employee"}}`,
+		`{"user": {"oid": "user-3", "name": "bob", "subtype": "contractor"}}`,
+		`{"user": {"oid": "user-4", "name": "alice", "subtype": "employee"}}`,
+		`{"user": {"oid": "user-5", "name": "erin", "subtype": "employee"}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/users", user); err != nil {
+			t.Fatalf("datasource_midpoint_objects_test.go: Failed to create a user: %s", err)
+		}
+	}
+
+	for _, test := range []struct {
+		config map[string]interface{}
+		names  []interface{}
+	}{
+		/* Pages of two objects until a short one */
+		{map[string]interface{}{"type": "users", "page_size": 2}, []interface{}{"dave", "carol", "bob", "alice", "erin"}},
+		{map[string]interface{}{"type": "users", "page_size": 2, "order_by": "name"}, []interface{}{"alice", "bob", "carol", "dave", "erin"}},
+		{map[string]interface{}{"type": "users", "page_size": 2, "order_by": "name", "order_direction": "descending", "max_results": 3}, []interface{}{"erin", "dave", "carol"}},
+		{map[string]interface{}{"type": "users", "filter": `{"equal": {"path": "subtype", "value": "employee"}}`, "order_by": "name"}, []interface{}{"alice", "carol", "dave", "erin"}},
+		{map[string]interface{}{"type": "roles"}, []interface{}{}},
+	} {
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointObjects().Schema, test.config)
+		if err := dataSourceMidpointObjectsRead(d, client); err != nil {
+			t.Fatalf("datasource_midpoint_objects_test.go: Read of %v failed: %s", test.config, err)
+		}
+		if names := d.Get("names").([]interface{}); !reflect.DeepEqual(names, test.names) {
+			t.Fatalf("datasource_midpoint_objects_test.go: Expected %v for %v, got %v", test.names, test.config, names)
+		}
+		if len(d.Get("objects").([]interface{})) != len(test.names) || len(d.Get("objects_by_oid").(map[string]interface{})) != len(test.names) {
+			t.Fatalf("datasource_midpoint_objects_test.go: Unexpected objects for %v: %v", test.config, d.State())
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceMidpointObjects().Schema, map[string]interface{}{"type": "users", "filter": `{"equal": {"path": "name", "value": "bob"}}`})
+	if err := dataSourceMidpointObjectsRead(d, client); err != nil {
+		t.Fatalf("datasource_midpoint_objects_test.go: Read failed: %s", err)
+	}
+	if d.Get("oids.0") != "user-3" || d.Get("objects.0") != `{"name":"bob","oid":"user-3","subtype":"contractor"}` || d.Get("objects_by_oid.user-3") != d.Get("objects.0") {
+		t.Fatalf("datasource_midpoint_objects_test.go: Unexpected attributes %v", d.State())
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceMidpointObjects().Schema, map[string]interface{}{"type": "users", "query": `name = "bob"`})
+	query, _ := midpointSearchQuery(d)
+	if !reflect.DeepEqual(query, map[string]interface{}{"filter": map[string]interface{}{"text": `name = "bob"`}}) {
+		t.Fatalf("datasource_midpoint_objects_test.go: Unexpected query %v", query)
+	}
+}
@@ -0,0 +1,367 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceMidpointResourceSchema() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointResourceSchemaRead,
+		Description: "Reads the schema midPoint discovered for a resource and exposes its object classes and their attributes, so that schemaHandling can be validated or generated from it.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/resources`. The API path of the resources on the midPoint server.",
+				Optional:    true,
+				Default:     "/resources",
+			},
+			"oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the resource.",
+				Required:    true,
+			},
+			"object_class": {
+				Type:        schema.TypeString,
+				Description: "The name of the only object class to return, such as `AccountObjectClass`. Not set returns every object class.",
+				Optional:    true,
+			},
+			"test_connection": {
+				Type:        schema.TypeBool,
+				Description: "Whether to test the connection of the resource first, which makes midPoint discover its schema when it has none yet. The read fails when the test does.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the resource.",
+				Computed:    true,
+			},
+			"object_classes": {
+				Type:        schema.TypeList,
+				Description: "The object classes of the schema, in its order.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the object class, as used with the `ri:` prefix in schemaHandling.",
+							Computed:    true,
+						},
+						"native_name": {
+							Type:        schema.TypeString,
+							Description: "The name of the object class in the connector, such as `__ACCOUNT__`.",
+							Computed:    true,
+						},
+						"display_name": {
+							Type:        schema.TypeString,
+							Description: "The display name of the object class.",
+							Computed:    true,
+						},
+						"kind": {
+							Type:        schema.TypeString,
+							Description: "The kind the object class is for by default, such as `account`.",
+							Computed:    true,
+						},
+						"default": {
+							Type:        schema.TypeBool,
+							Description: "Whether this is the default object class of its kind.",
+							Computed:    true,
+						},
+						"auxiliary": {
+							Type:        schema.TypeBool,
+							Description: "Whether this is an auxiliary object class.",
+							Computed:    true,
+						},
+						"identifiers": {
+							Type:        schema.TypeList,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The names of the primary identifier attributes.",
+							Computed:    true,
+						},
+						"secondary_identifiers": {
+							Type:        schema.TypeList,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The names of the secondary identifier attributes.",
+							Computed:    true,
+						},
+						"attributes": {
+							Type:        schema.TypeList,
+							Description: "The attributes of the object class, in the order of the schema.",
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:        schema.TypeString,
+										Description: "The name of the attribute, as used with the `ri:` prefix in schemaHandling.",
+										Computed:    true,
+									},
+									"type": {
+										Type:        schema.TypeString,
+										Description: "The XSD type of the attribute, such as `string` or `int`.",
+										Computed:    true,
+									},
+									"native_name": {
+										Type:        schema.TypeString,
+										Description: "The name of the attribute in the connector.",
+										Computed:    true,
+									},
+									"framework_name": {
+										Type:        schema.TypeString,
+										Description: "The ConnId name of the attribute, such as `__NAME__`.",
+										Computed:    true,
+									},
+									"display_name": {
+										Type:        schema.TypeString,
+										Description: "The display name of the attribute.",
+										Computed:    true,
+									},
+									"required": {
+										Type:        schema.TypeBool,
+										Description: "Whether the attribute must have a value.",
+										Computed:    true,
+									},
+									"multivalued": {
+										Type:        schema.TypeBool,
+										Description: "Whether the attribute can have more than one value.",
+										Computed:    true,
+									},
+									"readable": {
+										Type:        schema.TypeBool,
+										Description: "Whether the attribute can be read.",
+										Computed:    true,
+									},
+									"creatable": {
+										Type:        schema.TypeBool,
+										Description: "Whether the attribute can be set when creating objects.",
+										Computed:    true,
+									},
+									"updatable": {
+										Type:        schema.TypeBool,
+										Description: "Whether the attribute can be modified.",
+										Computed:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"schema": {
+				Type:        schema.TypeString,
+				Description: "The XSD schema of the resource as JSON, to be read with `jsondecode`.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/*
+xsdLocalNames returns a schema definition with the namespace prefixes of its
+
+	keys dropped, such as xsd:complexType becoming complexType, and without
+	the namespace declarations, so that it is walked the same whichever
+	prefixes the server chose. A definition that is XML text is parsed first.
+*/
+func xsdLocalNames(value interface{}) (interface{}, error) {
+	if text, ok := value.(string); ok && isXMLBody(text) {
+		converted, err := xmlToJSON(text)
+		if err != nil {
+			return nil, err
+		}
+		var parsed map[string]interface{}
+		json.Unmarshal([]byte(converted), &parsed)
+		return xsdLocalNames(parsed)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if strings.HasPrefix(key, "@xmlns") {
+				continue
+			}
+			local := key
+			if i := strings.LastIndex(key, ":"); i >= 0 {
+				local = key[i+1:]
+				if strings.HasPrefix(key, "@") {
+					local = "@" + local
+				}
+			}
+			converted, err := xsdLocalNames(item)
+			if err != nil {
+				return nil, err
+			}
+			result[local] = converted
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			converted, err := xsdLocalNames(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+/* xsdAppinfo returns the appinfo of the annotation of an XSD definition */
+func xsdAppinfo(definition map[string]interface{}) map[string]interface{} {
+	annotation, _ := definition["annotation"].(map[string]interface{})
+	appinfo, _ := annotation["appinfo"].(map[string]interface{})
+	if appinfo == nil {
+		return map[string]interface{}{}
+	}
+	return appinfo
+}
+
+/* xsdValues returns the values of an item that may be repeated, as a list */
+func xsdValues(value interface{}) []interface{} {
+	if value == nil {
+		return nil
+	}
+	if values, ok := value.([]interface{}); ok {
+		return values
+	}
+	return []interface{}{value}
+}
+
+/* xsdRefs returns the local names the ref attributes of appinfo items such as ra:identifier point to */
+func xsdRefs(value interface{}) []interface{} {
+	refs := make([]interface{}, 0)
+	for _, item := range xsdValues(value) {
+		if hash, ok := item.(map[string]interface{}); ok {
+			refs = append(refs, refQName(scalarStringAt(hash, "@ref")))
+		}
+	}
+	return refs
+}
+
+/* flattenXSDAttribute returns the attribute block of an element of an object class */
+func flattenXSDAttribute(element map[string]interface{}) map[string]interface{} {
+	appinfo := xsdAppinfo(element)
+	access := make(map[string]bool)
+	for _, value := range xsdValues(appinfo["access"]) {
+		access[scalarString(value)] = true
+	}
+	/* Attributes without access annotations can be read, created and updated */
+	unrestricted := len(access) == 0
+
+	return map[string]interface{}{
+		"name":           refQName(scalarStringAt(element, "@name")),
+		"type":           refQName(scalarStringAt(element, "@type")),
+		"native_name":    scalarStringAt(appinfo, "nativeAttributeName"),
+		"framework_name": scalarStringAt(appinfo, "frameworkAttributeName"),
+		"display_name":   scalarStringAt(appinfo, "displayName"),
+		"required":       scalarStringAt(element, "@minOccurs") != "0",
+		"multivalued":    scalarStringAt(element, "@maxOccurs") == "unbounded",
+		"readable":       unrestricted || access["read"],
+		"creatable":      unrestricted || access["create"],
+		"updatable":      unrestricted || access["update"],
+	}
+}
+
+/* flattenXSDObjectClass returns the object class block of a complexType of a resource schema */
+func flattenXSDObjectClass(complexType map[string]interface{}) map[string]interface{} {
+	appinfo := xsdAppinfo(complexType)
+	attributes := make([]interface{}, 0)
+	sequence, _ := complexType["sequence"].(map[string]interface{})
+	for _, value := range xsdValues(sequence["element"]) {
+		if element, ok := value.(map[string]interface{}); ok {
+			attributes = append(attributes, flattenXSDAttribute(element))
+		}
+	}
+
+	return map[string]interface{}{
+		"name":                  refQName(scalarStringAt(complexType, "@name")),
+		"native_name":           scalarStringAt(appinfo, "nativeObjectClass"),
+		"display_name":          scalarStringAt(appinfo, "displayName"),
+		"kind":                  scalarStringAt(appinfo, "kind"),
+		"default":               scalarStringAt(appinfo, "default") == "true",
+		"auxiliary":             scalarStringAt(appinfo, "auxiliary") == "true",
+		"identifiers":           xsdRefs(appinfo["identifier"]),
+		"secondary_identifiers": xsdRefs(appinfo["secondaryIdentifier"]),
+		"attributes":            attributes,
+	}
+}
+
+func dataSourceMidpointResourceSchemaRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*APIClient)
+	debug := d.Get("debug").(bool)
+	oid := d.Get("oid").(string)
+	path := strings.TrimSuffix(d.Get("path").(string), "/")
+
+	if d.Get("test_connection").(bool) {
+		if err := testMidpointResource(client, fmt.Sprintf("%s/%s", path, oid), debug); err != nil {
+			return err
+		}
+	}
+
+	resultString, err := client.sendRequest("GET", fmt.Sprintf("%s/%s", path, oid), "")
+	if err != nil {
+		return err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(resultString), &result); err != nil {
+		return fmt.Errorf("failed to parse the resource '%s': %v", oid, err)
+	}
+	resource := midpointObjectOf(result)
+
+	definition, err := GetObjectAtKey(resource, "schema/definition", debug)
+	if err != nil || definition == nil {
+		return fmt.Errorf("the resource '%s' has no schema yet; set test_connection to have midPoint discover it", oid)
+	}
+	if hash, ok := definition.(map[string]interface{}); ok && len(hash) == 1 {
+		/* The definition holds the xsd:schema element */
+		for _, inner := range hash {
+			definition = inner
+		}
+	}
+	xsd, err := xsdLocalNames(definition)
+	if err != nil {
+		return fmt.Errorf("failed to parse the schema of the resource '%s': %v", oid, err)
+	}
+	if hash, ok := xsd.(map[string]interface{}); ok {
+		if inner, ok := hash["schema"]; ok && len(hash) == 1 {
+			xsd = inner
+		}
+	}
+	xsdSchema, ok := xsd.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the schema of the resource '%s' is not an XSD schema", oid)
+	}
+
+	wanted := d.Get("object_class").(string)
+	objectClasses := make([]interface{}, 0)
+	for _, value := range xsdValues(xsdSchema["complexType"]) {
+		complexType, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		objectClass := flattenXSDObjectClass(complexType)
+		if wanted == "" || objectClass["name"] == refQName(wanted) {
+			objectClasses = append(objectClasses, objectClass)
+		}
+	}
+	if wanted != "" && len(objectClasses) == 0 {
+		return fmt.Errorf("the schema of the resource '%s' has no object class '%s'", oid, wanted)
+	}
+
+	d.SetId(oid)
+	d.Set("name", scalarStringAt(resource, "name"))
+	d.Set("object_classes", objectClasses)
+	b, _ := json.Marshal(xsdSchema)
+	return d.Set("schema", string(b))
+}
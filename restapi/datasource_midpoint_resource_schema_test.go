@@ -0,0 +1,105 @@
+package restapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const testResourceXSD = `<xsd:schema xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:ra="http://midpoint.evolveum.com/xml/ns/public/resource/annotation-3"
+	xmlns:a="http://prism.evolveum.com/xml/ns/public/annotation-3" xmlns:icfs="http://midpoint.evolveum.com/xml/ns/public/connector/icf-1/resource-schema-3"
+	targetNamespace="http://midpoint.evolveum.com/xml/ns/public/resource/instance-3">
+  <xsd:complexType name="AccountObjectClass">
+    <xsd:annotation><xsd:appinfo>
+      <ra:resourceObject/>
+      <ra:identifier ref="icfs:uid"/>
+      <ra:secondaryIdentifier ref="icfs:name"/>
+      <ra:nativeObjectClass>__ACCOUNT__</ra:nativeObjectClass>
+      <ra:kind>account</ra:kind>
+      <ra:default>true</ra:default>
+    </xsd:appinfo></xsd:annotation>
+    <xsd:sequence>
+      <xsd:element name="icfs:uid" type="xsd:string" minOccurs="0">
+        <xsd:annotation><xsd:appinfo><a:displayName>ConnId UID</a:displayName><a:access>read</a:access><ra:frameworkAttributeName>__UID__</ra:frameworkAttributeName></xsd:appinfo></xsd:annotation>
+      </xsd:element>
+      <xsd:element name="icfs:name" type="xsd:string"/>
+      <xsd:element name="mail" type="xsd:string" minOccurs="0" maxOccurs="unbounded">
+        <xsd:annotation><xsd:appinfo><ra:nativeAttributeName>mail</ra:nativeAttributeName><a:access>read</a:access><a:access>update</a:access></xsd:appinfo></xsd:annotation>
+      </xsd:element>
+    </xsd:sequence>
+  </xsd:complexType>
+  <xsd:complexType name="GroupObjectClass">
+    <xsd:annotation><xsd:appinfo><ra:resourceObject/><ra:nativeObjectClass>__GROUP__</ra:nativeObjectClass></xsd:appinfo></xsd:annotation>
+    <xsd:sequence><xsd:element name="members" type="xsd:string" minOccurs="0" maxOccurs="unbounded"/></xsd:sequence>
+  </xsd:complexType>
+</xsd:schema>`
+
+func TestDataSourceMidpointResourceSchema(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "resource/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_resource_schema_test.go: Failed to create API client: %s", err)
+	}
+
+	/* The same schema as XML text and as the JSON midPoint may serialize it to */
+	converted, _ := xmlToJSON(testResourceXSD)
+	var definition map[string]interface{}
+	json.Unmarshal([]byte(converted), &definition)
+	for oid, value := range map[string]interface{}{"resource-xml": testResourceXSD, "resource-json": definition["schema"]} {
+		resource, _ := json.Marshal(map[string]interface{}{"resource": map[string]interface{}{
+			"oid": oid, "name": "LDAP",
+			"schema": map[string]interface{}{"definition": map[string]interface{}{"xsd:schema": value}},
+		}})
+		if _, err := client.sendRequest("POST", "/resources", string(resource)); err != nil {
+			t.Fatalf("datasource_midpoint_resource_schema_test.go: Failed to create a resource: %s", err)
+		}
+	}
+	if _, err := client.sendRequest("POST", "/resources", `{"resource": {"oid": "resource-new", "name": "New"}}`); err != nil {
+		t.Fatalf("datasource_midpoint_resource_schema_test.go: Failed to create a resource: %s", err)
+	}
+
+	for _, oid := range []string{"resource-xml", "resource-json"} {
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointResourceSchema().Schema, map[string]interface{}{"oid": oid})
+		if err := dataSourceMidpointResourceSchemaRead(d, client); err != nil {
+			t.Fatalf("datasource_midpoint_resource_schema_test.go: Read of '%s' failed: %s", oid, err)
+		}
+		if d.Get("name") != "LDAP" || d.Get("object_classes.#") != 2 || d.Get("object_classes.1.name") != "GroupObjectClass" {
+			t.Fatalf("datasource_midpoint_resource_schema_test.go: Unexpected object classes of '%s': %v", oid, d.Get("object_classes"))
+		}
+		account := d.Get("object_classes.0").(map[string]interface{})
+		if account["name"] != "AccountObjectClass" || account["native_name"] != "__ACCOUNT__" || account["kind"] != "account" || account["default"] != true || account["auxiliary"] != false ||
+			!reflect.DeepEqual(account["identifiers"], []interface{}{"uid"}) || !reflect.DeepEqual(account["secondary_identifiers"], []interface{}{"name"}) {
+			t.Fatalf("datasource_midpoint_resource_schema_test.go: Unexpected object class of '%s': %v", oid, account)
+		}
+
+		expected := []interface{}{
+			map[string]interface{}{"name": "uid", "type": "string", "native_name": "", "framework_name": "__UID__", "display_name": "ConnId UID",
+				"required": false, "multivalued": false, "readable": true, "creatable": false, "updatable": false},
+			map[string]interface{}{"name": "name", "type": "string", "native_name": "", "framework_name": "", "display_name": "",
+				"required": true, "multivalued": false, "readable": true, "creatable": true, "updatable": true},
+			map[string]interface{}{"name": "mail", "type": "string", "native_name": "mail", "framework_name": "", "display_name": "",
+				"required": false, "multivalued": true, "readable": true, "creatable": false, "updatable": true},
+		}
+		if !reflect.DeepEqual(account["attributes"], expected) {
+			t.Fatalf("datasource_midpoint_resource_schema_test.go: Unexpected attributes of '%s':\n%v", oid, account["attributes"])
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceMidpointResourceSchema().Schema, map[string]interface{}{"oid": "resource-xml", "object_class": "ri:GroupObjectClass"})
+	if err := dataSourceMidpointResourceSchemaRead(d, client); err != nil {
+		t.Fatalf("datasource_midpoint_resource_schema_test.go: Read failed: %s", err)
+	}
+	if d.Get("object_classes.#") != 1 || d.Get("object_classes.0.native_name") != "__GROUP__" || d.Get("object_classes.0.attributes.0.multivalued") != true {
+		t.Fatalf("datasource_midpoint_resource_schema_test.go: Unexpected object classes %v", d.Get("object_classes"))
+	}
+
+	for config, message := range map[string]string{"resource-xml/Missing": "has no object class 'Missing'", "resource-new/": "has no schema yet"} {
+		parts := strings.SplitN(config, "/", 2)
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointResourceSchema().Schema, map[string]interface{}{"oid": parts[0], "object_class": parts[1]})
+		if err := dataSourceMidpointResourceSchemaRead(d, client); err == nil || !strings.Contains(err.Error(), message) {
+			t.Fatalf("datasource_midpoint_resource_schema_test.go: Expected '%s' to fail with '%s', got %v", config, message, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceMidpointRole() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointRoleRead,
+		Description: "Finds a single midPoint role by name, identifier, archetype or query filter and exposes its oid and main properties, so that assignments can reference roles not managed by Terraform.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/roles`. The API path of the roles on the midPoint server.",
+				Optional:    true,
+				Default:     "/roles",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name of the role to find.",
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"name", "identifier", "archetype_oid", "filter"},
+			},
+			"identifier": {
+				Type:        schema.TypeString,
+				Description: "The identifier of the role to find.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"archetype_oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of an archetype of the role to find. Combined with the other attributes, it narrows the search down.",
+				Optional:    true,
+			},
+			"filter": {
+				Type:         schema.TypeString,
+				Description:  "A midPoint query filter as JSON. Combined with the other attributes, it narrows the search down.",
+				Optional:     true,
+				ValidateFunc: validation.StringIsJSON,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the role.",
+				Computed:    true,
+			},
+			"display_name": {
+				Type:        schema.TypeString,
+				Description: "The display name of the role.",
+				Computed:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the role.",
+				Computed:    true,
+			},
+			"lifecycle_state": {
+				Type:        schema.TypeString,
+				Description: "The lifecycle state of the role, such as `active` or `draft`.",
+				Computed:    true,
+			},
+			"archetype_oids": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The oids of the archetypes of the role (archetypeRef).",
+				Computed:    true,
+			},
+			"inducement": computedRefsSchema("The inducements of the role that have a target."),
+			"object": {
+				Type:        schema.TypeString,
+				Description: "The whole role as JSON, to be read with `jsondecode`.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceMidpointRoleRead(d *schema.ResourceData, meta interface{}) error {
+	var clauses map[string]interface{}
+	if archetype := d.Get("archetype_oid").(string); archetype != "" {
+		clauses = map[string]interface{}{"ref": map[string]interface{}{"path": "archetypeRef", "value": map[string]interface{}{"oid": archetype}}}
+	}
+	filter, err := lookupFilter(d, [][2]string{{"name", "name"}, {"identifier", "identifier"}}, clauses)
+	if err != nil {
+		return err
+	}
+	role, err := findMidpointObject(meta.(*APIClient), d.Get("path").(string), filter, d.Get("debug").(bool))
+	if err != nil {
+		return err
+	}
+
+	oid := stringAtKey(role, "oid")
+	if oid == "" {
+		return fmt.Errorf("the role found has no oid")
+	}
+	d.SetId(oid)
+	d.Set("oid", oid)
+	d.Set("name", scalarStringAt(role, "name"))
+	d.Set("identifier", scalarStringAt(role, "identifier"))
+	d.Set("display_name", scalarStringAt(role, "displayName"))
+	d.Set("description", scalarStringAt(role, "description"))
+	d.Set("lifecycle_state", scalarStringAt(role, "lifecycleState"))
+
+	archetypes := make([]string, 0)
+	for _, ref := range flattenRefs(role["archetypeRef"]) {
+		archetypes = append(archetypes, ref.(map[string]interface{})["target_oid"].(string))
+	}
+	d.Set("archetype_oids", archetypes)
+	d.Set("inducement", flattenTargetRefs(role["inducement"], nil))
+
+	b, _ := json.Marshal(role)
+	return d.Set("object", string(b))
+}
@@ -0,0 +1,57 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceMidpointRole(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "role/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_role_test.go: Failed to create API client: %s", err)
+	}
+	for _, role := range []string{
+		`{"role": {"oid": "role-1", "name": {"orig": "Auditor", "norm": "auditor"}, "identifier": "AUD", "displayName": "Auditor", "lifecycleState": "active",
+			"archetypeRef": {"oid": "business-role", "type": "c:ArchetypeType"}, "inducement": [{"@id": 1, "targetRef": {"oid": "role-3", "type": "c:RoleType"}}]}}`,
+		`{"role": {"oid": "role-2", "name": "Approver", "identifier": "APR", "archetypeRef": [{"oid": "business-role", "type": "c:ArchetypeType"}]}}`,
+		`{"role": {"oid": "role-3", "name": "Reader", "archetypeRef": [{"oid": "application-role", "type": "c:ArchetypeType"}]}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/roles", role); err != nil {
+			t.Fatalf("datasource_midpoint_role_test.go: Failed to create a role: %s", err)
+		}
+	}
+
+	for _, test := range []struct {
+		config map[string]interface{}
+		oid    string
+	}{
+		{map[string]interface{}{"name": "Auditor"}, "role-1"},
+		{map[string]interface{}{"identifier": "APR"}, "role-2"},
+		{map[string]interface{}{"archetype_oid": "application-role"}, "role-3"},
+		{map[string]interface{}{"archetype_oid": "business-role", "identifier": "AUD"}, "role-1"},
+		{map[string]interface{}{"archetype_oid": "business-role", "filter": `{"equal": {"path": "lifecycleState", "value": "active"}}`}, "role-1"},
+	} {
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointRole().Schema, test.config)
+		if err := dataSourceMidpointRoleRead(d, client); err != nil {
+			t.Fatalf("datasource_midpoint_role_test.go: Read of %v failed: %s", test.config, err)
+		}
+		if d.Id() != test.oid || d.Get("oid") != test.oid {
+			t.Fatalf("datasource_midpoint_role_test.go: Expected %s for %v, got '%s'", test.oid, test.config, d.Id())
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceMidpointRole().Schema, map[string]interface{}{"name": "Auditor"})
+	if err := dataSourceMidpointRoleRead(d, client); err != nil {
+		t.Fatalf("datasource_midpoint_role_test.go: Read failed: %s", err)
+	}
+	if d.Get("identifier") != "AUD" || d.Get("display_name") != "Auditor" || d.Get("archetype_oids.0") != "business-role" || d.Get("inducement.0.target_oid") != "role-3" || d.Get("lifecycle_state") != "active" {
+		t.Fatalf("datasource_midpoint_role_test.go: Unexpected attributes %v", d.State())
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceMidpointRole().Schema, map[string]interface{}{"archetype_oid": "business-role"})
+	if err := dataSourceMidpointRoleRead(d, client); err == nil || !strings.Contains(err.Error(), "2 objects at '/roles' match") {
+		t.Fatalf("datasource_midpoint_role_test.go: Expected an ambiguous archetype to fail, got %v", err)
+	}
+}
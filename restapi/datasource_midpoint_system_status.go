@@ -0,0 +1,237 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* midpointVersionPattern matches the numeric part of a version such as 4.8.3 or 4.9-SNAPSHOT */
+var midpointVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+func dataSourceMidpointSystemStatus() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointSystemStatusRead,
+		Description: "Reads the version and build of midPoint and the status of its cluster nodes, so that configurations can depend on the version of the server, as in `count = data.restapi_midpoint_system_status.this.meets_minimum_version ? 1 : 0`.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/nodes`. The API path of the nodes on the midPoint server.",
+				Optional:    true,
+				Default:     "/nodes",
+			},
+			"minimum_version": {
+				Type:        schema.TypeString,
+				Description: "A version such as `4.8` or `4.8.2` that `meets_minimum_version` compares the version of midPoint with.",
+				Optional:    true,
+				ValidateFunc: func(value interface{}, key string) ([]string, []error) {
+					if !midpointVersionPattern.MatchString(value.(string)) {
+						return nil, []error{fmt.Errorf("%s must be a version such as 4.8 or 4.8.2, got '%s'", key, value)}
+					}
+					return nil, nil
+				},
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Description: "The version of midPoint, such as `4.8.3`, as built into the first node that is up.",
+				Computed:    true,
+			},
+			"version_major": {
+				Type:        schema.TypeInt,
+				Description: "The major number of the version, such as `4`.",
+				Computed:    true,
+			},
+			"version_minor": {
+				Type:        schema.TypeInt,
+				Description: "The minor number of the version, such as `8`.",
+				Computed:    true,
+			},
+			"version_patch": {
+				Type:        schema.TypeInt,
+				Description: "The patch number of the version, or 0 when it has none.",
+				Computed:    true,
+			},
+			"revision": {
+				Type:        schema.TypeString,
+				Description: "The source revision midPoint was built from.",
+				Computed:    true,
+			},
+			"build_timestamp": {
+				Type:        schema.TypeString,
+				Description: "When midPoint was built.",
+				Computed:    true,
+			},
+			"meets_minimum_version": {
+				Type:        schema.TypeBool,
+				Description: "Whether the version is `minimum_version` or newer. True when `minimum_version` is not set.",
+				Computed:    true,
+			},
+			"nodes_up": {
+				Type:        schema.TypeInt,
+				Description: "The number of nodes whose operational state is `up`.",
+				Computed:    true,
+			},
+			"nodes": {
+				Type:        schema.TypeList,
+				Description: "The nodes of the midPoint cluster.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"oid": {
+							Type:        schema.TypeString,
+							Description: "The oid of the node object.",
+							Computed:    true,
+						},
+						"node_identifier": {
+							Type:        schema.TypeString,
+							Description: "The identifier of the node.",
+							Computed:    true,
+						},
+						"hostname": {
+							Type:        schema.TypeString,
+							Description: "The host name of the node.",
+							Computed:    true,
+						},
+						"url": {
+							Type:        schema.TypeString,
+							Description: "The URL of the node for intra-cluster communication.",
+							Computed:    true,
+						},
+						"version": {
+							Type:        schema.TypeString,
+							Description: "The version of midPoint the node runs.",
+							Computed:    true,
+						},
+						"operational_state": {
+							Type:        schema.TypeString,
+							Description: "The operational state of the node, such as `up`, `down` or `starting`.",
+							Computed:    true,
+						},
+						"execution_state": {
+							Type:        schema.TypeString,
+							Description: "Whether the node runs tasks, such as `running` or `paused`.",
+							Computed:    true,
+						},
+						"last_check_in_time": {
+							Type:        schema.TypeString,
+							Description: "When the node last checked in with the cluster.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+/* parseMidpointVersion returns the major, minor and patch numbers of a version; missing ones are 0 */
+func parseMidpointVersion(version string) ([3]int, bool) {
+	var numbers [3]int
+	match := midpointVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return numbers, false
+	}
+	for i, part := range match[1:] {
+		numbers[i], _ = strconv.Atoi(part)
+	}
+	return numbers, true
+}
+
+/* versionAtLeast reports whether version is minimum or newer */
+func versionAtLeast(version [3]int, minimum [3]int) bool {
+	for i := range version {
+		if version[i] != minimum[i] {
+			return version[i] > minimum[i]
+		}
+	}
+	return true
+}
+
+func dataSourceMidpointSystemStatusRead(d *schema.ResourceData, meta interface{}) error {
+	debug := d.Get("debug").(bool)
+	path := d.Get("path").(string)
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:       path,
+		searchPath: path + "/search",
+		debug:      debug,
+	})
+	if err != nil {
+		return err
+	}
+	records, err := obj.listObjects("POST", "", "object/object", `{"query": {}}`)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no node found at '%s'", path)
+	}
+
+	nodes := make([]interface{}, 0, len(records))
+	up := 0
+	var current map[string]interface{}
+	for _, record := range records {
+		hash, ok := record.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("the nodes found at '%s' are not a map of key value pairs", path)
+		}
+		node := midpointObjectOf(hash)
+		build, _ := node["build"].(map[string]interface{})
+		state := scalarStringAt(node, "operationalState")
+		if state == "up" {
+			up++
+			if current == nil {
+				current = node
+			}
+		}
+		nodes = append(nodes, map[string]interface{}{
+			"oid":                stringAtKey(node, "oid"),
+			"node_identifier":    scalarStringAt(node, "nodeIdentifier"),
+			"hostname":           scalarStringAt(node, "hostname"),
+			"url":                scalarStringAt(node, "url"),
+			"version":            scalarStringAt(build, "version"),
+			"operational_state":  state,
+			"execution_state":    scalarStringAt(node, "executionState"),
+			"last_check_in_time": scalarStringAt(node, "lastCheckInTime"),
+		})
+	}
+	if current == nil {
+		/* No node reports being up, as before midPoint 4.0 */
+		current = midpointObjectOf(records[0].(map[string]interface{}))
+	}
+
+	build, _ := current["build"].(map[string]interface{})
+	version := scalarStringAt(build, "version")
+	numbers, ok := parseMidpointVersion(version)
+	if !ok {
+		return fmt.Errorf("the node '%s' reports no midPoint version, got '%s'", scalarStringAt(current, "nodeIdentifier"), version)
+	}
+	if debug {
+		log.Printf("datasource_midpoint_system_status.go: midPoint %s with %d of %d nodes up", version, up, len(nodes))
+	}
+
+	meets := true
+	if minimum := d.Get("minimum_version").(string); minimum != "" {
+		minimumNumbers, _ := parseMidpointVersion(minimum)
+		meets = versionAtLeast(numbers, minimumNumbers)
+	}
+
+	d.SetId(version)
+	d.Set("version", version)
+	d.Set("version_major", numbers[0])
+	d.Set("version_minor", numbers[1])
+	d.Set("version_patch", numbers[2])
+	d.Set("revision", scalarStringAt(build, "revision"))
+	d.Set("build_timestamp", scalarStringAt(build, "timestamp"))
+	d.Set("meets_minimum_version", meets)
+	d.Set("nodes_up", up)
+	return d.Set("nodes", nodes)
+}
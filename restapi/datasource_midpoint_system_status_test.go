@@ -0,0 +1,45 @@
+package restapi
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceMidpointSystemStatus(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "node/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_system_status_test.go: Failed to create API client: %s", err)
+	}
+	for _, node := range []string{
+		`{"node": {"oid": "node-1", "nodeIdentifier": "node-a", "operationalState": "down", "build": {"version": "4.7.1"}}}`,
+		`{"node": {"oid": "node-2", "nodeIdentifier": "node-b", "hostname": "mp2", "operationalState": "up", "executionState": "running",
+			"lastCheckInTime": "2026-10-16T10:00:00.000Z", "build": {"version": "4.8.3-SNAPSHOT", "revision": "v4.8.3-12-gabc", "timestamp": "2026-01-02T03:04:05Z"}}}`,
+		`{"node": {"oid": "node-3", "nodeIdentifier": "node-c", "operationalState": "up", "build": {"version": "4.8.3"}}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/nodes", node); err != nil {
+			t.Fatalf("datasource_midpoint_system_status_test.go: Failed to create a node: %s", err)
+		}
+	}
+
+	for minimum, meets := range map[string]bool{"": true, "4": true, "4.8": true, "4.8.3": true, "4.8.4": false, "4.10": false, "5.0": false} {
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointSystemStatus().Schema, map[string]interface{}{"minimum_version": minimum})
+		if err := dataSourceMidpointSystemStatusRead(d, client); err != nil {
+			t.Fatalf("datasource_midpoint_system_status_test.go: Read failed: %s", err)
+		}
+		if d.Get("meets_minimum_version") != meets {
+			t.Fatalf("datasource_midpoint_system_status_test.go: Expected meets_minimum_version %v for '%s'", meets, minimum)
+		}
+		if d.Get("version") != "4.8.3-SNAPSHOT" || d.Get("version_major") != 4 || d.Get("version_minor") != 8 || d.Get("version_patch") != 3 ||
+			d.Get("revision") != "v4.8.3-12-gabc" || d.Get("nodes_up") != 2 || d.Get("nodes.#") != 3 ||
+			d.Get("nodes.1.hostname") != "mp2" || d.Get("nodes.0.operational_state") != "down" || d.Get("nodes.0.version") != "4.7.1" {
+			t.Fatalf("datasource_midpoint_system_status_test.go: Unexpected attributes %v", d.State())
+		}
+	}
+
+	empty, _ := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "node/oid", mode: clientModeMemory})
+	d := schema.TestResourceDataRaw(t, dataSourceMidpointSystemStatus().Schema, map[string]interface{}{})
+	if err := dataSourceMidpointSystemStatusRead(d, empty); err == nil {
+		t.Fatalf("datasource_midpoint_system_status_test.go: Expected a server without nodes to fail")
+	}
+}
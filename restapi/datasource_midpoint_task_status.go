@@ -0,0 +1,164 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* Result statuses of a task that mean its last run failed */
+var failedTaskResults = []string{"fatal_error", "partial_error"}
+
+func dataSourceMidpointTaskStatus() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointTaskStatusRead,
+		Description: "Reads the execution state, result, progress and run timestamps of a midPoint task, so that `check` blocks can assert that tasks such as reconciliations are healthy.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/tasks`. The API path of the tasks on the midPoint server.",
+				Optional:    true,
+				Default:     "/tasks",
+			},
+			"oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the task.",
+				Required:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the task.",
+				Computed:    true,
+			},
+			"execution_state": {
+				Type:        schema.TypeString,
+				Description: "The execution state of the task, such as `running`, `runnable`, `suspended` or `closed`.",
+				Computed:    true,
+			},
+			"scheduling_state": {
+				Type:        schema.TypeString,
+				Description: "The scheduling state of the task, such as `ready`, `waiting`, `suspended` or `closed`. Empty before midPoint 4.4.",
+				Computed:    true,
+			},
+			"result_status": {
+				Type:        schema.TypeString,
+				Description: "The status of the result of the task, such as `success`, `in_progress` or `fatal_error`.",
+				Computed:    true,
+			},
+			"result_message": {
+				Type:        schema.TypeString,
+				Description: "The message of the result of the task, such as the error it failed with.",
+				Computed:    true,
+			},
+			"progress": {
+				Type:        schema.TypeInt,
+				Description: "The number of items the task processed.",
+				Computed:    true,
+			},
+			"expected_total": {
+				Type:        schema.TypeInt,
+				Description: "The number of items the task expects to process, or 0 when it is not known.",
+				Computed:    true,
+			},
+			"last_run_start_timestamp": {
+				Type:        schema.TypeString,
+				Description: "When the last run of the task started.",
+				Computed:    true,
+			},
+			"last_run_finish_timestamp": {
+				Type:        schema.TypeString,
+				Description: "When the last run of the task finished.",
+				Computed:    true,
+			},
+			"completion_timestamp": {
+				Type:        schema.TypeString,
+				Description: "When the task was closed, for tasks that are.",
+				Computed:    true,
+			},
+			"node": {
+				Type:        schema.TypeString,
+				Description: "The identifier of the node the task runs on, while it runs.",
+				Computed:    true,
+			},
+			"healthy": {
+				Type:        schema.TypeBool,
+				Description: "Whether the task is not suspended and its result is not `fatal_error` or `partial_error`.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* integerAt returns the number at key, which midPoint may send as a string */
+func integerAt(hash map[string]interface{}, key string) int {
+	switch value := hash[key].(type) {
+	case float64:
+		return int(value)
+	case json.Number:
+		number, _ := value.Int64()
+		return int(number)
+	case string:
+		number, _ := strconv.Atoi(value)
+		return number
+	}
+	return 0
+}
+
+/* taskResult returns the status and message of the result of a task */
+func taskResult(task map[string]interface{}) (string, string) {
+	result, _ := task["result"].(map[string]interface{})
+	status := scalarStringAt(task, "resultStatus")
+	if status == "" {
+		status = scalarStringAt(result, "status")
+	}
+	return status, scalarStringAt(result, "message")
+}
+
+/* taskHealthy reports whether a task is not suspended and its result is not an error */
+func taskHealthy(task map[string]interface{}) bool {
+	status, _ := taskResult(task)
+	return scalarStringAt(task, "executionState") != "suspended" && !containsString(failedTaskResults, status)
+}
+
+func dataSourceMidpointTaskStatusRead(d *schema.ResourceData, meta interface{}) error {
+	oid := d.Get("oid").(string)
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:  d.Get("path").(string),
+		id:    oid,
+		debug: d.Get("debug").(bool),
+	})
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+	if obj.id == "" {
+		return fmt.Errorf("no task with the oid '%s' at '%s'", oid, d.Get("path").(string))
+	}
+
+	task := midpointObjectOf(obj.apiData)
+	resultStatus, resultMessage := taskResult(task)
+
+	d.SetId(oid)
+	d.Set("name", scalarStringAt(task, "name"))
+	d.Set("execution_state", scalarStringAt(task, "executionState"))
+	d.Set("scheduling_state", scalarStringAt(task, "schedulingState"))
+	d.Set("result_status", resultStatus)
+	d.Set("result_message", resultMessage)
+	d.Set("progress", integerAt(task, "progress"))
+	d.Set("expected_total", integerAt(task, "expectedTotal"))
+	d.Set("last_run_start_timestamp", scalarStringAt(task, "lastRunStartTimestamp"))
+	d.Set("last_run_finish_timestamp", scalarStringAt(task, "lastRunFinishTimestamp"))
+	d.Set("completion_timestamp", scalarStringAt(task, "completionTimestamp"))
+	d.Set("node", scalarStringAt(task, "node"))
+	return d.Set("healthy", taskHealthy(task))
+}
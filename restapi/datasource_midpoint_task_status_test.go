@@ -0,0 +1,52 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceMidpointTaskStatus(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "task/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_task_status_test.go: Failed to create API client: %s", err)
+	}
+	for _, task := range []string{
+		`{"task": {"oid": "task-1", "name": "Reconcile LDAP", "executionState": "running", "schedulingState": "ready", "resultStatus": "in_progress",
+			"progress": 120, "expectedTotal": "500", "lastRunStartTimestamp": "2026-10-16T02:00:00.000Z", "lastRunFinishTimestamp": "2026-10-15T02:10:00.000Z", "node": "node-a"}}`,
+		`{"task": {"oid": "task-2", "name": "Import HR", "executionState": "suspended", "result": {"status": "fatal_error", "message": "Connection refused"}}}`,
+		`{"task": {"oid": "task-3", "name": "Cleanup", "executionState": "closed", "resultStatus": "partial_error", "completionTimestamp": "2026-10-01T00:00:00.000Z"}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/tasks", task); err != nil {
+			t.Fatalf("datasource_midpoint_task_status_test.go: Failed to create a task: %s", err)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceMidpointTaskStatus().Schema, map[string]interface{}{"oid": "task-1"})
+	if err := dataSourceMidpointTaskStatusRead(d, client); err != nil {
+		t.Fatalf("datasource_midpoint_task_status_test.go: Read failed: %s", err)
+	}
+	if d.Get("name") != "Reconcile LDAP" || d.Get("execution_state") != "running" || d.Get("scheduling_state") != "ready" || d.Get("result_status") != "in_progress" ||
+		d.Get("progress") != 120 || d.Get("expected_total") != 500 || d.Get("last_run_start_timestamp") != "2026-10-16T02:00:00.000Z" || d.Get("node") != "node-a" || d.Get("healthy") != true {
+		t.Fatalf("datasource_midpoint_task_status_test.go: Unexpected attributes %v", d.State())
+	}
+
+	for oid, status := range map[string]string{"task-2": "fatal_error", "task-3": "partial_error"} {
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointTaskStatus().Schema, map[string]interface{}{"oid": oid})
+		if err := dataSourceMidpointTaskStatusRead(d, client); err != nil {
+			t.Fatalf("datasource_midpoint_task_status_test.go: Read of '%s' failed: %s", oid, err)
+		}
+		if d.Get("result_status") != status || d.Get("healthy") != false {
+			t.Fatalf("datasource_midpoint_task_status_test.go: Unexpected attributes of '%s': %v", oid, d.State())
+		}
+	}
+	if d.Set("oid", "task-2"); dataSourceMidpointTaskStatusRead(d, client) != nil || d.Get("result_message") != "Connection refused" {
+		t.Fatalf("datasource_midpoint_task_status_test.go: Expected the result message, got %v", d.State())
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceMidpointTaskStatus().Schema, map[string]interface{}{"oid": "missing"})
+	if err := dataSourceMidpointTaskStatusRead(d, client); err == nil || !strings.Contains(err.Error(), "no task with the oid 'missing'") {
+		t.Fatalf("datasource_midpoint_task_status_test.go: Expected a missing task to fail, got %v", err)
+	}
+}
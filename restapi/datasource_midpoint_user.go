@@ -0,0 +1,239 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceMidpointUser() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceMidpointUserRead,
+		Description: "Finds a single midPoint user by name, email address or query filter and exposes its oid, activation, assignments and role memberships, so that users not managed by Terraform can be referenced.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/users`. The API path of the users on the midPoint server.",
+				Optional:    true,
+				Default:     "/users",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "The name (username) of the user to find.",
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "email", "filter"},
+			},
+			"email": {
+				Type:        schema.TypeString,
+				Description: "The email address of the user to find.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"filter": {
+				Type:         schema.TypeString,
+				Description:  "A midPoint query filter as JSON that matches exactly one user, such as `jsonencode({ equal = { path = \"employeeNumber\", value = \"4711\" } })`.",
+				Optional:     true,
+				ValidateFunc: validation.StringIsJSON,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the user.",
+				Computed:    true,
+			},
+			"given_name": {
+				Type:        schema.TypeString,
+				Description: "The given name of the user.",
+				Computed:    true,
+			},
+			"family_name": {
+				Type:        schema.TypeString,
+				Description: "The family name of the user.",
+				Computed:    true,
+			},
+			"administrative_status": {
+				Type:        schema.TypeString,
+				Description: "The administrative status of the user, or empty when midPoint computes it.",
+				Computed:    true,
+			},
+			"effective_status": {
+				Type:        schema.TypeString,
+				Description: "The effective status midPoint computed for the user, such as `enabled`.",
+				Computed:    true,
+			},
+			"assignment":      computedRefsSchema("The assignments of the user that have a target."),
+			"role_membership": computedRefsSchema("The roles, orgs and services the user is a member of, directly or through inducements (roleMembershipRef)."),
+			"object": {
+				Type:        schema.TypeString,
+				Description: "The whole user as JSON, to be read with `jsondecode`.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* computedRefsSchema is a computed list of the targets of assignments or references */
+func computedRefsSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: description,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"target_oid": {
+					Type:        schema.TypeString,
+					Description: "The oid of the target.",
+					Computed:    true,
+				},
+				"target_type": {
+					Type:        schema.TypeString,
+					Description: "The type of the target, such as `RoleType`.",
+					Computed:    true,
+				},
+				"relation": {
+					Type:        schema.TypeString,
+					Description: "The relation, or empty for the default relation.",
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
+
+/* flattenRefs returns the computedRefsSchema blocks of references such as roleMembershipRef */
+func flattenRefs(value interface{}) []interface{} {
+	values, _ := asValueList(value)
+	blocks := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if ref, ok := value.(map[string]interface{}); ok {
+			blocks = append(blocks, map[string]interface{}{
+				"target_oid":  stringAtKey(ref, "oid"),
+				"target_type": refQName(stringAtKey(ref, "type")),
+				"relation":    refQName(stringAtKey(ref, "relation")),
+			})
+		}
+	}
+	return blocks
+}
+
+/*
+findMidpointObject searches path for the single object matching filter, a
+
+	midPoint query filter, and returns it below its wrapper key. It fails
+	when no object or more than one matches.
+*/
+func findMidpointObject(client *APIClient, path string, filter map[string]interface{}, debug bool) (map[string]interface{}, error) {
+	obj, err := NewAPIObject(client, &apiObjectOpts{
+		path:       path,
+		searchPath: path + "/search",
+		debug:      debug,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	search, _ := json.Marshal(map[string]interface{}{"query": map[string]interface{}{"filter": filter}})
+	if debug {
+		log.Printf("datasource_midpoint_user.go: Searching '%s' for %s", obj.searchPath, string(search))
+	}
+	records, err := obj.listObjects("POST", "", "object/object", string(search))
+	if err != nil {
+		return nil, err
+	}
+	switch len(records) {
+	case 0:
+		return nil, fmt.Errorf("no object at '%s' matches %s", path, string(search))
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d objects at '%s' match %s; narrow the search down to one", len(records), path, string(search))
+	}
+
+	record, ok := records[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the object found at '%s' is not a map of key value pairs", path)
+	}
+	return midpointObjectOf(record), nil
+}
+
+/*
+lookupFilter returns the filter of the attributes that are set: an equal
+
+	clause for each of attributes (by the item path it equals), the clauses
+	in clauses and the filter attribute, combined with and.
+*/
+func lookupFilter(d *schema.ResourceData, attributes [][2]string, clauses map[string]interface{}) (map[string]interface{}, error) {
+	kinds := make(map[string][]interface{})
+	for _, attribute := range attributes {
+		if value := d.Get(attribute[0]).(string); value != "" {
+			kinds["equal"] = append(kinds["equal"], map[string]interface{}{"path": attribute[1], "value": value})
+		}
+	}
+	for kind, clause := range clauses {
+		kinds[kind] = append(kinds[kind], clause)
+	}
+	if filter := d.Get("filter").(string); filter != "" {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(filter), &parsed); err != nil {
+			return nil, fmt.Errorf("filter is not a JSON object: %v", err)
+		}
+		for kind, clause := range parsed {
+			kinds[kind] = append(kinds[kind], clause)
+		}
+	}
+
+	count := 0
+	combined := make(map[string]interface{}, len(kinds))
+	for kind, values := range kinds {
+		count += len(values)
+		combined[kind] = values
+		if len(values) == 1 {
+			combined[kind] = values[0]
+		}
+	}
+	switch count {
+	case 0:
+		return nil, fmt.Errorf("no attribute to search by is set")
+	case 1:
+		return combined, nil
+	}
+	return map[string]interface{}{"and": combined}, nil
+}
+
+func dataSourceMidpointUserRead(d *schema.ResourceData, meta interface{}) error {
+	filter, err := lookupFilter(d, [][2]string{{"name", "name"}, {"email", "emailAddress"}}, nil)
+	if err != nil {
+		return err
+	}
+	user, err := findMidpointObject(meta.(*APIClient), d.Get("path").(string), filter, d.Get("debug").(bool))
+	if err != nil {
+		return err
+	}
+
+	oid := stringAtKey(user, "oid")
+	if oid == "" {
+		return fmt.Errorf("the user found has no oid")
+	}
+	d.SetId(oid)
+	d.Set("oid", oid)
+	d.Set("name", scalarStringAt(user, "name"))
+	d.Set("email", scalarStringAt(user, "emailAddress"))
+	d.Set("given_name", scalarStringAt(user, "givenName"))
+	d.Set("family_name", scalarStringAt(user, "familyName"))
+	activation, _ := user["activation"].(map[string]interface{})
+	d.Set("administrative_status", scalarStringAt(activation, "administrativeStatus"))
+	d.Set("effective_status", scalarStringAt(activation, "effectiveStatus"))
+	d.Set("assignment", flattenTargetRefs(user["assignment"], nil))
+	d.Set("role_membership", flattenRefs(user["roleMembershipRef"]))
+
+	b, _ := json.Marshal(user)
+	return d.Set("object", string(b))
+}
@@ -0,0 +1,60 @@
+package restapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceMidpointUser(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("datasource_midpoint_user_test.go: Failed to create API client: %s", err)
+	}
+	for _, user := range []string{
+		`{"user": {"oid": "user-1", "name": {"orig": "jsmith", "norm": "jsmith"}, "emailAddress": "jsmith@example.com", "employeeNumber": "4711",
+			"activation": {"effectiveStatus": "enabled"},
+			"assignment": [{"@id": 1, "targetRef": {"oid": "role-1", "type": "c:RoleType"}}, {"@id": 2, "policyRule": {"name": "sod"}}],
+			"roleMembershipRef": [{"oid": "role-1", "type": "c:RoleType", "relation": "org:default"}, {"oid": "org-1", "type": "c:OrgType", "relation": "org:manager"}]}}`,
+		`{"user": {"oid": "user-2", "name": "jdoe", "emailAddress": "shared@example.com"}}`,
+		`{"user": {"oid": "user-3", "name": "rroe", "emailAddress": "shared@example.com"}}`,
+	} {
+		if _, err := client.sendRequest("POST", "/users", user); err != nil {
+			t.Fatalf("datasource_midpoint_user_test.go: Failed to create a user: %s", err)
+		}
+	}
+
+	for _, config := range []map[string]interface{}{
+		{"name": "jsmith"},
+		{"email": "jsmith@example.com"},
+		{"filter": `{"equal": {"path": "employeeNumber", "value": "4711"}}`},
+	} {
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointUser().Schema, config)
+		if err := dataSourceMidpointUserRead(d, client); err != nil {
+			t.Fatalf("datasource_midpoint_user_test.go: Read of %v failed: %s", config, err)
+		}
+		if d.Id() != "user-1" || d.Get("name") != "jsmith" || d.Get("email") != "jsmith@example.com" || d.Get("effective_status") != "enabled" {
+			t.Fatalf("datasource_midpoint_user_test.go: Unexpected user found by %v: %v", config, d.State())
+		}
+		expectedMemberships := []interface{}{
+			map[string]interface{}{"target_oid": "role-1", "target_type": "RoleType", "relation": ""},
+			map[string]interface{}{"target_oid": "org-1", "target_type": "OrgType", "relation": "manager"},
+		}
+		if !reflect.DeepEqual(d.Get("role_membership"), expectedMemberships) || d.Get("assignment.#") != 1 {
+			t.Fatalf("datasource_midpoint_user_test.go: Unexpected references %v and %v", d.Get("role_membership"), d.Get("assignment"))
+		}
+	}
+
+	for config, expected := range map[string]string{"nobody": "no object at '/users' matches", "shared@example.com": "2 objects at '/users' match"} {
+		attribute := "name"
+		if strings.Contains(config, "@") {
+			attribute = "email"
+		}
+		d := schema.TestResourceDataRaw(t, dataSourceMidpointUser().Schema, map[string]interface{}{attribute: config})
+		if err := dataSourceMidpointUserRead(d, client); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("datasource_midpoint_user_test.go: Expected '%s' for %s, got %v", expected, config, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceRestAPIReconcile() *schema.Resource {
+	return &schema.Resource{
+		Read:        dataSourceRestAPIReconcileRead,
+		Description: "Compares the desired state of a set of objects with the API server and immediately sends corrective updates for any drift found. Because this happens whenever the data source is read (including during `terraform plan`), it can be used for scheduled drift-repair jobs that do not require a full `terraform apply`. Set `dry_run` to only report drift.",
+
+		Schema: map[string]*schema.Schema{
+			"object": {
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: "An object to reconcile.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:        schema.TypeString,
+							Description: "The API path on top of the base URL set in the provider that represents objects of this type on the API server.",
+							Required:    true,
+						},
+						"object_id": {
+							Type:        schema.TypeString,
+							Description: "The id of the object on the API server.",
+							Required:    true,
+						},
+						"data": {
+							Type:        schema.TypeString,
+							Description: "Valid JSON object with the desired state of the object.",
+							Required:    true,
+						},
+						"read_path": {
+							Type:        schema.TypeString,
+							Description: "Defaults to `path/{id}`. The API path that represents where to READ (GET) the object.",
+							Optional:    true,
+						},
+						"update_path": {
+							Type:        schema.TypeString,
+							Description: "Defaults to `path/{id}`. The API path that represents where to UPDATE the object.",
+							Optional:    true,
+						},
+						"patch_path": {
+							Type:        schema.TypeString,
+							Description: "Defaults to `update_path`. The API path that represents where to send the PATCH requests of the patch update strategies.",
+							Optional:    true,
+						},
+						"update_method": {
+							Type:        schema.TypeString,
+							Description: "Defaults to `update_method` set on the provider.",
+							Optional:    true,
+						},
+						"update_strategy": {
+							Type:         schema.TypeString,
+							Description:  "Defaults to `update_strategy` set on the provider (see `update_strategy` provider config documentation).",
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(updateStrategies, false),
+						},
+						"object_type": {
+							Type:        schema.TypeString,
+							Description: "The midPoint object type (such as `UserType`) used in the `delta_list_path` request. Defaults to the type of the collection at the end of `path`, such as `UserType` for `/users`.",
+							Optional:    true,
+						},
+						"ignore_changes_to": {
+							Type:        schema.TypeList,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Optional:    true,
+							Description: "A list of fields whose differences are not considered drift (see the `restapi_object` resource documentation).",
+						},
+					},
+				},
+			},
+			"dry_run": {
+				Type:        schema.TypeBool,
+				Description: "When true, drift is only reported in `drifted` and no update is sent. Default: false",
+				Optional:    true,
+				Default:     false,
+			},
+			"delta_list_path": {
+				Type:        schema.TypeString,
+				Description: "When set (for example to midPoint's `/rpc/executeChanges`), the drifted objects using the `midpoint-patch` update strategy are updated with a single POST of an `ObjectDeltaListType` to this path instead of one PATCH each, so related objects are changed together. The result of every object's delta decides whether it is `remediated` or `failed`; an object the response has no result for is `failed`. Defaults to `delta_list_path` set on the provider.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the API objects on the server.",
+				Optional:    true,
+			},
+			"drifted": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The `object_id` of every object whose server state differed from `data`.",
+				Computed:    true,
+			},
+			"remediated": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The `object_id` of every object that was updated to remove drift.",
+				Computed:    true,
+			},
+			"failed": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The `object_id` of every object whose delta failed in the `delta_list_path` request.",
+				Computed:    true,
+			},
+		}, /* End schema */
+
+	}
+}
+
+func dataSourceRestAPIReconcileRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*APIClient)
+	dryRun := d.Get("dry_run").(bool)
+	debug := d.Get("debug").(bool)
+	deltaListPath := d.Get("delta_list_path").(string)
+	if deltaListPath == "" {
+		deltaListPath = client.deltaListPath
+	}
+
+	drifted := make([]string, 0)
+	remediated := make([]string, 0)
+	failed := make([]string, 0)
+	batch := make([]objectDelta, 0)
+
+	for _, raw := range d.Get("object").([]interface{}) {
+		item := raw.(map[string]interface{})
+
+		ignoreList := append(expandStringList(item["ignore_changes_to"].([]interface{})), midpointModeIgnoreList(client.midpointMode)...)
+		opts := &apiObjectOpts{
+			path:           item["path"].(string),
+			id:             item["object_id"].(string),
+			getPath:        item["read_path"].(string),
+			putPath:        item["update_path"].(string),
+			patchPath:      item["patch_path"].(string),
+			updateMethod:   item["update_method"].(string),
+			updateStrategy: item["update_strategy"].(string),
+			data:           item["data"].(string),
+			debug:          debug,
+		}
+
+		obj, err := NewAPIObject(client, opts)
+		if err != nil {
+			return fmt.Errorf("failed to build object '%s': %v", opts.id, err)
+		}
+		obj.ignoreChangesTo = ignoreList
+
+		if err := obj.readObject(); err != nil {
+			return fmt.Errorf("failed to read object '%s': %v", opts.id, err)
+		}
+		if obj.id == "" {
+			return fmt.Errorf("object '%s' at '%s' does not exist on the API server and cannot be reconciled", opts.id, opts.path)
+		}
+
+		_, hasDifferences := getDelta(filterIgnoredFields(obj.data, ignoreList), obj.apiData, ignoreList, obj.normalize)
+		if !hasDifferences {
+			continue
+		}
+
+		log.Printf("datasource_reconcile.go: Found drift in object '%s' at '%s'", obj.id, opts.path)
+		drifted = append(drifted, obj.id)
+		if dryRun {
+			continue
+		}
+
+		if deltaListPath != "" && obj.updateStrategy == updateStrategyMidpointPatch {
+			objectType := item["object_type"].(string)
+			if objectType == "" {
+				if objectType, err = objectTypeForPath(opts.path); err != nil {
+					return fmt.Errorf("%v, set object_type", err)
+				}
+			}
+			batch = append(batch, objectDelta{obj: obj, objectType: objectType})
+			continue
+		}
+
+		if err := obj.updateObject(); err != nil {
+			return fmt.Errorf("failed to remediate drift in object '%s': %v", obj.id, err)
+		}
+		remediated = append(remediated, obj.id)
+	}
+
+	if len(batch) > 0 {
+		failures, err := sendObjectDeltaList(client, deltaListPath, batch)
+		if err != nil {
+			return fmt.Errorf("failed to remediate drift with '%s': %v", deltaListPath, err)
+		}
+		for _, delta := range batch {
+			if message, ok := failures[delta.obj.id]; ok {
+				log.Printf("datasource_reconcile.go: Failed to remediate drift in object '%s': %s", delta.obj.id, message)
+				failed = append(failed, delta.obj.id)
+			} else {
+				remediated = append(remediated, delta.obj.id)
+			}
+		}
+	}
+
+	if debug {
+		driftedJSON, _ := json.Marshal(drifted)
+		log.Printf("datasource_reconcile.go: Drifted objects: %s (dry_run=%t)", string(driftedJSON), dryRun)
+	}
+
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("drifted", drifted)
+	d.Set("remediated", remediated)
+	d.Set("failed", failed)
+	return nil
+}
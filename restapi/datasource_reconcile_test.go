@@ -0,0 +1,145 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Mastercard/terraform-provider-restapi/fakeserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceReconcile(t *testing.T) {
+	debug := false
+	apiServerObjects := map[string]map[string]interface{}{
+		"1": {"id": "1", "name": "in sync"},
+		"2": {"id": "2", "name": "drifted"},
+	}
+
+	svr := fakeserver.NewFakeServer(8088, apiServerObjects, true, debug, "")
+	defer svr.Shutdown()
+
+	client, err := NewAPIClient(&apiClientOpt{
+		uri:                "http://127.0.0.1:8088/",
+		timeout:            5,
+		writeReturnsObject: true,
+	})
+	if err != nil {
+		t.Fatalf("datasource_reconcile_test.go: Failed to create API client: %s", err)
+	}
+
+	config := func(dryRun bool) map[string]interface{} {
+		return map[string]interface{}{
+			"dry_run": dryRun,
+			"object": []interface{}{
+				map[string]interface{}{
+					"path":      "/api/objects",
+					"object_id": "1",
+					"data":      `{"id": "1", "name": "in sync"}`,
+				},
+				map[string]interface{}{
+					"path":      "/api/objects",
+					"object_id": "2",
+					"data":      `{"id": "2", "name": "desired"}`,
+				},
+			},
+		}
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceRestAPIReconcile().Schema, config(true))
+		if err := dataSourceRestAPIReconcileRead(d, client); err != nil {
+			t.Fatalf("datasource_reconcile_test.go: Reconcile failed: %s", err)
+		}
+
+		drifted := d.Get("drifted").([]interface{})
+		if len(drifted) != 1 || drifted[0] != "2" {
+			t.Fatalf("datasource_reconcile_test.go: Expected only object '2' to be drifted, got %v", drifted)
+		}
+		if len(d.Get("remediated").([]interface{})) != 0 {
+			t.Fatalf("datasource_reconcile_test.go: Expected nothing to be remediated in dry run")
+		}
+		if apiServerObjects["2"]["name"] != "drifted" {
+			t.Fatalf("datasource_reconcile_test.go: Object was modified during a dry run")
+		}
+	})
+
+	t.Run("remediate", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceRestAPIReconcile().Schema, config(false))
+		if err := dataSourceRestAPIReconcileRead(d, client); err != nil {
+			t.Fatalf("datasource_reconcile_test.go: Reconcile failed: %s", err)
+		}
+
+		remediated := d.Get("remediated").([]interface{})
+		if len(remediated) != 1 || remediated[0] != "2" {
+			t.Fatalf("datasource_reconcile_test.go: Expected object '2' to be remediated, got %v", remediated)
+		}
+		if apiServerObjects["2"]["name"] != "desired" {
+			t.Fatalf("datasource_reconcile_test.go: Expected drift to be corrected on the server, got '%v'", apiServerObjects["2"]["name"])
+		}
+	})
+}
+
+func TestDataSourceReconcileDeltaList(t *testing.T) {
+	var requests []string
+	var sent map[string]interface{}
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET":
+			id := strings.TrimPrefix(r.URL.Path, "/users/")
+			w.Write([]byte(fmt.Sprintf(`{"user": {"oid": "%s", "name": "drifted %s"}}`, id, id)))
+		case r.URL.Path == "/rpc/executeChanges":
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"objectDeltaOperationList": {"deltaOperation": [
+				{"objectDelta": {"oid": "1"}, "executionResult": {"status": "success"}},
+				{"objectDelta": {"oid": "2"}, "executionResult": {"status": "fatal_error", "message": "denied"}}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("datasource_reconcile_test.go: Failed to create API client: %s", err)
+	}
+
+	object := func(id string) map[string]interface{} {
+		return map[string]interface{}{
+			"path":            "/users",
+			"object_id":       id,
+			"update_strategy": updateStrategyMidpointPatch,
+			"data":            fmt.Sprintf(`{"user": {"oid": "%s", "name": "desired"}}`, id),
+		}
+	}
+	d := schema.TestResourceDataRaw(t, dataSourceRestAPIReconcile().Schema, map[string]interface{}{
+		"delta_list_path": "/rpc/executeChanges",
+		"object":          []interface{}{object("1"), object("2")},
+	})
+	if err := dataSourceRestAPIReconcileRead(d, client); err != nil {
+		t.Fatalf("datasource_reconcile_test.go: Reconcile failed: %s", err)
+	}
+
+	expected := []string{"GET /users/1", "GET /users/2", "POST /rpc/executeChanges"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("datasource_reconcile_test.go: Expected both objects to be updated in one request: expected %v, got %v", expected, requests)
+	}
+	deltas := sent["objectDeltaList"].(map[string]interface{})["delta"].([]interface{})
+	first := deltas[0].(map[string]interface{})
+	if len(deltas) != 2 || first["objectType"] != "UserType" || first["oid"] != "1" {
+		t.Fatalf("datasource_reconcile_test.go: Unexpected object delta list %v", sent)
+	}
+
+	if remediated := d.Get("remediated").([]interface{}); !reflect.DeepEqual(remediated, []interface{}{"1"}) {
+		t.Fatalf("datasource_reconcile_test.go: Expected object '1' to be remediated, got %v", remediated)
+	}
+	if failed := d.Get("failed").([]interface{}); !reflect.DeepEqual(failed, []interface{}{"2"}) {
+		t.Fatalf("datasource_reconcile_test.go: Expected object '2' to have failed, got %v", failed)
+	}
+}
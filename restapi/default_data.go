@@ -0,0 +1,108 @@
+package restapi
+
+import (
+	"reflect"
+)
+
+/*
+mergeDefaultData deep-merges the provider's default_data underneath data:
+
+	items data does not have are added and maps both have are merged, while
+	every value data sets wins. When data wraps its object in a single key
+	such as `role` that the defaults do not have, the defaults are merged
+	into the wrapped object.
+*/
+func mergeDefaultData(data map[string]interface{}, defaults map[string]interface{}) {
+	mergeDefaultMap(defaultDataTarget(data, defaults), defaults)
+}
+
+/* mergeDefaultMap merges defaults into the nested map target, keeping the values target has */
+func mergeDefaultMap(target map[string]interface{}, defaults map[string]interface{}) {
+	for key, value := range defaults {
+		current, ok := target[key]
+		if !ok {
+			target[key] = copyJSONValue(value)
+			continue
+		}
+		currentMap, currentIsMap := current.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if currentIsMap && valueIsMap {
+			mergeDefaultMap(currentMap, valueMap)
+		}
+	}
+}
+
+/*
+withoutDefaultData returns stored without the items that only come from
+
+	defaults: those recorded does not set and whose value on the server still
+	equals the default. This keeps the data in state comparable with the
+	configuration, while a default changed on the server still shows up.
+*/
+func withoutDefaultData(stored map[string]interface{}, recorded map[string]interface{}, defaults map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return stored
+	}
+	result := copyMap(stored)
+	target := defaultDataTarget(result, defaults)
+	source := defaultDataTarget(recorded, defaults)
+	removeDefaultData(target, source, defaults)
+	return result
+}
+
+func removeDefaultData(target map[string]interface{}, recorded map[string]interface{}, defaults map[string]interface{}) {
+	for key, value := range defaults {
+		current, ok := target[key]
+		if !ok {
+			continue
+		}
+		recordedValue, isRecorded := recorded[key]
+		if !isRecorded {
+			if reflect.DeepEqual(current, value) {
+				delete(target, key)
+			}
+			continue
+		}
+		currentMap, currentIsMap := current.(map[string]interface{})
+		recordedMap, recordedIsMap := recordedValue.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if currentIsMap && recordedIsMap && valueIsMap {
+			removeDefaultData(currentMap, recordedMap, valueMap)
+		}
+	}
+}
+
+/* defaultDataTarget returns the map of data the defaults apply to */
+func defaultDataTarget(data map[string]interface{}, defaults map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return map[string]interface{}{}
+	}
+	object := midpointObjectOf(data)
+	if len(data) == 1 {
+		for wrapper := range data {
+			if _, ok := defaults[wrapper]; ok {
+				return data
+			}
+		}
+	}
+	return object
+}
+
+/* copyJSONValue returns a deep copy of a value decoded from JSON */
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, inner := range v {
+			copied[key] = copyJSONValue(inner)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, inner := range v {
+			copied[i] = copyJSONValue(inner)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package restapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDefaultData(t *testing.T) {
+	var defaults map[string]interface{}
+	json.Unmarshal([]byte(`{"subtype": "managed", "description": "Managed by Terraform", "tenantRef": {"oid": "tenant-1", "type": "OrgType"}}`), &defaults)
+
+	data := map[string]interface{}{"role": map[string]interface{}{"name": "Finance", "description": "Finance team", "tenantRef": map[string]interface{}{"oid": "tenant-2"}}}
+	mergeDefaultData(data, defaults)
+	var expected map[string]interface{}
+	json.Unmarshal([]byte(`{"role": {"name": "Finance", "description": "Finance team", "subtype": "managed", "tenantRef": {"oid": "tenant-2", "type": "OrgType"}}}`), &expected)
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("default_data_test.go: Expected the defaults underneath the wrapped object, got %v", data)
+	}
+
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", mode: clientModeMemory, idAttribute: "oid", defaultData: defaults})
+	if err != nil {
+		t.Fatalf("default_data_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path": "/roles",
+		"data": `{"oid": "role-1", "name": "Finance", "description": "Finance team"}`,
+	})
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("default_data_test.go: Create failed: %s", err)
+	}
+	body, err := client.sendRequest("GET", "/roles/role-1", "")
+	if err != nil {
+		t.Fatalf("default_data_test.go: Failed to read the object: %s", err)
+	}
+	json.Unmarshal([]byte(body), &expected)
+	if expected["subtype"] != "managed" || expected["description"] != "Finance team" {
+		t.Fatalf("default_data_test.go: Expected the defaults to be created with the object, got %s", body)
+	}
+
+	/* Defaults are not in state while the server has them */
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("default_data_test.go: Read failed: %s", err)
+	}
+	var stored map[string]interface{}
+	json.Unmarshal([]byte(d.Get("data").(string)), &stored)
+	if _, ok := stored["subtype"]; ok || stored["description"] != "Finance team" {
+		t.Fatalf("default_data_test.go: Expected only the configured items in state, got %v", stored)
+	}
+
+	/* A default changed on the server shows up */
+	if _, err := client.sendRequest("PUT", "/roles/role-1", `{"oid": "role-1", "name": "Finance", "description": "Finance team", "subtype": "unmanaged", "tenantRef": {"oid": "tenant-1", "type": "OrgType"}}`); err != nil {
+		t.Fatalf("default_data_test.go: Failed to change the object: %s", err)
+	}
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("default_data_test.go: Read failed: %s", err)
+	}
+	stored = nil
+	json.Unmarshal([]byte(d.Get("data").(string)), &stored)
+	if stored["subtype"] != "unmanaged" || stored["tenantRef"] != nil {
+		t.Fatalf("default_data_test.go: Expected the changed default in state, got %v", stored)
+	}
+}
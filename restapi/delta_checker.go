@@ -1,22 +1,29 @@
 package restapi
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 /*
  * Performs a deep comparison of two maps - the resource as recorded in state, and the resource as returned by the API.
- * Accepts a third argument that is a set of fields that are to be ignored when looking for differences.
+ * Accepts a third argument that is a set of fields that are to be ignored when looking for differences,
+ * and a fourth with the rules for values that are equal although represented differently (nil compares values as they are).
  *
- * Supports three ignore pattern types:
+ * Supports four ignore pattern types:
  * 1. Wildcard patterns (e.g., "*.metadata"): Match at any nesting level
  * 2. Simple keys without dots (e.g., "metadata"): Match only at root level
  * 3. Dotted paths (e.g., "resource.connectorRef.oid"): Match at specific path
+ * 4. Array elements (e.g., "assignment[*].metadata", "items[2].value", "linkRef[*]"): Match inside every element or the element at one index of a list
  *
  * Returns 1. the recordedResource overlaid with fields that have been modified in actualResource but not ignored, and 2. a bool true if there were any changes.
  */
-func getDelta(recordedResource map[string]interface{}, actualResource map[string]interface{}, ignoreList []string) (modifiedResource map[string]interface{}, hasChanges bool) {
+func getDelta(recordedResource map[string]interface{}, actualResource map[string]interface{}, ignoreList []string, rules *normalizeRules) (modifiedResource map[string]interface{}, hasChanges bool) {
 	modifiedResource = map[string]interface{}{}
 	hasChanges = false
 
@@ -41,18 +48,26 @@ func getDelta(recordedResource map[string]interface{}, actualResource map[string
 			if actualHasKey && valActual != nil {
 				hasChanges = true
 			}
+		} else if rules.sameProtectedString(valRecorded, valActual) {
+			// Protected strings the server only returns encrypted are compared by presence
+			modifiedResource[key] = valRecorded
 		} else if reflect.TypeOf(valRecorded).Kind() == reflect.Map {
 			// If valRecorded was a map, assert both values are maps
 			subMapA, okA := valRecorded.(map[string]interface{})
 			subMapB, okB := valActual.(map[string]interface{})
 			if !okA || !okB {
-				modifiedResource[key] = valActual
-				hasChanges = true
+				// A PolyString may be returned as the plain string it was written as
+				if rules.equal(valRecorded, valActual) {
+					modifiedResource[key] = valRecorded
+				} else {
+					modifiedResource[key] = valActual
+					hasChanges = true
+				}
 				continue
 			}
 			// Recursively compare
 			deeperIgnoreList := _descendIgnoreList(key, ignoreList)
-			if modifiedSubResource, hasChange := getDelta(subMapA, subMapB, deeperIgnoreList); hasChange {
+			if modifiedSubResource, hasChange := getDelta(subMapA, subMapB, deeperIgnoreList, rules); hasChange {
 				modifiedResource[key] = modifiedSubResource
 				hasChanges = true
 			} else {
@@ -106,22 +121,28 @@ func getDelta(recordedResource map[string]interface{}, actualResource map[string
 					elemRecorded := sliceRecorded[i]
 					elemActual := sliceActual[i]
 
+					// Elements ignored by index (e.g. "items[2]") are never compared
+					if ignoresListElement(i, deeperIgnoreList) || rules.sameProtectedString(elemRecorded, elemActual) {
+						modifiedSlice[i] = elemRecorded
+						continue
+					}
+
 					// If element is a map, recursively compare with descended ignore list
-					if reflect.TypeOf(elemRecorded).Kind() == reflect.Map {
+					if elemRecorded != nil && reflect.TypeOf(elemRecorded).Kind() == reflect.Map {
 						mapRecorded, okRecorded := elemRecorded.(map[string]interface{})
 						mapActual, okActual := elemActual.(map[string]interface{})
 
 						if okRecorded && okActual {
 							// Recursively compare maps within the array with descended ignore list
-							if modifiedElem, elemChanged := getDelta(mapRecorded, mapActual, deeperIgnoreList); elemChanged {
+							if modifiedElem, elemChanged := getDelta(mapRecorded, mapActual, _descendIgnoreListElement(i, deeperIgnoreList), rules); elemChanged {
 								modifiedSlice[i] = modifiedElem
 								sliceHasChanges = true
 							} else {
 								modifiedSlice[i] = elemRecorded
 							}
 						} else {
-							// Can't cast to maps, use DeepEqual
-							if !reflect.DeepEqual(elemRecorded, elemActual) {
+							// Can't cast to maps, compare the values
+							if !rules.equal(elemRecorded, elemActual) {
 								modifiedSlice[i] = elemActual
 								sliceHasChanges = true
 							} else {
@@ -129,8 +150,8 @@ func getDelta(recordedResource map[string]interface{}, actualResource map[string
 							}
 						}
 					} else {
-						// For non-map elements (strings, numbers, etc.), use DeepEqual
-						if !reflect.DeepEqual(elemRecorded, elemActual) {
+						// For non-map elements (strings, numbers, etc.), compare the normalized values
+						if !rules.equal(elemRecorded, elemActual) {
 							modifiedSlice[i] = elemActual
 							sliceHasChanges = true
 						} else {
@@ -146,7 +167,7 @@ func getDelta(recordedResource map[string]interface{}, actualResource map[string
 					modifiedResource[key] = valRecorded
 				}
 			}
-		} else if valRecorded != valActual {
+		} else if !rules.equal(valRecorded, valActual) {
 			modifiedResource[key] = valActual
 			hasChanges = true
 		} else {
@@ -181,29 +202,56 @@ func getDelta(recordedResource map[string]interface{}, actualResource map[string
  * Modifies an ignoreList to be relative to a descended path.
  * E.g. given descendPath = "bar", and the ignoreList [foo, bar.alpha, bar.bravo], this returns [alpha, bravo]
  *
- * Supports three pattern types:
+ * Supports four pattern types:
  * 1. Wildcard patterns (e.g., "*.metadata"): Propagated to all nested levels for recursive matching
  * 2. Simple keys without dots (e.g., "metadata"): Only match at root level, NOT propagated
  * 3. Dotted paths (e.g., "resource.connectorRef.oid"): Only match at specific paths
+ * 4. Array element paths: "bar[*].alpha" descends to "alpha" for every element of bar, while "bar[2].alpha"
+ *    descends to "[2].alpha", which _descendIgnoreListElement resolves for each element
+ * 5. Any depth paths (e.g., "**.approvalRef") and regular expressions (e.g., "re:/.*Timestamp$/"): Propagated to all nested levels
+ *    like wildcard patterns. "metadata.*" descends to "*", which matches every key below metadata
  */
 func _descendIgnoreList(descendPath string, ignoreList []string) []string {
 	newIgnoreList := make([]string, 0, len(ignoreList))
 
 	for _, ignorePath := range ignoreList {
-		// Wildcard patterns (*.field) are propagated recursively to all levels
-		if strings.HasPrefix(ignorePath, "*.") {
+		// Wildcard patterns (*.field) and regular expressions are propagated recursively to all levels
+		if strings.HasPrefix(ignorePath, "*.") || strings.HasPrefix(ignorePath, regexIgnorePrefix) {
 			newIgnoreList = append(newIgnoreList, ignorePath)
 			continue
 		}
 
+		// Any depth patterns (**.path) are propagated, and their path may also start at this level
+		if strings.HasPrefix(ignorePath, "**.") {
+			newIgnoreList = append(newIgnoreList, ignorePath)
+			newIgnoreList = append(newIgnoreList, _descendIgnoreList(descendPath, []string{ignorePath[3:]})...)
+			continue
+		}
+
 		pathComponents := strings.Split(ignorePath, ".")
 
 		// Simple keys without dots only match at root level - do NOT propagate
-		if len(pathComponents) == 1 {
+		if len(pathComponents) == 1 && !strings.Contains(ignorePath, "[") {
 			// Don't add to newIgnoreList - this key only matches at the current level
 			continue
 		}
 
+		// For array element paths, descend if the name before the selector matches
+		if name, selector, ok := splitListSelector(pathComponents[0]); ok {
+			if name != descendPath || len(pathComponents) == 1 && selector == "*" {
+				continue
+			}
+			rest := strings.Join(pathComponents[1:], ".")
+			if selector == "*" {
+				newIgnoreList = append(newIgnoreList, rest)
+			} else if rest == "" {
+				newIgnoreList = append(newIgnoreList, "["+selector+"]")
+			} else {
+				newIgnoreList = append(newIgnoreList, "["+selector+"]."+rest)
+			}
+			continue
+		}
+
 		// For dotted paths, descend if the first component matches
 		if pathComponents[0] == descendPath {
 			// If this ignorePath starts with the descendPath, remove the first component and keep the rest
@@ -215,6 +263,48 @@ func _descendIgnoreList(descendPath string, ignoreList []string) []string {
 	return newIgnoreList
 }
 
+/*
+ * _descendIgnoreListElement modifies an ignoreList that was descended into a list to be relative to the element at index.
+ * E.g. given index = 2, and the ignoreList [alpha, [2].bravo, [3].charlie], this returns [alpha, bravo]
+ */
+func _descendIgnoreListElement(index int, ignoreList []string) []string {
+	newIgnoreList := make([]string, 0, len(ignoreList))
+	element := "[" + strconv.Itoa(index) + "]"
+
+	for _, ignorePath := range ignoreList {
+		if !strings.HasPrefix(ignorePath, "[") {
+			newIgnoreList = append(newIgnoreList, ignorePath)
+		} else if strings.HasPrefix(ignorePath, element+".") {
+			newIgnoreList = append(newIgnoreList, strings.TrimPrefix(ignorePath, element+"."))
+		}
+	}
+
+	return newIgnoreList
+}
+
+/* ignoresListElement reports whether a descended ignoreList ignores the whole element at index (e.g. "items[2]") */
+func ignoresListElement(index int, ignoreList []string) bool {
+	return contains(ignoreList, "["+strconv.Itoa(index)+"]")
+}
+
+/*
+ * splitListSelector splits an array element path component such as "assignment[*]" or "items[2]"
+ * into its name and selector. ok is false when the component has no valid selector.
+ */
+func splitListSelector(component string) (name string, selector string, ok bool) {
+	open := strings.Index(component, "[")
+	if open <= 0 || !strings.HasSuffix(component, "]") {
+		return "", "", false
+	}
+	selector = component[open+1 : len(component)-1]
+	if selector != "*" {
+		if _, err := strconv.Atoi(selector); err != nil {
+			return "", "", false
+		}
+	}
+	return component[:open], selector, true
+}
+
 func contains(list []string, elem string) bool {
 	for _, a := range list {
 		if a == elem {
@@ -226,35 +316,70 @@ func contains(list []string, elem string) bool {
 
 /*
  * matchesIgnorePattern checks if a field name matches any pattern in the ignore list.
- * Supports two pattern types:
+ * Supports five pattern types:
  * 1. Exact match: "fieldname" matches only "fieldname"
- * 2. Wildcard match: "*.fieldname" matches "fieldname" at any level
+ * 2. Wildcard match: "*.fieldname" or "**.fieldname" matches "fieldname" at any level
+ * 3. All elements: "fieldname[*]" matches the list "fieldname", including elements added or removed by the server
+ * 4. All children: "*" matches every field (this is what "parent.*" descends to)
+ * 5. Regular expression: "re:/.*Timestamp$/" matches every field whose name matches the expression, at any level
  */
 func matchesIgnorePattern(fieldName string, ignoreList []string) bool {
 	for _, pattern := range ignoreList {
 		// Check for exact match
-		if pattern == fieldName {
+		if pattern == fieldName || pattern == fieldName+"[*]" || pattern == "*" {
 			return true
 		}
-		// Check for wildcard match (pattern starts with "*.")
-		if strings.HasPrefix(pattern, "*.") {
-			wildcardField := pattern[2:] // Remove "*." prefix
+		// Check for wildcard match (pattern starts with "*." or "**.")
+		if strings.HasPrefix(pattern, "*.") || strings.HasPrefix(pattern, "**.") {
+			wildcardField := strings.TrimLeft(pattern, "*")[1:] // Remove "*." or "**." prefix
 			if wildcardField == fieldName {
 				return true
 			}
 		}
+		if strings.HasPrefix(pattern, regexIgnorePrefix) {
+			if re, err := ignorePatternRegexp(pattern); err == nil && re.MatchString(fieldName) {
+				return true
+			}
+		}
 	}
 	return false
 }
 
+/* Prefix of ignore patterns that are regular expressions matched against field names */
+const regexIgnorePrefix = "re:"
+
+var ignoreRegexps sync.Map
+
+/*
+ * ignorePatternRegexp compiles the regular expression of a "re:" ignore pattern. The expression
+ * may be wrapped in slashes ("re:/.*Timestamp$/"). Compiled expressions are cached.
+ */
+func ignorePatternRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := ignoreRegexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	expr := strings.TrimPrefix(pattern, regexIgnorePrefix)
+	if len(expr) >= 2 && strings.HasPrefix(expr, "/") && strings.HasSuffix(expr, "/") {
+		expr = expr[1 : len(expr)-1]
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression in ignore pattern '%s': %v", pattern, err)
+	}
+	ignoreRegexps.Store(pattern, re)
+	return re, nil
+}
+
 /*
  * filterIgnoredFields recursively removes fields from a map that match patterns in the ignore list.
  * This is used to remove server-managed fields from input JSON before sending to the API.
  *
- * Supports three pattern types:
+ * Supports four pattern types:
  * 1. Wildcard patterns (e.g., "*.metadata"): Filtered recursively at all levels
  * 2. Simple keys without dots (e.g., "metadata"): Only filtered at root level
  * 3. Dotted paths (e.g., "resource.connectorRef.oid"): Only filtered at the specific path
+ * 4. Array element paths (e.g., "assignment[*].metadata", "items[2].value"): Filtered inside the matching list elements.
+ *    Whole elements ignored by index (e.g., "items[2]") are kept so the other elements do not move.
  */
 func filterIgnoredFields(data map[string]interface{}, ignoreList []string) map[string]interface{} {
 	if data == nil {
@@ -281,7 +406,7 @@ func filterIgnoredFields(data map[string]interface{}, ignoreList []string) map[s
 			for i, elem := range sliceValue {
 				if mapElem, ok := elem.(map[string]interface{}); ok {
 					// Recursively filter maps within the array using descended ignore list
-					filteredSlice[i] = filterIgnoredFields(mapElem, descendedIgnoreList)
+					filteredSlice[i] = filterIgnoredFields(mapElem, _descendIgnoreListElement(i, descendedIgnoreList))
 				} else {
 					// For non-map elements, keep them as-is
 					filteredSlice[i] = elem
@@ -296,3 +421,81 @@ func filterIgnoredFields(data map[string]interface{}, ignoreList []string) map[s
 
 	return result
 }
+
+/*
+ * changedKeys returns the sorted dotted paths (e.g. "activation.administrativeStatus", "assignment[1].targetRef")
+ * at which actualResource differs from recordedResource, following the same comparison and ignore rules as getDelta.
+ * A list whose length changed is reported as a whole.
+ */
+func changedKeys(recordedResource map[string]interface{}, actualResource map[string]interface{}, ignoreList []string, rules *normalizeRules) []string {
+	changed := appendChangedKeys(make([]string, 0), "", recordedResource, actualResource, ignoreList, rules)
+	sort.Strings(changed)
+	return changed
+}
+
+func appendChangedKeys(changed []string, prefix string, recorded map[string]interface{}, actual map[string]interface{}, ignoreList []string, rules *normalizeRules) []string {
+	for key, valRecorded := range recorded {
+		if matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		changed = appendChangedValue(changed, prefix+key, key, valRecorded, actual[key], ignoreList, rules)
+	}
+
+	for key := range actual {
+		if _, exists := recorded[key]; exists || matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		changed = append(changed, prefix+key)
+	}
+
+	return changed
+}
+
+func appendChangedValue(changed []string, path string, key string, recorded interface{}, actual interface{}, ignoreList []string, rules *normalizeRules) []string {
+	if rules.sameProtectedString(recorded, actual) {
+		return changed
+	}
+	if recorded == nil {
+		if actual != nil {
+			changed = append(changed, path)
+		}
+		return changed
+	}
+
+	switch recordedValue := recorded.(type) {
+	case map[string]interface{}:
+		actualMap, ok := actual.(map[string]interface{})
+		if !ok {
+			if rules.equal(recorded, actual) {
+				return changed
+			}
+			return append(changed, path)
+		}
+		return appendChangedKeys(changed, path+".", recordedValue, actualMap, _descendIgnoreList(key, ignoreList), rules)
+	case []interface{}:
+		actualSlice, ok := actual.([]interface{})
+		if !ok || len(recordedValue) != len(actualSlice) {
+			return append(changed, path)
+		}
+		deeperIgnoreList := _descendIgnoreList(key, ignoreList)
+		for i := range recordedValue {
+			if ignoresListElement(i, deeperIgnoreList) || rules.sameProtectedString(recordedValue[i], actualSlice[i]) {
+				continue
+			}
+			elemPath := path + "[" + strconv.Itoa(i) + "]"
+			recordedMap, okRecorded := recordedValue[i].(map[string]interface{})
+			actualMap, okActual := actualSlice[i].(map[string]interface{})
+			if okRecorded && okActual {
+				changed = appendChangedKeys(changed, elemPath+".", recordedMap, actualMap, _descendIgnoreListElement(i, deeperIgnoreList), rules)
+			} else if !rules.equal(recordedValue[i], actualSlice[i]) {
+				changed = append(changed, elemPath)
+			}
+		}
+		return changed
+	}
+
+	if !rules.equal(recorded, actual) {
+		changed = append(changed, path)
+	}
+	return changed
+}
@@ -329,6 +329,102 @@ var deltaTestCases = []deltaTestCase{
 		ignoreList:     []string{"*.id"},
 		resultHasDelta: false,
 	},
+
+	{
+		testCase:       "Array element field ignored in every element",
+		o1:             MapAny{"assignment": []interface{}{MapAny{"targetRef": "r1"}, MapAny{"targetRef": "r2"}}},
+		o2:             MapAny{"assignment": []interface{}{MapAny{"targetRef": "r1", "metadata": "m1"}, MapAny{"targetRef": "r2", "metadata": "m2"}}},
+		ignoreList:     []string{"assignment[*].metadata"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "Array element field ignored but other fields compared",
+		o1:             MapAny{"assignment": []interface{}{MapAny{"targetRef": "r1"}}},
+		o2:             MapAny{"assignment": []interface{}{MapAny{"targetRef": "r3", "metadata": "m1"}}},
+		ignoreList:     []string{"assignment[*].metadata"},
+		resultHasDelta: true,
+	},
+
+	{
+		testCase:       "All array elements ignored",
+		o1:             MapAny{"name": "a", "linkRef": []interface{}{"s1"}},
+		o2:             MapAny{"name": "a", "linkRef": []interface{}{"s1", "s2"}},
+		ignoreList:     []string{"linkRef[*]"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "Indexed array element field ignored",
+		o1:             MapAny{"items": []interface{}{MapAny{"value": "a"}, MapAny{"value": "b"}, MapAny{"value": "c"}}},
+		o2:             MapAny{"items": []interface{}{MapAny{"value": "a"}, MapAny{"value": "b"}, MapAny{"value": "changed"}}},
+		ignoreList:     []string{"items[2].value"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "Indexed array element field only ignored at its index",
+		o1:             MapAny{"items": []interface{}{MapAny{"value": "a"}, MapAny{"value": "b"}, MapAny{"value": "c"}}},
+		o2:             MapAny{"items": []interface{}{MapAny{"value": "changed"}, MapAny{"value": "b"}, MapAny{"value": "c"}}},
+		ignoreList:     []string{"items[2].value"},
+		resultHasDelta: true,
+	},
+
+	{
+		testCase:       "Whole indexed array element ignored",
+		o1:             MapAny{"items": []interface{}{"a", "b"}},
+		o2:             MapAny{"items": []interface{}{"a", "changed"}},
+		ignoreList:     []string{"items[1]"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "All children ignored",
+		o1:             MapAny{"name": "a", "metadata": MapAny{"createTimestamp": "1"}},
+		o2:             MapAny{"name": "a", "metadata": MapAny{"createTimestamp": "2", "modifyTimestamp": "3"}},
+		ignoreList:     []string{"metadata.*"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "All children ignored only below their parent",
+		o1:             MapAny{"name": "a", "metadata": MapAny{}},
+		o2:             MapAny{"name": "b", "metadata": MapAny{"createTimestamp": "2"}},
+		ignoreList:     []string{"metadata.*"},
+		resultHasDelta: true,
+	},
+
+	{
+		testCase:       "Any depth path",
+		o1:             MapAny{"case": MapAny{"workItem": []interface{}{MapAny{"approvalRef": MapAny{"oid": "1"}, "name": "w"}}}},
+		o2:             MapAny{"case": MapAny{"workItem": []interface{}{MapAny{"approvalRef": MapAny{"oid": "2"}, "name": "w"}}}},
+		ignoreList:     []string{"**.approvalRef"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "Any depth dotted path",
+		o1:             MapAny{"a": MapAny{"metadata": MapAny{"createTimestamp": "1", "creatorRef": "x"}}},
+		o2:             MapAny{"a": MapAny{"metadata": MapAny{"createTimestamp": "2", "creatorRef": "x"}}},
+		ignoreList:     []string{"**.metadata.createTimestamp"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "Regular expression at any level",
+		o1:             MapAny{"name": "a", "activation": MapAny{"enableTimestamp": "1"}, "createTimestamp": "1"},
+		o2:             MapAny{"name": "a", "activation": MapAny{"enableTimestamp": "2"}, "createTimestamp": "2"},
+		ignoreList:     []string{"re:/.*Timestamp$/"},
+		resultHasDelta: false,
+	},
+
+	{
+		testCase:       "Regular expression does not match other fields",
+		o1:             MapAny{"name": "a", "timestampFormat": "iso"},
+		o2:             MapAny{"name": "a", "timestampFormat": "unix"},
+		ignoreList:     []string{"re:/.*Timestamp$/"},
+		resultHasDelta: true,
+	},
 }
 
 /*
@@ -369,7 +465,7 @@ func generateTypeConversionTests() []deltaTestCase {
 func TestHasDelta(t *testing.T) {
 	// Run the main test cases
 	for _, testCase := range deltaTestCases {
-		_, result := getDelta(testCase.o1, testCase.o2, testCase.ignoreList)
+		_, result := getDelta(testCase.o1, testCase.o2, testCase.ignoreList, nil)
 		if result != testCase.resultHasDelta {
 			t.Errorf("delta_checker_test.go: Test Case [%s] wanted [%v] got [%v]", testCase.testCase, testCase.resultHasDelta, result)
 		}
@@ -377,7 +473,7 @@ func TestHasDelta(t *testing.T) {
 
 	// Test type changes
 	for _, testCase := range generateTypeConversionTests() {
-		_, result := getDelta(testCase.o1, testCase.o2, testCase.ignoreList)
+		_, result := getDelta(testCase.o1, testCase.o2, testCase.ignoreList, nil)
 		if result != testCase.resultHasDelta {
 			t.Errorf("delta_checker_test.go: TYPE CONVERSION Test Case [%d:%s] wanted [%v] got [%v]", testCase.testId, testCase.testCase, testCase.resultHasDelta, result)
 		}
@@ -418,8 +514,63 @@ func TestHasDeltaModifiedResource(t *testing.T) {
 
 	ignoreList := []string{"hairball", "hobbies.sleeping", "name"}
 
-	modified, _ := getDelta(recordedInput, actualInput, ignoreList)
+	modified, _ := getDelta(recordedInput, actualInput, ignoreList, nil)
 	if !reflect.DeepEqual(expectedOutput, modified) {
 		t.Errorf("delta_checker_test.go: Unexpected delta: expected %v but got %v", expectedOutput, modified)
 	}
 }
+
+func TestFilterIgnoredFieldsArrayElements(t *testing.T) {
+	data := MapAny{
+		"assignment": []interface{}{MapAny{"targetRef": "r1", "metadata": "m1"}, MapAny{"targetRef": "r2", "metadata": "m2"}},
+		"items":      []interface{}{MapAny{"value": "a", "id": 1}, MapAny{"value": "b", "id": 2}},
+		"linkRef":    []interface{}{"s1"},
+	}
+
+	filtered := filterIgnoredFields(data, []string{"assignment[*].metadata", "items[1].value", "linkRef[*]"})
+	expected := MapAny{
+		"assignment": []interface{}{MapAny{"targetRef": "r1"}, MapAny{"targetRef": "r2"}},
+		"items":      []interface{}{MapAny{"value": "a", "id": 1}, MapAny{"id": 2}},
+	}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Fatalf("delta_checker_test.go: Expected %v, got %v", expected, filtered)
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	recorded := MapAny{
+		"name":       "jsmith",
+		"activation": MapAny{"administrativeStatus": "enabled", "validFrom": "2020"},
+		"assignment": []interface{}{MapAny{"targetRef": "r1", "metadata": "m1"}, MapAny{"targetRef": "r2"}},
+		"linkRef":    []interface{}{"s1"},
+		"gone":       "x",
+	}
+	actual := MapAny{
+		"name":       "jsmith",
+		"activation": MapAny{"administrativeStatus": "disabled", "validFrom": "2021"},
+		"assignment": []interface{}{MapAny{"targetRef": "r1", "metadata": "m2"}, MapAny{"targetRef": "r3"}},
+		"linkRef":    []interface{}{"s1", "s2"},
+		"extra":      "y",
+	}
+
+	changed := changedKeys(recorded, actual, []string{"activation.validFrom", "assignment[*].metadata"}, nil)
+	expected := []string{"activation.administrativeStatus", "assignment[1].targetRef", "extra", "gone", "linkRef"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("delta_checker_test.go: Expected changed keys %v, got %v", expected, changed)
+	}
+
+	if changed := changedKeys(recorded, recorded, nil, nil); len(changed) != 0 {
+		t.Fatalf("delta_checker_test.go: Expected no changed keys, got %v", changed)
+	}
+}
+
+func TestValidateIgnorePattern(t *testing.T) {
+	for _, pattern := range []string{"metadata", "metadata.*", "**.approvalRef", "re:/.*Timestamp$/", "re:^iteration"} {
+		if _, errs := validateIgnorePattern(pattern, "ignore_changes_to"); len(errs) > 0 {
+			t.Errorf("delta_checker_test.go: Expected '%s' to be valid, got %v", pattern, errs)
+		}
+	}
+	if _, errs := validateIgnorePattern("re:/(unclosed/", "ignore_changes_to"); len(errs) == 0 {
+		t.Errorf("delta_checker_test.go: Expected an invalid regular expression to be rejected")
+	}
+}
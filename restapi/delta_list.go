@@ -0,0 +1,149 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+/* midPoint object types by the REST collection objects of that type are found at */
+var midpointObjectTypes = map[string]string{
+	"users":                "UserType",
+	"roles":                "RoleType",
+	"orgs":                 "OrgType",
+	"services":             "ServiceType",
+	"archetypes":           "ArchetypeType",
+	"resources":            "ResourceType",
+	"shadows":              "ShadowType",
+	"tasks":                "TaskType",
+	"objectTemplates":      "ObjectTemplateType",
+	"securityPolicies":     "SecurityPolicyType",
+	"systemConfigurations": "SystemConfigurationType",
+	"valuePolicies":        "ValuePolicyType",
+	"policies":             "PolicyType",
+}
+
+/* objectTypeForPath returns the midPoint object type of the collection at path, such as UserType for /ws/rest/users */
+func objectTypeForPath(path string) (string, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if objectType, ok := midpointObjectTypes[segments[len(segments)-1]]; ok {
+		return objectType, nil
+	}
+	return "", fmt.Errorf("cannot derive the midPoint object type of '%s'", path)
+}
+
+/*
+objectDelta is one object of an ObjectDeltaListType request, along with the
+
+	type midPoint needs to apply it.
+*/
+type objectDelta struct {
+	obj        *APIObject
+	objectType string
+}
+
+/*
+buildObjectDeltaList returns midPoint's ObjectDeltaListType carrying a modify
+
+	delta with the itemDeltas of every object, along with the itemDeltas by
+	the id of the objects that have any.
+*/
+func buildObjectDeltaList(deltas []objectDelta) (map[string]interface{}, map[string][]interface{}) {
+	list := make([]interface{}, 0, len(deltas))
+	sent := make(map[string][]interface{})
+	for _, delta := range deltas {
+		itemDeltas := make([]interface{}, 0)
+		for _, itemDelta := range delta.obj.midpointDeltas() {
+			itemDeltas = append(itemDeltas, delta.obj.buildItemDelta(itemDelta.modificationType, itemDelta.path, itemDelta.value))
+		}
+		if len(itemDeltas) == 0 {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"changeType": "modify",
+			"objectType": delta.objectType,
+			"oid":        delta.obj.id,
+			"itemDelta":  itemDeltas,
+		})
+		sent[delta.obj.id] = itemDeltas
+	}
+	return map[string]interface{}{"objectDeltaList": map[string]interface{}{"delta": list}}, sent
+}
+
+/*
+sendObjectDeltaList applies all deltas in a single request to path (such as
+
+	midPoint's /rpc/executeChanges) and returns the execution error of every
+	object whose delta failed, by id. An object the response has no result
+	for failed too, as nothing tells it was applied. The applied deltas of
+	the other objects are recorded with them. No request is sent when no
+	object changed.
+*/
+func sendObjectDeltaList(client *APIClient, path string, deltas []objectDelta) (map[string]string, error) {
+	payload, sent := buildObjectDeltaList(deltas)
+	failed := make(map[string]string)
+	if len(sent) == 0 {
+		return failed, nil
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the object delta list to JSON: %v", err)
+	}
+
+	body, err := client.sendRequest("POST", path, string(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]interface{}
+	if err := unmarshalJSON([]byte(body), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse the object delta list response: %v", err)
+	}
+
+	statuses := make(map[string]string)
+	if results, err := GetObjectAtKey(response, "objectDeltaOperationList/deltaOperation", false); err == nil {
+		list, ok := results.([]interface{})
+		if !ok {
+			/* A single result may not be wrapped in a list */
+			list = []interface{}{results}
+		}
+		for _, raw := range list {
+			result, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			oid, _ := GetStringAtKey(result, "objectDelta/oid", false)
+			if oid == "" {
+				continue
+			}
+			status, _ := GetStringAtKey(result, "executionResult/status", false)
+			switch status {
+			case "success", "warning", "handled_error":
+				statuses[oid] = ""
+			default:
+				message, _ := GetStringAtKey(result, "executionResult/message", false)
+				statuses[oid] = fmt.Sprintf("%s: %s", status, message)
+			}
+		}
+	}
+
+	for _, delta := range deltas {
+		itemDeltas, ok := sent[delta.obj.id]
+		if !ok {
+			continue
+		}
+		message, ok := statuses[delta.obj.id]
+		switch {
+		case !ok:
+			log.Printf("delta_list.go: No result for '%s' in the object delta list response", delta.obj.id)
+			failed[delta.obj.id] = "no result in the response"
+		case message != "":
+			failed[delta.obj.id] = message
+		default:
+			delta.obj.appliedDeltas, _ = marshalModifications(itemDeltas)
+		}
+	}
+	return failed, nil
+}
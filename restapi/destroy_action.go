@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+/* What a destroy does with the object */
+const (
+	destroyActionDelete  = "delete"
+	destroyActionArchive = "archive"
+	destroyActionDisable = "disable"
+	destroyActionAbandon = "abandon"
+)
+
+var destroyActions = []string{destroyActionDelete, destroyActionArchive, destroyActionDisable, destroyActionAbandon}
+
+/* How often a deleted object is read while waiting for it to be gone; tests shorten it */
+var destroyPollInterval = 2 * time.Second
+
+/*
+retireObject destroys the object without deleting it, for the archive and
+
+	disable destroy actions: it replaces the lifecycleState or the
+	administrative status of the object with a modification.
+*/
+func (obj *APIObject) retireObject(action string, lifecycleState string) error {
+	if obj.id == "" {
+		log.Printf("WARNING: Attempting to %s an object that has no id set. Assuming this is OK.\n", action)
+		return nil
+	}
+
+	var itemDelta map[string]interface{}
+	switch action {
+	case destroyActionArchive:
+		itemDelta = obj.buildItemDelta("replace", "lifecycleState", lifecycleState)
+	case destroyActionDisable:
+		itemDelta = obj.buildItemDelta("replace", "activation/administrativeStatus", "disabled")
+	default:
+		return fmt.Errorf("the destroy action '%s' does not modify the object", action)
+	}
+	if obj.debug {
+		log.Printf("destroy_action.go: Destroying '%s' with the %s action instead of deleting it", obj.id, action)
+	}
+	return obj.sendObjectModification([]interface{}{itemDelta})
+}
+
+/*
+waitUntilDeleted reads the object until the server no longer returns it, for
+
+	verify_destroy: midPoint may process a delete asynchronously, and creating
+	an object with the same name fails until it is gone.
+*/
+func (obj *APIObject) waitUntilDeleted(timeout time.Duration) error {
+	id := obj.id
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := obj.readObject(); err != nil {
+			return fmt.Errorf("failed to verify that '%s' was deleted: %v", id, err)
+		}
+		if obj.id == "" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("'%s' was deleted, but the server still returned it after %s", id, timeout)
+		}
+		if obj.debug {
+			log.Printf("destroy_action.go: Waiting for '%s' to be gone after its delete", id)
+		}
+		time.Sleep(destroyPollInterval)
+	}
+}
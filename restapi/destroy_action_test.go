@@ -0,0 +1,127 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDestroyAction(t *testing.T) {
+	var requests []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid", destroyMethod: "DELETE"})
+	if err != nil {
+		t.Fatalf("destroy_action_test.go: Failed to create API client: %s", err)
+	}
+
+	for action, expected := range map[string]string{
+		destroyActionDelete:  "DELETE /users/user-1 ",
+		destroyActionArchive: `PATCH /users/user-1 {"objectModification":{"itemDelta":[{"modificationType":"replace","path":"lifecycleState","value":"retired"}]}}`,
+		destroyActionDisable: `PATCH /users/user-1 {"objectModification":{"itemDelta":[{"modificationType":"replace","path":"activation/administrativeStatus","value":"disabled"}]}}`,
+		destroyActionAbandon: "",
+	} {
+		requests = nil
+		d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":                    "/users",
+			"data":                    `{"oid": "user-1", "name": "jsmith"}`,
+			"destroy_action":          action,
+			"archive_lifecycle_state": "retired",
+		})
+		d.SetId("user-1")
+		if err := resourceRestAPIDelete(d, client); err != nil {
+			t.Fatalf("destroy_action_test.go: Destroy with '%s' failed: %s", action, err)
+		}
+		if strings.Join(requests, "\n") != expected {
+			t.Fatalf("destroy_action_test.go: Expected '%s' to send '%s', got %v", action, expected, requests)
+		}
+	}
+}
+
+func TestVerifyDestroy(t *testing.T) {
+	destroyPollInterval = 10 * time.Millisecond
+	reads := 0
+	goneAfter := 3
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		/* The delete is processed in the background */
+		reads++
+		if reads >= goneAfter {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"oid": "user-1", "name": "jsmith"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid", destroyMethod: "DELETE"})
+	if err != nil {
+		t.Fatalf("destroy_action_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func() *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":            "/users",
+			"data":            `{"oid": "user-1", "name": "jsmith"}`,
+			"verify_destroy":  true,
+			"destroy_timeout": 1,
+		})
+		d.SetId("user-1")
+		return d
+	}
+
+	if err := resourceRestAPIDelete(newResourceData(), client); err != nil || reads != goneAfter {
+		t.Fatalf("destroy_action_test.go: Expected the destroy to wait for the object to be gone, got %v after %d reads", err, reads)
+	}
+
+	reads = 0
+	goneAfter = 1000
+	if err := resourceRestAPIDelete(newResourceData(), client); err == nil || !strings.Contains(err.Error(), "still returned it after 1s") {
+		t.Fatalf("destroy_action_test.go: Expected the destroy to time out, got %v", err)
+	}
+}
+
+func TestPreventDestroyRemote(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", mode: clientModeMemory, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("destroy_action_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(action string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":                   "/securityPolicies",
+			"data":                   `{"oid": "policy-1", "name": "Default Security Policy"}`,
+			"prevent_destroy_remote": true,
+			"destroy_action":         action,
+		})
+	}
+
+	d := newResourceData(destroyActionDelete)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("destroy_action_test.go: Create failed: %s", err)
+	}
+	if err := resourceRestAPIDelete(d, client); err == nil || !strings.Contains(err.Error(), "protected by prevent_destroy_remote") {
+		t.Fatalf("destroy_action_test.go: Expected the destroy to be prevented, got %v", err)
+	}
+	if err := resourceRestAPIRead(d, client); err != nil || d.Id() != "policy-1" {
+		t.Fatalf("destroy_action_test.go: Expected the object to still exist, got %v and '%s'", err, d.Id())
+	}
+
+	/* Abandoning leaves the object on the server, so it is allowed */
+	d = newResourceData(destroyActionAbandon)
+	d.SetId("policy-1")
+	if err := resourceRestAPIDelete(d, client); err != nil {
+		t.Fatalf("destroy_action_test.go: Expected abandoning to be allowed, got %v", err)
+	}
+}
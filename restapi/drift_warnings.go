@@ -0,0 +1,89 @@
+package restapi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+/* The number of drifted paths listed in a warning; the rest are only counted */
+const driftWarningMaxPaths = 20
+
+/* Matches the list indexes at the end of a segment of a changed key, such as assignment[1] */
+var changedKeyIndex = regexp.MustCompile(`\[(\d+)\]`)
+
+/*
+driftWarning describes the paths that drifted on the server with the value
+
+	in state and the one on the server, so a plan tells precisely what
+	changed instead of only showing the whole of data differing. Values of
+	sensitive items and protected strings are redacted, and all values are
+	when API_DATA_IS_SENSITIVE is set.
+*/
+func driftWarning(id string, recorded map[string]interface{}, actual map[string]interface{}, paths []string) diag.Diagnostic {
+	sensitive, _ := strconv.ParseBool(GetEnvOrDefault("API_DATA_IS_SENSITIVE", "false"))
+
+	lines := make([]string, 0, len(paths))
+	for i, path := range paths {
+		if i == driftWarningMaxPaths {
+			lines = append(lines, fmt.Sprintf("  and %d more", len(paths)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s -> %s", path,
+			driftValue(path, valueAtChangedKey(recorded, path), sensitive),
+			driftValue(path, valueAtChangedKey(actual, path), sensitive)))
+	}
+
+	return diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("The object '%s' drifted on the server", id),
+		Detail:   fmt.Sprintf("These paths differ from state; the next apply reverts them unless they are added to ignore_changes_to:\n%s", strings.Join(lines, "\n")),
+	}
+}
+
+/* driftValue returns the text a drifted value is shown as */
+func driftValue(path string, value interface{}, sensitive bool) string {
+	if value == nil {
+		return "(absent)"
+	}
+	if sensitive {
+		return auditRedacted
+	}
+	redacted := redactAuditValue(path, value)
+	if s, ok := redacted.(string); ok && s == auditRedacted {
+		return s
+	}
+	encoded, err := canonicalJSON(redacted)
+	if err != nil {
+		return fmt.Sprintf("%v", redacted)
+	}
+	return encoded
+}
+
+/* valueAtChangedKey returns the value at a path of changedKeys, such as assignment[1].targetRef, or nil */
+func valueAtChangedKey(data map[string]interface{}, path string) interface{} {
+	var current interface{} = data
+	for _, segment := range strings.Split(path, ".") {
+		name := segment
+		if i := strings.Index(segment, "["); i > 0 {
+			name = segment[:i]
+		}
+		hash, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = hash[name]
+		for _, match := range changedKeyIndex.FindAllStringSubmatch(segment[len(name):], -1) {
+			index, _ := strconv.Atoi(match[1])
+			list, ok := current.([]interface{})
+			if !ok || index >= len(list) {
+				return nil
+			}
+			current = list[index]
+		}
+	}
+	return current
+}
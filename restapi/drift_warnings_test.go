@@ -0,0 +1,55 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDriftWarnings(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"oid": "user-1", "name": "jsmith", "description": "Changed", "assignment": [{"targetRef": {"oid": "r1"}}, {"targetRef": {"oid": "r3"}}], "credentials": {"password": {"value": "other"}}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("drift_warnings_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path": "/users",
+		"data": `{"oid": "user-1", "name": "jsmith", "description": "Accountant", "assignment": [{"targetRef": {"oid": "r1"}}, {"targetRef": {"oid": "r2"}}], "credentials": {"password": {"value": "secret"}}}`,
+	})
+	d.SetId("user-1")
+
+	diags := resourceRestAPIReadContext(context.Background(), d, client)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "The object 'user-1' drifted on the server" {
+		t.Fatalf("drift_warnings_test.go: Expected one drift warning, got %v", diags)
+	}
+	for _, line := range []string{
+		`  assignment[1].targetRef.oid: "r2" -> "r3"`,
+		`  credentials.password.value: (redacted) -> (redacted)`,
+		`  description: "Accountant" -> "Changed"`,
+	} {
+		if !strings.Contains(diags[0].Detail, line+"\n") && !strings.HasSuffix(diags[0].Detail, line) {
+			t.Fatalf("drift_warnings_test.go: Expected '%s' in the warning, got:\n%s", line, diags[0].Detail)
+		}
+	}
+	if strings.Contains(diags[0].Detail, "secret") || strings.Contains(diags[0].Detail, "other") {
+		t.Fatalf("drift_warnings_test.go: Expected the password to be redacted, got:\n%s", diags[0].Detail)
+	}
+
+	/* Nothing drifted after the refresh stored the server's object */
+	if diags := resourceRestAPIReadContext(context.Background(), d, client); len(diags) != 0 {
+		t.Fatalf("drift_warnings_test.go: Expected no warning without drift, got %v", diags)
+	}
+
+	if value := valueAtChangedKey(map[string]interface{}{"a": []interface{}{[]interface{}{"x", "y"}}}, "a[0][1]"); value != "y" {
+		t.Fatalf("drift_warnings_test.go: Expected the value of nested lists, got %v", value)
+	}
+}
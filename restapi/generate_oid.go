@@ -0,0 +1,50 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/*
+customizeDiffGeneratedOID generates the oid of a new object with generate_oid
+
+	at plan time, so the oid of objects created in the same apply can be
+	referenced without it being known after apply only.
+*/
+func customizeDiffGeneratedOID(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" || !d.Get("generate_oid").(bool) || d.Get("oid").(string) != "" {
+		return nil
+	}
+	oid, err := generateOID()
+	if err != nil {
+		return err
+	}
+	return d.SetNew("oid", oid)
+}
+
+/*
+useGeneratedOID makes the create of obj send the oid planned by generate_oid:
+
+	the oid is added to the object in data, and the object is created with a
+	PUT to its update path instead of a POST.
+*/
+func (obj *APIObject) useGeneratedOID(oid string) error {
+	if oid == "" {
+		var err error
+		if oid, err = generateOID(); err != nil {
+			return err
+		}
+	}
+	object := midpointObjectOf(obj.data)
+	if existing := stringAtKey(object, "oid"); existing != "" && existing != oid {
+		return fmt.Errorf("data sets the oid '%s', which generate_oid would replace with '%s'; remove one of them", existing, oid)
+	}
+	object["oid"] = oid
+
+	obj.id = oid
+	obj.createMethod = "PUT"
+	obj.postPath = obj.putPath
+	return nil
+}
@@ -0,0 +1,74 @@
+package restapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestGenerateOID(t *testing.T) {
+	/* The oid is planned for new objects only */
+	resource := resourceRestAPI()
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"user": {"name": "jsmith"}}`,
+		"generate_oid": true,
+	})
+	diff, err := resource.Diff(context.Background(), nil, config, nil)
+	if err != nil {
+		t.Fatalf("generate_oid_test.go: Failed to plan the object: %s", err)
+	}
+	planned := diff.Attributes["oid"]
+	if planned == nil || planned.NewComputed || len(planned.New) != 36 {
+		t.Fatalf("generate_oid_test.go: Expected the oid to be known at plan time, got %v", planned)
+	}
+
+	created := ""
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/users/"+planned.New:
+			created = string(body)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/users/"+planned.New && created != "":
+			w.Write([]byte(created))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid"})
+	if err != nil {
+		t.Fatalf("generate_oid_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"user": {"name": "jsmith"}}`,
+		"generate_oid": true,
+	})
+	d.Set("oid", planned.New)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("generate_oid_test.go: Create failed: %s", err)
+	}
+	if d.Id() != planned.New || d.Get("oid") != planned.New || !strings.Contains(created, `"oid":"`+planned.New+`"`) {
+		t.Fatalf("generate_oid_test.go: Expected the object to be created with the planned oid %s, got '%s' and %s", planned.New, d.Id(), created)
+	}
+
+	/* A different oid in data is not replaced */
+	d = schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"user": {"oid": "user-1", "name": "jsmith"}}`,
+		"generate_oid": true,
+	})
+	d.Set("oid", planned.New)
+	if err := resourceRestAPICreate(d, client); err == nil || !strings.Contains(err.Error(), "data sets the oid 'user-1'") {
+		t.Fatalf("generate_oid_test.go: Expected the create to fail with the oid in data, got %v", err)
+	}
+}
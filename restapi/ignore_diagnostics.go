@@ -0,0 +1,283 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* Kinds of ignore_changes_to diagnostics, the first word of every warning */
+const (
+	ignoreDiagnosticImpossible = "impossible pattern"
+	ignoreDiagnosticShape      = "never matches data"
+	ignoreDiagnosticOverlap    = "redundant pattern"
+)
+
+/*
+ignorePatternProblem returns why an ignore_changes_to entry can never
+
+	match any field, or "" when it can. Only the pattern itself is looked
+	at; ignoreListDiagnostics checks it against the data and the other entries.
+*/
+func ignorePatternProblem(pattern string) string {
+	if strings.HasPrefix(pattern, regexIgnorePrefix) {
+		return ""
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return "the pattern is empty"
+	}
+	if pattern == "**" {
+		return "'**' must be followed by a path, such as '**.approvalRef'; use '*' to ignore every field"
+	}
+	if strings.Contains(pattern, "/") {
+		return fmt.Sprintf("paths are separated by dots, not slashes; use '%s'", strings.ReplaceAll(strings.Trim(pattern, "/"), "/", "."))
+	}
+	if strings.HasPrefix(pattern, "*.") && strings.Contains(pattern[2:], ".") {
+		return fmt.Sprintf("'*.' matches a single field name at any level; use '**.%s' to match a path", pattern[2:])
+	}
+
+	components := strings.Split(strings.TrimPrefix(pattern, "**."), ".")
+	for i, component := range components {
+		switch {
+		case component == "":
+			return "the path has an empty component; check for a leading, trailing or doubled dot"
+		case component == "*" || component == "**" && i > 0:
+		case strings.Contains(component, "["):
+			if _, _, ok := splitListSelector(component); !ok {
+				return fmt.Sprintf("'%s' is not a list selector; use 'name[*]' for every element or 'name[2]' for one", component)
+			}
+			if strings.Contains(component[:strings.Index(component, "[")], "*") {
+				return fmt.Sprintf("'*' only matches whole field names, not part of '%s'; use a 're:' pattern", component)
+			}
+		case strings.Contains(component, "*"):
+			return fmt.Sprintf("'*' only matches whole field names, not part of '%s'; use a 're:' pattern", component)
+		}
+	}
+	return ""
+}
+
+/*
+validateIgnorePattern rejects ignore_changes_to entries whose regular expression
+
+	does not compile and warns about entries that can never match any field.
+*/
+func validateIgnorePattern(val interface{}, key string) (warns []string, errs []error) {
+	pattern := val.(string)
+	if strings.HasPrefix(pattern, regexIgnorePrefix) {
+		if _, err := ignorePatternRegexp(pattern); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if problem := ignorePatternProblem(pattern); problem != "" {
+		warns = append(warns, fmt.Sprintf("%s: ignore_changes_to entry '%s' never ignores anything: %s", key, pattern, problem))
+	}
+	return warns, errs
+}
+
+/*
+ignoreListDiagnostics returns a warning for every entry of ignoreList that
+
+	can never match a field: patterns that are impossible, paths that cross a
+	value of data which is not an object or list, and entries already covered
+	by another entry (so removing one of them changes nothing). Each warning
+	names the entry and its index.
+*/
+func ignoreListDiagnostics(ignoreList []string, data map[string]interface{}) []string {
+	warnings := make([]string, 0)
+	warn := func(i int, kind string, detail string) {
+		warnings = append(warnings, fmt.Sprintf("ignore_changes_to[%d] '%s': %s: %s", i, ignoreList[i], kind, detail))
+	}
+
+	/* Entries that never match are reported once and do not cover others */
+	problems := make([]string, len(ignoreList))
+	kinds := make([]string, len(ignoreList))
+	for i, pattern := range ignoreList {
+		if problems[i] = ignorePatternProblem(pattern); problems[i] != "" {
+			kinds[i] = ignoreDiagnosticImpossible
+		} else if data != nil && isConcreteIgnorePath(pattern) {
+			if problems[i] = ignorePathShapeProblem(strings.Split(pattern, "."), "", data); problems[i] != "" {
+				kinds[i] = ignoreDiagnosticShape
+			}
+		}
+	}
+
+	for i, pattern := range ignoreList {
+		if problems[i] != "" {
+			warn(i, kinds[i], problems[i])
+			continue
+		}
+		if !isConcreteIgnorePath(pattern) {
+			continue
+		}
+
+		if first := indexOf(ignoreList, pattern); first < i {
+			warn(i, ignoreDiagnosticOverlap, fmt.Sprintf("already listed as ignore_changes_to[%d]", first))
+			continue
+		}
+		for j, other := range ignoreList {
+			if other == pattern || problems[j] != "" {
+				continue
+			}
+			/* Of two entries that ignore the same, the later one is redundant */
+			covered := ignoresPath(strings.Split(pattern, "."), []string{other})
+			if covered && j > i && isConcreteIgnorePath(other) && ignoresPath(strings.Split(other, "."), []string{pattern}) {
+				continue
+			}
+			if covered {
+				warn(i, ignoreDiagnosticOverlap, fmt.Sprintf("already ignored by ignore_changes_to[%d] '%s'", j, other))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+/* isConcreteIgnorePath reports whether an ignore pattern names a single path without wildcards */
+func isConcreteIgnorePath(pattern string) bool {
+	if strings.HasPrefix(pattern, regexIgnorePrefix) {
+		return false
+	}
+	for _, component := range strings.Split(pattern, ".") {
+		if component == "*" || component == "**" || strings.HasPrefix(component, "*") {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ignoresPath reports whether ignoreList ignores the dotted path, or one of the
+
+	fields above it, following the rules filterIgnoredFields applies.
+*/
+func ignoresPath(components []string, ignoreList []string) bool {
+	for _, component := range components {
+		name, selector, isElement := splitListSelector(component)
+		if !isElement {
+			name = component
+		}
+		if matchesIgnorePattern(name, ignoreList) {
+			return true
+		}
+		ignoreList = _descendIgnoreList(name, ignoreList)
+		if isElement && selector != "*" {
+			index, _ := strconv.Atoi(selector)
+			if ignoresListElement(index, ignoreList) {
+				return true
+			}
+			ignoreList = _descendIgnoreListElement(index, ignoreList)
+		}
+		if len(ignoreList) == 0 {
+			return false
+		}
+	}
+	return false
+}
+
+/*
+ignorePathShapeProblem follows the path components through value and returns
+
+	why the path can never match, or "" when it can. Fields missing from the
+	data are fine, as the server may add them.
+*/
+func ignorePathShapeProblem(components []string, parent string, value interface{}) string {
+	if len(components) == 0 {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		/* A path without a list selector applies to every element */
+		for _, elem := range v {
+			if problem := ignorePathShapeProblem(components, parent, elem); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	case map[string]interface{}:
+		name, selector, isElement := splitListSelector(components[0])
+		if !isElement {
+			name = components[0]
+		}
+		field := strings.TrimPrefix(parent+"."+name, ".")
+		child, ok := v[name]
+		if !ok {
+			return ""
+		}
+		if isElement {
+			list, ok := child.([]interface{})
+			if !ok {
+				return fmt.Sprintf("'%s' is %s in data, not a list", field, describeJSONType(child))
+			}
+			if selector != "*" {
+				index, _ := strconv.Atoi(selector)
+				if index >= len(list) {
+					return ""
+				}
+				return ignorePathShapeProblem(components[1:], field+"["+selector+"]", list[index])
+			}
+		}
+		return ignorePathShapeProblem(components[1:], field, child)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("'%s' is %s in data, so it has no field '%s'", parent, describeJSONType(value), components[0])
+	}
+}
+
+/* describeJSONType names the JSON type of a decoded value with its article */
+func describeJSONType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	case string:
+		return "a string"
+	case float64, json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "null"
+}
+
+func indexOf(list []string, elem string) int {
+	for i, a := range list {
+		if a == elem {
+			return i
+		}
+	}
+	return -1
+}
+
+/*
+customizeDiffIgnoreWarnings checks ignore_changes_to against data and against
+
+	itself at plan time and stores the warnings in ignore_changes_to_warnings,
+	so that a typo in an ignore path shows up in the plan instead of as drift
+	that never goes away.
+*/
+func customizeDiffIgnoreWarnings(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("ignore_changes_to") {
+		return d.SetNewComputed("ignore_changes_to_warnings")
+	}
+
+	var data map[string]interface{}
+	if d.NewValueKnown("data") {
+		/* Invalid data is reported by validation */
+		unmarshalJSON([]byte(d.Get("data").(string)), &data)
+	}
+
+	warnings := ignoreListDiagnostics(expandStringList(d.Get("ignore_changes_to").([]interface{})), data)
+	for _, warning := range warnings {
+		log.Printf("[WARN] ignore_diagnostics.go: %s", warning)
+	}
+
+	return d.SetNew("ignore_changes_to_warnings", warnings)
+}
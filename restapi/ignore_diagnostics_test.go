@@ -0,0 +1,78 @@
+package restapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIgnorePatternProblem(t *testing.T) {
+	possible := []string{"metadata", "metadata.timestamp", "*.metadata", "**.approvalRef.oid", "metadata.*", "assignment[*].metadata", "items[2]", "a.**.b", "*", "re:/.*Timestamp$/"}
+	for _, pattern := range possible {
+		if problem := ignorePatternProblem(pattern); problem != "" {
+			t.Fatalf("ignore_diagnostics_test.go: Expected '%s' to be possible, got '%s'", pattern, problem)
+		}
+	}
+
+	impossible := map[string]string{
+		"":                 "empty",
+		"metadata.":        "empty component",
+		"user/name":        "use 'user.name'",
+		"*.metadata.oid":   "use '**.metadata.oid'",
+		"meta*":            "not part of 'meta*'",
+		"items[first]":     "not a list selector",
+		"assign*[*].oid":   "not part of 'assign*[*]'",
+		"**":               "must be followed by a path",
+		"activation..time": "empty component",
+	}
+	for pattern, expected := range impossible {
+		if problem := ignorePatternProblem(pattern); !strings.Contains(problem, expected) {
+			t.Fatalf("ignore_diagnostics_test.go: Expected '%s' to be impossible with '%s', got '%s'", pattern, expected, problem)
+		}
+	}
+
+	warns, errs := validateIgnorePattern("meta*", "ignore_changes_to.0")
+	if len(errs) != 0 || len(warns) != 1 || !strings.Contains(warns[0], "'meta*'") {
+		t.Fatalf("ignore_diagnostics_test.go: Expected a warning naming the entry, got %v %v", warns, errs)
+	}
+}
+
+func TestIgnoreListDiagnostics(t *testing.T) {
+	data := MapAny{
+		"name":       "jsmith",
+		"activation": MapAny{"administrativeStatus": "enabled"},
+		"assignment": []interface{}{MapAny{"targetRef": MapAny{"oid": "r1"}}},
+	}
+	ignoreList := []string{
+		"metadata",
+		"name.orig",
+		"assignment.targetRef.relation",
+		"activation[*]",
+		"metadata.createTimestamp",
+		"*.operationExecution",
+		"activation.operationExecution",
+		"linkRef",
+		"linkRef[*]",
+		"metadata",
+		"meta*",
+		"assignment[*].metadata",
+	}
+
+	expected := []string{
+		"ignore_changes_to[1] 'name.orig': never matches data: 'name' is a string in data, so it has no field 'orig'",
+		"ignore_changes_to[3] 'activation[*]': never matches data: 'activation' is an object in data, not a list",
+		"ignore_changes_to[4] 'metadata.createTimestamp': redundant pattern: already ignored by ignore_changes_to[0] 'metadata'",
+		"ignore_changes_to[6] 'activation.operationExecution': redundant pattern: already ignored by ignore_changes_to[5] '*.operationExecution'",
+		"ignore_changes_to[8] 'linkRef[*]': redundant pattern: already ignored by ignore_changes_to[7] 'linkRef'",
+		"ignore_changes_to[9] 'metadata': redundant pattern: already listed as ignore_changes_to[0]",
+		"ignore_changes_to[10] 'meta*': impossible pattern: '*' only matches whole field names, not part of 'meta*'; use a 're:' pattern",
+	}
+	if warnings := ignoreListDiagnostics(ignoreList, data); !reflect.DeepEqual(expected, warnings) {
+		t.Fatalf("ignore_diagnostics_test.go: Unexpected warnings\nexpected: %v\n     got: %v", expected, warnings)
+	}
+
+	/* Without data, only the patterns are checked */
+	if warnings := ignoreListDiagnostics([]string{"name.orig", "re:/.*Timestamp$/"}, nil); len(warnings) != 0 {
+		t.Fatalf("ignore_diagnostics_test.go: Expected no warnings without data, got %v", warnings)
+	}
+}
@@ -1,7 +1,10 @@
 package restapi
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/Mastercard/terraform-provider-restapi/fakeserver"
@@ -59,3 +62,126 @@ func TestAccRestApiObject_importBasic(t *testing.T) {
 
 	svr.Shutdown()
 }
+
+func TestRestApiObjectImportOptions(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"user": {"oid": "1234", "name": "jsmith", "metadata": {"createTimestamp": "2026-01-01T00:00:00Z"}, "operationExecution": [{"@id": 1}]}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 2, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("import_api_object_test.go: Failed to create API client: %s", err)
+	}
+
+	d := resourceRestAPI().TestResourceData()
+	d.SetId("/users/1234?read_results_key=user&ignore_changes_to=metadata,operationExecution")
+	imported, err := resourceRestAPIImport(d, client)
+	if err != nil {
+		t.Fatalf("import_api_object_test.go: Import failed: %s", err)
+	}
+
+	d = imported[0]
+	if d.Id() != "1234" || d.Get("path") != "/users" {
+		t.Fatalf("import_api_object_test.go: Unexpected id '%s' and path '%s'", d.Id(), d.Get("path"))
+	}
+	if d.Get("read_results_key") != "user" {
+		t.Fatalf("import_api_object_test.go: Expected read_results_key to be set from the import options, got '%s'", d.Get("read_results_key"))
+	}
+	if data := d.Get("data").(string); data != `{"name":"jsmith","oid":"1234"}` {
+		t.Fatalf("import_api_object_test.go: Expected the imported data without ignored fields, got '%s'", data)
+	}
+
+	/* Without options, data is the whole object too, ready for generated configuration */
+	d = resourceRestAPI().TestResourceData()
+	d.SetId("/users/1234")
+	if imported, err = resourceRestAPIImport(d, client); err != nil {
+		t.Fatalf("import_api_object_test.go: Import failed: %s", err)
+	}
+	if d = imported[0]; !strings.Contains(d.Get("data").(string), `"name":"jsmith"`) || d.Get("debug").(bool) {
+		t.Fatalf("import_api_object_test.go: Expected the imported data to be the object without debug set, got '%s' and %v", d.Get("data"), d.Get("debug"))
+	}
+
+	d = resourceRestAPI().TestResourceData()
+	d.SetId("/users/1234?wrapper=user")
+	if _, err := resourceRestAPIImport(d, client); err == nil {
+		t.Fatalf("import_api_object_test.go: Expected an unknown import option to fail")
+	}
+}
+
+func TestRestApiObjectImportByName(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/search":
+			w.Write([]byte(`{"object": {"object": [{"user": {"oid": "1234", "name": {"orig": "jdoe", "norm": "jdoe"}}}]}}`))
+		case "/roles/search":
+			w.Write([]byte(`{"object": {"object": [{"role": {"oid": "5678", "name": "Finance Approver"}}, {"role": {"oid": "9012", "name": "finance approver"}}]}}`))
+		case "/users/1234":
+			w.Write([]byte(`{"user": {"oid": "1234", "name": "jdoe", "metadata": {"createTimestamp": "2026-01-01T00:00:00Z"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 2, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("import_api_object_test.go: Failed to create API client: %s", err)
+	}
+
+	for _, id := range []string{"users:name=jdoe?read_results_key=user", "users?name=jdoe&read_results_key=user"} {
+		d := resourceRestAPI().TestResourceData()
+		d.SetId(id)
+		imported, err := resourceRestAPIImport(d, client)
+		if err != nil {
+			t.Fatalf("import_api_object_test.go: Import of '%s' failed: %s", id, err)
+		}
+		if d = imported[0]; d.Id() != "1234" || d.Get("path") != "/users" || d.Get("read_results_key") != "user" {
+			t.Fatalf("import_api_object_test.go: Unexpected id '%s', path '%s' and read_results_key '%s' importing '%s'", d.Id(), d.Get("path"), d.Get("read_results_key"), id)
+		}
+	}
+
+	for id, expected := range map[string]string{
+		"roles?name=Finance Approver": "2 existing objects have name 'Finance Approver'",
+		"users:name=nobody":           "no existing object with name 'nobody'",
+		"users:representation=strict": "does not name an item and value to search by",
+		"users":                       "invalid ID to import",
+	} {
+		d := resourceRestAPI().TestResourceData()
+		d.SetId(id)
+		if _, err := resourceRestAPIImport(d, client); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("import_api_object_test.go: Expected importing '%s' to fail with '%s', got %v", id, expected, err)
+		}
+	}
+}
+
+func TestRestApiObjectImportReadOverrides(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		/* The object is only found behind a nonstandard read endpoint */
+		if r.URL.Path != "/legacy/users/view/1234" || r.URL.RawQuery != "options=raw" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"result": {"user": {"uid": "1234", "name": "jsmith"}}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 2, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("import_api_object_test.go: Failed to create API client: %s", err)
+	}
+
+	d := resourceRestAPI().TestResourceData()
+	d.SetId("/users/1234?read_path=/legacy/users/view/{id}&id_attribute=user/uid&query_string=options%3Draw&read_results_key=result&read_search.results_key=result")
+	imported, err := resourceRestAPIImport(d, client)
+	if err != nil {
+		t.Fatalf("import_api_object_test.go: Import failed: %s", err)
+	}
+	d = imported[0]
+	if d.Id() != "1234" || d.Get("read_path") != "/legacy/users/view/{id}" || d.Get("id_attribute") != "user/uid" || d.Get("query_string") != "options=raw" || d.Get("read_search.results_key") != "result" {
+		t.Fatalf("import_api_object_test.go: Expected the read overrides to be set, got %v", d.State())
+	}
+	if data := d.Get("data").(string); data != `{"user":{"name":"jsmith","uid":"1234"}}` {
+		t.Fatalf("import_api_object_test.go: Expected the object read at read_path, got '%s'", data)
+	}
+}
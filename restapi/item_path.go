@@ -0,0 +1,90 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+/* Name of the midPoint container holding schema extension attributes */
+const extensionContainer = "extension"
+
+/*
+qualifyItemPath prepends a "declare namespace" clause for every prefix of
+
+	path_namespaces that is used in path, so midPoint can resolve qualified
+	segments such as extension/my:costCenter. Paths without known prefixes
+	are returned unchanged.
+*/
+func qualifyItemPath(path string, namespaces map[string]string) string {
+	if len(namespaces) == 0 {
+		return path
+	}
+
+	used := make(map[string]bool)
+	for _, segment := range strings.Split(path, "/") {
+		if i := strings.Index(segment, ":"); i > 0 {
+			if _, ok := namespaces[segment[:i]]; ok {
+				used[segment[:i]] = true
+			}
+		}
+	}
+	if len(used) == 0 {
+		return path
+	}
+
+	var buffer strings.Builder
+	for _, prefix := range sortedStringKeys(namespaces) {
+		if used[prefix] {
+			buffer.WriteString(fmt.Sprintf("declare namespace %s='%s'; ", prefix, namespaces[prefix]))
+		}
+	}
+	buffer.WriteString(path)
+	return buffer.String()
+}
+
+/*
+extensionDeltas returns one delta per changed item of the extension
+
+	container instead of replacing the container as a whole, which midPoint
+	rejects for items defined in a schema extension. Unqualified item names
+	are qualified with the prefix of the container's @ns namespace, or with
+	the only prefix declared in path_namespaces.
+*/
+func extensionDeltas(path string, current map[string]interface{}, desired map[string]interface{}, namespaces map[string]string, ignoreList []string, debug bool) []midpointItemDelta {
+	prefix := extensionPrefix(current, desired, namespaces)
+	if debug {
+		log.Printf("item_path.go: Diffing '%s' item by item (default prefix '%s')", path, prefix)
+	}
+
+	compareIgnoreList := append(append([]string{}, ignoreList...), "@ns")
+	deltas := containerValueDeltas(path, current, desired, compareIgnoreList)
+	for i := range deltas {
+		item := strings.TrimPrefix(deltas[i].path, path+"/")
+		if prefix != "" && !strings.Contains(item, ":") {
+			deltas[i].path = fmt.Sprintf("%s/%s:%s", path, prefix, item)
+		}
+	}
+	return deltas
+}
+
+func extensionPrefix(current map[string]interface{}, desired map[string]interface{}, namespaces map[string]string) string {
+	for _, hash := range []map[string]interface{}{desired, current} {
+		ns, ok := hash["@ns"].(string)
+		if !ok {
+			continue
+		}
+		for _, prefix := range sortedStringKeys(namespaces) {
+			if namespaces[prefix] == ns {
+				return prefix
+			}
+		}
+	}
+
+	if len(namespaces) == 1 {
+		for prefix := range namespaces {
+			return prefix
+		}
+	}
+	return ""
+}
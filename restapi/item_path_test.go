@@ -0,0 +1,71 @@
+package restapi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestQualifyItemPath(t *testing.T) {
+	namespaces := map[string]string{
+		"my":  "http://example.com/my",
+		"org": "http://example.com/org",
+	}
+
+	cases := map[string]string{
+		"description":                  "description",
+		"extension/my:costCenter":      "declare namespace my='http://example.com/my'; extension/my:costCenter",
+		"extension/other:costCenter":   "extension/other:costCenter",
+		"assignment/[3]/org:unit":      "declare namespace org='http://example.com/org'; assignment/[3]/org:unit",
+		"extension/my:a/org:b":         "declare namespace my='http://example.com/my'; declare namespace org='http://example.com/org'; extension/my:a/org:b",
+		"extension/costCenter/my:nope": "declare namespace my='http://example.com/my'; extension/costCenter/my:nope",
+	}
+	for path, expected := range cases {
+		if got := qualifyItemPath(path, namespaces); got != expected {
+			t.Errorf("item_path_test.go: Expected '%s' to become '%s', got '%s'", path, expected, got)
+		}
+	}
+
+	if got := qualifyItemPath("extension/my:costCenter", nil); got != "extension/my:costCenter" {
+		t.Errorf("item_path_test.go: Expected path to be unchanged without namespaces, got '%s'", got)
+	}
+}
+
+func TestExtensionDeltas(t *testing.T) {
+	current := map[string]interface{}{
+		"@ns":        "http://example.com/my",
+		"costCenter": "100",
+		"building":   "A",
+		"retired":    "yes",
+	}
+	desired := map[string]interface{}{
+		"costCenter":  "200",
+		"building":    "A",
+		"org:station": "5",
+	}
+
+	namespaces := map[string]string{
+		"my":  "http://example.com/my",
+		"org": "http://example.com/org",
+	}
+	deltas := extensionDeltas("extension", current, desired, namespaces, nil, false)
+
+	expected := []string{
+		"replace extension/my:costCenter",
+		"add extension/org:station",
+		"delete extension/my:retired",
+	}
+	if len(deltas) != len(expected) {
+		t.Fatalf("item_path_test.go: Expected %d deltas, got %d: %v", len(expected), len(deltas), deltas)
+	}
+	for i, delta := range deltas {
+		if got := fmt.Sprintf("%s %s", delta.modificationType, delta.path); got != expected[i] {
+			t.Errorf("item_path_test.go: Expected delta %d to be '%s', got '%s'", i, expected[i], got)
+		}
+	}
+
+	obj := &APIObject{pathNamespaces: namespaces}
+	itemDelta := obj.buildItemDelta(deltas[0].modificationType, deltas[0].path, deltas[0].value)
+	if itemDelta["path"] != "declare namespace my='http://example.com/my'; extension/my:costCenter" {
+		t.Errorf("item_path_test.go: Expected a namespace-declaring path, got '%v'", itemDelta["path"])
+	}
+}
@@ -0,0 +1,61 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+/*
+unmarshalJSON decodes data like json.Unmarshal, but keeps numbers as
+
+	json.Number instead of float64. Decoding to float64 turns 1000000000 into
+	1e+09 when it is encoded again and rounds long numeric identifiers, which
+	shows up as changes nobody made.
+*/
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	/* Like json.Unmarshal, fail on anything after the value */
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid character after top-level value at offset %d", decoder.InputOffset())
+	}
+	return nil
+}
+
+/*
+canonicalNumber is the exact value of a number, so that numbers written
+
+	differently, such as 1e9 and 1000000000 or 1.50 and 1.5, compare equal.
+*/
+type canonicalNumber string
+
+/* canonicalNumberOf returns the canonical form of the number s, and false when s is not a number */
+func canonicalNumberOf(s string) (canonicalNumber, bool) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return "", false
+	}
+	return canonicalNumber(r.RatString()), true
+}
+
+/* canonicalNumberValue returns the canonical form of a decoded JSON number, or v when it is not one */
+func canonicalNumberValue(v interface{}) interface{} {
+	switch n := v.(type) {
+	case json.Number:
+		if c, ok := canonicalNumberOf(n.String()); ok {
+			return c
+		}
+	case float64:
+		if c, ok := canonicalNumberOf(strconv.FormatFloat(n, 'g', -1, 64)); ok {
+			return c
+		}
+	}
+	return v
+}
@@ -0,0 +1,47 @@
+package restapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONNumbers(t *testing.T) {
+	var data map[string]interface{}
+	state := `{"id":12345678901234567890123,"limit":1000000000,"ratio":0.1}`
+	if err := unmarshalJSON([]byte(state), &data); err != nil {
+		t.Fatalf("json_numbers_test.go: Failed to decode: %s", err)
+	}
+	if b, _ := json.Marshal(data); string(b) != state {
+		t.Fatalf("json_numbers_test.go: Expected the numbers to round-trip unchanged, got %s", b)
+	}
+	if id, err := GetStringAtKey(data, "id", false); err != nil || id != "12345678901234567890123" {
+		t.Fatalf("json_numbers_test.go: Expected the exact id, got %s, %v", id, err)
+	}
+	if err := unmarshalJSON([]byte(`{"a": 1} x`), &data); err == nil {
+		t.Fatalf("json_numbers_test.go: Expected content after the value to fail")
+	}
+
+	/* Numbers are compared by value, also against float64 */
+	cases := []struct {
+		recorded interface{}
+		actual   interface{}
+		equal    bool
+	}{
+		{json.Number("1e9"), json.Number("1000000000"), true},
+		{json.Number("1.50"), json.Number("1.5"), true},
+		{json.Number("1000000000"), 1e9, true},
+		{json.Number("12345678901234567890123"), json.Number("12345678901234567890124"), false},
+		{json.Number("1"), "1", false},
+	}
+	for _, c := range cases {
+		_, changed := getDelta(map[string]interface{}{"n": c.recorded}, map[string]interface{}{"n": c.actual}, nil, nil)
+		if changed == c.equal {
+			t.Fatalf("json_numbers_test.go: Expected %v and %v to be equal: %v", c.recorded, c.actual, c.equal)
+		}
+	}
+
+	rules := &normalizeRules{numericStrings: true}
+	if !rules.equal("1000000000", json.Number("1e9")) || !rules.equal("0.1", 0.1) {
+		t.Fatalf("json_numbers_test.go: Expected numeric strings to equal the numbers")
+	}
+}
@@ -0,0 +1,104 @@
+package restapi
+
+import (
+	"log"
+	"reflect"
+)
+
+/*
+diffListByMergeKey compares two values of a multi-value container (such as
+
+	midPoint's assignment or inducement) by matching their elements on
+	mergeKey, a '/'-delimited path inside each element (e.g. targetRef/oid).
+	Instead of replacing the whole container, it returns an add delta for the
+	elements only present in desired and a delete delta for the elements only
+	present in current. Elements that exist on both sides but differ are
+	deleted and re-added.
+
+	If any element lacks the merge key the lists cannot be matched and ok is
+	false, so the caller should fall back to replacing the whole container.
+*/
+func diffListByMergeKey(path string, current []interface{}, desired []interface{}, mergeKey string, ignoreList []string, debug bool) (deltas []midpointItemDelta, ok bool) {
+	currentByKey, ok := indexListByMergeKey(current, mergeKey, debug)
+	if !ok {
+		return nil, false
+	}
+	desiredByKey, ok := indexListByMergeKey(desired, mergeKey, debug)
+	if !ok {
+		return nil, false
+	}
+
+	toAdd := make([]interface{}, 0)
+	toDelete := make([]interface{}, 0)
+
+	for _, elem := range desired {
+		key, _ := mergeKeyOf(elem, mergeKey, debug)
+		currentElem, exists := currentByKey[key]
+		if !exists {
+			toAdd = append(toAdd, elem)
+		} else if listElementChanged(currentElem, elem, ignoreList) {
+			toDelete = append(toDelete, currentElem)
+			toAdd = append(toAdd, elem)
+		}
+	}
+	for _, elem := range current {
+		key, _ := mergeKeyOf(elem, mergeKey, debug)
+		if _, exists := desiredByKey[key]; !exists {
+			toDelete = append(toDelete, elem)
+		}
+	}
+
+	if debug {
+		log.Printf("list_merge.go: '%s' matched on '%s': %d value(s) to add, %d value(s) to delete", path, mergeKey, len(toAdd), len(toDelete))
+	}
+
+	/* Deletes go first so a changed value is removed before its replacement is added */
+	deltas = make([]midpointItemDelta, 0, 2)
+	if len(toDelete) > 0 {
+		deltas = append(deltas, midpointItemDelta{modificationType: "delete", path: path, value: toDelete})
+	}
+	if len(toAdd) > 0 {
+		deltas = append(deltas, midpointItemDelta{modificationType: "add", path: path, value: toAdd})
+	}
+	return deltas, true
+}
+
+func indexListByMergeKey(list []interface{}, mergeKey string, debug bool) (map[string]interface{}, bool) {
+	index := make(map[string]interface{}, len(list))
+	for _, elem := range list {
+		key, ok := mergeKeyOf(elem, mergeKey, debug)
+		if !ok {
+			return nil, false
+		}
+		if _, duplicate := index[key]; duplicate {
+			if debug {
+				log.Printf("list_merge.go: Duplicate merge key '%s'='%s', cannot match list elements", mergeKey, key)
+			}
+			return nil, false
+		}
+		index[key] = elem
+	}
+	return index, true
+}
+
+func mergeKeyOf(elem interface{}, mergeKey string, debug bool) (string, bool) {
+	hash, ok := elem.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	key, err := GetStringAtKey(hash, mergeKey, debug)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+func listElementChanged(current interface{}, desired interface{}, ignoreList []string) bool {
+	currentMap, okCurrent := current.(map[string]interface{})
+	desiredMap, okDesired := desired.(map[string]interface{})
+	if !okCurrent || !okDesired {
+		return !reflect.DeepEqual(current, desired)
+	}
+	_, changed := getDelta(filterIgnoredFields(desiredMap, ignoreList), filterIgnoredFields(currentMap, ignoreList), ignoreList, nil)
+	return changed
+}
@@ -0,0 +1,69 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffListByMergeKey(t *testing.T) {
+	endUser := MapAny{"targetRef": MapAny{"oid": "00000000-0000-0000-0000-000000000008"}}
+	superuser := MapAny{"targetRef": MapAny{"oid": "00000000-0000-0000-0000-000000000004"}}
+	approver := MapAny{"targetRef": MapAny{"oid": "00000000-0000-0000-0000-00000000000a"}, "@id": 3}
+	approverChanged := MapAny{"targetRef": MapAny{"oid": "00000000-0000-0000-0000-00000000000a"}, "description": "temporary"}
+
+	current := []interface{}{endUser, superuser, approver}
+	desired := []interface{}{endUser, approverChanged, MapAny{"targetRef": MapAny{"oid": "new-role"}}}
+
+	deltas, ok := diffListByMergeKey("assignment", current, desired, "targetRef/oid", []string{"@id"}, false)
+	if !ok {
+		t.Fatalf("list_merge_test.go: Expected lists to be matched by merge key")
+	}
+
+	expected := []midpointItemDelta{
+		{modificationType: "delete", path: "assignment", value: []interface{}{approver, superuser}},
+		{modificationType: "add", path: "assignment", value: []interface{}{approverChanged, MapAny{"targetRef": MapAny{"oid": "new-role"}}}},
+	}
+	if !reflect.DeepEqual(expected, deltas) {
+		t.Fatalf("list_merge_test.go: Unexpected deltas: expected %v but got %v", expected, deltas)
+	}
+
+	/* Ignored fields such as container ids alone do not make a value change */
+	approverWithoutID := MapAny{"targetRef": MapAny{"oid": "00000000-0000-0000-0000-00000000000a"}}
+	deltas, ok = diffListByMergeKey("assignment", []interface{}{approver}, []interface{}{approverWithoutID}, "targetRef/oid", []string{"@id"}, false)
+	if !ok || len(deltas) != 0 {
+		t.Fatalf("list_merge_test.go: Expected no deltas when only ignored fields differ, got %v", deltas)
+	}
+
+	/* Elements without the merge key cannot be matched */
+	if _, ok := diffListByMergeKey("assignment", current, []interface{}{MapAny{"description": "no target"}}, "targetRef/oid", nil, false); ok {
+		t.Fatalf("list_merge_test.go: Expected matching to fail when an element lacks the merge key")
+	}
+
+	/* Duplicate keys cannot be matched either */
+	if _, ok := diffListByMergeKey("assignment", []interface{}{endUser, endUser}, desired, "targetRef/oid", nil, false); ok {
+		t.Fatalf("list_merge_test.go: Expected matching to fail on duplicate merge keys")
+	}
+}
+
+func TestListMergeDeltasSingleValue(t *testing.T) {
+	obj := &APIObject{listMergeKeys: map[string]string{"assignment": "targetRef/oid"}}
+
+	/* midPoint returns a single assignment as an object rather than a list */
+	current := MapAny{"targetRef": MapAny{"oid": "a"}}
+	desired := []interface{}{MapAny{"targetRef": MapAny{"oid": "a"}}, MapAny{"targetRef": MapAny{"oid": "b"}}}
+
+	deltas, ok := obj.listMergeDeltas("assignment", current, desired, obj.ignoreChangesTo)
+	if !ok {
+		t.Fatalf("list_merge_test.go: Expected single-valued container to be merged")
+	}
+	expected := []midpointItemDelta{
+		{modificationType: "add", path: "assignment", value: []interface{}{MapAny{"targetRef": MapAny{"oid": "b"}}}},
+	}
+	if !reflect.DeepEqual(expected, deltas) {
+		t.Fatalf("list_merge_test.go: Unexpected deltas: expected %v but got %v", expected, deltas)
+	}
+
+	if _, ok := obj.listMergeDeltas("inducement", current, desired, obj.ignoreChangesTo); ok {
+		t.Fatalf("list_merge_test.go: Expected attributes without a merge key to be replaced")
+	}
+}
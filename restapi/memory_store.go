@@ -0,0 +1,657 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/* Supported values for the provider's mode */
+const (
+	clientModeHTTP   = "http"
+	clientModeMemory = "memory"
+)
+
+/*
+memoryStore stands in for the API server in the memory mode of the provider.
+
+	It is the transport of the client's http.Client, so requests go through
+	the same code paths as over the network and are answered from objects
+	kept in the process. It implements the CRUD semantics of a REST API, the
+	midPoint, JSON Patch and JSON Merge Patch updates and midPoint searches,
+	so modules can be tested without a server.
+*/
+type memoryStore struct {
+	mu          sync.Mutex
+	idAttribute string
+	/* Objects by id, by the path of their collection */
+	collections map[string]map[string]map[string]interface{}
+	nextID      int
+}
+
+func newMemoryStore(idAttribute string) *memoryStore {
+	return &memoryStore{
+		idAttribute: idAttribute,
+		collections: make(map[string]map[string]map[string]interface{}),
+	}
+}
+
+/* memoryError is a failure answered with an HTTP status, as the server would */
+type memoryError struct {
+	status  int
+	message string
+}
+
+func (e *memoryError) Error() string {
+	return e.message
+}
+
+func memoryErrorf(status int, format string, args ...interface{}) *memoryError {
+	return &memoryError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+/* RoundTrip answers a request from the objects in the store */
+func (store *memoryStore) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	store.mu.Lock()
+	status, result, err := store.handle(req.Method, strings.TrimSuffix(req.URL.Path, "/"), req.Header.Get("Content-Type"), body)
+	store.mu.Unlock()
+
+	response := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Request:    req,
+	}
+	var payload []byte
+	if err != nil {
+		log.Printf("memory_store.go: %s %s failed: %v", req.Method, req.URL.Path, err)
+		payload, _ = json.Marshal(map[string]interface{}{"error": err.Error()})
+	} else if result != nil {
+		payload, _ = json.Marshal(result)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(payload))
+	response.ContentLength = int64(len(payload))
+	return response, nil
+}
+
+func (store *memoryStore) handle(method string, path string, contentType string, body []byte) (int, interface{}, error) {
+	var document map[string]interface{}
+	if len(body) > 0 && !strings.HasPrefix(contentType, contentTypeJSONPatch) {
+		if err := unmarshalJSON(body, &document); err != nil {
+			return http.StatusBadRequest, nil, fmt.Errorf("the request body is not a JSON object: %v", err)
+		}
+	}
+
+	if method == "POST" && strings.HasSuffix(path, "/search") {
+		objects, err := store.search(strings.TrimSuffix(path, "/search"), document)
+		if err != nil {
+			return err.status, nil, err
+		}
+		return http.StatusOK, map[string]interface{}{"object": map[string]interface{}{"object": objects}}, nil
+	}
+
+	if i := strings.LastIndex(path, "/"); method == "POST" && i >= 0 && (path[i:] == "/recompute" || path[i:] == "/test") {
+		return store.operation(path[:i], path[i+1:])
+	}
+
+	if method == "POST" {
+		object, err := store.create(path, document)
+		if err != nil {
+			return err.status, nil, err
+		}
+		return http.StatusCreated, object, nil
+	}
+
+	collection, id := path, ""
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		collection, id = path[:i], path[i+1:]
+	}
+	object, exists := store.collections[collection][id]
+
+	switch method {
+	case "GET":
+		if !exists {
+			/* A path that objects were created at lists them */
+			if objects, ok := store.collections[path]; ok {
+				return http.StatusOK, sortedObjects(objects), nil
+			}
+			return http.StatusNotFound, nil, memoryErrorf(http.StatusNotFound, "no object at '%s'", path)
+		}
+		return http.StatusOK, object, nil
+	case "PUT":
+		if document == nil {
+			return http.StatusBadRequest, nil, memoryErrorf(http.StatusBadRequest, "PUT to '%s' without an object", path)
+		}
+		store.collection(collection)[id] = document
+		return http.StatusOK, document, nil
+	case "PATCH":
+		if !exists {
+			return http.StatusNotFound, nil, memoryErrorf(http.StatusNotFound, "no object at '%s'", path)
+		}
+		updated, err := patchMemoryObject(object, contentType, body, document)
+		if err != nil {
+			return err.status, nil, err
+		}
+		store.collections[collection][id] = updated
+		return http.StatusOK, updated, nil
+	case "DELETE":
+		if !exists {
+			return http.StatusNotFound, nil, memoryErrorf(http.StatusNotFound, "no object at '%s'", path)
+		}
+		delete(store.collections[collection], id)
+		return http.StatusNoContent, nil, nil
+	}
+	return http.StatusMethodNotAllowed, nil, memoryErrorf(http.StatusMethodNotAllowed, "method %s is not supported", method)
+}
+
+/*
+operation answers midPoint's operations on the object at path. Objects are
+
+	not computed in memory, so a recompute only needs the object, and the
+	test of a resource always succeeds.
+*/
+func (store *memoryStore) operation(path string, name string) (int, interface{}, error) {
+	collection, id := path, ""
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		collection, id = path[:i], path[i+1:]
+	}
+	if _, exists := store.collections[collection][id]; !exists {
+		return http.StatusNotFound, nil, memoryErrorf(http.StatusNotFound, "no object at '%s'", path)
+	}
+	if name == "test" {
+		return http.StatusOK, map[string]interface{}{"object": map[string]interface{}{"operation": "testResource", "status": "success"}}, nil
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (store *memoryStore) collection(path string) map[string]map[string]interface{} {
+	if _, ok := store.collections[path]; !ok {
+		store.collections[path] = make(map[string]map[string]interface{})
+	}
+	return store.collections[path]
+}
+
+/*
+create stores a new object in the collection at path. An object without a
+
+	value at id_attribute is given a generated oid there, and creating an
+	object whose id is taken fails with 409 Conflict, as midPoint does.
+*/
+func (store *memoryStore) create(path string, object map[string]interface{}) (map[string]interface{}, *memoryError) {
+	if object == nil {
+		return nil, memoryErrorf(http.StatusBadRequest, "POST to '%s' without an object", path)
+	}
+
+	id, err := GetStringAtKey(object, store.idAttribute, false)
+	if err != nil || id == "" {
+		store.nextID++
+		id = fmt.Sprintf("00000000-0000-0000-0000-%012d", store.nextID)
+		if err := SetObjectAtKey(object, store.idAttribute, id, false); err != nil {
+			return nil, memoryErrorf(http.StatusBadRequest, "cannot set the id of the new object: %v", err)
+		}
+	}
+
+	objects := store.collection(path)
+	if _, exists := objects[id]; exists {
+		return nil, memoryErrorf(http.StatusConflict, "an object with id '%s' already exists at '%s'", id, path)
+	}
+	objects[id] = object
+	return object, nil
+}
+
+/*
+search returns the objects of the collection at path matching the midPoint
+
+	query of the request, in the order and page its paging asks for.
+*/
+func (store *memoryStore) search(path string, request map[string]interface{}) ([]interface{}, *memoryError) {
+	var filter, paging map[string]interface{}
+	if query, ok := request["query"].(map[string]interface{}); ok {
+		filter, _ = query["filter"].(map[string]interface{})
+		paging, _ = query["paging"].(map[string]interface{})
+	}
+
+	matches := make([]interface{}, 0)
+	for _, object := range sortedObjects(store.collections[path]) {
+		matched, err := matchesMemoryFilter(unwrapMemoryObject(object.(map[string]interface{})), filter)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, object)
+		}
+	}
+	return pageMemoryObjects(matches, paging), nil
+}
+
+/* pageMemoryObjects applies the orderBy, orderDirection, offset and maxSize of midPoint paging */
+func pageMemoryObjects(objects []interface{}, paging map[string]interface{}) []interface{} {
+	if orderBy, ok := paging["orderBy"].(string); ok && orderBy != "" {
+		key := func(i int) string {
+			value, _ := GetObjectAtKey(unwrapMemoryObject(objects[i].(map[string]interface{})), orderBy, false)
+			if poly := polyStringValue(value); poly != "" {
+				return poly
+			}
+			return fmt.Sprint(value)
+		}
+		descending := paging["orderDirection"] == "descending"
+		sort.SliceStable(objects, func(i, j int) bool {
+			if descending {
+				return key(i) > key(j)
+			}
+			return key(i) < key(j)
+		})
+	}
+	if _, ok := paging["offset"]; ok {
+		offset := integerAt(paging, "offset")
+		if offset >= len(objects) {
+			return []interface{}{}
+		}
+		objects = objects[offset:]
+	}
+	if _, ok := paging["maxSize"]; ok {
+		if maxSize := integerAt(paging, "maxSize"); maxSize < len(objects) {
+			objects = objects[:maxSize]
+		}
+	}
+	return objects
+}
+
+func sortedObjects(objects map[string]map[string]interface{}) []interface{} {
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, objects[id])
+	}
+	return result
+}
+
+/*
+matchesMemoryFilter evaluates the equal, substring, ref, inOid, and, or and not clauses
+
+	of a midPoint query filter. Each clause may be a single value or a list.
+*/
+func matchesMemoryFilter(object map[string]interface{}, filter map[string]interface{}) (bool, *memoryError) {
+	for kind, raw := range filter {
+		clauses, _ := asValueList(raw)
+		for _, rawClause := range clauses {
+			clause, ok := rawClause.(map[string]interface{})
+			if !ok {
+				return false, memoryErrorf(http.StatusBadRequest, "invalid '%s' filter", kind)
+			}
+
+			var matched bool
+			var err *memoryError
+			switch kind {
+			case "equal", "substring":
+				matched = memoryItemMatches(object, fmt.Sprint(clause["path"]), clause["value"], kind == "substring")
+			case "ref":
+				matched = memoryRefMatches(object, fmt.Sprint(clause["path"]), clause["value"])
+			case "inOid":
+				oids, _ := asValueList(clause["value"])
+				for _, oid := range oids {
+					matched = matched || fmt.Sprint(oid) == scalarStringAt(object, "oid")
+				}
+			case "and":
+				matched, err = matchesMemoryFilter(object, clause)
+			case "or":
+				matched, err = matchesAnyMemoryFilter(object, clause)
+			case "not":
+				matched, err = matchesMemoryFilter(object, clause)
+				matched = !matched
+			default:
+				return false, memoryErrorf(http.StatusBadRequest, "the '%s' filter is not supported in memory mode", kind)
+			}
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+/* matchesAnyMemoryFilter reports whether any clause of an or filter matches */
+func matchesAnyMemoryFilter(object map[string]interface{}, filter map[string]interface{}) (bool, *memoryError) {
+	for kind, raw := range filter {
+		clauses, _ := asValueList(raw)
+		for _, clause := range clauses {
+			matched, err := matchesMemoryFilter(object, map[string]interface{}{kind: clause})
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+	}
+	return false, nil
+}
+
+/* memoryItemMatches compares the values of the item at path, and the originals of PolyStrings, with want */
+func memoryItemMatches(object map[string]interface{}, path string, want interface{}, substring bool) bool {
+	value, err := GetObjectAtKey(object, path, false)
+	if err != nil {
+		return false
+	}
+	values, ok := asValueList(value)
+	if !ok {
+		values = []interface{}{value}
+	}
+	for _, v := range values {
+		have := polyStringValue(v)
+		if have == "" {
+			have = fmt.Sprint(v)
+		}
+		if have == fmt.Sprint(want) || substring && strings.Contains(have, fmt.Sprint(want)) {
+			return true
+		}
+	}
+	return false
+}
+
+/* memoryRefMatches reports whether a reference at path points to the oid (and type, if given) of any of the wanted references */
+func memoryRefMatches(object map[string]interface{}, path string, want interface{}) bool {
+	value, err := GetObjectAtKey(object, path, false)
+	if err != nil {
+		return false
+	}
+	refs, _ := asValueList(value)
+	wanted, _ := asValueList(want)
+	for _, ref := range refs {
+		for _, w := range wanted {
+			ref, okRef := ref.(map[string]interface{})
+			w, okWant := w.(map[string]interface{})
+			if !okRef || !okWant || ref["oid"] != w["oid"] {
+				continue
+			}
+			if _, typed := w["type"]; !typed || refQName(fmt.Sprint(ref["type"])) == refQName(fmt.Sprint(w["type"])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/* unwrapMemoryObject returns the object inside a single wrapper key such as "user", which midPoint item paths start below */
+func unwrapMemoryObject(object map[string]interface{}) map[string]interface{} {
+	if len(object) == 1 {
+		for _, value := range object {
+			if inner, ok := value.(map[string]interface{}); ok {
+				return inner
+			}
+		}
+	}
+	return object
+}
+
+/* patchMemoryObject applies a patch document to a copy of object, so a failing patch leaves it untouched */
+func patchMemoryObject(object map[string]interface{}, contentType string, body []byte, document map[string]interface{}) (map[string]interface{}, *memoryError) {
+	var updated map[string]interface{}
+	b, _ := json.Marshal(object)
+	if err := unmarshalJSON(b, &updated); err != nil {
+		return nil, memoryErrorf(http.StatusInternalServerError, "the stored object cannot be copied: %v", err)
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, contentTypeJSONPatch):
+		var ops []map[string]interface{}
+		if err := unmarshalJSON(body, &ops); err != nil {
+			return nil, memoryErrorf(http.StatusBadRequest, "the JSON Patch document is not an array of operations: %v", err)
+		}
+		for _, op := range ops {
+			if err := applyMemoryJSONPatch(updated, op); err != nil {
+				return nil, err
+			}
+		}
+		return updated, nil
+	case strings.HasPrefix(contentType, contentTypeMergePatch):
+		return applyMemoryMergePatch(updated, document), nil
+	}
+
+	modification, ok := document["objectModification"].(map[string]interface{})
+	if !ok {
+		return nil, memoryErrorf(http.StatusBadRequest, "a PATCH needs an objectModification, a JSON Patch or a JSON Merge Patch document")
+	}
+	itemDeltas, _ := asValueList(modification["itemDelta"])
+	target := unwrapMemoryObject(updated)
+	for _, raw := range itemDeltas {
+		itemDelta, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, memoryErrorf(http.StatusBadRequest, "invalid itemDelta")
+		}
+		if err := applyMemoryItemDelta(target, itemDelta); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+/*
+applyMemoryItemDelta applies a midPoint itemDelta. Path segments such as
+
+	[3] select the container value with that id. add appends to multi-valued
+	items, delete removes the given values or, without values, the item.
+*/
+func applyMemoryItemDelta(object map[string]interface{}, itemDelta map[string]interface{}) *memoryError {
+	path := fmt.Sprint(itemDelta["path"])
+	/* Namespace declarations only qualify the path */
+	if i := strings.LastIndex(path, ";"); i >= 0 {
+		path = strings.TrimSpace(path[i+1:])
+	}
+	segments := strings.Split(path, "/")
+
+	parent := object
+	for i := 0; i < len(segments)-1; i++ {
+		containerID := ""
+		if i+1 < len(segments)-1 && isContainerIDSegment(segments[i+1]) {
+			containerID = strings.Trim(segments[i+1], "[]")
+		}
+		next, err := memoryPathStep(parent, segments[i], containerID, path)
+		if err != nil {
+			return err
+		}
+		if containerID != "" {
+			i++
+		}
+		parent = next
+	}
+	name := segments[len(segments)-1]
+	value, hasValue := itemDelta["value"]
+
+	switch itemDelta["modificationType"] {
+	case "replace":
+		if !hasValue || value == nil {
+			delete(parent, name)
+		} else {
+			parent[name] = value
+		}
+	case "add":
+		if !hasValue {
+			return memoryErrorf(http.StatusBadRequest, "add of '%s' without a value", path)
+		}
+		existing, exists := parent[name]
+		if !exists {
+			parent[name] = value
+			return nil
+		}
+		existingValues, isList := existing.([]interface{})
+		if _, isMap := existing.(map[string]interface{}); !isList && isMap {
+			existingValues, isList = []interface{}{existing}, true
+		}
+		if !isList {
+			parent[name] = value
+			return nil
+		}
+		added, _ := asValueList(value)
+		if added == nil {
+			added = []interface{}{value}
+		}
+		parent[name] = append(existingValues, added...)
+	case "delete":
+		existing, exists := parent[name]
+		if !exists {
+			return nil
+		}
+		if !hasValue || value == nil {
+			delete(parent, name)
+			return nil
+		}
+		removed, _ := asValueList(value)
+		if removed == nil {
+			removed = []interface{}{value}
+		}
+		existingValues, ok := asValueList(existing)
+		if !ok {
+			existingValues = []interface{}{existing}
+		}
+		kept := make([]interface{}, 0, len(existingValues))
+		for _, v := range existingValues {
+			if !memoryValueIn(v, removed) {
+				kept = append(kept, v)
+			}
+		}
+		if len(kept) == 0 {
+			delete(parent, name)
+		} else {
+			parent[name] = kept
+		}
+	default:
+		return memoryErrorf(http.StatusBadRequest, "unsupported modificationType '%v'", itemDelta["modificationType"])
+	}
+	return nil
+}
+
+/*
+memoryPathStep descends one segment of an item path, creating missing
+
+	containers. With a containerID, it descends into the value of the
+	multi-valued container with that id instead.
+*/
+func memoryPathStep(parent map[string]interface{}, segment string, containerID string, path string) (map[string]interface{}, *memoryError) {
+	if containerID != "" {
+		values, _ := asValueList(parent[segment])
+		for _, value := range values {
+			if hash, ok := value.(map[string]interface{}); ok {
+				if id, ok := containerIDString(hash); ok && id == containerID {
+					return hash, nil
+				}
+			}
+		}
+		return nil, memoryErrorf(http.StatusNotFound, "no '%s' value with id %s in '%s'", segment, containerID, path)
+	}
+
+	switch next := parent[segment].(type) {
+	case map[string]interface{}:
+		return next, nil
+	case nil:
+		created := make(map[string]interface{})
+		parent[segment] = created
+		return created, nil
+	}
+	return nil, memoryErrorf(http.StatusBadRequest, "'%s' in '%s' is not a single container; select a value with a container id", segment, path)
+}
+
+/* isContainerIDSegment reports whether an item path segment such as [3] selects a container value */
+func isContainerIDSegment(segment string) bool {
+	return strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]")
+}
+
+/* memoryValueIn reports whether value is in values, matching container values by id */
+func memoryValueIn(value interface{}, values []interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+		a, aIsMap := value.(map[string]interface{})
+		b, bIsMap := v.(map[string]interface{})
+		if aIsMap && bIsMap {
+			aID, aOk := containerIDString(a)
+			bID, bOk := containerIDString(b)
+			if aOk && bOk && aID == bID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/* applyMemoryJSONPatch applies a single RFC 6902 add, replace or remove operation */
+func applyMemoryJSONPatch(object map[string]interface{}, op map[string]interface{}) *memoryError {
+	pointer := fmt.Sprint(op["path"])
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	var parent interface{} = object
+	for _, token := range tokens[:len(tokens)-1] {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			parent = p[token]
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(p) {
+				return memoryErrorf(http.StatusUnprocessableEntity, "invalid index '%s' in '%s'", token, pointer)
+			}
+			parent = p[index]
+		default:
+			return memoryErrorf(http.StatusUnprocessableEntity, "'%s' does not exist", pointer)
+		}
+	}
+
+	last := tokens[len(tokens)-1]
+	hash, ok := parent.(map[string]interface{})
+	if !ok {
+		return memoryErrorf(http.StatusUnprocessableEntity, "only object members can be patched in memory mode, not '%s'", pointer)
+	}
+	switch op["op"] {
+	case "add", "replace":
+		if _, exists := hash[last]; !exists && op["op"] == "replace" {
+			return memoryErrorf(http.StatusUnprocessableEntity, "cannot replace missing '%s'", pointer)
+		}
+		hash[last] = op["value"]
+	case "remove":
+		if _, exists := hash[last]; !exists {
+			return memoryErrorf(http.StatusUnprocessableEntity, "cannot remove missing '%s'", pointer)
+		}
+		delete(hash, last)
+	default:
+		return memoryErrorf(http.StatusUnprocessableEntity, "the '%v' operation is not supported in memory mode", op["op"])
+	}
+	return nil
+}
+
+/* applyMemoryMergePatch applies an RFC 7386 merge patch */
+func applyMemoryMergePatch(object map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		patchMap, patchIsMap := value.(map[string]interface{})
+		current, currentIsMap := object[key].(map[string]interface{})
+		if patchIsMap && currentIsMap {
+			object[key] = applyMemoryMergePatch(current, patchMap)
+		} else if patchIsMap {
+			object[key] = applyMemoryMergePatch(make(map[string]interface{}), patchMap)
+		} else {
+			object[key] = value
+		}
+	}
+	return object
+}
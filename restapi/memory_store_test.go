@@ -0,0 +1,125 @@
+package restapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMemoryStore(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", writeReturnsObject: true, mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("memory_store_test.go: Failed to create API client: %s", err)
+	}
+
+	newObject := func(data string, strategy string) *APIObject {
+		t.Helper()
+		obj, err := NewAPIObject(client, &apiObjectOpts{path: "/users", data: data, updateStrategy: strategy})
+		if err != nil {
+			t.Fatalf("memory_store_test.go: Failed to create API object: %s", err)
+		}
+		return obj
+	}
+
+	obj := newObject(`{"user": {"name": "jsmith", "assignment": [{"@id": 1, "targetRef": {"oid": "r1"}}, {"@id": 2, "targetRef": {"oid": "r2"}}]}}`, updateStrategyMidpointPatch)
+	if err := obj.createObject(); err != nil {
+		t.Fatalf("memory_store_test.go: Create failed: %s", err)
+	}
+	if obj.id != "00000000-0000-0000-0000-000000000001" {
+		t.Fatalf("memory_store_test.go: Expected a generated oid, got '%s'", obj.id)
+	}
+
+	/* midPoint itemDeltas below the wrapper key, including container values selected by id */
+	patch := `{"objectModification": {"itemDelta": [
+		{"modificationType": "replace", "path": "givenName", "value": "John"},
+		{"modificationType": "replace", "path": "assignment/[2]/description", "value": "second"},
+		{"modificationType": "delete", "path": "assignment", "value": {"@id": 1}},
+		{"modificationType": "add", "path": "assignment", "value": {"@id": 3, "targetRef": {"oid": "r3"}}}
+	]}}`
+	if _, err := client.sendRequest("PATCH", "/users/"+obj.id, patch); err != nil {
+		t.Fatalf("memory_store_test.go: Patch failed: %s", err)
+	}
+	if err := obj.readObject(); err != nil {
+		t.Fatalf("memory_store_test.go: Read failed: %s", err)
+	}
+	expected := MapAny{"user": MapAny{
+		"oid":       obj.id,
+		"name":      "jsmith",
+		"givenName": "John",
+		"assignment": []interface{}{
+			MapAny{"@id": json.Number("2"), "targetRef": MapAny{"oid": "r2"}, "description": "second"},
+			MapAny{"@id": json.Number("3"), "targetRef": MapAny{"oid": "r3"}},
+		},
+	}}
+	if !reflect.DeepEqual(expected, obj.apiData) {
+		t.Fatalf("memory_store_test.go: Unexpected object after the patch\nexpected: %v\n     got: %v", expected, obj.apiData)
+	}
+
+	/* The update strategies of the provider are answered too */
+	for _, strategy := range []string{updateStrategyMidpointPatch, updateStrategyJSONPatch, updateStrategyMergePatch, updateStrategyPut} {
+		updated := newObject(`{"user": {"oid": "`+obj.id+`", "name": "jsmith", "description": "`+strategy+`"}}`, strategy)
+		updated.id = obj.id
+		if err := updated.readObject(); err != nil {
+			t.Fatalf("memory_store_test.go: Read before the %s update failed: %s", strategy, err)
+		}
+		if err := updated.updateObject(); err != nil {
+			t.Fatalf("memory_store_test.go: %s update failed: %s", strategy, err)
+		}
+		if !reflect.DeepEqual(updated.apiData, updated.data) {
+			t.Fatalf("memory_store_test.go: Expected the %s update to leave the object as in data, got %v", strategy, updated.apiData)
+		}
+	}
+
+	other := newObject(`{"user": {"oid": "jdoe", "name": "jdoe", "emailAddress": "jsmith@example.com"}}`, "")
+	if err := other.createObject(); err != nil {
+		t.Fatalf("memory_store_test.go: Create failed: %s", err)
+	}
+	if err := other.createObject(); err == nil || !strings.Contains(err.Error(), "'409'") {
+		t.Fatalf("memory_store_test.go: Expected creating an existing oid to conflict, got %v", err)
+	}
+
+	/* Numbers are kept as written, through patches too */
+	patch = `{"objectModification": {"itemDelta": [{"modificationType": "replace", "path": "employeeNumber", "value": 12345678901234567890}]}}`
+	if _, err := client.sendRequest("PATCH", "/users/jdoe", patch); err != nil {
+		t.Fatalf("memory_store_test.go: Patch failed: %s", err)
+	}
+	if body, err := client.sendRequest("GET", "/users/jdoe", ""); err != nil || !strings.Contains(body, `"employeeNumber":12345678901234567890`) {
+		t.Fatalf("memory_store_test.go: Expected the number to be kept as written, got %s (%v)", body, err)
+	}
+
+	/* Searches page their results */
+	search := `{"query": {"paging": {"orderBy": "name", "offset": 1, "maxSize": 1}}}`
+	if body, err := client.sendRequest("POST", "/users/search", search); err != nil || !strings.Contains(body, `"jsmith"`) || strings.Contains(body, `"jdoe"`) {
+		t.Fatalf("memory_store_test.go: Expected the second user by name only, got %s (%v)", body, err)
+	}
+
+	/* Searches evaluate midPoint query filters */
+	d := schema.TestResourceDataRaw(t, dataSourceRestAPIConflicts().Schema, map[string]interface{}{
+		"path":        "/users",
+		"name":        "jsmith",
+		"unique_keys": map[string]interface{}{"emailAddress": "jsmith@example.com"},
+	})
+	if err := dataSourceRestAPIConflictsRead(d, client); err != nil {
+		t.Fatalf("memory_store_test.go: Search failed: %s", err)
+	}
+	if conflicts := d.Get("conflicts").([]interface{}); len(conflicts) != 2 {
+		t.Fatalf("memory_store_test.go: Expected both users to match the search, got %v", conflicts)
+	}
+
+	if err := obj.deleteObject(); err != nil {
+		t.Fatalf("memory_store_test.go: Delete failed: %s", err)
+	}
+	if err := obj.readObject(); err != nil {
+		t.Fatalf("memory_store_test.go: Read after the delete failed: %s", err)
+	}
+	if obj.id != "" {
+		t.Fatalf("memory_store_test.go: Expected the deleted object to be gone, got id '%s'", obj.id)
+	}
+
+	if _, err := NewAPIClient(&apiClientOpt{uri: "http://memory", mode: "disk"}); err == nil {
+		t.Fatalf("memory_store_test.go: Expected an unknown mode to fail")
+	}
+}
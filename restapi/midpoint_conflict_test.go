@@ -0,0 +1,89 @@
+package restapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPatchConflictRetry(t *testing.T) {
+	debug := false
+	conflictRetryDelay = 10 * time.Millisecond
+
+	testObjects := map[string]map[string]interface{}{
+		"conflict1": {
+			"Id":          "conflict1",
+			"name":        "jsmith",
+			"description": "Initial description",
+		},
+	}
+
+	svr := NewMidpointFakeServer(8086, testObjects, debug)
+	defer svr.Shutdown()
+
+	newObject := func(retries int) *APIObject {
+		client, err := NewAPIClient(&apiClientOpt{
+			uri:             "http://127.0.0.1:8086/",
+			timeout:         5,
+			idAttribute:     "Id",
+			updateMethod:    "PATCH",
+			conflictRetries: retries,
+			debug:           debug,
+		})
+		if err != nil {
+			t.Fatalf("midpoint_conflict_test.go: Failed to create API client: %s", err)
+		}
+
+		obj, err := NewAPIObject(client, &apiObjectOpts{
+			path:  "/api/objects",
+			id:    "conflict1",
+			debug: debug,
+		})
+		if err != nil {
+			t.Fatalf("midpoint_conflict_test.go: Failed to create API object: %s", err)
+		}
+		return obj
+	}
+
+	t.Run("Retries_Until_Success", func(t *testing.T) {
+		obj := newObject(3)
+		obj.data = map[string]interface{}{
+			"Id":          "conflict1",
+			"name":        "jsmith",
+			"description": "Updated after conflicts",
+		}
+
+		svr.conflicts = 2
+		svr.patchCount = 0
+		if err := obj.updateObject(); err != nil {
+			t.Fatalf("midpoint_conflict_test.go: Expected update to succeed after retries, got: %s", err)
+		}
+
+		if svr.patchCount != 3 {
+			t.Fatalf("midpoint_conflict_test.go: Expected 3 PATCH requests (2 conflicts + 1 success), got %d", svr.patchCount)
+		}
+		if svr.objects["conflict1"]["description"] != "Updated after conflicts" {
+			t.Fatalf("midpoint_conflict_test.go: Expected description to be updated, got '%v'", svr.objects["conflict1"]["description"])
+		}
+	})
+
+	t.Run("Gives_Up_After_Retries", func(t *testing.T) {
+		obj := newObject(1)
+		obj.data = map[string]interface{}{
+			"Id":          "conflict1",
+			"name":        "jsmith",
+			"description": "Never applied",
+		}
+
+		svr.conflicts = 5
+		svr.patchCount = 0
+		err := obj.updateObject()
+		if err == nil || !isConflictError(err) {
+			t.Fatalf("midpoint_conflict_test.go: Expected a conflict error, got: %v", err)
+		}
+
+		if svr.patchCount != 2 {
+			t.Fatalf("midpoint_conflict_test.go: Expected 2 PATCH requests (1 try + 1 retry), got %d", svr.patchCount)
+		}
+		svr.conflicts = 0
+	})
+}
@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+/* Statuses of an OperationResult that mean the operation failed */
+var failedOperationResults = []string{"fatal_error", "partial_error"}
+
+/*
+testMidpointResource runs midPoint's testResource operation on the resource at
+
+	objectPath, such as /resources/<oid>. midPoint answers it with an
+	OperationResult even when the connection, credentials or schema test
+	fails, so that result is turned into an error listing the failed steps.
+*/
+func testMidpointResource(client *APIClient, objectPath string, debug bool) error {
+	if debug {
+		log.Printf("midpoint_connection.go: Testing the resource at '%s'", objectPath)
+	}
+	resultString, err := client.sendRequest("POST", objectPath+"/test", "")
+	if err != nil {
+		return fmt.Errorf("failed to test the resource at '%s': %v", objectPath, err)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(resultString), &response); err != nil {
+		return fmt.Errorf("failed to parse the test result of the resource at '%s': %v", objectPath, err)
+	}
+	result, ok := response["object"].(map[string]interface{})
+	if !ok {
+		result = midpointObjectOf(response)
+	}
+
+	if status := scalarStringAt(result, "status"); containsString(failedOperationResults, status) {
+		return fmt.Errorf("the test of the resource at '%s' failed with %s:\n%s", objectPath, status, strings.Join(failedOperationSteps(result, ""), "\n"))
+	}
+	return nil
+}
+
+/* testAfterWrite tests the midPoint resource that was created or updated when test_on_apply is set */
+func (obj *APIObject) testAfterWrite() error {
+	if !obj.testOnApply || obj.id == "" {
+		return nil
+	}
+	return testMidpointResource(obj.apiClient, strings.Replace(obj.getPath, "{id}", obj.id, -1), obj.debug)
+}
+
+/* failedOperationSteps lists the failed operations of an OperationResult and its partial results, one per line */
+func failedOperationSteps(result map[string]interface{}, indent string) []string {
+	steps := make([]string, 0)
+	status := scalarStringAt(result, "status")
+	if !containsString(failedOperationResults, status) {
+		return steps
+	}
+
+	step := fmt.Sprintf("%s- %s: %s", indent, scalarStringAt(result, "operation"), status)
+	if message := scalarStringAt(result, "message"); message != "" {
+		step += ": " + message
+	}
+	steps = append(steps, step)
+
+	partials, _ := asValueList(result["partialResults"])
+	for _, partial := range partials {
+		if partial, ok := partial.(map[string]interface{}); ok {
+			steps = append(steps, failedOperationSteps(partial, indent+"  ")...)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,75 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestTestOnApply(t *testing.T) {
+	testResult := `{"@ns": "http://prism.evolveum.com/xml/ns/public/types-3", "object": {"@type": "c:OperationResultType", "operation": "testResource", "status": "success"}}`
+	tests := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/test") {
+			tests++
+			w.Write([]byte(testResult))
+			return
+		}
+		w.Write([]byte(`{"resource": {"oid": "ldap", "name": "LDAP"}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "resource/oid", writeReturnsObject: true})
+	if err != nil {
+		t.Fatalf("midpoint_connection_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":          "/resources",
+		"data":          `{"resource": {"oid": "ldap", "name": "LDAP"}}`,
+		"test_on_apply": true,
+	})
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("midpoint_connection_test.go: Create failed: %s", err)
+	}
+	if tests != 1 {
+		t.Fatalf("midpoint_connection_test.go: Expected the resource to be tested once, got %d", tests)
+	}
+
+	testResult = `{"object": {"operation": "testResource", "status": "fatal_error", "partialResults": [
+		{"operation": "testConnectorConnection", "status": "success"},
+		{"operation": "testConnection", "status": "fatal_error", "message": "Invalid credentials", "partialResults": [{"operation": "connect", "status": "fatal_error", "message": "LDAP: error code 49"}]},
+		{"operation": "testSchema", "status": "not_applicable"}]}}`
+	err = resourceRestAPIUpdate(d, client)
+	expected := "the test of the resource at '/resources/ldap' failed with fatal_error:\n- testResource: fatal_error\n  - testConnection: fatal_error: Invalid credentials\n    - connect: fatal_error: LDAP: error code 49"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("midpoint_connection_test.go: Expected the update to fail with\n%s\ngot\n%v", expected, err)
+	}
+	if tests != 2 {
+		t.Fatalf("midpoint_connection_test.go: Expected the resource to be tested twice, got %d", tests)
+	}
+
+	/* Without test_on_apply, nothing is tested */
+	d.Set("test_on_apply", false)
+	if err := resourceRestAPIUpdate(d, client); err != nil || tests != 2 {
+		t.Fatalf("midpoint_connection_test.go: Expected an untested update, got %v after %d tests", err, tests)
+	}
+}
+
+func TestTestMidpointResourceMemory(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "resource/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("midpoint_connection_test.go: Failed to create API client: %s", err)
+	}
+	client.sendRequest("POST", "/resources", `{"resource": {"oid": "ldap", "name": "LDAP"}}`)
+
+	if err := testMidpointResource(client, "/resources/ldap", false); err != nil {
+		t.Fatalf("midpoint_connection_test.go: Expected the test to succeed in memory, got %s", err)
+	}
+	if err := testMidpointResource(client, "/resources/missing", false); err == nil || !strings.Contains(err.Error(), "failed to test the resource at '/resources/missing'") {
+		t.Fatalf("midpoint_connection_test.go: Expected testing a missing resource to fail, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package restapi
+
+import (
+	"strings"
+)
+
+/*
+describeErrorBody returns the body of a failed response for its error. When it
+
+	is a midPoint operation result, its status and message are returned with
+	the failed steps of its partial results, one per line, instead of the
+	whole JSON document.
+*/
+func describeErrorBody(body string) string {
+	result := operationResultOf(body)
+	status := scalarStringAt(result, "status")
+	message := scalarStringAt(result, "message")
+	if status == "" || message == "" && scalarStringAt(result, "operation") == "" {
+		return body
+	}
+
+	description := status
+	if operation := scalarStringAt(result, "operation"); operation != "" {
+		description = operation + ": " + status
+	}
+	if message != "" {
+		description += ": " + message
+	}
+	steps := make([]string, 0)
+	partials, _ := asValueList(result["partialResults"])
+	for _, partial := range partials {
+		if partial, ok := partial.(map[string]interface{}); ok {
+			steps = append(steps, failedOperationSteps(partial, "  ")...)
+		}
+	}
+	if len(steps) > 0 {
+		description += "\n" + strings.Join(steps, "\n")
+	}
+	return description
+}
@@ -0,0 +1,44 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDescribeErrorBody(t *testing.T) {
+	body := `{"@ns": "http://prism.evolveum.com/xml/ns/public/types-3", "object": {"@type": "c:OperationResultType", "operation": "executeChanges", "status": "fatal_error", "message": "Object of type 'UserType' with name 'jdoe' already exists",
+		"partialResults": [
+			{"operation": "preprocessChanges", "status": "success"},
+			{"operation": "checkConflicts", "status": "fatal_error", "message": "Name 'jdoe' is not unique",
+				"partialResults": [{"operation": "searchObjects", "status": "partial_error", "message": "Repository timeout"}]}
+		]}}`
+	expected := "executeChanges: fatal_error: Object of type 'UserType' with name 'jdoe' already exists\n" +
+		"  - checkConflicts: fatal_error: Name 'jdoe' is not unique\n" +
+		"    - searchObjects: partial_error: Repository timeout"
+	if description := describeErrorBody(body); description != expected {
+		t.Fatalf("midpoint_error_test.go: Expected the operation result summarized, got:\n%s", description)
+	}
+
+	/* Other bodies are kept as they are */
+	for _, other := range []string{`{"error": "not found"}`, "Internal Server Error", ""} {
+		if description := describeErrorBody(other); description != other {
+			t.Fatalf("midpoint_error_test.go: Expected '%s' unchanged, got '%s'", other, description)
+		}
+	}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(body))
+	}))
+	defer svr.Close()
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("midpoint_error_test.go: Failed to create API client: %s", err)
+	}
+	_, err = client.sendRequest("POST", "/users", `{"user": {"name": "jdoe"}}`)
+	if err == nil || !isConflictError(err) || !strings.HasSuffix(err.Error(), ": "+expected) {
+		t.Fatalf("midpoint_error_test.go: Expected the summarized result after the response code, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package restapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 )
 
@@ -268,4 +269,87 @@ func TestPatchMidpointObject(t *testing.T) {
 				updatedObj["attribute1"])
 		}
 	})
-}
\ No newline at end of file
+}
+func TestPatchMidpointObjectSingleRequest(t *testing.T) {
+	testObjects := map[string]map[string]interface{}{
+		"test3": {
+			"Id":          "test3",
+			"name":        "testname",
+			"description": "test description",
+			"attribute1":  "value1",
+		},
+	}
+
+	svr := NewMidpointFakeServer(8087, testObjects, false)
+	defer svr.Shutdown()
+
+	desired := map[string]interface{}{
+		"Id":         "test3",
+		"name":       "updated name",
+		"newField":   "new value",
+		"attribute1": "value1",
+	}
+
+	for _, perDelta := range []bool{false, true} {
+		client, err := NewAPIClient(&apiClientOpt{
+			uri:                   "http://127.0.0.1:8087/",
+			timeout:               5,
+			idAttribute:           "Id",
+			updateMethod:          "PATCH",
+			midpointPatchPerDelta: perDelta,
+		})
+		if err != nil {
+			t.Fatalf("midpoint_patch_internal_test.go: Failed to create API client: %s", err)
+		}
+
+		obj, err := NewAPIObject(client, &apiObjectOpts{path: "/api/objects", id: "test3"})
+		if err != nil {
+			t.Fatalf("midpoint_patch_internal_test.go: Failed to create API object: %s", err)
+		}
+
+		svr.objects["test3"] = map[string]interface{}{
+			"Id":          "test3",
+			"name":        "testname",
+			"description": "test description",
+			"attribute1":  "value1",
+		}
+		if err := obj.readObject(); err != nil {
+			t.Fatalf("midpoint_patch_internal_test.go: Failed to read object: %s", err)
+		}
+		obj.data = desired
+
+		svr.patchCount = 0
+		if err := obj.patchMidpointObject(); err != nil {
+			t.Fatalf("midpoint_patch_internal_test.go: patchMidpointObject failed (per delta: %t): %s", perDelta, err)
+		}
+
+		expectedRequests := 1
+		if perDelta {
+			expectedRequests = 3
+		}
+		if svr.patchCount != expectedRequests {
+			t.Fatalf("midpoint_patch_internal_test.go: Expected %d PATCH request(s) (per delta: %t), got %d", expectedRequests, perDelta, svr.patchCount)
+		}
+
+		if !perDelta {
+			deltas := lastItemDeltas(t, svr.lastBody)
+			if len(deltas) != 3 {
+				t.Fatalf("midpoint_patch_internal_test.go: Expected 3 itemDelta elements in a single request, got %d", len(deltas))
+			}
+			expected := []string{"replace name", "add newField", "delete description"}
+			for i, delta := range deltas {
+				if got := fmt.Sprintf("%v %v", delta["modificationType"], delta["path"]); got != expected[i] {
+					t.Fatalf("midpoint_patch_internal_test.go: Expected itemDelta %d to be '%s', got '%s'", i, expected[i], got)
+				}
+			}
+		}
+
+		updatedObj := svr.objects["test3"]
+		if updatedObj["name"] != "updated name" || updatedObj["newField"] != "new value" {
+			t.Fatalf("midpoint_patch_internal_test.go: Object not updated correctly (per delta: %t): %v", perDelta, updatedObj)
+		}
+		if _, exists := updatedObj["description"]; exists {
+			t.Fatalf("midpoint_patch_internal_test.go: Expected description to be deleted (per delta: %t)", perDelta)
+		}
+	}
+}
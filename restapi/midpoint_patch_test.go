@@ -19,6 +19,11 @@ type midpointFakeServer struct {
 	running     bool
 	lastRequest *http.Request
 	lastBody    []byte
+
+	// Number of upcoming PATCH requests to reject with 409 Conflict
+	conflicts int
+	// Number of PATCH requests received
+	patchCount int
 }
 
 func NewMidpointFakeServer(port int, objects map[string]map[string]interface{}, debug bool) *midpointFakeServer {
@@ -114,6 +119,13 @@ func (svr *midpointFakeServer) handleAPIObject(w http.ResponseWriter, r *http.Re
 	}
 	
 	if r.Method == "PATCH" && len(b) > 0 {
+		svr.patchCount++
+		if svr.conflicts > 0 {
+			svr.conflicts--
+			http.Error(w, "concurrent modification", http.StatusConflict)
+			return
+		}
+
 		// Handle Midpoint PATCH request with ObjectModificationType
 		// Format: { "objectModification": { "itemDelta": { "modificationType": "...", "path": "...", "value": ... } } }
 		var patchRequest map[string]interface{}
@@ -131,55 +143,66 @@ func (svr *midpointFakeServer) handleAPIObject(w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		// Extract itemDelta object
-		itemDelta, ok := objectMod["itemDelta"].(map[string]interface{})
-		if !ok {
+		// itemDelta is either a single object or a list of them
+		var itemDeltas []interface{}
+		switch v := objectMod["itemDelta"].(type) {
+		case map[string]interface{}:
+			itemDeltas = []interface{}{v}
+		case []interface{}:
+			itemDeltas = v
+		default:
 			http.Error(w, "Missing itemDelta in request", http.StatusBadRequest)
 			return
 		}
-		
-		// Process the patch according to modificationType
-		modType, ok := itemDelta["modificationType"].(string)
-		if !ok {
-			http.Error(w, "Missing modificationType in request", http.StatusBadRequest)
-			return
-		}
-		
-		path, ok := itemDelta["path"].(string)
-		if !ok {
-			http.Error(w, "Missing path in request", http.StatusBadRequest)
-			return
+
+		// Apply to a copy so a bad delta leaves the object untouched, like midPoint does
+		updated := make(map[string]interface{})
+		for k, v := range obj {
+			updated[k] = v
 		}
-		
-		switch modType {
-		case "add":
-			value, exists := itemDelta["value"]
-			if !exists {
-				http.Error(w, "Missing value for add operation", http.StatusBadRequest)
+
+		for _, rawDelta := range itemDeltas {
+			itemDelta, ok := rawDelta.(map[string]interface{})
+			if !ok {
+				http.Error(w, "Invalid itemDelta in request", http.StatusBadRequest)
 				return
 			}
-			obj[path] = value
-			
-		case "delete":
-			delete(obj, path)
-			if svr.debug {
-				log.Printf("midpoint_patch_test.go: Deleted attribute '%s'", path)
-				log.Printf("midpoint_patch_test.go: Object after deletion: %v", obj)
+
+			// Process the patch according to modificationType
+			modType, ok := itemDelta["modificationType"].(string)
+			if !ok {
+				http.Error(w, "Missing modificationType in request", http.StatusBadRequest)
+				return
 			}
-			
-		case "replace":
-			value, exists := itemDelta["value"]
-			if !exists {
-				http.Error(w, "Missing value for replace operation", http.StatusBadRequest)
+
+			path, ok := itemDelta["path"].(string)
+			if !ok {
+				http.Error(w, "Missing path in request", http.StatusBadRequest)
+				return
+			}
+
+			switch modType {
+			case "add", "replace":
+				value, exists := itemDelta["value"]
+				if !exists {
+					http.Error(w, fmt.Sprintf("Missing value for %s operation", modType), http.StatusBadRequest)
+					return
+				}
+				updated[path] = value
+
+			case "delete":
+				delete(updated, path)
+				if svr.debug {
+					log.Printf("midpoint_patch_test.go: Deleted attribute '%s'", path)
+				}
+
+			default:
+				http.Error(w, fmt.Sprintf("Unsupported modificationType: %s", modType), http.StatusBadRequest)
 				return
 			}
-			obj[path] = value
-			
-		default:
-			http.Error(w, fmt.Sprintf("Unsupported modificationType: %s", modType), http.StatusBadRequest)
-			return
 		}
-		
+		obj = updated
+
 		// Save changes
 		svr.objects[id] = obj
 		
@@ -226,6 +249,32 @@ func (svr *midpointFakeServer) handleAPIObject(w http.ResponseWriter, r *http.Re
 	w.Write(respBody)
 }
 
+// lastItemDeltas returns the itemDelta elements of the last PATCH body, whether sent as a single object or a list
+func lastItemDeltas(t *testing.T, body []byte) []map[string]interface{} {
+	var patchReq map[string]interface{}
+	if err := json.Unmarshal(body, &patchReq); err != nil {
+		t.Fatalf("midpoint_patch_test.go: Failed to unmarshal PATCH request body: %s", err)
+	}
+
+	objectMod, ok := patchReq["objectModification"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("midpoint_patch_test.go: Missing objectModification in PATCH request")
+	}
+
+	result := make([]map[string]interface{}, 0)
+	switch v := objectMod["itemDelta"].(type) {
+	case map[string]interface{}:
+		result = append(result, v)
+	case []interface{}:
+		for _, d := range v {
+			result = append(result, d.(map[string]interface{}))
+		}
+	default:
+		t.Fatalf("midpoint_patch_test.go: Missing itemDelta in PATCH request")
+	}
+	return result
+}
+
 func TestMidpointPatchIntegration(t *testing.T) {
 	// Initialize test data
 	testObject := map[string]interface{}{
@@ -303,22 +352,12 @@ func TestMidpointPatchIntegration(t *testing.T) {
 			t.Fatalf("midpoint_patch_test.go: Expected PATCH request, got %s", svr.lastRequest.Method)
 		}
 		
-		var patchReq map[string]interface{}
-		err = json.Unmarshal(svr.lastBody, &patchReq)
-		if err != nil {
-			t.Fatalf("midpoint_patch_test.go: Failed to unmarshal PATCH request body: %s", err)
-		}
-		
-		// Verify the ObjectModificationType structure
-		objectMod, ok := patchReq["objectModification"].(map[string]interface{})
-		if !ok {
-			t.Fatalf("midpoint_patch_test.go: Missing objectModification in PATCH request")
-		}
-
-		itemDelta, ok := objectMod["itemDelta"].(map[string]interface{})
-		if !ok {
-			t.Fatalf("midpoint_patch_test.go: Missing itemDelta in PATCH request")
+		// All changes of an update are sent in a single ObjectModificationType
+		deltas := lastItemDeltas(t, svr.lastBody)
+		if len(deltas) != 1 {
+			t.Fatalf("midpoint_patch_test.go: Expected 1 itemDelta, got %d", len(deltas))
 		}
+		itemDelta := deltas[0]
 		
 		if itemDelta["modificationType"] != "add" {
 			t.Fatalf("midpoint_patch_test.go: Expected modificationType='add', got '%v'", 
@@ -358,22 +397,12 @@ func TestMidpointPatchIntegration(t *testing.T) {
 			t.Fatalf("midpoint_patch_test.go: Expected PATCH request, got %s", svr.lastRequest.Method)
 		}
 		
-		var patchReq map[string]interface{}
-		err = json.Unmarshal(svr.lastBody, &patchReq)
-		if err != nil {
-			t.Fatalf("midpoint_patch_test.go: Failed to unmarshal PATCH request body: %s", err)
-		}
-		
-		// Verify the ObjectModificationType structure
-		objectMod, ok := patchReq["objectModification"].(map[string]interface{})
-		if !ok {
-			t.Fatalf("midpoint_patch_test.go: Missing objectModification in PATCH request")
-		}
-
-		itemDelta, ok := objectMod["itemDelta"].(map[string]interface{})
-		if !ok {
-			t.Fatalf("midpoint_patch_test.go: Missing itemDelta in PATCH request")
+		// All changes of an update are sent in a single ObjectModificationType
+		deltas := lastItemDeltas(t, svr.lastBody)
+		if len(deltas) != 1 {
+			t.Fatalf("midpoint_patch_test.go: Expected 1 itemDelta, got %d", len(deltas))
 		}
+		itemDelta := deltas[0]
 		
 		if itemDelta["modificationType"] != "replace" {
 			t.Fatalf("midpoint_patch_test.go: Expected modificationType='replace', got '%v'", 
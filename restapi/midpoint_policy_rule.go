@@ -0,0 +1,100 @@
+package restapi
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/*
+policyRuleSchema returns the attributes of a midPoint policy rule. The
+
+	constraints and actions have too many forms to type, so they are given
+	as JSON in midPoint's own representation.
+*/
+func policyRuleSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"description": {
+			Type:        schema.TypeString,
+			Description: "The description of the policy rule.",
+			Optional:    true,
+		},
+		"policy_constraints": {
+			Type:             schema.TypeString,
+			Description:      "The policyConstraints of the rule as JSON, such as `jsonencode({ modification = [{ name = \"modified\" }] })`.",
+			Required:         true,
+			ValidateFunc:     validation.StringIsJSON,
+			DiffSuppressFunc: suppressEquivalentJSON,
+		},
+		"policy_actions": {
+			Type:             schema.TypeString,
+			Description:      "The policyActions of the rule as JSON, such as `jsonencode({ record = {} })`. Not set means the rule only marks the objects it applies to.",
+			Optional:         true,
+			ValidateFunc:     validation.StringIsJSON,
+			DiffSuppressFunc: suppressEquivalentJSON,
+		},
+	}
+}
+
+/* buildPolicyRule returns the policy rule container of the attributes in block */
+func buildPolicyRule(block map[string]interface{}) map[string]interface{} {
+	rule := map[string]interface{}{"name": block["name"]}
+	setIfNotEmpty(rule, "description", block["description"].(string))
+	for attribute, key := range map[string]string{"policy_constraints": "policyConstraints", "policy_actions": "policyActions"} {
+		var value interface{}
+		if err := json.Unmarshal([]byte(block[attribute].(string)), &value); err == nil && value != nil {
+			rule[key] = value
+		}
+	}
+	return rule
+}
+
+/* flattenPolicyRule returns the attributes of a policy rule container read from the server */
+func flattenPolicyRule(rule map[string]interface{}) map[string]interface{} {
+	block := map[string]interface{}{
+		"name":               scalarStringAt(rule, "name"),
+		"description":        scalarStringAt(rule, "description"),
+		"policy_constraints": "",
+		"policy_actions":     "",
+	}
+	for attribute, key := range map[string]string{"policy_constraints": "policyConstraints", "policy_actions": "policyActions"} {
+		if value, ok := rule[key]; ok {
+			/* The ids midPoint gives the containers are not part of the configuration */
+			b, _ := json.Marshal(withoutContainerIDs(value))
+			block[attribute] = string(b)
+		}
+	}
+	return block
+}
+
+/* withoutContainerIDs returns value without the container ids at any level */
+func withoutContainerIDs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		stripped := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			if indexOf(containerIDKeys, key) < 0 {
+				stripped[key] = withoutContainerIDs(elem)
+			}
+		}
+		return stripped
+	case []interface{}:
+		stripped := make([]interface{}, len(v))
+		for i, elem := range v {
+			stripped[i] = withoutContainerIDs(elem)
+		}
+		return stripped
+	}
+	return value
+}
+
+/* suppressEquivalentJSON suppresses diffs between JSON documents that only differ in formatting */
+func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	var oldValue, newValue interface{}
+	if json.Unmarshal([]byte(old), &oldValue) != nil || json.Unmarshal([]byte(new), &newValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldValue, newValue)
+}
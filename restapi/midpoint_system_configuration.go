@@ -0,0 +1,123 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* The oid of midPoint's system configuration */
+const midpointSystemConfigurationOID = "00000000-0000-0000-0000-000000000001"
+
+/*
+systemConfigurationAttributes adds to attributes the ones shared by resources
+
+	that manage a single named container value of the system configuration,
+	such as a global policy rule.
+*/
+func systemConfigurationAttributes(attributes map[string]*schema.Schema, what string) map[string]*schema.Schema {
+	attributes["system_configuration_path"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "Defaults to `/systemConfigurations`. The API path of the system configuration objects.",
+		Optional:    true,
+		Default:     "/systemConfigurations",
+		ForceNew:    true,
+	}
+	attributes["system_configuration_oid"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: fmt.Sprintf("Defaults to `%s`. The oid of the system configuration.", midpointSystemConfigurationOID),
+		Optional:    true,
+		Default:     midpointSystemConfigurationOID,
+		ForceNew:    true,
+	}
+	attributes["container_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: fmt.Sprintf("The container id (@id) midPoint gave the %s.", what),
+		Computed:    true,
+	}
+	attributes["debug"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Whether to emit verbose debug output while working with the object on the server.",
+		Optional:    true,
+	}
+	return attributes
+}
+
+/* midpointSystemConfiguration returns the APIObject of the system configuration */
+func midpointSystemConfiguration(d *schema.ResourceData, meta interface{}) (*APIObject, error) {
+	return NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:  d.Get("system_configuration_path").(string),
+		id:    d.Get("system_configuration_oid").(string),
+		debug: d.Get("debug").(bool),
+	})
+}
+
+/*
+findNamedValue returns the value of the container at the '/'-delimited path of
+
+	the object read into obj whose name is name, or nil when there is none.
+*/
+func findNamedValue(obj *APIObject, path string, name string) map[string]interface{} {
+	container, err := GetObjectAtKey(midpointObjectOf(obj.apiData), path, false)
+	if err != nil {
+		return nil
+	}
+	values, _ := asValueList(container)
+	for _, value := range values {
+		if hash, ok := value.(map[string]interface{}); ok && scalarStringAt(hash, "name") == name {
+			return hash
+		}
+	}
+	return nil
+}
+
+/* itemChanged reports whether an item differs from its current value, apart from the form and ids the server gives it */
+func itemChanged(desired interface{}, current interface{}) bool {
+	/* Compare the values as they are sent, so that numbers are alike */
+	var sent interface{}
+	b, _ := json.Marshal(desired)
+	json.Unmarshal(b, &sent)
+	return !reflect.DeepEqual(withoutContainerIDs(alignToServer(sent, current)), withoutContainerIDs(current))
+}
+
+/*
+itemDeltasOf returns the itemDeltas that give the items at keys of the
+
+	container value at valuePath their desired value, replacing the changed
+	ones and deleting the ones no longer desired.
+*/
+func itemDeltasOf(obj *APIObject, valuePath string, keys []string, current map[string]interface{}, desired map[string]interface{}) []interface{} {
+	itemDeltas := make([]interface{}, 0)
+	for _, key := range keys {
+		path := valuePath + "/" + key
+		value, wanted := desired[key]
+		currentValue, present := current[key]
+		switch {
+		case wanted && (!present || itemChanged(value, currentValue)):
+			itemDeltas = append(itemDeltas, obj.buildItemDelta("replace", path, value))
+		case !wanted && present:
+			itemDeltas = append(itemDeltas, obj.buildItemDelta("delete", path, nil))
+		}
+	}
+	return itemDeltas
+}
+
+/* containerValuePath returns the path of a container value by its id, such as globalPolicyRule/[3] */
+func containerValuePath(container string, value map[string]interface{}) (string, bool) {
+	id, ok := containerIDString(value)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s/[%s]", strings.Trim(container, "/"), id), true
+}
+
+/* containerValueToDelete returns what identifies a container value in a delete: its id, or else the whole value */
+func containerValueToDelete(value map[string]interface{}) map[string]interface{} {
+	if idKey, idValue, ok := containerIDOf(value); ok {
+		return map[string]interface{}{idKey: idValue}
+	}
+	return value
+}
@@ -0,0 +1,453 @@
+package restapi
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/*
+midpointType describes a typed midPoint resource, such as restapi_midpoint_user.
+
+	The resource builds the object's JSON from its attributes and reuses the
+	midpoint-patch delta engine of restapi_object for updates, managing only
+	the items it has attributes for.
+*/
+type midpointType struct {
+	wrapper       string            /* The key wrapping the object, such as "user" */
+	path          string            /* The default API path, such as "/users" */
+	managedFields []string          /* The items the attributes set, relative to the wrapper */
+	listMergeKeys map[string]string /* Multi-value containers matched by a key, see list_merge_keys */
+
+	/* keepValue reports current values of a listMergeKeys container the attributes do not manage; nil keeps those without the key */
+	keepValue func(container string, value map[string]interface{}) bool
+
+	build   func(d *schema.ResourceData) map[string]interface{}
+	flatten func(d *schema.ResourceData, object map[string]interface{}) error
+}
+
+/* schema adds the attributes shared by typed midPoint resources to attributes */
+func (t *midpointType) schema(attributes map[string]*schema.Schema) map[string]*schema.Schema {
+	attributes["path"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: fmt.Sprintf("Defaults to `%s`. The API path of the objects of this type on the midPoint server.", t.path),
+		Optional:    true,
+		Default:     t.path,
+		ForceNew:    true,
+	}
+	attributes["oid"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The oid of the object. A random oid is generated when not set.",
+		Optional:    true,
+		Computed:    true,
+		ForceNew:    true,
+	}
+	attributes["last_applied_deltas"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "The midPoint itemDeltas the last update sent, as JSON.",
+		Computed:    true,
+	}
+	attributes["recompute_after_write"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Whether to have midPoint recompute the object after it is created or updated, so its projections are refreshed right away.",
+		Optional:    true,
+	}
+	attributes["debug"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Description: "Whether to emit verbose debug output while working with the object on the server.",
+		Optional:    true,
+	}
+	return attributes
+}
+
+/* resource returns the Terraform resource of the type with its attributes */
+func (t *midpointType) resource(description string, attributes map[string]*schema.Schema) *schema.Resource {
+	return &schema.Resource{
+		Create:      t.create,
+		Read:        t.read,
+		Update:      t.update,
+		Delete:      t.delete,
+		Description: description,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: t.schema(attributes),
+	}
+}
+
+/* object returns the APIObject for the resource, with data built from its attributes */
+func (t *midpointType) object(d *schema.ResourceData, meta interface{}, oid string) (*APIObject, error) {
+	inner := t.build(d)
+	inner["oid"] = oid
+	data, err := json.Marshal(map[string]interface{}{t.wrapper: inner})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:           d.Get("path").(string),
+		id:             oid,
+		idAttribute:    t.wrapper + "/oid",
+		updateStrategy: updateStrategyMidpointPatch,
+		listMergeKeys:  t.listMergeKeys,
+		pathNamespaces: midpointTypedNamespaces(d),
+		recompute:      d.Get("recompute_after_write").(bool),
+		debug:          d.Get("debug").(bool),
+		data:           string(data),
+	})
+}
+
+/* managedPaths restricts the delta engine to the items the attributes set */
+func (t *midpointType) managedPaths() []string {
+	fields := make([]string, 0, len(t.managedFields)+1)
+	fields = append(fields, t.wrapper+".oid")
+	for _, field := range t.managedFields {
+		fields = append(fields, t.wrapper+"."+field)
+	}
+	return fields
+}
+
+func (t *midpointType) create(d *schema.ResourceData, meta interface{}) error {
+	oid := d.Get("oid").(string)
+	if oid == "" {
+		var err error
+		if oid, err = generateOID(); err != nil {
+			return err
+		}
+	}
+
+	obj, err := t.object(d, meta, oid)
+	if err != nil {
+		return err
+	}
+	if obj.debug {
+		log.Printf("midpoint_typed.go: Create routine called. Object built:\n%s\n", obj.toString())
+	}
+	if err := obj.createObject(); err != nil {
+		return err
+	}
+
+	d.SetId(oid)
+	d.Set("last_applied_deltas", make([]string, 0))
+	if err := obj.recomputeAfterWrite(); err != nil {
+		return err
+	}
+	return t.read(d, meta)
+}
+
+func (t *midpointType) read(d *schema.ResourceData, meta interface{}) error {
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:        d.Get("path").(string),
+		id:          d.Id(),
+		idAttribute: t.wrapper + "/oid",
+		debug:       d.Get("debug").(bool),
+	})
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+	if obj.id == "" {
+		/* The object is gone */
+		d.SetId("")
+		return nil
+	}
+
+	object, ok := obj.apiData[t.wrapper].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the object read from '%s' has no '%s' key; is path pointing to objects of this type?", obj.getPath, t.wrapper)
+	}
+	d.Set("oid", d.Id())
+	return t.flatten(d, object)
+}
+
+func (t *midpointType) update(d *schema.ResourceData, meta interface{}) error {
+	obj, err := t.object(d, meta, d.Id())
+	if err != nil {
+		return err
+	}
+	obj.managedFields = t.managedPaths()
+
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+	if current, ok := obj.apiData[t.wrapper].(map[string]interface{}); ok {
+		/* Values written differently by the server (such as "c:RoleType") are not changes */
+		desired := alignToServer(obj.data[t.wrapper], current).(map[string]interface{})
+		/* Container values the attributes cannot express, such as policy rules, are kept */
+		for key, mergeKey := range t.listMergeKeys {
+			if values := withUnmanagedValues(desired[key], current[key], t.unmanaged(key, mergeKey)); len(values) > 0 {
+				desired[key] = values
+			}
+		}
+		obj.data[t.wrapper] = desired
+	}
+	if obj.debug {
+		log.Printf("midpoint_typed.go: Update routine called. Object built:\n%s\n", obj.toString())
+	}
+
+	if err := obj.updateObject(); err != nil {
+		return err
+	}
+	d.Set("last_applied_deltas", obj.appliedDeltas)
+	if err := obj.recomputeAfterWrite(); err != nil {
+		return err
+	}
+	return t.read(d, meta)
+}
+
+func (t *midpointType) delete(d *schema.ResourceData, meta interface{}) error {
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:        d.Get("path").(string),
+		id:          d.Id(),
+		idAttribute: t.wrapper + "/oid",
+		debug:       d.Get("debug").(bool),
+	})
+	if err != nil {
+		return err
+	}
+	if err := obj.deleteObject(); err != nil && !strings.Contains(err.Error(), "404") {
+		return err
+	}
+	return nil
+}
+
+/* unmanaged returns whether a current value of the container is kept on updates */
+func (t *midpointType) unmanaged(container string, mergeKey string) func(map[string]interface{}) bool {
+	return func(value map[string]interface{}) bool {
+		if _, err := GetObjectAtKey(value, mergeKey, false); err != nil {
+			return true
+		}
+		return t.keepValue != nil && t.keepValue(container, value)
+	}
+}
+
+/* buildArchetypeAssignments returns the assignments that give an object the archetypes with the oids */
+func buildArchetypeAssignments(oids []interface{}) []interface{} {
+	assignments := make([]interface{}, 0, len(oids))
+	for _, oid := range oids {
+		assignments = append(assignments, map[string]interface{}{"targetRef": map[string]interface{}{"oid": oid, "type": "ArchetypeType"}})
+	}
+	return assignments
+}
+
+/* flattenArchetypeOids returns the oids of the archetypes assigned by the assignments in value */
+func flattenArchetypeOids(value interface{}) []string {
+	oids := make([]string, 0)
+	for _, block := range flattenTargetRefs(value, func(targetRef map[string]interface{}) bool {
+		return refQName(stringAtKey(targetRef, "type")) == "ArchetypeType"
+	}) {
+		oids = append(oids, block.(map[string]interface{})["target_oid"].(string))
+	}
+	return oids
+}
+
+/* keepNonArchetypeAssignment keeps the assignments that are not to archetypes when the attributes only set archetypes */
+func keepNonArchetypeAssignment(container string, value map[string]interface{}) bool {
+	return container == "assignment" && refQName(stringAtKey(value, "targetRef/type")) != "ArchetypeType"
+}
+
+/*
+midpointObjectOf returns the object in data read from midPoint, below the
+
+	single key midPoint wraps it in, such as user.
+*/
+func midpointObjectOf(data map[string]interface{}) map[string]interface{} {
+	if len(data) == 1 {
+		for _, inner := range data {
+			if innerMap, ok := inner.(map[string]interface{}); ok {
+				return innerMap
+			}
+		}
+	}
+	return data
+}
+
+/* midpointTypedNamespaces declares the prefix of the extension namespace, if the resource has one */
+func midpointTypedNamespaces(d *schema.ResourceData) map[string]string {
+	if ns, ok := d.GetOk("extension_namespace"); ok {
+		return map[string]string{"ext": ns.(string)}
+	}
+	return nil
+}
+
+/* generateOID returns a random (version 4) UUID, the form of midPoint oids */
+func generateOID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+/*
+alignToServer returns desired with the values that current holds in another
+
+	form replaced by the form of current: PolyStrings whose orig is the
+	desired string, timestamps of the same instant, scalars with the same text,
+	references to the same object with qualified types or the default
+	relation, and containers that only lack their ids. The delta engine then
+	only sees the real changes.
+*/
+func alignToServer(desired interface{}, current interface{}) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return desired
+		}
+		aligned := make(map[string]interface{}, len(d))
+		for key, value := range d {
+			aligned[key] = alignToServer(value, c[key])
+		}
+		/* References to the same object, or object selectors without one */
+		if d["oid"] == c["oid"] {
+			for _, key := range []string{"type", "relation"} {
+				if _, ok := c[key]; ok && refQName(fmt.Sprintf("%v", d[key])) == refQName(fmt.Sprintf("%v", c[key])) {
+					aligned[key] = c[key]
+				}
+			}
+		}
+		/* A value equal apart from the ids midPoint gave it is the value on the server */
+		if _, hasID := containerIDString(c); hasID && !listElementChanged(c, aligned, containerIDKeys) {
+			return current
+		}
+		return aligned
+	case []interface{}:
+		c, ok := asValueList(current)
+		if !ok {
+			/* A single value may be written without the list */
+			if len(d) == 1 && current != nil && alignToServer(d[0], current) == current {
+				return current
+			}
+			return desired
+		}
+		aligned := make([]interface{}, len(d))
+		for i, value := range d {
+			aligned[i] = alignToServer(value, correspondingValue(value, i, c))
+		}
+		return aligned
+	case string:
+		switch c := current.(type) {
+		case map[string]interface{}:
+			if polyStringValue(c) == d {
+				return current
+			}
+		case string:
+			if sameInstant(d, c) {
+				return current
+			}
+		case float64, json.Number, bool:
+			if fmt.Sprintf("%v", c) == d {
+				return current
+			}
+		}
+	}
+	return desired
+}
+
+/* withUnmanagedValues returns the desired values followed by the current values unmanaged reports */
+func withUnmanagedValues(desired interface{}, current interface{}, unmanaged func(map[string]interface{}) bool) []interface{} {
+	values, _ := asValueList(desired)
+	values = append([]interface{}{}, values...)
+	currentValues, _ := asValueList(current)
+	for _, value := range currentValues {
+		hash, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if unmanaged(hash) {
+			values = append(values, hash)
+		}
+	}
+	return values
+}
+
+/*
+correspondingValue returns the current value matching the desired value at
+
+	index by its reference, or the one at the same index when it has none.
+	Aligning to a value that does not correspond changes nothing, as only
+	equivalent forms are taken from it.
+*/
+func correspondingValue(value interface{}, index int, current []interface{}) interface{} {
+	oid := refOidOf(value)
+	if oid == "" {
+		if index < len(current) {
+			return current[index]
+		}
+		return nil
+	}
+	for _, candidate := range current {
+		if refOidOf(candidate) == oid {
+			return candidate
+		}
+	}
+	return nil
+}
+
+/* refOidOf returns the oid a container value refers to, at oid or targetRef/oid */
+func refOidOf(value interface{}) string {
+	hash, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if oid := stringAtKey(hash, "oid"); oid != "" {
+		return oid
+	}
+	return stringAtKey(hash, "targetRef/oid")
+}
+
+/*
+refQName returns the local part of a reference type or relation, treating
+
+	a missing relation as the default one, so "c:RoleType" equals "RoleType"
+	and "org:default" equals "".
+*/
+func refQName(name string) string {
+	if name == "<nil>" {
+		name = ""
+	}
+	if i := strings.LastIndexAny(name, ":#"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "default" {
+		return ""
+	}
+	return name
+}
+
+/* sameInstant reports whether two strings are timestamps of the same instant */
+func sameInstant(a string, b string) bool {
+	ta, err := time.Parse(time.RFC3339Nano, a)
+	if err != nil {
+		return false
+	}
+	tb, err := time.Parse(time.RFC3339Nano, b)
+	return err == nil && ta.Equal(tb)
+}
+
+/* scalarString returns the text of a scalar value read from the server, or its JSON */
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if orig := polyStringValue(v); orig != "" {
+			return orig
+		}
+	case float64, json.Number, bool:
+		return fmt.Sprintf("%v", v)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
@@ -0,0 +1,156 @@
+package restapi
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/*
+normalizeRules describes which differences in the representation of a value
+are not changes, such as midPoint returning "true" where data has true.
+A nil *normalizeRules compares values as they are, apart from how numbers
+are written.
+*/
+type normalizeRules struct {
+	numericStrings   bool
+	booleanStrings   bool
+	trimWhitespace   bool
+	timestampFormats []string
+	protectedStrings bool
+	polyStrings      bool
+}
+
+func normalizeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Rules for values that are equal although they are represented differently, such as `\"true\"` and `true`. They apply when `data` is compared with the object on the server, so such differences neither show up as drift nor get patched.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"numeric_strings": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Compare strings holding a number (such as `\"42\"`) with the number.",
+				},
+				"boolean_strings": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Compare the strings `\"true\"` and `\"false\"`, in any case, with the booleans.",
+				},
+				"trim_whitespace": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Ignore leading and trailing whitespace of strings.",
+				},
+				"timestamp_formats": {
+					Type:        schema.TypeList,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Optional:    true,
+					Description: "Go time layouts (such as `2006-01-02 15:04:05`) of the timestamps in the object. When set, strings in one of these layouts or in RFC 3339 are compared as points in time, so `2024-01-02T03:04:05.000Z` equals `2024-01-02T04:04:05+01:00`.",
+				},
+				"protected_strings": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Defaults to `true`, also without a `normalize` block. Compare midPoint protected strings, such as `{\"clearValue\": \"secret\"}` in credentials or connector configuration properties, by whether they have a value, as midPoint only returns them encrypted or hashed. The clear values of `data` are kept in state, so changing one is still a change. Set to `false` to compare them as they are.",
+				},
+				"poly_strings": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Defaults to `true`, also without a `normalize` block. Compare midPoint PolyStrings of only `orig` and `norm`, such as `{\"orig\": \"Finance\", \"norm\": \"finance\"}`, with the plain string of their `orig`. Set to `false` to compare them as they are.",
+				},
+			},
+		},
+	}
+}
+
+/* expandNormalizeRules returns the rules of the normalize block; without one, only the midPoint types are normalized */
+func expandNormalizeRules(v []interface{}) *normalizeRules {
+	if len(v) == 0 || v[0] == nil {
+		return &normalizeRules{protectedStrings: true, polyStrings: true}
+	}
+	n := v[0].(map[string]interface{})
+	return &normalizeRules{
+		numericStrings:   n["numeric_strings"].(bool),
+		booleanStrings:   n["boolean_strings"].(bool),
+		trimWhitespace:   n["trim_whitespace"].(bool),
+		timestampFormats: expandStringList(n["timestamp_formats"].([]interface{})),
+		protectedStrings: n["protected_strings"].(bool),
+		polyStrings:      n["poly_strings"].(bool),
+	}
+}
+
+/* literal returns the rules with protected strings compared as they are, for deciding what to send */
+func (rules *normalizeRules) literal() *normalizeRules {
+	if rules == nil {
+		return nil
+	}
+	copied := *rules
+	copied.protectedStrings = false
+	return &copied
+}
+
+/* equal reports whether a and b are the same value once normalized */
+func (rules *normalizeRules) equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(rules.normalize(a), rules.normalize(b))
+}
+
+/* normalize returns v, including the values of its maps and lists, in its canonical representation */
+func (rules *normalizeRules) normalize(v interface{}) interface{} {
+	if rules == nil {
+		rules = &normalizeRules{}
+	}
+	if orig, ok := simplePolyString(v); ok && rules.polyStrings {
+		return rules.normalizeString(orig)
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(value))
+		for k, elem := range value {
+			normalized[k] = rules.normalize(elem)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(value))
+		for i, elem := range value {
+			normalized[i] = rules.normalize(elem)
+		}
+		return normalized
+	case string:
+		return rules.normalizeString(value)
+	}
+	return canonicalNumberValue(v)
+}
+
+func (rules *normalizeRules) normalizeString(s string) interface{} {
+	if rules.trimWhitespace {
+		s = strings.TrimSpace(s)
+	}
+	if rules.booleanStrings && (strings.EqualFold(s, "true") || strings.EqualFold(s, "false")) {
+		return strings.EqualFold(s, "true")
+	}
+	if rules.numericStrings {
+		/* NaN and infinities are left as strings as they are not JSON numbers */
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			if c, ok := canonicalNumberOf(strings.TrimPrefix(s, "+")); ok {
+				return c
+			}
+			return canonicalNumberValue(f)
+		}
+	}
+	if len(rules.timestampFormats) > 0 {
+		for _, layout := range append([]string{time.RFC3339Nano}, rules.timestampFormats...) {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	return s
+}
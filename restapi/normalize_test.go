@@ -0,0 +1,72 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestNormalizeRules(t *testing.T) {
+	rules := &normalizeRules{
+		numericStrings:   true,
+		booleanStrings:   true,
+		trimWhitespace:   true,
+		timestampFormats: []string{"2006-01-02 15:04:05"},
+	}
+
+	recorded := MapAny{
+		"enabled":    true,
+		"priority":   float64(10),
+		"name":       "jsmith",
+		"validFrom":  "2024-01-02 03:04:05",
+		"validTo":    "2024-12-31T23:00:00Z",
+		"assignment": []interface{}{MapAny{"order": float64(1)}},
+		"tags":       []interface{}{"a", "b"},
+	}
+	actual := MapAny{
+		"enabled":    "TRUE",
+		"priority":   "10",
+		"name":       " jsmith\n",
+		"validFrom":  "2024-01-02T03:04:05.000Z",
+		"validTo":    "2025-01-01T00:00:00+01:00",
+		"assignment": []interface{}{MapAny{"order": "1"}},
+		"tags":       []interface{}{"a ", "b"},
+	}
+
+	if _, changed := getDelta(recorded, actual, nil, nil); !changed {
+		t.Fatalf("normalize_test.go: Expected differences without normalization")
+	}
+	if _, changed := getDelta(recorded, actual, nil, rules); changed {
+		t.Fatalf("normalize_test.go: Expected no differences after normalization, changed keys: %v", changedKeys(recorded, actual, nil, rules))
+	}
+
+	actual["priority"] = "11"
+	actual["validFrom"] = "2024-01-02T03:04:06Z"
+	if changed := changedKeys(recorded, actual, nil, rules); !reflect.DeepEqual(changed, []string{"priority", "validFrom"}) {
+		t.Fatalf("normalize_test.go: Expected priority and validFrom to have changed, got %v", changed)
+	}
+
+	/* Each rule only applies when it is set */
+	if (&normalizeRules{numericStrings: true}).equal(true, "true") {
+		t.Fatalf("normalize_test.go: Expected boolean strings to be compared as strings")
+	}
+	if !(&normalizeRules{numericStrings: true}).equal("1e2", float64(100)) {
+		t.Fatalf("normalize_test.go: Expected a numeric string to be equal to the number")
+	}
+}
+
+func TestNormalizeSuppressesDataDiff(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":      "/users",
+		"data":      `{"name": "jsmith", "enabled": true}`,
+		"normalize": []interface{}{map[string]interface{}{"boolean_strings": true}},
+	})
+
+	if !suppressDiffForIgnoredFields("data", `{"name": "jsmith", "enabled": "true"}`, `{"name": "jsmith", "enabled": true}`, d) {
+		t.Fatalf("normalize_test.go: Expected a boolean string to be equal to the boolean")
+	}
+	if suppressDiffForIgnoredFields("data", `{"name": "jsmith", "enabled": "false"}`, `{"name": "jsmith", "enabled": true}`, d) {
+		t.Fatalf("normalize_test.go: Expected a changed boolean to be a difference")
+	}
+}
@@ -0,0 +1,261 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/* Supported values for the pagination type */
+const (
+	paginationOffset = "offset"
+	paginationPage   = "page"
+	paginationCursor = "cursor"
+	paginationLink   = "link"
+)
+
+var paginationTypes = []string{
+	paginationOffset,
+	paginationPage,
+	paginationCursor,
+	paginationLink,
+}
+
+/*paginationOpts describes how to request the pages of a search*/
+type paginationOpts struct {
+	kind         string
+	pageSize     int
+	limitParam   string
+	offsetParam  string
+	pageParam    string
+	perPageParam string
+	firstPage    int
+	cursorParam  string
+	cursorKey    string
+	maxPages     int
+}
+
+func paginationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "How to request further pages of search results. Searches stop as soon as the wanted record is found.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  "One of `offset` (`limit_param`/`offset_param` query parameters), `page` (`page_param`/`per_page_param` query parameters), `cursor` (the next cursor is read from the response at `cursor_key` and sent in `cursor_param`) or `link` (the `next` URL of the RFC 8288 `Link` response header is followed).",
+					ValidateFunc: validation.StringInSlice(paginationTypes, false),
+				},
+				"page_size": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     100,
+					Description: "Number of results requested per page. For `offset` and `page`, a shorter page ends the search. Default: 100",
+				},
+				"limit_param": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "limit",
+					Description: "Query parameter carrying the page size for `offset` and `cursor`. Default: `limit`",
+				},
+				"offset_param": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "offset",
+					Description: "Query parameter carrying the offset for `offset`. Default: `offset`",
+				},
+				"page_param": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "page",
+					Description: "Query parameter carrying the page number for `page`. Default: `page`",
+				},
+				"per_page_param": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "perPage",
+					Description: "Query parameter carrying the page size for `page`. Default: `perPage`",
+				},
+				"first_page": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     1,
+					Description: "Number of the first page for `page`. Default: 1",
+				},
+				"cursor_param": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "cursor",
+					Description: "Query parameter carrying the cursor for `cursor`. Default: `cursor`",
+				},
+				"cursor_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "next",
+					Description: "The '/'-delimited location of the next cursor in the response for `cursor`. A missing or empty cursor ends the search. Default: `next`",
+				},
+				"max_pages": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     100,
+					Description: "Safety limit on the number of pages requested by one search. Default: 100",
+				},
+			},
+		},
+	}
+}
+
+func expandPagination(v []interface{}) *paginationOpts {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	p := v[0].(map[string]interface{})
+	return &paginationOpts{
+		kind:         p["type"].(string),
+		pageSize:     p["page_size"].(int),
+		limitParam:   p["limit_param"].(string),
+		offsetParam:  p["offset_param"].(string),
+		pageParam:    p["page_param"].(string),
+		perPageParam: p["per_page_param"].(string),
+		firstPage:    p["first_page"].(int),
+		cursorParam:  p["cursor_param"].(string),
+		cursorKey:    p["cursor_key"].(string),
+		maxPages:     p["max_pages"].(int),
+	}
+}
+
+/*
+pager walks the pages of one search. A nil *paginationOpts yields a single
+
+	request to the search path, as before pagination was supported.
+*/
+type pager struct {
+	opts     *paginationOpts
+	uri      string
+	page     int
+	nextPath string
+}
+
+func newPager(opts *paginationOpts, uri string, searchPath string) *pager {
+	return &pager{opts: opts, uri: uri, nextPath: searchPath}
+}
+
+/* path returns the path of the current page */
+func (p *pager) path() string {
+	if p.opts == nil {
+		return p.nextPath
+	}
+
+	params := map[string]string{}
+	switch p.opts.kind {
+	case paginationOffset:
+		params[p.opts.limitParam] = strconv.Itoa(p.opts.pageSize)
+		params[p.opts.offsetParam] = strconv.Itoa(p.page * p.opts.pageSize)
+	case paginationPage:
+		params[p.opts.perPageParam] = strconv.Itoa(p.opts.pageSize)
+		params[p.opts.pageParam] = strconv.Itoa(p.opts.firstPage + p.page)
+	case paginationCursor:
+		params[p.opts.limitParam] = strconv.Itoa(p.opts.pageSize)
+	case paginationLink:
+		return p.nextPath
+	}
+	return appendQueryParams(p.nextPath, params)
+}
+
+/*
+advance moves to the next page given the current response. It returns false
+
+	when there are no more pages to request.
+*/
+func (p *pager) advance(result interface{}, headers http.Header, count int) (bool, error) {
+	if p.opts == nil || !p.hasNext(result, headers, count) {
+		return false, nil
+	}
+
+	p.page++
+	if p.page >= p.opts.maxPages {
+		return false, fmt.Errorf("pagination: more results are available after max_pages (%d) pages", p.opts.maxPages)
+	}
+	return true, nil
+}
+
+func (p *pager) hasNext(result interface{}, headers http.Header, count int) bool {
+	switch p.opts.kind {
+	case paginationOffset, paginationPage:
+		return count > 0 && count >= p.opts.pageSize
+	case paginationCursor:
+		hash, ok := result.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		cursor, err := GetStringAtKey(hash, p.opts.cursorKey, false)
+		if err != nil || cursor == "" {
+			return false
+		}
+		p.nextPath = appendQueryParams(stripQueryParam(p.nextPath, p.opts.cursorParam), map[string]string{p.opts.cursorParam: cursor})
+		return true
+	case paginationLink:
+		next := nextLink(headers)
+		if next == "" {
+			return false
+		}
+		p.nextPath = strings.TrimPrefix(next, p.uri)
+		if u, err := url.Parse(p.nextPath); err == nil && u.IsAbs() {
+			p.nextPath = u.RequestURI()
+		}
+		return true
+	}
+	return false
+}
+
+func appendQueryParams(path string, params map[string]string) string {
+	if len(params) == 0 {
+		return path
+	}
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + values.Encode()
+}
+
+func stripQueryParam(path string, param string) string {
+	i := strings.Index(path, "?")
+	if i < 0 {
+		return path
+	}
+	values, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		return path
+	}
+	values.Del(param)
+	if len(values) == 0 {
+		return path[:i]
+	}
+	return path[:i+1] + values.Encode()
+}
+
+var linkNextRegexp = regexp.MustCompile(`<([^>]*)>[^,]*;\s*rel="?next"?`)
+
+/* nextLink returns the target of the rel="next" link of an RFC 8288 Link header */
+func nextLink(headers http.Header) string {
+	for _, header := range headers.Values("Link") {
+		if m := linkNextRegexp.FindStringSubmatch(header); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
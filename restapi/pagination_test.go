@@ -0,0 +1,113 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestFindObjectPagination(t *testing.T) {
+	/* Five records, served two at a time */
+	records := make([]string, 5)
+	for i := range records {
+		records[i] = fmt.Sprintf(`{"id": "%d", "name": "user%d"}`, i, i)
+	}
+	page := func(from int) string {
+		body := "["
+		for i := from; i < from+2 && i < len(records); i++ {
+			if i > from {
+				body += ","
+			}
+			body += records[i]
+		}
+		return body + "]"
+	}
+
+	var requests []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/offset":
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			w.Write([]byte(page(offset)))
+		case "/page":
+			p, _ := strconv.Atoi(q.Get("page"))
+			w.Write([]byte(page(p * 2)))
+		case "/cursor":
+			from, _ := strconv.Atoi(q.Get("cursor"))
+			next := ""
+			if from+2 < len(records) {
+				next = strconv.Itoa(from + 2)
+			}
+			w.Write([]byte(fmt.Sprintf(`{"items": %s, "meta": {"next": "%s"}}`, page(from), next)))
+		case "/link":
+			from, _ := strconv.Atoi(q.Get("from"))
+			if from+2 < len(records) {
+				w.Header().Set("Link", fmt.Sprintf(`<http://other.example/link?from=0>; rel="first", <http://%s/link?from=%d>; rel="next"`, r.Host, from+2))
+			}
+			w.Write([]byte(page(from)))
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("pagination_test.go: Failed to create API client: %s", err)
+	}
+
+	cases := []struct {
+		path       string
+		resultsKey string
+		opts       *paginationOpts
+		requests   int
+	}{
+		{"/offset", "", &paginationOpts{kind: paginationOffset, pageSize: 2, limitParam: "limit", offsetParam: "offset", maxPages: 10}, 3},
+		{"/page", "", &paginationOpts{kind: paginationPage, pageSize: 2, pageParam: "page", perPageParam: "perPage", firstPage: 0, maxPages: 10}, 3},
+		{"/cursor", "items", &paginationOpts{kind: paginationCursor, pageSize: 2, limitParam: "limit", cursorParam: "cursor", cursorKey: "meta/next", maxPages: 10}, 3},
+		{"/link", "", &paginationOpts{kind: paginationLink, maxPages: 10}, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.opts.kind, func(t *testing.T) {
+			obj, err := NewAPIObject(client, &apiObjectOpts{path: tc.path, pagination: tc.opts})
+			if err != nil {
+				t.Fatalf("pagination_test.go: Failed to create API object: %s", err)
+			}
+
+			requests = nil
+			found, err := obj.findObject("", "name", "user4", tc.resultsKey, "")
+			if err != nil {
+				t.Fatalf("pagination_test.go: Search failed: %s (requests: %v)", err, requests)
+			}
+			if found == nil || obj.id != "4" {
+				t.Fatalf("pagination_test.go: Expected to find user4 on the last page, got %v", found)
+			}
+			if len(requests) != tc.requests {
+				t.Fatalf("pagination_test.go: Expected %d requests, got %v", tc.requests, requests)
+			}
+		})
+	}
+
+	t.Run("stops_when_found", func(t *testing.T) {
+		obj, _ := NewAPIObject(client, &apiObjectOpts{path: "/offset", pagination: cases[0].opts})
+		requests = nil
+		if _, err := obj.findObject("", "name", "user1", "", ""); err != nil {
+			t.Fatalf("pagination_test.go: Search failed: %s", err)
+		}
+		if len(requests) != 1 {
+			t.Fatalf("pagination_test.go: Expected the search to stop on the first page, got %v", requests)
+		}
+	})
+
+	t.Run("max_pages", func(t *testing.T) {
+		opts := *cases[0].opts
+		opts.maxPages = 2
+		obj, _ := NewAPIObject(client, &apiObjectOpts{path: "/offset", pagination: &opts})
+		if _, err := obj.findObject("", "name", "user4", "", ""); err == nil {
+			t.Fatalf("pagination_test.go: Expected max_pages to stop the search with an error")
+		}
+	})
+}
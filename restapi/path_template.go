@@ -0,0 +1,52 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+/* Placeholders such as {data.name} or {data.user.name} in the paths of an object */
+var dataPlaceholder = regexp.MustCompile(`\{data\.([^{}]+)\}`)
+
+/*
+expandDataPlaceholders replaces the {data.<path>} placeholders of path with the
+
+	values at the dotted path in data, path escaped, so APIs keyed by natural
+	identifiers can be addressed by items of the object.
+*/
+func expandDataPlaceholders(path string, data map[string]interface{}, debug bool) (string, error) {
+	var err error
+	expanded := dataPlaceholder.ReplaceAllStringFunc(path, func(placeholder string) string {
+		key := dataPlaceholder.FindStringSubmatch(placeholder)[1]
+		value, lookupErr := GetObjectAtKey(data, strings.Replace(key, ".", "/", -1), debug)
+		if lookupErr != nil || value == nil {
+			if err == nil {
+				err = fmt.Errorf("the path '%s' refers to '%s', which data does not have", path, key)
+			}
+			return placeholder
+		}
+		/* PolyStrings stand for their orig, other values for their JSON */
+		s := polyStringValue(value)
+		if s == "" {
+			encoded, _ := json.Marshal(value)
+			s = string(encoded)
+		}
+		return url.PathEscape(s)
+	})
+	return expanded, err
+}
+
+/* expandPathTemplates fills in the {data.<path>} placeholders of every path of the object */
+func (obj *APIObject) expandPathTemplates() error {
+	for _, path := range []*string{&obj.getPath, &obj.postPath, &obj.putPath, &obj.patchPath, &obj.deletePath} {
+		expanded, err := expandDataPlaceholders(*path, obj.data, obj.debug)
+		if err != nil {
+			return err
+		}
+		*path = expanded
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPathTemplates(t *testing.T) {
+	data := map[string]interface{}{
+		"user":    map[string]interface{}{"name": map[string]interface{}{"orig": "Jürgen Müller", "norm": "jurgen muller"}},
+		"account": "jdoe/ext",
+		"index":   3,
+	}
+	expanded, err := expandDataPlaceholders("/accounts/{data.account}/users/{data.user.name}/{data.index}/{id}", data, false)
+	if err != nil || expanded != "/accounts/jdoe%2Fext/users/J%C3%BCrgen%20M%C3%BCller/3/{id}" {
+		t.Fatalf("path_template_test.go: Expected the placeholders filled in and escaped, got '%s' and %v", expanded, err)
+	}
+	if _, err := expandDataPlaceholders("/accounts/{data.user.fullName}", data, false); err == nil || !strings.Contains(err.Error(), "user.fullName") {
+		t.Fatalf("path_template_test.go: Expected a missing item to fail, got %v", err)
+	}
+
+	var requests []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == "GET" {
+			w.Write([]byte(`{"name": "jdoe", "mail": "jdoe@example.com"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "name", createMethod: "PUT", updateMethod: "PUT"})
+	if err != nil {
+		t.Fatalf("path_template_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":         "/directories/main/accounts",
+		"create_path":  "/directories/main/accounts/{data.name}",
+		"read_path":    "/directories/main/accounts/{data.name}",
+		"update_path":  "/directories/main/accounts/{data.name}",
+		"destroy_path": "/directories/main/accounts/{data.name}",
+		"data":         `{"name": "jdoe", "mail": "jdoe@example.com"}`,
+	})
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("path_template_test.go: Create failed: %s", err)
+	}
+	if err := resourceRestAPIDelete(d, client); err != nil {
+		t.Fatalf("path_template_test.go: Delete failed: %s", err)
+	}
+	for _, request := range requests {
+		if !strings.HasSuffix(request, " /directories/main/accounts/jdoe") {
+			t.Fatalf("path_template_test.go: Expected every request to go to the account's path, got %v", requests)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package restapi
+
+/* Supported values for content_type */
+const (
+	contentTypeJSONPayload = "json"
+	contentTypeXMLPayload  = "xml"
+	contentTypeYAMLPayload = "yaml"
+)
+
+var payloadFormats = []string{
+	contentTypeJSONPayload,
+	contentTypeXMLPayload,
+	contentTypeYAMLPayload,
+}
+
+/* Media types sent and accepted for each payload format other than JSON */
+var payloadMediaTypes = map[string]string{
+	contentTypeXMLPayload:  "application/xml",
+	contentTypeYAMLPayload: "application/yaml",
+}
+
+/* encodePayload converts a JSON request body into format */
+func encodePayload(format string, data string) (string, error) {
+	if format == contentTypeYAMLPayload {
+		return jsonToYAML(data)
+	}
+	return jsonToXML(data)
+}
+
+/*
+decodePayload converts a response body in format back into JSON. Bodies
+
+	that are not in format, such as JSON errors from a gateway, are returned
+	as they are.
+*/
+func decodePayload(format string, body string) (string, error) {
+	switch {
+	case format == contentTypeXMLPayload && isXMLBody(body):
+		return xmlToJSON(body)
+	case format == contentTypeYAMLPayload && body != "":
+		return yamlToJSON(body)
+	}
+	return body, nil
+}
@@ -0,0 +1,43 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+/*
+withPerformanceWarnings returns a copy of the client collecting the requests
+
+	that exceed slow_request_threshold or large_payload_threshold in the
+	returned resultWarnings, so they are reported with the object they were
+	made for. Without thresholds, the client is returned as it is.
+*/
+func (client *APIClient) withPerformanceWarnings() (*APIClient, *resultWarnings) {
+	if client.slowRequest <= 0 && client.largePayload <= 0 {
+		return client, nil
+	}
+	warningClient := *client
+	warningClient.perfWarnings = &resultWarnings{}
+	return &warningClient, warningClient.perfWarnings
+}
+
+/* checkPerformance warns about a request that took longer or sent or received more than the thresholds */
+func (client *APIClient) checkPerformance(method string, path string, sent int, received int, latency time.Duration) {
+	warnings := make([]string, 0)
+	if client.slowRequest > 0 && latency > client.slowRequest {
+		warnings = append(warnings, fmt.Sprintf("%s %s took %s, more than slow_request_threshold (%s)", method, path, latency.Round(time.Millisecond), client.slowRequest))
+	}
+	if client.largePayload > 0 && sent > client.largePayload {
+		warnings = append(warnings, fmt.Sprintf("%s %s sent %d bytes, more than large_payload_threshold (%d)", method, path, sent, client.largePayload))
+	}
+	if client.largePayload > 0 && received > client.largePayload {
+		warnings = append(warnings, fmt.Sprintf("%s %s received %d bytes, more than large_payload_threshold (%d)", method, path, received, client.largePayload))
+	}
+	for _, warning := range warnings {
+		log.Printf("[WARN] performance_warnings.go: %s", warning)
+		if client.perfWarnings != nil {
+			client.perfWarnings.add(warning)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPerformanceWarnings(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.Write([]byte(`{"id": "1", "name": "jsmith", "description": "A user with a long description"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id", slowRequest: 20 * time.Millisecond, largePayload: 50})
+	if err != nil {
+		t.Fatalf("performance_warnings_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path": "/users",
+		"data": `{"id": "1", "name": "jsmith"}`,
+	})
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("performance_warnings_test.go: Create failed: %s", err)
+	}
+	warnings := d.Get("performance_warnings").([]interface{})
+	joined := make([]string, len(warnings))
+	for i, w := range warnings {
+		joined[i] = w.(string)
+	}
+	if !strings.Contains(strings.Join(joined, "\n"), "POST /users took") || !strings.Contains(strings.Join(joined, "\n"), "received 78 bytes") || strings.Contains(strings.Join(joined, "\n"), "sent") {
+		t.Fatalf("performance_warnings_test.go: Expected the slow create and the large responses, got %v", joined)
+	}
+
+	/* Without thresholds nothing is collected */
+	client, _ = NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id"})
+	if copied, warnings := client.withPerformanceWarnings(); copied != client || warnings != nil {
+		t.Fatal("performance_warnings_test.go: Expected no warnings to be collected without thresholds")
+	}
+}
@@ -0,0 +1,108 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/*
+customizeDiffPlannedDeltas computes at plan time the modifications an update
+
+	would send and stores them in planned_deltas, so the plan shows the
+	itemDeltas (or JSON Patch operations) instead of only a changed data string.
+	The deltas are computed against the data recorded in state, which the
+	refresh before the plan keeps in line with the server.
+*/
+func customizeDiffPlannedDeltas(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || meta == nil || !d.HasChange("data") {
+		return nil
+	}
+	if !d.NewValueKnown("data") {
+		return d.SetNewComputed("planned_deltas")
+	}
+
+	oldData, newData := d.GetChange("data")
+
+	opts := &apiObjectOpts{
+		path:           d.Get("path").(string),
+		id:             d.Id(),
+		idAttribute:    d.Get("id_attribute").(string),
+		updateMethod:   d.Get("update_method").(string),
+		updateStrategy: d.Get("update_strategy").(string),
+		listMergeKeys:  expandReadSearch(d.Get("list_merge_keys").(map[string]interface{})),
+		pathNamespaces: expandReadSearch(d.Get("path_namespaces").(map[string]interface{})),
+		normalize:      expandNormalizeRules(d.Get("normalize").([]interface{})),
+		protectedPaths: expandStringList(d.Get("protected_fields").([]interface{})),
+		managedFields:  getManagedFields(d),
+		data:           newData.(string),
+	}
+	if v, ok := d.GetOk("object_id"); ok {
+		opts.id = v.(string)
+	}
+
+	if representation, err := meta.(*APIClient).lookupRepresentation(d.Get("representation").(string)); err == nil && representation != nil && opts.updateStrategy == "" {
+		opts.updateStrategy = representation.updateStrategy
+	}
+
+	obj, err := NewAPIObject(meta.(*APIClient), opts)
+	if err != nil {
+		/* Invalid data is reported by validation; there is nothing to preview */
+		log.Printf("planned_deltas.go: Not computing planned deltas: %v", err)
+		return nil
+	}
+	if err := unmarshalJSON([]byte(oldData.(string)), &obj.apiData); err != nil {
+		log.Printf("planned_deltas.go: Not computing planned deltas, state data is invalid: %v", err)
+		return nil
+	}
+	/* The data in state is also what the update may remove items of */
+	obj.previousData = obj.apiData
+
+	if !d.Get("ignore_all_server_changes").(bool) {
+		obj.ignoreChangesTo = getIgnoreList(d)
+		obj.data = filterIgnoredFields(obj.data, obj.ignoreChangesTo)
+	}
+
+	planned, err := obj.plannedDeltas()
+	if err != nil {
+		return err
+	}
+	return d.SetNew("planned_deltas", planned)
+}
+
+/* plannedDeltas returns the modifications of the update strategy as JSON strings, one per modification */
+func (obj *APIObject) plannedDeltas() ([]string, error) {
+	modifications := make([]interface{}, 0)
+
+	switch obj.updateStrategy {
+	case updateStrategyMidpointPatch:
+		for _, delta := range obj.midpointDeltas() {
+			modifications = append(modifications, obj.buildItemDelta(delta.modificationType, delta.path, delta.value))
+		}
+	case updateStrategyJSONPatch:
+		for _, op := range withoutProtectedRemovals(buildJSONPatch(obj.managedOnly(obj.apiData), obj.managedOnly(obj.data), obj.managedData(), obj.ignoreChangesTo, obj.normalize), obj.protectedPaths) {
+			modifications = append(modifications, op)
+		}
+	case updateStrategyMergePatch:
+		if patch := withoutProtectedNulls(buildMergePatch(obj.managedOnly(obj.apiData), obj.managedOnly(obj.data), obj.managedData(), obj.ignoreChangesTo, obj.normalize), "", obj.protectedPaths); len(patch) > 0 {
+			modifications = append(modifications, patch)
+		}
+	}
+
+	return marshalModifications(modifications)
+}
+
+/* marshalModifications returns the modifications as JSON strings, one per modification */
+func marshalModifications(modifications []interface{}) ([]string, error) {
+	marshalled := make([]string, 0, len(modifications))
+	for _, modification := range modifications {
+		b, err := json.Marshal(modification)
+		if err != nil {
+			return nil, err
+		}
+		marshalled = append(marshalled, string(b))
+	}
+	return marshalled, nil
+}
@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestPlannedDeltas(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1})
+	if err != nil {
+		t.Fatalf("planned_deltas_test.go: Failed to create API client: %s", err)
+	}
+
+	state := &terraform.InstanceState{
+		ID: "1",
+		Attributes: map[string]string{
+			"id":   "1",
+			"path": "/users",
+			"data": `{"id": "1", "name": "jsmith", "description": "old", "metadata": "server"}`,
+		},
+	}
+
+	diff := func(strategy string) []string {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"path":              "/users",
+			"update_strategy":   strategy,
+			"ignore_changes_to": []interface{}{"metadata"},
+			"data":              `{"id": "1", "name": "jsmith", "description": "new", "fullName": "John Smith"}`,
+		})
+		instanceDiff, err := resourceRestAPI().Diff(context.TODO(), state, config, client)
+		if err != nil {
+			t.Fatalf("planned_deltas_test.go: Diff failed: %s", err)
+		}
+
+		/* An empty list in state and plan leaves planned_deltas out of the diff */
+		planned := make([]string, 0)
+		count := instanceDiff.Attributes["planned_deltas.#"]
+		if count == nil {
+			return planned
+		}
+		n, _ := strconv.Atoi(count.New)
+		for i := 0; i < n; i++ {
+			planned = append(planned, instanceDiff.Attributes["planned_deltas."+strconv.Itoa(i)].New)
+		}
+		return planned
+	}
+
+	t.Run("midpoint-patch", func(t *testing.T) {
+		planned := diff(updateStrategyMidpointPatch)
+		expected := []string{
+			`{"modificationType":"replace","path":"description","value":"new"}`,
+			`{"modificationType":"add","path":"fullName","value":"John Smith"}`,
+		}
+		if len(planned) != len(expected) {
+			t.Fatalf("planned_deltas_test.go: Expected %v, got %v", expected, planned)
+		}
+		for i := range expected {
+			if planned[i] != expected[i] {
+				t.Fatalf("planned_deltas_test.go: Expected delta %d to be %s, got %s", i, expected[i], planned[i])
+			}
+		}
+	})
+
+	t.Run("json-patch", func(t *testing.T) {
+		planned := diff(updateStrategyJSONPatch)
+		if len(planned) != 2 || planned[0] != `{"op":"replace","path":"/description","value":"new"}` {
+			t.Fatalf("planned_deltas_test.go: Unexpected JSON Patch preview %v", planned)
+		}
+	})
+
+	t.Run("put", func(t *testing.T) {
+		if planned := diff(updateStrategyPut); len(planned) != 0 {
+			t.Fatalf("planned_deltas_test.go: Expected no planned deltas for put, got %v", planned)
+		}
+	})
+}
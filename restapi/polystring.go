@@ -0,0 +1,54 @@
+package restapi
+
+/* The items of a PolyString without translations or language variants */
+var polyStringItems = []string{"orig", "norm"}
+
+/*
+simplePolyString returns the orig of v when it is a PolyString of only orig
+
+	and norm, such as {"orig": "Finance", "norm": "finance"}, which means the
+	same as the plain string "Finance".
+*/
+func simplePolyString(v interface{}) (string, bool) {
+	hash, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	orig, ok := hash["orig"].(string)
+	if !ok {
+		return "", false
+	}
+	for key := range hash {
+		if !containsString(polyStringItems, key) {
+			return "", false
+		}
+	}
+	return orig, true
+}
+
+/*
+withoutPolyStringNorms returns v with the norm of its PolyStrings left out,
+
+	as midPoint computes it from orig itself, so that a norm written in data
+	by hand cannot disagree with the one midPoint would compute.
+*/
+func withoutPolyStringNorms(v interface{}) interface{} {
+	if orig, ok := simplePolyString(v); ok {
+		return map[string]interface{}{"orig": orig}
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			result[key] = withoutPolyStringNorms(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = withoutPolyStringNorms(item)
+		}
+		return result
+	}
+	return v
+}
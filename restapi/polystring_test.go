@@ -0,0 +1,62 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolyStrings(t *testing.T) {
+	rules := expandNormalizeRules(nil)
+	poly := map[string]interface{}{"orig": "Finance", "norm": "finance"}
+
+	/* Either side may be the plain string */
+	for _, pair := range [][2]map[string]interface{}{
+		{{"name": "Finance"}, {"name": poly}},
+		{{"name": poly}, {"name": "Finance"}},
+		{{"names": []interface{}{"Finance"}}, {"names": []interface{}{poly}}},
+	} {
+		if modified, changed := getDelta(pair[0], pair[1], nil, rules); changed || !reflect.DeepEqual(modified, pair[0]) {
+			t.Fatalf("polystring_test.go: Expected %v to equal %v, got %v", pair[0], pair[1], modified)
+		}
+		if keys := changedKeys(pair[0], pair[1], nil, rules); len(keys) != 0 {
+			t.Fatalf("polystring_test.go: Expected no changed keys between %v and %v, got %v", pair[0], pair[1], keys)
+		}
+		if _, changed := getDelta(pair[0], pair[1], nil, nil); !changed {
+			t.Fatalf("polystring_test.go: Expected %v to differ from %v without rules", pair[0], pair[1])
+		}
+	}
+
+	/* A different orig, and PolyStrings with translations, are changes */
+	if _, changed := getDelta(map[string]interface{}{"name": "Sales"}, map[string]interface{}{"name": poly}, nil, rules); !changed {
+		t.Fatal("polystring_test.go: Expected a different orig to be a change")
+	}
+	translated := map[string]interface{}{"orig": "Finance", "translation": map[string]interface{}{"key": "finance"}}
+	if _, changed := getDelta(map[string]interface{}{"name": "Finance"}, map[string]interface{}{"name": translated}, nil, rules); !changed {
+		t.Fatal("polystring_test.go: Expected a PolyString with a translation to differ from the plain string")
+	}
+
+	optOut := expandNormalizeRules([]interface{}{map[string]interface{}{"numeric_strings": false, "boolean_strings": false, "trim_whitespace": false, "timestamp_formats": []interface{}{}, "protected_strings": true, "poly_strings": false}})
+	if _, changed := getDelta(map[string]interface{}{"name": "Finance"}, map[string]interface{}{"name": poly}, nil, optOut); !changed {
+		t.Fatal("polystring_test.go: Expected the opt-out to compare PolyStrings as they are")
+	}
+
+	/* Only the real changes are patched, and without the norms */
+	obj, err := NewAPIObject(&APIClient{}, &apiObjectOpts{
+		path:      "/roles",
+		id:        "role-1",
+		normalize: rules,
+		data:      `{"role": {"oid": "role-1", "name": "Finance", "description": "Money", "subtype": [{"orig": "Dept", "norm": "wrong"}]}}`,
+	})
+	if err != nil {
+		t.Fatalf("polystring_test.go: Failed to create the API object: %s", err)
+	}
+	obj.apiData = map[string]interface{}{"role": map[string]interface{}{"oid": "role-1", "name": poly, "description": "Cash"}}
+	deltas := obj.midpointDeltas()
+	if len(deltas) != 2 || deltas[0].path != "description" || deltas[1].path != "subtype" {
+		t.Fatalf("polystring_test.go: Expected deltas of description and subtype only, got %v", deltas)
+	}
+	itemDelta := obj.buildItemDelta(deltas[1].modificationType, deltas[1].path, deltas[1].value)
+	if expected := []interface{}{map[string]interface{}{"orig": "Dept"}}; !reflect.DeepEqual(itemDelta["value"], expected) {
+		t.Fatalf("polystring_test.go: Expected the value %v without the norm, got %v", expected, itemDelta["value"])
+	}
+}
@@ -0,0 +1,127 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* A request sent after the object was created, such as setting its password */
+type postCreateRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+/* Placeholders such as {id} or {user/name} in the path and body of follow-up requests */
+var postCreatePlaceholder = regexp.MustCompile(`\{([A-Za-z0-9_@.\-]+(?:/[A-Za-z0-9_@.\-]+)*)\}`)
+
+func postCreateRequestsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Requests sent in order right after the object was created, such as setting the password of a user and then assigning an archetype. `{id}` in their path and body is replaced with the id of the created object and `{<path>}`, such as `{user/name}`, with the item at the `/` separated path of the object read back after the create. If a request fails, the created object is deleted again and the create fails. They are not sent by updates.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"method": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "POST",
+					Description: "The HTTP method of the request. Default: POST",
+				},
+				"path": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The API path of the request on top of the base URL set in the provider, such as `/users/{id}`.",
+				},
+				"body": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The body of the request, such as a midPoint `objectModification`.",
+				},
+			},
+		},
+	}
+}
+
+func expandPostCreateRequests(v []interface{}) []postCreateRequest {
+	requests := make([]postCreateRequest, 0, len(v))
+	for _, raw := range v {
+		if raw == nil {
+			continue
+		}
+		r := raw.(map[string]interface{})
+		requests = append(requests, postCreateRequest{
+			method: r["method"].(string),
+			path:   r["path"].(string),
+			body:   r["body"].(string),
+		})
+	}
+	return requests
+}
+
+/*
+expandPostCreateTemplate replaces the placeholders of a path or body with the
+
+	id and items of the created object. Items that are not strings are
+	inserted as JSON.
+*/
+func (obj *APIObject) expandPostCreateTemplate(template string) (string, error) {
+	var err error
+	expanded := postCreatePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		key := postCreatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if key == "id" {
+			return obj.id
+		}
+		value, lookupErr := GetObjectAtKey(obj.apiData, key, obj.debug)
+		if lookupErr != nil {
+			if err == nil {
+				err = fmt.Errorf("the created object has no '%s' for the placeholder %s", key, placeholder)
+			}
+			return placeholder
+		}
+		if s, ok := value.(string); ok {
+			return s
+		}
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	})
+	return expanded, err
+}
+
+/*
+sendPostCreateRequests sends the follow-up requests of a created object in
+
+	order. When one fails, the object is deleted again, so a failed create
+	leaves nothing behind that is not in state.
+*/
+func (obj *APIObject) sendPostCreateRequests() error {
+	for i, request := range obj.postCreate {
+		path, err := obj.expandPostCreateTemplate(request.path)
+		if err == nil {
+			var body string
+			if body, err = obj.expandPostCreateTemplate(request.body); err == nil {
+				if obj.debug {
+					log.Printf("post_create.go: Sending post_create_requests[%d] %s '%s' for '%s'", i, request.method, path, obj.id)
+				}
+				_, err = obj.apiClient.sendRequest(request.method, path, body)
+			}
+		}
+		if err != nil {
+			return obj.rollBackCreate(fmt.Errorf("post_create_requests[%d] (%s %s) failed: %v", i, request.method, request.path, err))
+		}
+	}
+	return nil
+}
+
+/* rollBackCreate deletes the object whose create failed with cause */
+func (obj *APIObject) rollBackCreate(cause error) error {
+	log.Printf("[WARN] post_create.go: %v. Deleting the created object '%s'.", cause, obj.id)
+	if err := obj.deleteObject(); err != nil {
+		return fmt.Errorf("%v; deleting the created object '%s' failed as well, it is left on the server: %v", cause, obj.id, err)
+	}
+	return fmt.Errorf("%v; the created object '%s' was deleted", cause, obj.id)
+}
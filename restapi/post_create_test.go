@@ -0,0 +1,99 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPostCreateRequests(t *testing.T) {
+	var requests []string
+	stored := ""
+	failArchetype := false
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == "GET":
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(stored))
+			return
+		case r.Method == "POST" && r.URL.Path == "/users":
+			stored = `{"user": {"oid": "user-1", "name": "jsmith"}}`
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(stored))
+		case r.Method == "DELETE":
+			stored = ""
+			w.WriteHeader(http.StatusNoContent)
+		case failArchetype && strings.Contains(string(body), "archetypeRef"):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", createReturnsObject: true})
+	if err != nil {
+		t.Fatalf("post_create_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func() *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path": "/users",
+			"data": `{"user": {"name": "jsmith"}}`,
+			"post_create_requests": []interface{}{
+				map[string]interface{}{
+					"method": "PATCH",
+					"path":   "/users/{id}",
+					"body":   `{"objectModification": {"itemDelta": {"path": "description", "value": "Created as {user/name}"}}}`,
+				},
+				map[string]interface{}{
+					"path": "/users/{id}/assign",
+					"body": `{"archetypeRef": {"oid": "archetype-1"}}`,
+				},
+			},
+		})
+	}
+
+	d := newResourceData()
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("post_create_test.go: Create failed: %s", err)
+	}
+	expected := []string{
+		`POST /users {"user":{"name":"jsmith"}}`,
+		`PATCH /users/user-1 {"objectModification": {"itemDelta": {"path": "description", "value": "Created as jsmith"}}}`,
+		`POST /users/user-1/assign {"archetypeRef": {"oid": "archetype-1"}}`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") || d.Id() != "user-1" {
+		t.Fatalf("post_create_test.go: Expected the follow-up requests after the create, got %v and '%s'", requests, d.Id())
+	}
+
+	/* Refreshes do not send them again */
+	requests = nil
+	if err := resourceRestAPIRead(d, client); err != nil || len(requests) != 0 {
+		t.Fatalf("post_create_test.go: Expected the read to send no follow-up requests, got %v and %v", err, requests)
+	}
+
+	/* A failed follow-up deletes the created object */
+	requests = nil
+	failArchetype = true
+	d = newResourceData()
+	if err := resourceRestAPICreate(d, client); err == nil || !strings.Contains(err.Error(), "post_create_requests[1]") || !strings.Contains(err.Error(), "was deleted") {
+		t.Fatalf("post_create_test.go: Expected the create to fail and roll back, got %v", err)
+	}
+	if d.Id() != "" || stored != "" || requests[len(requests)-1] != "DELETE /users/user-1 " {
+		t.Fatalf("post_create_test.go: Expected the created object to be deleted, got '%s' and %v", d.Id(), requests)
+	}
+
+	obj := &APIObject{id: "user-1", apiData: map[string]interface{}{"user": map[string]interface{}{"name": "jsmith"}}}
+	if _, err := obj.expandPostCreateTemplate("/users/{user/fullName}"); err == nil {
+		t.Fatal("post_create_test.go: Expected a placeholder of a missing item to fail")
+	}
+}
@@ -0,0 +1,88 @@
+package restapi
+
+import (
+	"log"
+	"strings"
+)
+
+/*
+isProtectedPath reports whether the dotted path is one of the protected fields
+
+	or below one of them. Fields deleted by mistake, such as a missing
+	activation or credentials, could otherwise lock users out.
+*/
+func isProtectedPath(path string, protected []string) bool {
+	for _, field := range protected {
+		if path == field || strings.HasPrefix(path, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+withoutProtectedDeletions drops the delete itemDeltas of protected fields.
+
+	Paths are relative to the wrapper key (e.g. "user") when there is one, and
+	protected fields may be given with or without it.
+*/
+func withoutProtectedDeletions(deltas []midpointItemDelta, wrapper string, protected []string) []midpointItemDelta {
+	if len(protected) == 0 {
+		return deltas
+	}
+	kept := make([]midpointItemDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		path := strings.ReplaceAll(delta.path, "/", ".")
+		if delta.modificationType == "delete" && (isProtectedPath(path, protected) || wrapper != "" && isProtectedPath(wrapper+"."+path, protected)) {
+			log.Printf("protected_fields.go: Not deleting protected field '%s'", delta.path)
+			continue
+		}
+		kept = append(kept, delta)
+	}
+	return kept
+}
+
+/* withoutProtectedRemovals drops the JSON Patch remove operations of protected fields */
+func withoutProtectedRemovals(ops []jsonPatchOperation, protected []string) []jsonPatchOperation {
+	if len(protected) == 0 {
+		return ops
+	}
+	kept := make([]jsonPatchOperation, 0, len(ops))
+	for _, op := range ops {
+		if op.Op == "remove" && isProtectedPath(jsonPointerPath(op.Path), protected) {
+			log.Printf("protected_fields.go: Not removing protected field '%s'", op.Path)
+			continue
+		}
+		kept = append(kept, op)
+	}
+	return kept
+}
+
+/* withoutProtectedNulls drops the null values, which delete fields, of protected fields from a merge patch */
+func withoutProtectedNulls(patch map[string]interface{}, prefix string, protected []string) map[string]interface{} {
+	if len(protected) == 0 {
+		return patch
+	}
+	for key, value := range patch {
+		path := prefix + key
+		if value == nil && isProtectedPath(path, protected) {
+			log.Printf("protected_fields.go: Not removing protected field '%s'", path)
+			delete(patch, key)
+		} else if sub, ok := value.(map[string]interface{}); ok && len(sub) > 0 {
+			/* Drop objects left empty, which would be no change at all */
+			if withoutProtectedNulls(sub, path+".", protected); len(sub) == 0 {
+				delete(patch, key)
+			}
+		}
+	}
+	return patch
+}
+
+/* jsonPointerPath turns an RFC 6901 pointer such as "/activation/validTo" into a dotted path */
+func jsonPointerPath(pointer string) string {
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return strings.Join(tokens, ".")
+}
@@ -0,0 +1,52 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtectedFields(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1})
+	if err != nil {
+		t.Fatalf("protected_fields_test.go: Failed to create API client: %s", err)
+	}
+
+	current := MapAny{"user": MapAny{
+		"name":        "jsmith",
+		"description": "old",
+		"activation":  MapAny{"administrativeStatus": "enabled"},
+		"credentials": MapAny{"password": MapAny{"value": "secret"}},
+	}}
+	desired := `{"user": {"name": "jsmith"}}`
+
+	obj, err := NewAPIObject(client, &apiObjectOpts{
+		path:           "/users",
+		id:             "1",
+		data:           desired,
+		updateStrategy: updateStrategyMidpointPatch,
+		protectedPaths: []string{"activation", "user.credentials"},
+	})
+	if err != nil {
+		t.Fatalf("protected_fields_test.go: Failed to create API object: %s", err)
+	}
+	obj.apiData = current
+
+	expected := []midpointItemDelta{{modificationType: "delete", path: "description"}}
+	if deltas := obj.midpointDeltas(); !reflect.DeepEqual(deltas, expected) {
+		t.Fatalf("protected_fields_test.go: Expected only the unprotected field to be deleted: expected %v, got %v", expected, deltas)
+	}
+
+	ops := withoutProtectedRemovals(buildJSONPatch(current, MapAny{"user": MapAny{"name": "jsmith"}}, current, nil, nil), []string{"user.activation", "user.credentials"})
+	if !reflect.DeepEqual(ops, []jsonPatchOperation{{Op: "remove", Path: "/user/description"}}) {
+		t.Fatalf("protected_fields_test.go: Expected only the unprotected field to be removed, got %v", ops)
+	}
+
+	patch := withoutProtectedNulls(buildMergePatch(current, MapAny{"user": MapAny{"name": "jsmith", "description": "old"}}, current, nil, nil), "", []string{"user.activation", "user.credentials"})
+	if len(patch) != 0 {
+		t.Fatalf("protected_fields_test.go: Expected an empty merge patch, got %v", patch)
+	}
+
+	if !isProtectedPath("activation.validTo", []string{"activation"}) || isProtectedPath("activationHistory", []string{"activation"}) {
+		t.Fatalf("protected_fields_test.go: Expected fields below a protected field, and only those, to be protected")
+	}
+}
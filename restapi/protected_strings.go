@@ -0,0 +1,87 @@
+package restapi
+
+/* The items of a ProtectedStringType that hold its value, in clear or otherwise */
+var protectedStringItems = []string{"clearValue", "encryptedData", "hashedData", "externalData"}
+
+/*
+protectedString returns v as a ProtectedStringType, such as a password or a
+
+	connector configuration property, when it is a map of only the value
+	items of one (and attributes such as @type).
+*/
+func protectedString(v interface{}) (map[string]interface{}, bool) {
+	hash, ok := v.(map[string]interface{})
+	if !ok || len(hash) == 0 {
+		return nil, false
+	}
+	hasValue := false
+	for key := range hash {
+		if containsString(protectedStringItems, key) {
+			hasValue = true
+		} else if key[0] != '@' {
+			return nil, false
+		}
+	}
+	return hash, hasValue
+}
+
+/*
+sameProtectedString reports whether recorded and actual are protected strings
+
+	that are equal by presence: midPoint only returns them encrypted or
+	hashed, so one that has a value matches any other that has one. Two
+	clear values are compared as they are, so changing one in data is
+	still a change.
+*/
+func (rules *normalizeRules) sameProtectedString(recorded interface{}, actual interface{}) bool {
+	if rules == nil || !rules.protectedStrings {
+		return false
+	}
+	recordedString, okRecorded := protectedString(recorded)
+	actualString, okActual := protectedString(actual)
+	if !okRecorded || !okActual {
+		return false
+	}
+	_, recordedClear := recordedString["clearValue"]
+	_, actualClear := actualString["clearValue"]
+	return !recordedClear || !actualClear
+}
+
+/*
+withRecordedProtectedStrings returns a copy of actual whose protected strings
+
+	are replaced by the values recorded for them, so that the clear values
+	of data are kept in state instead of the encrypted ones the server
+	returns, and a new clear value in the configuration shows up as a change.
+*/
+func withRecordedProtectedStrings(recorded map[string]interface{}, actual map[string]interface{}, rules *normalizeRules) map[string]interface{} {
+	if rules == nil || !rules.protectedStrings {
+		return actual
+	}
+	result := make(map[string]interface{}, len(actual))
+	for key, value := range actual {
+		result[key] = withRecordedProtectedString(recorded[key], value, rules)
+	}
+	return result
+}
+
+func withRecordedProtectedString(recorded interface{}, actual interface{}, rules *normalizeRules) interface{} {
+	if rules.sameProtectedString(recorded, actual) {
+		return recorded
+	}
+	switch actualValue := actual.(type) {
+	case map[string]interface{}:
+		if recordedMap, ok := recorded.(map[string]interface{}); ok {
+			return withRecordedProtectedStrings(recordedMap, actualValue, rules)
+		}
+	case []interface{}:
+		if recordedSlice, ok := recorded.([]interface{}); ok && len(recordedSlice) == len(actualValue) {
+			result := make([]interface{}, len(actualValue))
+			for i := range actualValue {
+				result[i] = withRecordedProtectedString(recordedSlice[i], actualValue[i], rules)
+			}
+			return result
+		}
+	}
+	return actual
+}
@@ -0,0 +1,63 @@
+package restapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtectedStrings(t *testing.T) {
+	recorded := map[string]interface{}{
+		"name":        "ldap",
+		"credentials": map[string]interface{}{"password": map[string]interface{}{"value": map[string]interface{}{"clearValue": "secret"}}},
+		"properties":  []interface{}{map[string]interface{}{"clearValue": "bind"}},
+	}
+	actual := map[string]interface{}{
+		"name":        "ldap",
+		"credentials": map[string]interface{}{"password": map[string]interface{}{"value": map[string]interface{}{"encryptedData": map[string]interface{}{"cipherData": "abc"}}}},
+		"properties":  []interface{}{map[string]interface{}{"@type": "ProtectedStringType", "encryptedData": map[string]interface{}{"cipherData": "def"}}},
+	}
+
+	rules := expandNormalizeRules(nil)
+	if modified, changed := getDelta(recorded, actual, nil, rules); changed || !reflect.DeepEqual(modified, recorded) {
+		t.Fatalf("protected_strings_test.go: Expected encrypted values to match the clear ones, got %v", modified)
+	}
+	if keys := changedKeys(recorded, actual, nil, rules); len(keys) != 0 {
+		t.Fatalf("protected_strings_test.go: Expected no changed keys, got %v", keys)
+	}
+
+	/* The opt-out and the rules deciding what to send compare them as they are */
+	optOut := expandNormalizeRules([]interface{}{map[string]interface{}{"numeric_strings": false, "boolean_strings": false, "trim_whitespace": false, "timestamp_formats": []interface{}{}, "protected_strings": false, "poly_strings": true}})
+	for _, literal := range []*normalizeRules{optOut, rules.literal(), nil} {
+		if _, changed := getDelta(recorded, actual, nil, literal); !changed {
+			t.Fatalf("protected_strings_test.go: Expected encrypted values to differ from the clear ones with %v", literal)
+		}
+	}
+
+	/* Two clear values are compared as they are */
+	rotated := map[string]interface{}{"credentials": map[string]interface{}{"password": map[string]interface{}{"value": map[string]interface{}{"clearValue": "rotated"}}}}
+	if keys := changedKeys(recorded, rotated, nil, rules); !reflect.DeepEqual(keys, []string{"credentials.password.value.clearValue", "name", "properties"}) {
+		t.Fatalf("protected_strings_test.go: Unexpected changed keys %v", keys)
+	}
+
+	/* A value that is gone is a change */
+	removed := map[string]interface{}{"name": "ldap", "credentials": map[string]interface{}{"password": map[string]interface{}{}}, "properties": []interface{}{map[string]interface{}{}}}
+	if _, changed := getDelta(recorded, removed, nil, rules); !changed {
+		t.Fatal("protected_strings_test.go: Expected a removed protected string to be a change")
+	}
+
+	/* State keeps the clear values, without changing the data read */
+	stored := withRecordedProtectedStrings(recorded, actual, rules)
+	if !reflect.DeepEqual(stored, recorded) {
+		t.Fatalf("protected_strings_test.go: Expected the clear values to be stored, got %v", stored)
+	}
+	if _, ok := actual["credentials"].(map[string]interface{})["password"].(map[string]interface{})["value"].(map[string]interface{})["encryptedData"]; !ok {
+		t.Fatalf("protected_strings_test.go: Expected the data read to be left as it is, got %v", actual)
+	}
+	if stored := withRecordedProtectedStrings(recorded, actual, optOut); !reflect.DeepEqual(stored, actual) {
+		t.Fatalf("protected_strings_test.go: Expected the encrypted values to be stored with the opt-out, got %v", stored)
+	}
+
+	if _, ok := protectedString(map[string]interface{}{"clearValue": "x", "description": "y"}); ok {
+		t.Fatal("protected_strings_test.go: Expected a map with other items not to be a protected string")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 /*Provider implements the REST API provider*/
@@ -36,6 +38,13 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_PASSWORD", nil),
 				Description: "When set, will use this password for BASIC auth to the API.",
 			},
+			"default_data": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_DEFAULT_DATA", nil),
+				ValidateFunc: validation.StringIsJSON,
+				Description:  "A JSON object deep-merged underneath the `data` of every `restapi_object` and `restapi_object_set` object, such as `{\"subtype\": \"managed\", \"tenantRef\": {\"oid\": \"...\"}}`. Values the resource sets win, and maps both set are merged. When `data` wraps its object in a single key such as `role` that `default_data` does not have, the defaults are merged into the wrapped object. Items that only come from `default_data` are left out of the `data` in state while the server still has their default value. Can also be set with the `REST_API_DEFAULT_DATA` environment variable.",
+			},
 			"headers": {
 				Type:        schema.TypeMap,
 				Elem:        schema.TypeString,
@@ -66,6 +75,13 @@ func Provider() *schema.Provider {
 				Description: "Defaults to `POST`. The HTTP method used to CREATE objects of this type on the API server.",
 				Optional:    true,
 			},
+			"read_cache_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_READ_CACHE_TTL", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "When set, a GET answered successfully is kept in memory for this many seconds, and identical GETs (same path, body and headers) in that time are answered from memory instead of the server. Identical GETs sent at the same time are sent once. Any request that changes objects (POST, other than midPoint searches, PUT, PATCH and DELETE) empties the cache. This cuts the time of refreshes that read the same objects several times. Defaults to `0` (off). Can also be set with the `REST_API_READ_CACHE_TTL` environment variable.",
+			},
 			"read_method": {
 				Type:        schema.TypeString,
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_READ_METHOD", nil),
@@ -75,22 +91,35 @@ func Provider() *schema.Provider {
 			"update_method": {
 				Type:        schema.TypeString,
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_UPDATE_METHOD", nil),
-				Description: "Defaults to `PUT`. The HTTP method used to UPDATE objects of this type on the API server. For Midpoint integration, set this to `PATCH` to enable calculating changes and sending them in Midpoint's ObjectModificationType format.",
+				Description: "Defaults to `PUT`. The HTTP method used to UPDATE objects of this type on the API server. For Midpoint integration, set this to `PATCH` to enable calculating changes and sending them in Midpoint's ObjectModificationType format. Setting `update_strategy` takes precedence over this behavior.",
 				Optional:    true,
 			},
+			"update_strategy": {
+				Type:         schema.TypeString,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_UPDATE_STRATEGY", nil),
+				Description:  "How updates are sent to the API server. One of `put` (send the full object with `update_method`), `midpoint-patch` (send only the changes as midPoint ObjectModificationType itemDeltas), `json-patch` (send an RFC 6902 JSON Patch document of `add`, `replace` and `remove` operations, with keys escaped in paths as RFC 6901 requires, with content type `application/json-patch+json`) or `merge-patch` (send an RFC 7386 JSON Merge Patch document with content type `application/merge-patch+json`). The patch strategies only remove items that were in `data` at the previous apply, never the `id_attribute` or items the server adds itself. If not set, `midpoint-patch` is used when `update_method` is `PATCH` and `put` otherwise.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(updateStrategies, false),
+			},
 			"destroy_method": {
 				Type:        schema.TypeString,
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_DESTROY_METHOD", nil),
 				Description: "Defaults to `DELETE`. The HTTP method used to DELETE objects of this type on the API server.",
 				Optional:    true,
 			},
+			"audit_log_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_AUDIT_LOG_PATH", nil),
+				Description: "When set, a JSON line is appended to this file for every request that changes objects (POST, other than midPoint searches, PUT, PATCH and DELETE), as an operator-side change journal: its time, method, path, status code, correlation id (see `correlation_id_header`) and the changes it made (the item deltas, JSON Patch operations or items of the written object). Values of protected strings and of items named like passwords, secrets, tokens or credentials are redacted.",
+			},
 			"copy_keys": {
 				Type: schema.TypeList,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 				Optional:    true,
-				Description: "When set, any PUT to the API for an object will copy these keys from the data the provider has gathered about the object. This is useful if internal API information must also be provided with updates, such as the revision of the object.",
+				Description: "When set, any PUT to the API for an object will copy these keys from the data the provider has gathered about the object. This is useful if internal API information must also be provided with updates, such as the revision of the object. Nested items are named by dotted paths, such as `metadata.createTimestamp`, and a `*` segment matches every key of a map or element of a list. Resources can set their own `copy_keys`.",
 			},
 			"write_returns_object": {
 				Type:        schema.TypeBool,
@@ -98,6 +127,12 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_WRO", nil),
 				Description: "Set this when the API returns the object created on all write operations (POST, PUT). This is used by the provider to refresh internal data structures.",
 			},
+			"correlation_id_header": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_CORRELATION_ID_HEADER", nil),
+				Description: "When set, such as to `X-Correlation-ID`, every request carries this header with a generated id: one id for all requests of an operation on an object (including their retries), so provider logs can be correlated with midPoint server logs and audit records. The id is logged with the outcome of every request and added to the errors of failed ones.",
+			},
 			"create_returns_object": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -110,12 +145,107 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_XSSI_PREFIX", nil),
 				Description: "Trim the xssi prefix from response string, if present, before parsing.",
 			},
+			"accept": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_ACCEPT", nil),
+				Description: "The `Accept` header of every request, such as `application/json`, to request a specific representation. Takes precedence over an `Accept` in `headers`. Resources with a `content_type` other than `json` accept their own format.",
+			},
+			"expect_content_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_EXPECT_CONTENT_TYPE", nil),
+				Description: "The media type every successful response with a body must have, such as `application/json`. Other responses fail with a diagnostic naming the content type received and the start of the body, instead of an error parsing it, which makes a proxy answering with an HTML login page obvious. Parameters such as `charset` are ignored and suffixed types such as `application/problem+json` match `application/json`. Responses to resources with a `content_type` other than `json` must be of that format when this is not set.",
+			},
 			"rate_limit": {
 				Type:        schema.TypeFloat,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_RATE_LIMIT", math.MaxFloat64),
 				Description: "Set this to limit the number of requests per second made to the API.",
 			},
+			"update_conflict_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_UPDATE_CONFLICT_RETRIES", 3),
+				Description: "Defaults to `3`. When a PATCH update is rejected with `409 Conflict` (for example because a midPoint recompute task modified the object concurrently), the provider re-reads the object, recomputes the deltas against the fresh state and retries up to this many times. Set to `0` to disable.",
+			},
+			"throttle_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_THROTTLE_RETRIES", 5),
+				Description: "Defaults to `5`. When a request is answered with `429 Too Many Requests`, the affected resource is deferred while Terraform continues with the others: the request is retried after a backoff (the `Retry-After` header, or one second doubling with every attempt up to a minute) once no other request is in flight, up to this many times. Set to `0` to fail on the first `429`.",
+			},
+			"concurrency_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_CONCURRENCY_RETRIES", 3),
+				Description: "Defaults to `3`. When a request fails with a midPoint error telling of concurrent work on the same objects or of a locking or repository serialization error (such as `Concurrent modification` or `could not serialize access`), whatever its status code, it is sent again after a backoff (half a second doubling with every attempt up to ten seconds), up to this many times. `409 Conflict` is left to `update_conflict_retries`. Set to `0` to disable.",
+			},
+			"mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_MODE", clientModeHTTP),
+				ValidateFunc: validation.StringInSlice([]string{clientModeHTTP, clientModeMemory}, false),
+				Description:  "Defaults to `http`. With `memory`, no request leaves Terraform: an in-process object store answers them, with the create, read, update, delete and search semantics of midPoint's REST API. Objects are kept for the run only, so module authors can test ignore lists, deltas and searches quickly and without network access. `uri` is still required, but any value will do; authentication settings are not used.",
+			},
+			"large_payload_threshold": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_LARGE_PAYLOAD_THRESHOLD", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "When set, every request that sends or receives a body of more than this many bytes is logged as a warning and reported in the `performance_warnings` of the `restapi_object` it was made for. Defaults to `0` (off). Can also be set with the `REST_API_LARGE_PAYLOAD_THRESHOLD` environment variable.",
+			},
+			"max_parallel_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_MAX_PARALLEL_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "When set, at most this many requests are in flight to the server at once, whatever Terraform's parallelism; the others wait for a free slot. This keeps many parallel calls to midPoint's comparatively slow REST endpoints from starving its repository threads. Unlike `create_concurrency`, it counts every request. Defaults to `0` (unlimited). Can also be set with the `REST_API_MAX_PARALLEL_REQUESTS` environment variable.",
+			},
+			"max_response_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_MAX_RESPONSE_SIZE", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "When set, a request whose response body is larger than this many bytes fails instead of being read whole, so a search returning far more objects than expected cannot exhaust the memory of the provider. Defaults to `0` (unlimited). Can also be set with the `REST_API_MAX_RESPONSE_SIZE` environment variable.",
+			},
+			"midpoint_mode": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_MIDPOINT_MODE", nil),
+				Description: "Whether `restapi_object` resources and the objects checked by `restapi_reconcile` ignore the items midPoint maintains itself, at any depth, as if they were in their `ignore_changes_to`: `@metadata`, `metadata`, `@ns`, `fetchResult`, `operationExecution`, `iteration`, `iterationToken`, `version`, `lastProvisioningTimestamp` and `linkRef`.",
+			},
+			"midpoint_patch_per_delta": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_MIDPOINT_PATCH_PER_DELTA", nil),
+				Description: "By default the `midpoint-patch` update strategy sends all changes of an object as a single ObjectModificationType with a list of itemDelta elements, so midPoint applies them atomically. Set this to send one PATCH request per changed attribute instead (the behavior of earlier versions).",
+			},
+			"delta_list_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_DELTA_LIST_PATH", nil),
+				Description: "When set (for example to midPoint's `/rpc/executeChanges`), updates with the `midpoint-patch` update strategy are sent as a POST of an `ObjectDeltaListType` to this path: a `restapi_object_set` sends the deltas of all its changed objects in one request, so midPoint applies them together, and a `restapi_object` sends its own. Only the objects whose delta succeeded are updated in state; an object the response has no result for counts as failed. The midPoint object type is derived from the collection at the end of `path`, such as `UserType` for `/users`. Can also be set with the `REST_API_DELTA_LIST_PATH` environment variable.",
+			},
+			"create_concurrency": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_CREATE_CONCURRENCY", 0),
+				Description: "When set, at most this many objects are created at the same time, regardless of Terraform's parallelism. Combined with `create_priority` on the resources, this keeps large hierarchies from being created all at once. Defaults to `0` (unlimited).",
+			},
+			"create_journal_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_CREATE_JOURNAL_FILE", nil),
+				Description: "When set, every create is recorded in this file (path, idempotency key and payload hash) until its outcome is known, and the idempotency key is sent in an `Idempotency-Key` header. If the provider crashes mid-apply, the next create of the same payload first looks for the object (by its id, or with `read_search`) and adopts it instead of creating a duplicate; otherwise the create is sent again with the same idempotency key.",
+			},
+			"slow_request_threshold": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("REST_API_SLOW_REQUEST_THRESHOLD", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "When set, every request that takes longer than this many milliseconds is logged as a warning and reported in the `performance_warnings` of the `restapi_object` it was made for, so slow requests show up during the apply. Defaults to `0` (off). Can also be set with the `REST_API_SLOW_REQUEST_THRESHOLD` environment variable.",
+			},
 			"test_path": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -128,6 +258,7 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_DEBUG", nil),
 				Description: "Enabling this will cause lots of debug information to be printed to STDOUT by the API client.",
 			},
+			"representation": representationSchema(),
 			"oauth_client_credentials": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -179,6 +310,12 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("REST_API_KEY_STRING", nil),
 				Description: "When set with the cert_string parameter, the provider will load a client certificate as a string for mTLS authentication. Note that this mechanism simply delegates to golang's tls.LoadX509KeyPair which does not support passphrase protected private keys. The most robust security protections available to the key_file are simple file system permissions.",
 			},
+			"bulk_refresh": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("REST_API_BULK_REFRESH", nil),
+				Description: "Whether refreshes of `restapi_object` resources that are read with a plain GET of `path/{id}` are grouped by `path`: the objects refreshed at about the same time are read with one midPoint search of `path/search` by their oids (up to 100 per search) instead of a GET each, which cuts the refresh of thousands of objects to a few requests. Objects the search does not return are read on their own. Can also be set with the `REST_API_BULK_REFRESH` environment variable.",
+			},
 			"cert_file": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -208,10 +345,32 @@ func Provider() *schema.Provider {
 			/* Could only get terraform to recognize this resource if
 			         the name began with the provider's name and had at least
 				 one underscore. This is not documented anywhere I could find */
-			"restapi_object": resourceRestAPI(),
+			"restapi_object":                          resourceRestAPI(),
+			"restapi_object_set":                      resourceRestAPIObjectSet(),
+			"restapi_midpoint_assignment":             resourceMidpointAssignment(),
+			"restapi_midpoint_mark":                   resourceMidpointMark(),
+			"restapi_midpoint_notification_transport": resourceMidpointNotificationTransport(),
+			"restapi_midpoint_policy":                 resourceMidpointPolicy(),
+			"restapi_midpoint_resource_import_task":   resourceMidpointResourceImportTask(),
+			"restapi_midpoint_role":                   resourceMidpointRole(),
+			"restapi_midpoint_user":                   resourceMidpointUser(),
+			"restapi_midpoint_user_password":          resourceMidpointUserPassword(),
+			"restapi_raw_request":                     resourceRawRequest(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"restapi_object": dataSourceRestAPI(),
+			"restapi_object":                   dataSourceRestAPI(),
+			"restapi_objects":                  dataSourceRestAPIObjects(),
+			"restapi_reconcile":                dataSourceRestAPIReconcile(),
+			"restapi_cases":                    dataSourceRestAPICases(),
+			"restapi_work_items":               dataSourceRestAPIWorkItems(),
+			"restapi_conflicts":                dataSourceRestAPIConflicts(),
+			"restapi_midpoint_objects":         dataSourceMidpointObjects(),
+			"restapi_midpoint_import_ids":      dataSourceMidpointImportIDs(),
+			"restapi_midpoint_resource_schema": dataSourceMidpointResourceSchema(),
+			"restapi_midpoint_role":            dataSourceMidpointRole(),
+			"restapi_midpoint_system_status":   dataSourceMidpointSystemStatus(),
+			"restapi_midpoint_task_status":     dataSourceMidpointTaskStatus(),
+			"restapi_midpoint_user":            dataSourceMidpointUser(),
 		},
 		ConfigureFunc: configureProvider,
 	}
@@ -235,21 +394,49 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 		}
 	}
 
+	var defaultData map[string]interface{}
+	if v, ok := d.GetOk("default_data"); ok {
+		if err := unmarshalJSON([]byte(v.(string)), &defaultData); err != nil {
+			return nil, fmt.Errorf("default_data is not a JSON object: %v", err)
+		}
+	}
+
 	opt := &apiClientOpt{
-		uri:                 d.Get("uri").(string),
-		insecure:            d.Get("insecure").(bool),
-		username:            d.Get("username").(string),
-		password:            d.Get("password").(string),
-		headers:             headers,
-		useCookies:          d.Get("use_cookies").(bool),
-		timeout:             d.Get("timeout").(int),
-		idAttribute:         d.Get("id_attribute").(string),
-		copyKeys:            copyKeys,
-		writeReturnsObject:  d.Get("write_returns_object").(bool),
-		createReturnsObject: d.Get("create_returns_object").(bool),
-		xssiPrefix:          d.Get("xssi_prefix").(string),
-		rateLimit:           d.Get("rate_limit").(float64),
-		debug:               d.Get("debug").(bool),
+		uri:                   d.Get("uri").(string),
+		insecure:              d.Get("insecure").(bool),
+		username:              d.Get("username").(string),
+		password:              d.Get("password").(string),
+		headers:               headers,
+		useCookies:            d.Get("use_cookies").(bool),
+		timeout:               d.Get("timeout").(int),
+		idAttribute:           d.Get("id_attribute").(string),
+		copyKeys:              copyKeys,
+		defaultData:           defaultData,
+		correlationHeader:     d.Get("correlation_id_header").(string),
+		auditLogPath:          d.Get("audit_log_path").(string),
+		slowRequest:           time.Duration(d.Get("slow_request_threshold").(int)) * time.Millisecond,
+		largePayload:          d.Get("large_payload_threshold").(int),
+		readCacheTTL:          time.Duration(d.Get("read_cache_ttl").(int)) * time.Second,
+		bulkRefresh:           d.Get("bulk_refresh").(bool),
+		maxResponseSize:       int64(d.Get("max_response_size").(int)),
+		maxParallel:           d.Get("max_parallel_requests").(int),
+		writeReturnsObject:    d.Get("write_returns_object").(bool),
+		createReturnsObject:   d.Get("create_returns_object").(bool),
+		xssiPrefix:            d.Get("xssi_prefix").(string),
+		accept:                d.Get("accept").(string),
+		expectContentType:     d.Get("expect_content_type").(string),
+		rateLimit:             d.Get("rate_limit").(float64),
+		conflictRetries:       d.Get("update_conflict_retries").(int),
+		throttleRetries:       d.Get("throttle_retries").(int),
+		concurrencyRetries:    d.Get("concurrency_retries").(int),
+		midpointPatchPerDelta: d.Get("midpoint_patch_per_delta").(bool),
+		deltaListPath:         d.Get("delta_list_path").(string),
+		createConcurrency:     d.Get("create_concurrency").(int),
+		createJournalFile:     d.Get("create_journal_file").(string),
+		mode:                  d.Get("mode").(string),
+		representations:       expandRepresentations(d.Get("representation").([]interface{})),
+		midpointMode:          d.Get("midpoint_mode").(bool),
+		debug:                 d.Get("debug").(bool),
 	}
 
 	if v, ok := d.GetOk("create_method"); ok {
@@ -261,6 +448,9 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 	if v, ok := d.GetOk("update_method"); ok {
 		opt.updateMethod = v.(string)
 	}
+	if v, ok := d.GetOk("update_strategy"); ok {
+		opt.updateStrategy = v.(string)
+	}
 	if v, ok := d.GetOk("destroy_method"); ok {
 		opt.destroyMethod = v.(string)
 	}
@@ -300,6 +490,7 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 		opt.rootCAString = v.(string)
 
 	}
+
 	client, err := NewAPIClient(opt)
 
 	if v, ok := d.GetOk("test_path"); ok {
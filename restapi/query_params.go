@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+/*
+encodeQueryParams URL-encodes the query_params of a resource. A value that is
+
+	a JSON array of strings, as from jsonencode(["isImport", "overwrite"]),
+	is sent as one parameter per element.
+*/
+func encodeQueryParams(params map[string]interface{}) string {
+	values := url.Values{}
+	for name, raw := range params {
+		value := raw.(string)
+		var list []string
+		if strings.HasPrefix(strings.TrimSpace(value), "[") && json.Unmarshal([]byte(value), &list) == nil {
+			values[name] = append(values[name], list...)
+			continue
+		}
+		values.Add(name, value)
+	}
+	/* url.Values encodes its keys in order, so the paths do not change between runs */
+	return values.Encode()
+}
+
+/* joinQueryStrings joins query strings with &, leaving out empty ones */
+func joinQueryStrings(queryStrings ...string) string {
+	parts := make([]string, 0, len(queryStrings))
+	for _, queryString := range queryStrings {
+		if queryString != "" {
+			parts = append(parts, queryString)
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,47 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestQueryParams(t *testing.T) {
+	encoded := encodeQueryParams(map[string]interface{}{
+		"options": `["raw", "noFetch"]`,
+		"name":    "Jürgen Müller/ext & co",
+	})
+	if encoded != "name=J%C3%BCrgen+M%C3%BCller%2Fext+%26+co&options=raw&options=noFetch" {
+		t.Fatalf("query_params_test.go: Expected the parameters encoded in order, got '%s'", encoded)
+	}
+	if joined := joinQueryStrings("", "a=1", "", "b=2"); joined != "a=1&b=2" {
+		t.Fatalf("query_params_test.go: Expected the empty query strings left out, got '%s'", joined)
+	}
+
+	var requested string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		w.Write([]byte(`{"id": "1", "name": "jsmith"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id"})
+	if err != nil {
+		t.Fatalf("query_params_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"id": "1", "name": "jsmith"}`,
+		"query_string": "include=all",
+		"query_params": map[string]interface{}{"options": `["resolveNames"]`, "filter": "name eq 'J Smith'"},
+	})
+	d.SetId("1")
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("query_params_test.go: Read failed: %s", err)
+	}
+	if requested != "/users/1?include=all&filter=name+eq+%27J+Smith%27&options=resolveNames" {
+		t.Fatalf("query_params_test.go: Expected the parameters after query_string, got '%s'", requested)
+	}
+}
@@ -0,0 +1,116 @@
+package restapi
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+readCache answers identical GET requests made within read_cache_ttl of each
+
+	other from memory, and makes concurrent identical GETs wait for the one
+	in flight instead of sending their own. Any request that changes objects
+	empties it, so a read never returns what was there before a write.
+*/
+type readCache struct {
+	ttl      time.Duration
+	mutex    sync.Mutex
+	entries  map[string]*readCacheEntry
+	inFlight map[string]*readCacheCall
+
+	/* Counts invalidations, so a GET sent before a write does not cache what it read */
+	generation int
+}
+
+type readCacheEntry struct {
+	body    string
+	headers http.Header
+	status  int
+	expires time.Time
+}
+
+/* A GET in flight; done is closed once its response is in entry */
+type readCacheCall struct {
+	done  chan struct{}
+	entry *readCacheEntry
+	err   error
+}
+
+func newReadCache(ttl time.Duration) *readCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &readCache{
+		ttl:      ttl,
+		entries:  make(map[string]*readCacheEntry),
+		inFlight: make(map[string]*readCacheCall),
+	}
+}
+
+/*
+readCacheKey identifies a request by everything that can change its response,
+
+	including the token scope it is authorized with: the clients of objects
+	with their own token_audience or token_scopes share the cache.
+*/
+func readCacheKey(method string, path string, data string, contentType string, extraHeaders map[string]string, skipHeader string, tokenScope string) string {
+	names := make([]string, 0, len(extraHeaders))
+	for name := range extraHeaders {
+		if name != skipHeader {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	key := []string{method, path, contentType, tokenScope, data}
+	for _, name := range names {
+		key = append(key, name+": "+extraHeaders[name])
+	}
+	return strings.Join(key, "\n")
+}
+
+/*
+get returns the cached response of the request with key, or sends it with
+
+	send, once for all callers asking at the same time. Only successful
+	responses are cached.
+*/
+func (c *readCache) get(key string, send func() (string, http.Header, int, error)) (string, http.Header, int, error) {
+	c.mutex.Lock()
+	if entry, ok := c.entries[key]; ok && time.Now().Before(entry.expires) {
+		c.mutex.Unlock()
+		return entry.body, entry.headers, entry.status, nil
+	}
+	if call, ok := c.inFlight[key]; ok {
+		c.mutex.Unlock()
+		<-call.done
+		return call.entry.body, call.entry.headers, call.entry.status, call.err
+	}
+	call := &readCacheCall{done: make(chan struct{})}
+	c.inFlight[key] = call
+	generation := c.generation
+	c.mutex.Unlock()
+
+	body, headers, status, err := send()
+	call.entry = &readCacheEntry{body: body, headers: headers, status: status, expires: time.Now().Add(c.ttl)}
+	call.err = err
+
+	c.mutex.Lock()
+	delete(c.inFlight, key)
+	if err == nil && generation == c.generation {
+		c.entries[key] = call.entry
+	}
+	c.mutex.Unlock()
+	close(call.done)
+	return body, headers, status, err
+}
+
+/* invalidate empties the cache after a request that changes objects */
+func (c *readCache) invalidate() {
+	c.mutex.Lock()
+	c.entries = make(map[string]*readCacheEntry)
+	c.generation++
+	c.mutex.Unlock()
+}
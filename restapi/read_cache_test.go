@@ -0,0 +1,103 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadCache(t *testing.T) {
+	var mutex sync.Mutex
+	gets := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			mutex.Lock()
+			gets++
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+		}
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, readCacheTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("read_cache_test.go: Failed to create API client: %s", err)
+	}
+
+	/* Concurrent identical reads are sent once */
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if body, err := client.sendRequest("GET", "/users/1", ""); err != nil || body != `{"id": "1"}` {
+				t.Errorf("read_cache_test.go: Unexpected response %s, %v", body, err)
+			}
+		}()
+	}
+	wg.Wait()
+	client.sendRequest("GET", "/users/1", "")
+	if gets != 1 {
+		t.Fatalf("read_cache_test.go: Expected one GET for identical reads, got %d", gets)
+	}
+
+	/* Other paths and searches do not share it, writes empty it */
+	client.sendRequest("GET", "/users/2", "")
+	client.sendRequest("POST", "/users/search", `{}`)
+	client.sendRequest("GET", "/users/1", "")
+	if gets != 2 {
+		t.Fatalf("read_cache_test.go: Expected only the other path to be read, got %d GETs", gets)
+	}
+	client.sendRequest("PATCH", "/users/1", `{}`)
+	client.sendRequest("GET", "/users/1", "")
+	if gets != 3 {
+		t.Fatalf("read_cache_test.go: Expected the write to empty the cache, got %d GETs", gets)
+	}
+
+	/* Entries expire */
+	client.readCache.ttl = time.Millisecond
+	client.sendRequest("GET", "/users/3", "")
+	time.Sleep(5 * time.Millisecond)
+	client.sendRequest("GET", "/users/3", "")
+	if gets != 5 {
+		t.Fatalf("read_cache_test.go: Expected expired entries to be read again, got %d GETs", gets)
+	}
+}
+
+func TestReadCacheTokenScopes(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"` + r.Form.Get("scope") + `","token_type":"bearer"}`))
+			return
+		}
+		/* The response depends on the token the request is authorized with */
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{
+		uri:               svr.URL,
+		timeout:           2,
+		readCacheTTL:      time.Minute,
+		oauthClientID:     "terraform",
+		oauthClientSecret: "secret",
+		oauthTokenURL:     svr.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("read_cache_test.go: Failed to create API client: %s", err)
+	}
+	reader, _ := client.withTokenScope("", []string{"users.read"})
+	admin, _ := client.withTokenScope("", []string{"users.admin"})
+
+	if body, err := reader.sendRequest("GET", "/users/1", ""); err != nil || body != "Bearer users.read" {
+		t.Fatalf("read_cache_test.go: Unexpected response %s, %v", body, err)
+	}
+	if body, err := admin.sendRequest("GET", "/users/1", ""); err != nil || body != "Bearer users.admin" {
+		t.Fatalf("read_cache_test.go: Expected the read with other scopes not to be answered from the cache, got %s, %v", body, err)
+	}
+}
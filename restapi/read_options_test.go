@@ -0,0 +1,46 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestReadIncludeExclude(t *testing.T) {
+	var queries []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			queries = append(queries, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"user": {"oid": "user-1", "name": "jsmith", "description": "Accountant"}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid"})
+	if err != nil {
+		t.Fatalf("read_options_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"user": {"oid": "user-1", "name": "jsmith", "description": "Accountant", "jpegPhoto": "aGVsbG8="}}`,
+		"read_include": []interface{}{"description"},
+		"read_exclude": []interface{}{"jpegPhoto", "credentials/password"},
+	})
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("read_options_test.go: Read failed: %s", err)
+	}
+	if len(queries) != 1 || queries[0] != "exclude=jpegPhoto&exclude=credentials%2Fpassword&include=description" {
+		t.Fatalf("read_options_test.go: Expected the retrieve options in the read, got %v", queries)
+	}
+	if changed := d.Get("changed_keys").([]interface{}); len(changed) != 0 {
+		t.Fatalf("read_options_test.go: Expected the excluded items not to be changes, got %v", changed)
+	}
+
+	expected := []string{"jpegPhoto", "*.jpegPhoto", "credentials.password", "*.credentials.password"}
+	if patterns := readExcludePatterns([]string{"jpegPhoto", "/credentials/password"}); !reflect.DeepEqual(patterns, expected) {
+		t.Fatalf("read_options_test.go: Expected %v, got %v", expected, patterns)
+	}
+}
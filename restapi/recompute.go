@@ -0,0 +1,29 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+/*
+recomputeAfterWrite asks midPoint to recompute the object after it was created
+
+	or updated, when recompute_after_write is set, so that its projections
+	and computed items reflect the write right away instead of after the
+	next recompute or reconciliation task.
+*/
+func (obj *APIObject) recomputeAfterWrite() error {
+	if !obj.recompute || obj.id == "" {
+		return nil
+	}
+
+	recomputePath := strings.Replace(obj.getPath, "{id}", obj.id, -1) + "/recompute"
+	if obj.debug {
+		log.Printf("recompute.go: Recomputing '%s' at '%s'", obj.id, recomputePath)
+	}
+	if _, err := obj.apiClient.sendRequest("POST", recomputePath, ""); err != nil {
+		return fmt.Errorf("'%s' was written, but recomputing it failed: %v", obj.id, err)
+	}
+	return nil
+}
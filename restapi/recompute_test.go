@@ -0,0 +1,74 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRecomputeAfterWrite(t *testing.T) {
+	requests := make([]string, 0)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/recompute"):
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" || r.Method == "POST" || r.Method == "PUT":
+			w.Write([]byte(`{"id": "1", "name": "jsmith"}`))
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id", writeReturnsObject: true})
+	if err != nil {
+		t.Fatalf("recompute_test.go: Failed to create API client: %s", err)
+	}
+
+	for _, recompute := range []bool{false, true} {
+		requests = requests[:0]
+		d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":                  "/users",
+			"data":                  `{"id": "1", "name": "jsmith"}`,
+			"recompute_after_write": recompute,
+		})
+		if err := resourceRestAPICreate(d, client); err != nil {
+			t.Fatalf("recompute_test.go: Create failed: %s", err)
+		}
+		if err := resourceRestAPIUpdate(d, client); err != nil {
+			t.Fatalf("recompute_test.go: Update failed: %s", err)
+		}
+
+		expected := []string{"POST /users", "PUT /users/1"}
+		if recompute {
+			expected = []string{"POST /users", "POST /users/1/recompute", "PUT /users/1", "POST /users/1/recompute"}
+		}
+		if !reflect.DeepEqual(requests, expected) {
+			t.Fatalf("recompute_test.go: Expected the requests %v with recompute_after_write %t, got %v", expected, recompute, requests)
+		}
+	}
+}
+
+func TestRecomputeAfterWriteMidpointType(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("recompute_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceMidpointUser().Schema, map[string]interface{}{"name": "jsmith", "recompute_after_write": true})
+	if err := midpointUser.create(d, client); err != nil {
+		t.Fatalf("recompute_test.go: Create failed: %s", err)
+	}
+	d.Set("given_name", "John")
+	if err := midpointUser.update(d, client); err != nil {
+		t.Fatalf("recompute_test.go: Update failed: %s", err)
+	}
+
+	obj, _ := NewAPIObject(client, &apiObjectOpts{path: "/users", id: "missing", recompute: true})
+	if err := obj.recomputeAfterWrite(); err == nil || !strings.Contains(err.Error(), "'missing' was written, but recomputing it failed") {
+		t.Fatalf("recompute_test.go: Expected recomputing a missing object to fail, got %v", err)
+	}
+}
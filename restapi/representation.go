@@ -0,0 +1,218 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/*
+representationProfile is a named way of managing objects: the fields
+
+	Terraform manages, the fields whose changes are ignored and the update
+	strategy. Resources select one by name, so workspaces can manage the
+	same objects at different levels of strictness with the same module code.
+*/
+type representationProfile struct {
+	fields          []string
+	ignoreChangesTo []string
+	updateStrategy  string
+}
+
+func representationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Named representation profiles that resources select with their `representation` attribute. A profile sets the fields Terraform manages, the fields whose changes are ignored and the update strategy, so workspaces can manage the same objects at different levels of strictness without divergent module code.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The name resources use to select this profile.",
+				},
+				"fields": {
+					Type:        schema.TypeList,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Optional:    true,
+					Description: "The dotted paths (such as `displayName` or `activation.administrativeStatus`) Terraform manages. When set, every other field of `data` and of the object on the server is ignored. Use with a patch `update_strategy`, as a `put` only sends the managed fields.",
+				},
+				"ignore_changes_to": {
+					Type:        schema.TypeList,
+					Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateIgnorePattern},
+					Optional:    true,
+					Description: "Patterns added to the `ignore_changes_to` of the resources using this profile.",
+				},
+				"update_strategy": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Description:  "The `update_strategy` of the resources using this profile that do not set their own.",
+					ValidateFunc: validation.StringInSlice(updateStrategies, false),
+				},
+			},
+		},
+	}
+}
+
+/* expandRepresentations returns the profiles of the provider's representation blocks by name */
+func expandRepresentations(v []interface{}) map[string]*representationProfile {
+	profiles := make(map[string]*representationProfile, len(v))
+	for _, raw := range v {
+		p := raw.(map[string]interface{})
+		profiles[p["name"].(string)] = &representationProfile{
+			fields:          expandStringList(p["fields"].([]interface{})),
+			ignoreChangesTo: expandStringList(p["ignore_changes_to"].([]interface{})),
+			updateStrategy:  p["update_strategy"].(string),
+		}
+	}
+	return profiles
+}
+
+/* lookupRepresentation returns the named profile of the client's provider, or nil when name is empty */
+func (client *APIClient) lookupRepresentation(name string) (*representationProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := client.representations[name]
+	if !ok {
+		return nil, fmt.Errorf("representation '%s' is not defined in the provider configuration", name)
+	}
+	return profile, nil
+}
+
+/* ignoreList returns the ignore list entries the profile adds to a resource */
+func (profile *representationProfile) ignoreList() []string {
+	if profile == nil {
+		return []string{}
+	}
+	return profile.ignoreChangesTo
+}
+
+/* managedFields returns the fields the profile restricts a resource to */
+func (profile *representationProfile) managedFields() []string {
+	if profile == nil {
+		return []string{}
+	}
+	return profile.fields
+}
+
+/*
+setRepresentation resolves the representation profile of a resource with the
+
+	client of its provider and records the profile's fields and patterns in
+	state, where diff suppression, which is not given the client, finds them.
+*/
+func setRepresentation(d *schema.ResourceData, client *APIClient) error {
+	profile, err := client.lookupRepresentation(d.Get("representation").(string))
+	if err != nil {
+		return err
+	}
+	d.Set("representation_fields", profile.managedFields())
+	d.Set("representation_ignore_changes_to", profile.ignoreList())
+	return nil
+}
+
+/* customizeDiffRepresentation plans the fields and patterns of the representation profile a resource selects */
+func customizeDiffRepresentation(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil {
+		return nil
+	}
+	if !d.NewValueKnown("representation") {
+		if err := d.SetNewComputed("representation_fields"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("representation_ignore_changes_to")
+	}
+	profile, err := meta.(*APIClient).lookupRepresentation(d.Get("representation").(string))
+	if err != nil {
+		return err
+	}
+	for key, value := range map[string][]string{
+		"representation_fields":            profile.managedFields(),
+		"representation_ignore_changes_to": profile.ignoreList(),
+	} {
+		if reflect.DeepEqual(expandStringList(d.Get(key).([]interface{})), value) {
+			continue
+		}
+		if err := d.SetNew(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+filterManagedFields returns data restricted to the managed field paths, or
+
+	data itself when no fields are managed. A path such as "activation" keeps
+	the whole value, while "activation.administrativeStatus" keeps only that
+	item of it, in each element when activation is a list.
+*/
+func filterManagedFields(data map[string]interface{}, fields []string) map[string]interface{} {
+	if len(fields) == 0 || data == nil {
+		return data
+	}
+
+	/* The paths below each managed key, nil when the key is managed as a whole */
+	below := make(map[string][]string)
+	for _, field := range fields {
+		name, rest := field, ""
+		if i := strings.Index(field, "."); i >= 0 {
+			name, rest = field[:i], field[i+1:]
+		}
+		name = strings.TrimSuffix(name, "[*]")
+		paths, seen := below[name]
+		switch {
+		case rest == "" || seen && paths == nil:
+			below[name] = nil
+		default:
+			below[name] = append(paths, rest)
+		}
+	}
+
+	result := make(map[string]interface{})
+	for key, value := range data {
+		paths, ok := below[key]
+		if !ok {
+			continue
+		}
+		result[key] = filterManagedValue(value, paths)
+	}
+	return result
+}
+
+/*
+filterManagedValue restricts a value to the managed paths below it, all of
+
+	it when paths is nil. Container ids are kept, as they tell which value
+	on the server an element is.
+*/
+func filterManagedValue(value interface{}, paths []string) interface{} {
+	if paths == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		filtered := filterManagedFields(v, paths)
+		if id, ok := v["@id"]; ok {
+			filtered["@id"] = id
+		}
+		return filtered
+	case []interface{}:
+		filtered := make([]interface{}, len(v))
+		for i, elem := range v {
+			filtered[i] = filterManagedValue(elem, paths)
+		}
+		return filtered
+	}
+	return value
+}
+
+/* managedOnly returns data restricted to the object's managed fields */
+func (obj *APIObject) managedOnly(data map[string]interface{}) map[string]interface{} {
+	return filterManagedFields(data, obj.managedFields)
+}
@@ -0,0 +1,183 @@
+package restapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestManagedFields(t *testing.T) {
+	managed := []string{"displayName", "activation.administrativeStatus", "inducement"}
+
+	current := MapAny{
+		"displayName": "Role",
+		"description": "maintained elsewhere",
+		"activation":  MapAny{"administrativeStatus": "enabled", "validFrom": "2020"},
+		"inducement":  []interface{}{MapAny{"targetRef": "r1", "@id": 1}},
+	}
+	desired := MapAny{
+		"displayName": "Role",
+		"activation":  MapAny{"administrativeStatus": "enabled"},
+		"inducement":  []interface{}{MapAny{"targetRef": "r1", "@id": 1}},
+	}
+
+	if _, changed := getDelta(filterManagedFields(desired, managed), filterManagedFields(current, managed), nil, nil); changed {
+		t.Fatalf("representation_test.go: Expected fields outside of the managed fields to be left out")
+	}
+
+	current["inducement"].([]interface{})[0].(MapAny)["targetRef"] = "r2"
+	if changed := changedKeys(filterManagedFields(desired, managed), filterManagedFields(current, managed), nil, nil); !reflect.DeepEqual(changed, []string{"inducement[0].targetRef"}) {
+		t.Fatalf("representation_test.go: Expected a change below a field managed as a whole, got %v", changed)
+	}
+
+	filtered := filterManagedFields(current, managed)
+	expected := MapAny{
+		"displayName": "Role",
+		"activation":  MapAny{"administrativeStatus": "enabled"},
+		"inducement":  []interface{}{MapAny{"targetRef": "r2", "@id": 1}},
+	}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Fatalf("representation_test.go: Expected %v, got %v", expected, filtered)
+	}
+
+	/* Container ids stay with the managed items of a value */
+	filtered = filterManagedFields(current, []string{"inducement.targetRef"})
+	expected = MapAny{"inducement": []interface{}{MapAny{"targetRef": "r2", "@id": 1}}}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Fatalf("representation_test.go: Expected %v, got %v", expected, filtered)
+	}
+
+	if filtered := filterManagedFields(current, nil); !reflect.DeepEqual(filtered, current) {
+		t.Fatalf("representation_test.go: Expected all of the data without managed fields, got %v", filtered)
+	}
+
+	/* An ignore entry starting with "+" names a field like any other */
+	ignored := filterIgnoredFields(MapAny{"+1": "vote", "displayName": "Role"}, []string{"+1"})
+	if !reflect.DeepEqual(ignored, MapAny{"displayName": "Role"}) {
+		t.Fatalf("representation_test.go: Expected only the '+1' field to be ignored, got %v", ignored)
+	}
+}
+
+func TestRepresentation(t *testing.T) {
+	/* Two aliased providers defining the same profile differently */
+	newClient := func(fields ...interface{}) *APIClient {
+		t.Helper()
+		client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1, representations: expandRepresentations([]interface{}{
+			map[string]interface{}{
+				"name":              "loose",
+				"fields":            fields,
+				"ignore_changes_to": []interface{}{"re:/.*Timestamp$/"},
+				"update_strategy":   updateStrategyMidpointPatch,
+			},
+		})})
+		if err != nil {
+			t.Fatalf("representation_test.go: Failed to create API client: %s", err)
+		}
+		return client
+	}
+	loose, looser := newClient("name", "displayName"), newClient("name")
+
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":              "/roles",
+		"data":              `{"name": "r1", "displayName": "Role"}`,
+		"ignore_changes_to": []interface{}{"metadata"},
+		"representation":    "loose",
+	})
+
+	opts, err := buildAPIObjectOpts(d, loose)
+	if err != nil {
+		t.Fatalf("representation_test.go: Failed to build object options: %s", err)
+	}
+	if opts.updateStrategy != updateStrategyMidpointPatch {
+		t.Fatalf("representation_test.go: Expected the profile's update strategy, got '%s'", opts.updateStrategy)
+	}
+
+	expected := []string{"metadata", "re:/.*Timestamp$/"}
+	if ignoreList := getIgnoreList(d); !reflect.DeepEqual(ignoreList, expected) {
+		t.Fatalf("representation_test.go: Expected ignore list %v, got %v", expected, ignoreList)
+	}
+	if managed := getManagedFields(d); !reflect.DeepEqual(managed, []string{"name", "displayName"}) {
+		t.Fatalf("representation_test.go: Expected the profile's managed fields, got %v", managed)
+	}
+
+	old := `{"name": "r1", "displayName": "Role", "description": "set by other tooling"}`
+	if !suppressDiffForIgnoredFields("data", old, `{"name": "r1", "displayName": "Role"}`, d) {
+		t.Fatalf("representation_test.go: Expected differences outside of the profile's fields to be suppressed")
+	}
+
+	/* The other provider's profile does not change this one's */
+	if opts, err := buildAPIObjectOpts(d, looser); err != nil || !reflect.DeepEqual(opts.managedFields, []string{"name"}) {
+		t.Fatalf("representation_test.go: Expected the managed fields of the resource's own provider, got %v (%v)", opts, err)
+	}
+	if opts, err := buildAPIObjectOpts(d, loose); err != nil || !reflect.DeepEqual(opts.managedFields, []string{"name", "displayName"}) {
+		t.Fatalf("representation_test.go: Expected the managed fields of the resource's own provider, got %v (%v)", opts, err)
+	}
+
+	/* The profile is planned with the resource's provider */
+	state := &terraform.InstanceState{ID: "r1", Attributes: map[string]string{"id": "r1", "path": "/roles", "data": `{"name": "r1", "displayName": "Role"}`, "representation": "loose"}}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"path": "/roles", "data": `{"name": "r1", "displayName": "Role"}`, "representation": "loose"})
+	diff, err := resourceRestAPI().Diff(context.TODO(), state, config, looser)
+	if err != nil {
+		t.Fatalf("representation_test.go: Diff failed: %s", err)
+	}
+	if attr := diff.Attributes["representation_fields.0"]; attr == nil || attr.New != "name" || diff.Attributes["representation_fields.1"] != nil {
+		t.Fatalf("representation_test.go: Expected the planned fields of the resource's provider, got %v", diff.Attributes)
+	}
+
+	d.Set("representation", "strict")
+	if _, err := buildAPIObjectOpts(d, loose); err == nil {
+		t.Fatalf("representation_test.go: Expected an unknown representation to be an error")
+	}
+	if _, err := resourceRestAPI().Diff(context.TODO(), state, terraform.NewResourceConfigRaw(map[string]interface{}{"path": "/roles", "data": "{}", "representation": "strict"}), loose); err == nil {
+		t.Fatalf("representation_test.go: Expected an unknown representation to fail the plan")
+	}
+}
+
+func TestManagedFieldsPatch(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":            "/roles",
+		"data":            `{"role": {"displayName": "New", "description": "mine", "inducement": [{"targetRef": {"oid": "r1"}}]}}`,
+		"update_strategy": updateStrategyMidpointPatch,
+		"managed_fields":  []interface{}{"role.displayName", "role.inducement"},
+	})
+	if ignoreList := getIgnoreList(d); len(ignoreList) != 0 {
+		t.Fatalf("representation_test.go: Expected managed fields to stay out of the ignore list, got %v", ignoreList)
+	}
+
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1})
+	if err != nil {
+		t.Fatalf("representation_test.go: Failed to create API client: %s", err)
+	}
+	opts, err := buildAPIObjectOpts(d, client)
+	if err != nil {
+		t.Fatalf("representation_test.go: Failed to build object options: %s", err)
+	}
+	obj, err := NewAPIObject(client, opts)
+	if err != nil {
+		t.Fatalf("representation_test.go: Failed to create API object: %s", err)
+	}
+	if !reflect.DeepEqual(obj.managedFields, []string{"role.displayName", "role.inducement"}) {
+		t.Fatalf("representation_test.go: Unexpected managed fields %v", obj.managedFields)
+	}
+	obj.apiData = MapAny{"role": MapAny{
+		"displayName": "Old",
+		"description": "set by other tooling",
+		"inducement":  []interface{}{MapAny{"targetRef": MapAny{"oid": "r1"}}, MapAny{"targetRef": MapAny{"oid": "r2"}}},
+		"activation":  MapAny{"administrativeStatus": "enabled"},
+	}}
+
+	planned, err := obj.plannedDeltas()
+	if err != nil {
+		t.Fatalf("representation_test.go: Failed to compute deltas: %s", err)
+	}
+	expected := []string{
+		`{"modificationType":"replace","path":"displayName","value":"New"}`,
+		`{"modificationType":"replace","path":"inducement","value":[{"targetRef":{"oid":"r1"}}]}`,
+	}
+	if !reflect.DeepEqual(planned, expected) {
+		t.Fatalf("representation_test.go: Expected only the managed fields to be patched: expected %v, got %v", expected, planned)
+	}
+}
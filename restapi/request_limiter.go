@@ -0,0 +1,46 @@
+package restapi
+
+import (
+	"log"
+	"time"
+)
+
+/*
+requestLimiter caps the requests in flight to the server at once, whatever
+
+	Terraform's parallelism, queuing the others. midPoint's REST endpoints
+	are slow enough that many parallel calls starve its repository threads.
+	A nil requestLimiter does not limit anything.
+*/
+type requestLimiter struct {
+	slots chan struct{}
+}
+
+func newRequestLimiter(limit int) *requestLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &requestLimiter{slots: make(chan struct{}, limit)}
+}
+
+/* acquire blocks until fewer than the limit of requests are in flight */
+func (l *requestLimiter) acquire(method string, path string) {
+	if l == nil {
+		return
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return
+	default:
+	}
+	queued := time.Now()
+	l.slots <- struct{}{}
+	log.Printf("request_limiter.go: %s %s waited %s for one of the %d max_parallel_requests", method, path, time.Since(queued).Round(time.Millisecond), cap(l.slots))
+}
+
+func (l *requestLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,50 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMaxParallelRequests(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, maxParallel: 2})
+	if err != nil {
+		t.Fatalf("request_limiter_test.go: Failed to create API client: %s", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.sendRequest("GET", "/users", ""); err != nil {
+				t.Errorf("request_limiter_test.go: Request failed: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 2 {
+		t.Fatalf("request_limiter_test.go: Expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+	if len(client.limiter.slots) != 0 {
+		t.Fatal("request_limiter_test.go: Expected every slot to be released")
+	}
+}
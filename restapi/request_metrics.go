@@ -0,0 +1,114 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+/*
+Counters of the requests a provider sent, summarized in the log when the
+
+	provider shuts down to show where the time of a long apply went.
+*/
+type requestMetrics struct {
+	uri           string
+	mutex         sync.Mutex
+	started       time.Time
+	requests      int
+	byStatus      map[string]int
+	retries       int
+	bytesSent     int64
+	bytesReceived int64
+	latency       time.Duration
+	slowest       time.Duration
+	slowestLabel  string
+}
+
+/* The metrics of every configured provider, logged by LogRequestMetrics */
+var (
+	allRequestMetrics      []*requestMetrics
+	allRequestMetricsMutex sync.Mutex
+)
+
+func newRequestMetrics(uri string) *requestMetrics {
+	metrics := &requestMetrics{uri: uri, started: time.Now(), byStatus: make(map[string]int)}
+	allRequestMetricsMutex.Lock()
+	allRequestMetrics = append(allRequestMetrics, metrics)
+	allRequestMetricsMutex.Unlock()
+	return metrics
+}
+
+/* record counts a request answered with status, or with 0 when no response was received */
+func (m *requestMetrics) record(method string, path string, status int, sent int, received int, latency time.Duration) {
+	if m == nil {
+		return
+	}
+	outcome := "error"
+	if status != 0 {
+		outcome = fmt.Sprintf("%d", status)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests++
+	m.byStatus[method+" "+outcome]++
+	m.bytesSent += int64(sent)
+	m.bytesReceived += int64(received)
+	m.latency += latency
+	if latency > m.slowest {
+		m.slowest = latency
+		m.slowestLabel = method + " " + path
+	}
+}
+
+/* retried counts a request that is sent again */
+func (m *requestMetrics) retried() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	m.retries++
+	m.mutex.Unlock()
+}
+
+/* summary returns the counters as a JSON object */
+func (m *requestMetrics) summary() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	average := time.Duration(0)
+	if m.requests > 0 {
+		average = m.latency / time.Duration(m.requests)
+	}
+	encoded, _ := json.Marshal(map[string]interface{}{
+		"uri":                m.uri,
+		"elapsed_seconds":    time.Since(m.started).Seconds(),
+		"requests":           m.requests,
+		"requests_by_status": m.byStatus,
+		"retries":            m.retries,
+		"bytes_sent":         m.bytesSent,
+		"bytes_received":     m.bytesReceived,
+		"latency_seconds":    m.latency.Seconds(),
+		"average_latency_ms": average.Milliseconds(),
+		"slowest_request":    m.slowestLabel,
+		"slowest_latency_ms": m.slowest.Milliseconds(),
+	})
+	return string(encoded)
+}
+
+/*
+LogRequestMetrics logs a summary of the requests of every provider
+
+	configured in this process. It is called when the provider shuts down.
+*/
+func LogRequestMetrics() {
+	allRequestMetricsMutex.Lock()
+	defer allRequestMetricsMutex.Unlock()
+	for _, metrics := range allRequestMetrics {
+		if metrics.requests == 0 {
+			continue
+		}
+		log.Printf("[INFO] request_metrics.go: Request summary: %s", metrics.summary())
+	}
+}
@@ -0,0 +1,49 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	defer func(delay time.Duration) { throttleRetryDelay = delay }(throttleRetryDelay)
+	throttleRetryDelay = time.Millisecond
+
+	attempts := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, throttleRetries: 3})
+	if err != nil {
+		t.Fatalf("request_metrics_test.go: Failed to create API client: %s", err)
+	}
+	if _, err := client.sendRequest("PUT", "/users/1", `{"id": "1"}`); err != nil {
+		t.Fatalf("request_metrics_test.go: Request failed: %s", err)
+	}
+	if _, err := client.sendRequest("GET", "/users/1", ""); err != nil {
+		t.Fatalf("request_metrics_test.go: Request failed: %s", err)
+	}
+
+	var summary map[string]interface{}
+	if err := json.Unmarshal([]byte(client.metrics.summary()), &summary); err != nil {
+		t.Fatalf("request_metrics_test.go: Expected a JSON summary: %s", err)
+	}
+	byStatus := summary["requests_by_status"].(map[string]interface{})
+	if summary["requests"] != 3.0 || summary["retries"] != 1.0 || byStatus["PUT 429"] != 1.0 || byStatus["PUT 200"] != 1.0 || byStatus["GET 200"] != 1.0 {
+		t.Fatalf("request_metrics_test.go: Unexpected summary %v", summary)
+	}
+	if summary["bytes_sent"] != 22.0 || summary["bytes_received"] != 22.0 {
+		t.Fatalf("request_metrics_test.go: Unexpected byte counts in %v", summary)
+	}
+	LogRequestMetrics()
+}
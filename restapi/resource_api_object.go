@@ -1,15 +1,22 @@
 package restapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/url"
 	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceRestAPI() *schema.Resource {
@@ -17,17 +24,23 @@ func resourceRestAPI() *schema.Resource {
 	isDataSensitive, _ := strconv.ParseBool(GetEnvOrDefault("API_DATA_IS_SENSITIVE", "false"))
 
 	return &schema.Resource{
-		Create: resourceRestAPICreate,
-		Read:   resourceRestAPIRead,
-		Update: resourceRestAPIUpdate,
-		Delete: resourceRestAPIDelete,
-		Exists: resourceRestAPIExists,
+		Create:      resourceRestAPICreate,
+		ReadContext: resourceRestAPIReadContext,
+		Update:      resourceRestAPIUpdate,
+		Delete:      resourceRestAPIDelete,
+		Exists:      resourceRestAPIExists,
+
+		CustomizeDiff: customdiff.All(customizeDiffRepresentation, customizeDiffMidpointMode, customizeDiffBinaryHashes, customizeDiffGeneratedOID, customizeDiffPlannedDeltas, customizeDiffIgnoreWarnings, customizeDiffServerDrift),
 
 		Description: "Acting as a wrapper of cURL, this object supports POST, GET, PUT and DELETE on the specified url",
 
 		Importer: &schema.ResourceImporter{
 			State: resourceRestAPIImport,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"path": {
@@ -37,18 +50,188 @@ func resourceRestAPI() *schema.Resource {
 			},
 			"create_path": {
 				Type:        schema.TypeString,
-				Description: "Defaults to `path`. The API path that represents where to CREATE (POST) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object if the data contains the `id_attribute`.",
+				Description: "Defaults to `path`. The API path that represents where to CREATE (POST) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object if the data contains the `id_attribute`. `{data.<path>}`, such as `{data.name}` or `{data.user.name}`, is replaced with the item at that dotted path of `data`, path escaped.",
 				Optional:    true,
 			},
 			"read_path": {
 				Type:        schema.TypeString,
-				Description: "Defaults to `path/{id}`. The API path that represents where to READ (GET) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object.",
+				Description: "Defaults to `path/{id}`. The API path that represents where to READ (GET) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object. `{data.<path>}`, such as `{data.name}` or `{data.user.name}`, is replaced with the item at that dotted path of `data`, path escaped.",
 				Optional:    true,
 			},
 			"update_path": {
 				Type:        schema.TypeString,
-				Description: "Defaults to `path/{id}`. The API path that represents where to UPDATE (PUT) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object.",
+				Description: "Defaults to `path/{id}`. The API path that represents where to UPDATE (PUT) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object. `{data.<path>}`, such as `{data.name}` or `{data.user.name}`, is replaced with the item at that dotted path of `data`, path escaped.",
+				Optional:    true,
+			},
+			"patch_path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `update_path`. The API path that represents where to send the PATCH requests of the `midpoint-patch`, `json-patch` and `merge-patch` update strategies, for gateways that route modifications to a different endpoint (such as `/objects/{id}/delta`) than updates. The string `{id}` will be replaced with the terraform ID of the object. `{data.<path>}`, such as `{data.name}` or `{data.user.name}`, is replaced with the item at that dotted path of `data`, path escaped.",
+				Optional:    true,
+			},
+			"update_options": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "midPoint execute options (such as `raw`, `reconcile` or `force`) sent as `options` query parameters on updates only. Unlike `query_string`, they are also sent by the `midpoint-patch` update strategy.",
+			},
+			"update_precondition": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "A condition on the object on the server that must hold before an update sends any modification, so Terraform coexists with manual governance processes. It is a JSONPath such as `$.role.lifecycleState`, optionally followed by `==` or `!=` and a JSON value or bare word (`$.role.lifecycleState == active`), or negated with `!` to require a field to be absent or false; comparisons combine with `&&` and `||`. Paths not found at the root are looked up below the key wrapping the object, so `lifecycleState == active` works too. The object is read right before the update. See `update_precondition_action`.",
+				ValidateFunc: validatePrecondition,
+			},
+			"update_precondition_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      preconditionActionFail,
+				Description:  "What an update does when `update_precondition` does not hold: `fail` (the default) fails the apply, `skip` logs a warning and leaves the object and the `data` in state unchanged, so the update is planned again once the condition holds.",
+				ValidateFunc: validation.StringInSlice(preconditionActions, false),
+			},
+			"recompute_after_write": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether to have midPoint recompute the object (`POST path/{id}/recompute`) after it is created or updated, so its projections are refreshed right away instead of by the next recompute or reconciliation task. To recompute within the update itself, set `update_options` to `[\"reconcile\"]` instead.",
+			},
+			"test_on_apply": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "For midPoint resources: whether to run midPoint's testResource operation (`POST path/{id}/test`) after the resource is created or updated, failing the apply with the messages of the failed steps when its connection, credentials or schema test fails.",
+			},
+			"approval_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      approvalActionFail,
+				Description:  "What a create or update does when midPoint hands it to an approval process instead of applying it, answering with an `in_progress` result that refers to a case: `fail` (the default) fails the apply with the oid of the case, `wait` reads the case every few seconds until it is closed, failing when it was rejected or not closed within the create or update timeout, and `record` stores the oid of the case in `pending_case_oid` and keeps the configured `data` in state. While that case is open, refreshes leave the state as it is; once it is closed, the object is read again, so a rejected write shows up as drift and is planned again. `record` needs the id of a created object in `data`.",
+				ValidateFunc: validation.StringInSlice(approvalActions, false),
+			},
+			"cases_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "/cases",
+				Description: "Defaults to `/cases`. The API path of midPoint cases, read by the `wait` and `record` approval actions.",
+			},
+			"upsert": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether creates and updates both `PUT` the whole object to `update_path` with midPoint's `overwrite` option, which adds the object or replaces the one with the same id. Applies are then idempotent, even when the object exists but is not in state. Takes precedence over `create_method`, `create_path`, `update_method` and `update_strategy`. Needs the id of the object in `data`, or `generate_oid`.",
+			},
+			"singleton": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Whether `path` is the one object itself rather than a collection of objects, as for fixed-oid midPoint objects such as `/systemConfigurations/00000000-0000-0000-0000-000000000001` or other one-per-tenant configuration documents. Reads, updates and deletes then go to `path` without `/{id}` appended, and the create is a `create_method` request to `path`, typically with `create_method = \"PUT\"`. Without an id in `data`, the object is known by its path. Import such an object with `?singleton=true` after its path.",
+			},
+			"copy_keys": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Keys copied from the object on the server into the data sent with updates, replacing the provider's `copy_keys` for this resource. Nested items are named by dotted paths, such as `metadata.createTimestamp` or `role.oid`, and a `*` segment matches every key of a map or element of a list, such as `assignment.*.id`. Elements of lists are only copied into lists that `data` already has.",
+			},
+			"read_include": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "midPoint item paths, such as `jpegPhoto`, that reads of the object include although midPoint does not return them by default. They are sent as `include` retrieve options.",
+			},
+			"read_exclude": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "midPoint item paths, such as `jpegPhoto` or `fetchResult`, that reads of the object leave out, so refreshes do not transfer large items that are not managed. They are sent as `exclude` retrieve options and added to `ignore_changes_to` (with `/` separated paths such as `credentials/password` as `credentials.password`, also below a wrapping key such as `user`), so their absence is not a change.",
+			},
+			"retry": retrySchema(),
+			"warn_on_partial_error": {
+				Type:        schema.TypeBool,
 				Optional:    true,
+				Description: "Whether midPoint operation results with a `partial_error`, `handled_error` or `warning` status, which midPoint sends with codes such as 240 and 250 or as errors, are reported as warnings instead of failing the apply. They are logged as warnings and kept in `result_warnings`.",
+			},
+			"wait_for_task": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "For midPoint objects: whether a create or update that midPoint hands to a background task, answering with a result that refers to the task (e.g. with some `update_options`), waits for that task. The apply only succeeds once the task is closed with a successful result; a task that is suspended or fails fails the apply with its operation result, as does one that does not complete within the create or update timeout.",
+			},
+			"task_poll_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				Description:  "Defaults to `5`. How many seconds `wait_for_task` waits between reads of the task.",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"tasks_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "/tasks",
+				Description: "Defaults to `/tasks`. The API path of midPoint tasks, read by `wait_for_task`.",
+			},
+			"pending_case_oid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The oid of the approval case the last write awaits, with the `record` approval action.",
+			},
+			"adopt_existing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether a create that conflicts with an existing object (`409 Conflict`, or a message saying the object already exists) adopts that object instead of failing. The object is looked up with a midPoint search at `path/search` for the value of `data` at `adopt_key`; its oid becomes the id of the resource and `data` is applied to it as an update. The create still fails when no object or more than one object has that value.",
+			},
+			"adopt_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "name",
+				Description: "Defaults to `name`. The '/'-delimited item path of `data` (below the key wrapping the object, if any) whose value identifies the existing object `adopt_existing` adopts, such as `name` or `extension/employeeNumber`.",
+			},
+			"destroy_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      destroyActionDelete,
+				Description:  "What destroying the resource does with the object, for objects that policy forbids to delete: `delete` (the default) deletes it, `archive` sets its `lifecycleState` to `archive_lifecycle_state`, `disable` sets its `activation/administrativeStatus` to `disabled`, and `abandon` only removes it from state. `archive` and `disable` send midPoint modifications to `update_path`.",
+				ValidateFunc: validation.StringInSlice(destroyActions, false),
+			},
+			"archive_lifecycle_state": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "archived",
+				Description: "Defaults to `archived`. The lifecycleState the `archive` destroy action sets.",
+			},
+			"prevent_destroy_remote": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether destroying the resource fails instead of deleting, archiving or disabling the object, protecting critical objects (such as the system configuration or security policies) from an accidental `terraform destroy` of a whole workspace, including replacements. Unlike the `prevent_destroy` lifecycle argument, it is kept in state, so it also protects objects whose configuration was removed. To destroy the object, set it to `false` and apply first. The `abandon` destroy action, which leaves the object on the server, is allowed.",
+			},
+			"verify_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether a destroy that deletes the object reads it until the server answers `404`, because midPoint may process deletes asynchronously and creating an object with the same name fails until it is gone. The destroy fails when the object is still returned after `destroy_timeout`.",
+			},
+			"destroy_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60,
+				Description:  "Defaults to `60`. How many seconds `verify_destroy` waits for the deleted object to be gone.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"destroy_options": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "midPoint execute options (such as `raw` or `force`) sent as `options` query parameters on deletes only.",
+			},
+			"content_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      contentTypeJSONPayload,
+				ValidateFunc: validation.StringInSlice(payloadFormats, false),
+				Description:  "The format objects are exchanged with the API server in: `json`, `xml` or `yaml`. `data` is still given as JSON; to manage objects kept in YAML files, set it to `jsonencode(yamldecode(file(...)))`. Request bodies are converted to the format and sent with its media type, and responses are converted back, so they are compared with `data` as usual. With `xml`, `data` needs a single root key such as `user`, which is sent as midPoint XML in the common-3 namespace; keys starting with `@` become attributes and `#text` the text of an element. As XML has no types, set `normalize` with `numeric_strings` and `boolean_strings` for objects with numbers or booleans, and give lists with a single value as that value. Default: `json`",
+			},
+			"token_audience": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The audience OAuth tokens for this object are requested for, sent as the `audience` parameter to the token endpoint. Lets one provider manage objects in services that only accept tokens issued for them, such as midPoint and a SCIM gateway behind the same identity provider. Requires `oauth_client_credentials` in the provider.",
+			},
+			"token_scopes": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "The scopes OAuth tokens for this object are requested with, instead of the provider's `oauth_scopes`. Requires `oauth_client_credentials` in the provider.",
 			},
 			"create_method": {
 				Type:        schema.TypeString,
@@ -62,9 +245,15 @@ func resourceRestAPI() *schema.Resource {
 			},
 			"update_method": {
 				Type:        schema.TypeString,
-				Description: "Defaults to `update_method` set on the provider. Allows per-resource override of `update_method` (see `update_method` provider config documentation). Set to `PATCH` for Midpoint integration to enable calculating changes and sending them in Midpoint's ObjectModificationType format.",
+				Description: "Defaults to `update_method` set on the provider. Allows per-resource override of `update_method` (see `update_method` provider config documentation). Set to `PATCH` for Midpoint integration to enable calculating changes and sending them in Midpoint's ObjectModificationType format. Setting `update_strategy` takes precedence over this behavior.",
 				Optional:    true,
 			},
+			"update_strategy": {
+				Type:         schema.TypeString,
+				Description:  "Defaults to `update_strategy` set on the provider. Allows per-resource override of `update_strategy` (see `update_strategy` provider config documentation)",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(updateStrategies, false),
+			},
 			"destroy_method": {
 				Type:        schema.TypeString,
 				Description: "Defaults to `destroy_method` set on the provider. Allows per-resource override of `destroy_method` (see `destroy_method` provider config documentation)",
@@ -72,7 +261,7 @@ func resourceRestAPI() *schema.Resource {
 			},
 			"destroy_path": {
 				Type:        schema.TypeString,
-				Description: "Defaults to `path/{id}`. The API path that represents where to DESTROY (DELETE) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object.",
+				Description: "Defaults to `path/{id}`. The API path that represents where to DESTROY (DELETE) objects of this type on the API server. The string `{id}` will be replaced with the terraform ID of the object. `{data.<path>}`, such as `{data.name}` or `{data.user.name}`, is replaced with the item at that dotted path of `data`, path escaped.",
 				Optional:    true,
 			},
 			"id_attribute": {
@@ -80,6 +269,21 @@ func resourceRestAPI() *schema.Resource {
 				Description: "Defaults to `id_attribute` set on the provider. Allows per-resource override of `id_attribute` (see `id_attribute` provider config documentation)",
 				Optional:    true,
 			},
+			"create_priority": {
+				Type:        schema.TypeInt,
+				Description: "Defaults to `0`. A best-effort ordering of the creates Terraform runs in parallel: a create waits while a create with a lower `create_priority` is waiting or running, so parents (orgs, roles) mostly go before their dependents and fewer creates conflict. A create that reaches the provider before a create with a lower priority is not held back, as the provider does not know which creates the plan still holds, so use `depends_on` or a reference when the order must be guaranteed.",
+				Optional:    true,
+			},
+			"generate_oid": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether to generate the oid of a new object when planning it, so `oid` is known at plan time and other objects created in the same apply can refer to it without `(known after apply)`. The oid is added to the object in `data` (below the key wrapping it, if any), and the object is created with a `PUT` to `update_path` instead of a `POST` to `create_path`. `data` must not set a different oid.",
+			},
+			"oid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The id of the object. With `generate_oid`, it is known when the object is planned.",
+			},
 			"object_id": {
 				Type:        schema.TypeString,
 				Description: "Defaults to the id learned by the provider during normal operations and `id_attribute`. Allows you to set the id manually. This is used in conjunction with the `*_path` attributes.",
@@ -113,9 +317,69 @@ func resourceRestAPI() *schema.Resource {
 				Description: "Custom search for `read_path`. This map will take `search_data`, `search_key`, `search_value`, `results_key` and `query_string` (see datasource config documentation)",
 				Optional:    true,
 			},
+			"read_results_key": {
+				Type:        schema.TypeString,
+				Description: "Set when the read is a search that returns a list of results instead of the object, such as a `POST` to midPoint's `/users/search` (set `read_method`, `read_path` and a `read_data` query; `{id}` in `read_data` will be replaced with the terraform ID of the object). The '/'-delimited location of the results array in the response, for example `object/object`. The result whose `id_attribute` matches the object's id is used as the object, and the object is removed from state when no result matches. A read response that is itself an array is always treated this way. Also set when a plain read returns the object wrapped in an envelope such as `{\"result\": {...}}`: when the location holds an object instead of an array (here `result`), that object is used, so the envelope does not end up in `api_data` and in the comparison with `data`.",
+				Optional:    true,
+			},
+			"response_wrapper_key": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `request_wrapper_key`. The '/'-delimited location of the object in the responses of the API, such as `object` or `result/items/0`, for APIs returning it in an envelope. The object at this location is what `data` is compared with, instead of the whole response; when `data` holds the object below a single key, such as midPoint's `{\"user\": {...}}`, it is compared below that key. midPoint modifications are then computed below that key, instead of below the single key that `data` and the response are guessed to share. `api_response` still holds the whole response.",
+				Optional:    true,
+			},
+			"request_wrapper_key": {
+				Type:        schema.TypeString,
+				Description: "The '/'-delimited location the API expects the object at in the bodies of writes, such as `user` for midPoint's `{\"user\": {...}}`. `data` then holds the bare object, which creates, `PUT` updates and JSON Patch and JSON Merge Patch documents wrap in this envelope, so state and diffs hold only the content of the object. Also the default of `response_wrapper_key`.",
+				Optional:    true,
+			},
+			"pagination": paginationSchema(),
+			"read_field_paths": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Additional read endpoints for objects whose representation is split across several paths. Each key is a '/'-delimited location in the object (for example `membership` or `user/roleMembershipRef`) and each value is the API path to read it from; `{id}` will be replaced with the terraform ID of the object. After the main read, every path is read with `read_method` and the decoded response is merged into the object at its key so it is compared and patched together with the rest of the object.",
+				Optional:    true,
+			},
+			"list_merge_keys": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only used with the `midpoint-patch` update strategy. Maps multi-value container attributes (such as `assignment` or `inducement`) to a '/'-delimited key inside each value (such as `targetRef/oid`). When such an attribute changes, values are matched by this key and only the values that were added or removed are sent as `add`/`delete` itemDeltas instead of replacing the whole container. Container values that already carry a midPoint container id (`@id` or `id`) are always matched to their current value and only the items that changed inside them are modified, so unchanged values keep their id and metadata.",
+				Optional:    true,
+			},
+			"path_namespaces": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only used with the `midpoint-patch` update strategy. Maps namespace prefixes to namespace URIs (for example `my = \"http://example.com/my\"`). When set, changes to the `extension` container are sent as one itemDelta per extension attribute with a qualified path such as `extension/my:costCenter`, and every itemDelta path using one of the prefixes is sent with a `declare namespace` clause. Unqualified extension attributes use the prefix of the extension's `@ns`, or the only prefix if just one is configured.",
+				Optional:    true,
+			},
 			"query_string": {
 				Type:        schema.TypeString,
-				Description: "Query string to be included in the path",
+				Description: "Query string to be included in the path of every request whose own `create_query_string`, `read_query_string`, `update_query_string` or `destroy_query_string` is not set. It is not sent with midPoint modifications.",
+				Optional:    true,
+			},
+			"query_params": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Query parameters by name, added after `query_string` and sent wherever it is; the `<operation>_query_string` attributes replace them too. Names and values are URL-encoded, so values may hold spaces, slashes or any unicode characters. To send a parameter more than once, as midPoint's `options`, give its values as a JSON array of strings, such as `jsonencode([\"raw\", \"noFetch\"])`.",
+				Optional:    true,
+			},
+			"create_query_string": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `query_string`. Query string to be included in the path of the create request, such as `options=isImport&options=overwrite`.",
+				Optional:    true,
+			},
+			"read_query_string": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `query_string`. Query string to be included in the path of read requests, such as `options=resolveNames`.",
+				Optional:    true,
+			},
+			"update_query_string": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `query_string`. Query string to be included in the path of update requests. Unlike `query_string`, it is also sent with midPoint modifications (`update_strategy = \"midpoint-patch\"`), as `query_string` usually holds create options midPoint does not accept there.",
+				Optional:    true,
+			},
+			"destroy_query_string": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `query_string`. Query string to be included in the path of the destroy request.",
 				Optional:    true,
 			},
 			"api_data": {
@@ -139,6 +403,26 @@ func resourceRestAPI() *schema.Resource {
 				Computed:    true,
 				Sensitive:   isDataSensitive,
 			},
+			"changed_keys": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The dotted paths (such as `activation.administrativeStatus` or `assignment[1].targetRef`) at which the object on the server differed from state at the last refresh, after applying `ignore_changes_to`. A list whose length changed is reported as a whole. Empty when nothing drifted or when `ignore_all_server_changes` is set. A refresh that finds drift also warns with the drifted paths and their values in state and on the server, with sensitive values redacted.",
+				Computed:    true,
+			},
+			"planned_deltas": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The modifications the planned update will send, as JSON: one midPoint itemDelta per element for `midpoint-patch`, one RFC 6902 operation per element for `json-patch`, the merge patch document for `merge-patch` and none for `put`.",
+				Computed:    true,
+				Sensitive:   isDataSensitive,
+			},
+			"last_applied_deltas": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The modifications the last update actually sent, as JSON, in the format of `planned_deltas`, so audits can see exactly what Terraform changed. Empty when the last update sent none or used the `put` strategy.",
+				Computed:    true,
+				Sensitive:   isDataSensitive,
+			},
 			"force_new": {
 				Type:        schema.TypeList,
 				Elem:        &schema.Schema{Type: schema.TypeString},
@@ -199,11 +483,77 @@ func resourceRestAPI() *schema.Resource {
 			},
 			"ignore_changes_to": {
 				Type:        schema.TypeList,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateIgnorePattern},
 				Optional:    true,
-				Description: "A list of fields to which remote changes will be ignored. For example, an API might add or remove metadata, such as a 'last_modified' field, which Terraform should not attempt to correct. To ignore changes to nested fields, use the dot syntax: 'metadata.timestamp'",
+				Description: "A list of fields to which remote changes will be ignored. For example, an API might add or remove metadata, such as a 'last_modified' field, which Terraform should not attempt to correct. To ignore changes to nested fields, use the dot syntax: 'metadata.timestamp'. To ignore fields inside list elements, use 'assignment[*].metadata' for every element or 'items[2].value' for one element; 'linkRef[*]' ignores all elements of a list. 'metadata.*' ignores all children of a field, '**.approvalRef' matches a path below any key at any depth, and 're:/.*Timestamp$/' ignores every field whose name matches a regular expression.",
 				Sensitive:   isDataSensitive,
-				// TODO ValidateFunc not supported for lists, but should probably validate that the ignore paths are valid
+			},
+			"result_warnings": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "The operation results reported as warnings by the last create or update, with `warn_on_partial_error`.",
+			},
+			"performance_warnings": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "The requests of the last create or update that exceeded the provider's `slow_request_threshold` or `large_payload_threshold`.",
+			},
+			"ignore_changes_to_warnings": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Warnings about `ignore_changes_to` entries that can never take effect, computed at plan time so a typo does not silently result in perpetual drift. Each names the entry and its kind: `impossible pattern` (the pattern cannot match any field, such as `meta*` or `user/name`), `never matches data` (the path crosses a value of `data` that is not an object, such as `name.orig` when `name` is a string) or `redundant pattern` (another entry already ignores it).",
+				Computed:    true,
+			},
+			"managed_fields": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "The inverse of `ignore_changes_to`: when set, Terraform only diffs and patches these dotted paths (such as `role.displayName` and `role.inducement`) and every other field of `data` and of the object on the server is left untouched, so objects can be co-managed with other tooling. A path managed as a whole includes everything below it. Use with a patch `update_strategy`, as a `put` only sends the managed fields.",
+			},
+			"protected_fields": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Dotted paths (such as `activation` or `credentials`) the patch update strategies never delete, nor delete values of, even when they are missing from `data`, so a field left out of the configuration by mistake cannot lock users out. Paths below a protected field are protected too. With a wrapper key such as `user`, paths may be given with or without it.",
+			},
+			"representation": {
+				Type:        schema.TypeString,
+				Description: "The name of a `representation` profile of the provider. The profile's `ignore_changes_to` patterns are added to this resource's, its `fields` restrict the fields Terraform manages, and its `update_strategy` is used when this resource does not set one.",
+				Optional:    true,
+			},
+			"midpoint_mode": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether `midpoint_mode` is set on this resource's provider, which ignores the items midPoint maintains itself.",
+			},
+			"representation_fields": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "The `fields` of the `representation` profile, as resolved from the configuration of this resource's provider.",
+			},
+			"representation_ignore_changes_to": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "The `ignore_changes_to` patterns of the `representation` profile, as resolved from the configuration of this resource's provider.",
+			},
+			"shallow_read": shallowReadSchema(),
+			"read_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the object returned by the `shallow_read` request at the last full read.",
+			},
+			"normalize":            normalizeSchema(),
+			"binary_field":         binaryFieldSchema(),
+			"post_create_requests": postCreateRequestsSchema(),
+			"binary_hashes": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+				Description: "The SHA-256 hashes (`sha256:<hex>`) of the `binary_field` properties by path. A change of the configured content, or of the content on the server, shows up as a change of its hash.",
 			},
 			"ignore_all_server_changes": {
 				Type:        schema.TypeBool,
@@ -211,6 +561,12 @@ func resourceRestAPI() *schema.Resource {
 				Optional:    true,
 				Default:     false,
 			},
+			"update_data_from_api": {
+				Type:        schema.TypeBool,
+				Description: "Defaults to `true`, storing the object read from the server (without ignored fields) in `data` at every refresh. Set to `false` to keep `data` in state as configured, with its key order and the items the server fills with acceptable defaults. Drift is then reported by a warning and by planning an update that shows `changed_keys` changing, and the update patches the drifted items back.",
+				Optional:    true,
+				Default:     true,
+			},
 		}, /* End schema */
 
 	}
@@ -226,46 +582,84 @@ Since there is nothing in the ResourceData structure other
 func resourceRestAPIImport(d *schema.ResourceData, meta interface{}) (imported []*schema.ResourceData, err error) {
 	input := d.Id()
 
-	hasTrailingSlash := strings.HasSuffix(input, "/")
-	var n int
-	if hasTrailingSlash {
-		n = strings.LastIndex(input[0:len(input)-1], "/")
-	} else {
-		n = strings.LastIndex(input, "/")
+	/* An ID such as users:name=jdoe names the object by one of its items instead */
+	if !strings.HasPrefix(input, "/") {
+		if input, err = resolveImportSearch(input, meta.(*APIClient)); err != nil {
+			return imported, err
+		}
 	}
 
-	if n == -1 {
-		return imported, fmt.Errorf("invalid path to import api_object '%s' - must be /<full path from server root>/<object id>", input)
+	/* Options the configuration will set can be given after the path, so the
+	   import stores the object the way reads after it will */
+	var options url.Values
+	if i := strings.Index(input, "?"); i >= 0 {
+		if options, err = url.ParseQuery(input[i+1:]); err != nil {
+			return imported, fmt.Errorf("invalid import options in '%s': %v", input, err)
+		}
+		input = input[0:i]
 	}
 
-	path := input[0:n]
-	d.Set("path", path)
-
-	var id string
-	if hasTrailingSlash {
-		id = input[n+1 : len(input)-1]
+	if options.Get("singleton") == "true" {
+		/* A singleton is known by its path, which is the whole import ID */
+		d.Set("path", input)
+		d.Set("data", "{}")
+		d.SetId(input)
 	} else {
-		id = input[n+1:]
-	}
+		hasTrailingSlash := strings.HasSuffix(input, "/")
+		var n int
+		if hasTrailingSlash {
+			n = strings.LastIndex(input[0:len(input)-1], "/")
+		} else {
+			n = strings.LastIndex(input, "/")
+		}
 
-	d.Set("data", fmt.Sprintf(`{ "id": "%s" }`, id))
-	d.SetId(id)
+		if n == -1 {
+			return imported, fmt.Errorf("invalid path to import api_object '%s' - must be /<full path from server root>/<object id>", input)
+		}
 
-	/* Troubleshooting is hard enough. Emit log messages so TF_LOG
-	   has useful information in case an import isn't working */
-	d.Set("debug", true)
+		path := input[0:n]
+		d.Set("path", path)
+
+		var id string
+		if hasTrailingSlash {
+			id = input[n+1 : len(input)-1]
+		} else {
+			id = input[n+1:]
+		}
+
+		d.Set("data", fmt.Sprintf(`{ "id": "%s" }`, id))
+		d.SetId(id)
+	}
+
+	if err := setImportOptions(d, options); err != nil {
+		return imported, err
+	}
 
 	obj, err := makeAPIObject(d, meta)
 	if err != nil {
 		return imported, err
 	}
-	if obj.debug {
-		log.Printf("resource_api_object.go: Import routine called. Object built:\n%s\n", obj.toString())
-	}
+
+	/* Troubleshooting is hard enough. Emit log messages so TF_LOG
+	   has useful information in case an import isn't working. debug
+	   is not stored, so generated configuration does not set it */
+	obj.debug = true
+	log.Printf("resource_api_object.go: Import routine called. Object built:\n%s\n", obj.toString())
 
 	err = obj.readObject()
 	if err == nil {
 		setResourceState(obj, d)
+
+		/* Store the object without its ignored fields, as the reads after the import do,
+		   so configuration generated with plan -generate-config-out has the whole object */
+		if !d.Get("ignore_all_server_changes").(bool) {
+			encoded, err := canonicalJSON(filterIgnoredFields(obj.managedOnly(obj.apiData), getIgnoreList(d)))
+			if err != nil {
+				return imported, err
+			}
+			d.Set("data", encoded)
+		}
+
 		/* Data that we set in the state above must be passed along
 		   as an item in the stack of imported data */
 		imported = append(imported, d)
@@ -274,27 +668,178 @@ func resourceRestAPIImport(d *schema.ResourceData, meta interface{}) (imported [
 	return imported, err
 }
 
+/* The attributes that can be set as query parameters of an import ID */
+var importOptions = []string{"representation", "ignore_changes_to", "singleton", "read_results_key", "read_path", "read_method", "read_data", "read_search.<key>", "id_attribute", "query_string", "read_query_string"}
+
+/* isImportOption reports whether name is one of importOptions, such as read_search.search_key */
+func isImportOption(name string) bool {
+	if key := strings.TrimPrefix(name, "read_search."); key != name {
+		return key != ""
+	}
+	return containsString(importOptions, name)
+}
+
+/*
+resolveImportSearch turns an import ID naming the object by one of its items,
+
+	such as users:name=jdoe or roles?name=Finance Approver, into the
+	/<path>/<oid> form by searching for the object at /<path>/search. Import
+	options may follow as further query parameters, as in
+	users:name=jdoe?representation=strict.
+*/
+func resolveImportSearch(input string, client *APIClient) (string, error) {
+	i := strings.IndexAny(input, ":?")
+	if i <= 0 {
+		return "", fmt.Errorf("invalid ID to import api_object '%s' - must be /<full path from server root>/<object id>, or <type>:<item>=<value> such as users:name=jdoe", input)
+	}
+	path := "/" + strings.Trim(input[0:i], "/")
+	query := input[i+1:]
+	if input[i] == ':' {
+		query = strings.Replace(query, "?", "&", 1)
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid import search in '%s': %v", input, err)
+	}
+
+	options := url.Values{}
+	key, value := "", ""
+	for name, values := range params {
+		if isImportOption(name) {
+			options[name] = values
+			continue
+		}
+		if key != "" {
+			return "", fmt.Errorf("the import ID '%s' names more than one item to search by: %s and %s", input, key, name)
+		}
+		key, value = name, strings.Join(values, ",")
+	}
+	if key == "" || value == "" {
+		return "", fmt.Errorf("the import ID '%s' does not name an item and value to search by, such as name=jdoe", input)
+	}
+
+	oid, err := searchOIDByItem(client, path+"/search", key, value, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to import '%s': %v", input, err)
+	}
+	log.Printf("resource_api_object.go: Import ID '%s' resolved to the object '%s' at '%s'", input, oid, path)
+
+	resolved := path + "/" + oid
+	if len(options) > 0 {
+		resolved += "?" + options.Encode()
+	}
+	return resolved, nil
+}
+
+/*
+setImportOptions sets the attributes given as query parameters of an import
+
+	ID, such as /users/1234?representation=strict&ignore_changes_to=metadata,operationExecution.
+	Set to the values of the configuration, the first plan after the import
+	compares data without the fields the configuration ignores, and objects
+	behind nonstandard endpoints are read the way the configuration reads
+	them. Entries of read_search are given as read_search.<key>.
+*/
+func setImportOptions(d *schema.ResourceData, options url.Values) error {
+	for name, values := range options {
+		value := strings.Join(values, ",")
+		if !isImportOption(name) {
+			return fmt.Errorf("unknown import option '%s'; must be one of: %s", name, strings.Join(importOptions, ", "))
+		}
+		switch name {
+		case "ignore_changes_to":
+			patterns := strings.Split(value, ",")
+			for _, pattern := range patterns {
+				if _, errs := validateIgnorePattern(pattern, name); len(errs) > 0 {
+					return errs[0]
+				}
+			}
+			d.Set(name, patterns)
+		case "singleton":
+			d.Set(name, value == "true")
+		default:
+			if key := strings.TrimPrefix(name, "read_search."); key != name {
+				readSearch := d.Get("read_search").(map[string]interface{})
+				readSearch[key] = value
+				d.Set("read_search", readSearch)
+				continue
+			}
+			d.Set(name, value)
+		}
+	}
+	return nil
+}
+
 func resourceRestAPICreate(d *schema.ResourceData, meta interface{}) error {
 	obj, err := makeAPIObject(d, meta)
 	if err != nil {
 		return err
 	}
+	obj.taskTimeout = d.Timeout(schema.TimeoutCreate)
+	obj.retryUntil(obj.taskTimeout)
+	if d.Get("generate_oid").(bool) {
+		if err := obj.useGeneratedOID(d.Get("oid").(string)); err != nil {
+			return err
+		}
+	}
 	if obj.debug {
 		log.Printf("resource_api_object.go: Create routine called. Object built:\n%s\n", obj.toString())
 	}
 
 	err = obj.createObject()
+	if createConflicted(err) && d.Get("adopt_existing").(bool) {
+		if adoptErr := obj.adoptExistingObject(d.Get("adopt_key").(string), strings.TrimSuffix(d.Get("path").(string), "/")+"/search"); adoptErr != nil {
+			err = fmt.Errorf("%v; adopting the existing object failed: %w", err, adoptErr)
+		} else {
+			err = nil
+		}
+	}
+	var pending *approvalPendingError
+	if errors.As(err, &pending) {
+		var recorded bool
+		if recorded, err = handlePendingApproval(d, obj, pending, d.Timeout(schema.TimeoutCreate)); recorded {
+			/* Nothing is created until the case approves it; data stays as configured */
+			d.SetId(pending.id)
+			return nil
+		}
+	}
+	if err == nil {
+		err = obj.sendPostCreateRequests()
+	}
 	if err == nil {
 		/* Setting terraform ID tells terraform the object was created or it exists */
 		d.SetId(obj.id)
+		d.Set("oid", obj.id)
 		setResourceState(obj, d)
+		/* The version changed with the write, so the next refresh reads the whole object */
+		d.Set("read_version", "")
 		/* Only set during create for APIs that don't return sensitive data on subsequent retrieval */
 		d.Set("create_response", obj.apiResponse)
+		d.Set("result_warnings", obj.warnings.list())
+		d.Set("performance_warnings", obj.perfWarnings.list())
+		err = obj.recomputeAfterWrite()
+		if err == nil {
+			err = obj.testAfterWrite()
+		}
 	}
 	return err
 }
 
 func resourceRestAPIRead(d *schema.ResourceData, meta interface{}) error {
+	return readRestAPIObject(d, meta, nil)
+}
+
+/* resourceRestAPIReadContext reads the object, warning about the paths that drifted on the server */
+func resourceRestAPIReadContext(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if err := readRestAPIObject(d, meta, &diags); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+/* readRestAPIObject reads the object into state. Drift found is added to drift as a warning when it is not nil */
+func readRestAPIObject(d *schema.ResourceData, meta interface{}, drift *diag.Diagnostics) error {
 	obj, err := makeAPIObject(d, meta)
 	if err != nil {
 		if strings.Contains(err.Error(), "error parsing data provided") {
@@ -309,53 +854,90 @@ func resourceRestAPIRead(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("resource_api_object.go: Read routine called. Object built:\n%s\n", obj.toString())
 	}
 
-	err = obj.readObject()
+	/* A write awaiting approval is not on the server yet */
+	if open, err := pendingApprovalOpen(d, meta.(*APIClient)); err != nil || open {
+		return err
+	}
+
+	/* An unchanged version means the object in state is still current */
+	version, projection, err := obj.readVersion()
+	if err != nil {
+		log.Printf("resource_api_object.go: Failed to read the version of '%s', reading the whole object: %v", obj.id, err)
+		version = ""
+	}
+	if obj.shallowReadCurrent(version, d.Get("read_version").(string), projection, append(getIgnoreList(d), binaryPaths(obj.binaryFields)...)) {
+		log.Printf("resource_api_object.go: Version '%s' of '%s' is unchanged, skipping the full read", version, obj.id)
+		return nil
+	}
+
+	err = obj.refreshObject()
 	if err == nil {
 		/* Setting terraform ID tells terraform the object was created or it exists */
 		log.Printf("resource_api_object.go: Read resource. Returned id is '%s'\n", obj.id)
 		d.SetId(obj.id)
+		d.Set("oid", obj.id)
 
 		setResourceState(obj, d)
+		d.Set("read_version", version)
 
 		// Check whether the remote resource has changed.
 		if !(d.Get("ignore_all_server_changes")).(bool) {
-			ignoreList := []string{}
-			v, ok := d.GetOk("ignore_changes_to")
-			if ok {
-				for _, s := range v.([]interface{}) {
-					ignoreList = append(ignoreList, s.(string))
-				}
-			}
+			/* Binary properties are compared by binary_hashes instead */
+			ignoreList := append(getIgnoreList(d), binaryPaths(obj.binaryFields)...)
 
-			// Filter ignored fields from state data before comparison
+			// Filter ignored and unmanaged fields from state data before comparison
 			// This ensures obj.data doesn't contain server-managed fields
-			stateData := obj.data
+			stateData := obj.managedOnly(obj.data)
+			apiData := obj.managedOnly(obj.apiData)
 			if len(ignoreList) > 0 {
-				stateData = filterIgnoredFields(obj.data, ignoreList)
+				stateData = filterIgnoredFields(stateData, ignoreList)
 			}
 
 			// This checks if there were any changes to the remote resource that will need to be corrected
 			// by comparing the filtered state with the response returned by the api.
-			_, hasDifferences := getDelta(stateData, obj.apiData, ignoreList)
+			_, hasDifferences := getDelta(stateData, apiData, ignoreList, obj.normalize)
 
+			changed := make([]string, 0)
 			if hasDifferences {
 				log.Printf("resource_api_object.go: Found differences in remote resource\n")
+				changed = changedKeys(stateData, apiData, ignoreList, obj.normalize)
+				if drift != nil {
+					*drift = append(*drift, driftWarning(obj.id, stateData, apiData, changed))
+				}
+			}
+			d.Set("changed_keys", changed)
+
+			// The configured data is kept in state; the drift is planned by customizeDiffServerDrift
+			if !d.Get("update_data_from_api").(bool) {
+				if hasDifferences {
+					log.Printf("[WARN] resource_api_object.go: The object '%s' drifted on the server at %s", obj.id, strings.Join(changed, ", "))
+				}
+				return nil
 			}
 
 			// Always store the filtered API data in state (what's currently in the API)
 			// This ensures state reflects reality, minus the ignored fields
-			dataToStore := obj.apiData
+			dataToStore := apiData
 			if len(ignoreList) > 0 {
-				dataToStore = filterIgnoredFields(obj.apiData, ignoreList)
+				dataToStore = filterIgnoredFields(apiData, ignoreList)
+			}
+			// Protected strings keep their clear values instead of the encrypted ones returned
+			dataToStore = withRecordedProtectedStrings(obj.data, dataToStore, obj.normalize)
+			// Items only set by the provider's default_data are not in the configuration
+			if defaults := obj.apiClient.defaultData; len(defaults) > 0 {
+				var recorded map[string]interface{}
+				unmarshalJSON([]byte(d.Get("data").(string)), &recorded)
+				dataToStore = withoutDefaultData(dataToStore, recorded, defaults)
 			}
 
 			// Store the filtered resource in state
-			encoded, err := json.Marshal(dataToStore)
+			encoded, err := canonicalJSON(dataToStore)
 			if err != nil {
 				return err
 			}
-			jsonString := string(encoded)
-			d.Set("data", jsonString)
+			d.Set("data", encoded)
+		} else {
+			d.Set("changed_keys", make([]string, 0))
 		}
 
 	}
@@ -368,11 +950,12 @@ func resourceRestAPIUpdate(d *schema.ResourceData, meta interface{}) error {
 		d.Partial(true)
 		return err
 	}
+	obj.taskTimeout = d.Timeout(schema.TimeoutUpdate)
+	obj.retryUntil(obj.taskTimeout)
 
 	/* If copy_keys is not empty, we have to grab the latest
 	   data so we can copy anything needed before the update */
-	client := meta.(*APIClient)
-	if len(client.copyKeys) > 0 {
+	if len(obj.copyKeys) > 0 {
 		err = obj.readObject()
 		if err != nil {
 			return err
@@ -383,22 +966,16 @@ func resourceRestAPIUpdate(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("resource_api_object.go: Update routine called. Object built:\n%s\n", obj.toString())
 	}
 
-	// For PATCH method, get the ignore list and set it on the object
-	if obj.updateMethod == "PATCH" && !(d.Get("ignore_all_server_changes")).(bool) {
-		// Get the ignore list from schema
-		ignoreList := []string{}
-		v, ok := d.GetOk("ignore_changes_to")
-		if ok {
-			for _, s := range v.([]interface{}) {
-				ignoreList = append(ignoreList, s.(string))
-			}
-		}
+	// For patch-based strategies, get the ignore list and set it on the object
+	if obj.updateStrategy != updateStrategyPut && !(d.Get("ignore_all_server_changes")).(bool) {
+		// Get the ignore list from schema. Binary properties whose content is not known are left as they are
+		ignoreList := append(getIgnoreList(d), unknownBinaryPaths(obj.binaryFields)...)
 
-		// Set the ignore list on the object so patchMidpointObject can use it
+		// Set the ignore list on the object so the patch builders can use it
 		obj.ignoreChangesTo = ignoreList
 
-		// If we have an ignore list, check if there are real changes
-		if len(ignoreList) > 0 {
+		// If we have an ignore list or managed fields, check if there are real changes
+		if len(ignoreList) > 0 || len(obj.managedFields) > 0 {
 			// Read current state from API to compare
 			err = obj.readObject()
 			if err != nil {
@@ -407,7 +984,8 @@ func resourceRestAPIUpdate(d *schema.ResourceData, meta interface{}) error {
 			}
 
 			// Check if there are real changes after filtering ignored fields
-			modifiedData, hasChanges := getDelta(obj.data, obj.apiData, ignoreList)
+			// A new clear value of a protected string is a change even though the server's is encrypted
+			modifiedData, hasChanges := getDelta(obj.managedOnly(obj.data), obj.managedOnly(obj.apiData), ignoreList, obj.normalize.literal())
 
 			if obj.debug {
 				log.Printf("resource_api_object.go: Change detection: hasChanges=%v", hasChanges)
@@ -423,6 +1001,8 @@ func resourceRestAPIUpdate(d *schema.ResourceData, meta interface{}) error {
 				}
 				// No real changes, just update state without sending PATCH
 				setResourceState(obj, d)
+				d.Set("last_applied_deltas", make([]string, 0))
+				resetServerDrift(d)
 				return nil
 			}
 
@@ -433,9 +1013,40 @@ func resourceRestAPIUpdate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	err = obj.updateObject()
+	var failed *preconditionError
+	if errors.As(err, &failed) && d.Get("update_precondition_action").(string) == preconditionActionSkip {
+		/* Keeping the old data in state plans the update again next time */
+		log.Printf("[WARN] resource_api_object.go: Skipping the update of '%s': %v", obj.id, err)
+		oldData, _ := d.GetChange("data")
+		if obj.id != "" {
+			setResourceState(obj, d)
+		}
+		d.Set("data", oldData)
+		d.Set("last_applied_deltas", make([]string, 0))
+		return nil
+	}
+	var pending *approvalPendingError
+	if errors.As(err, &pending) {
+		var recorded bool
+		if recorded, err = handlePendingApproval(d, obj, pending, d.Timeout(schema.TimeoutUpdate)); recorded {
+			/* Nothing is modified until the case approves it; data stays as configured */
+			d.Set("last_applied_deltas", make([]string, 0))
+			return nil
+		}
+	}
 	if err == nil {
 		setResourceState(obj, d)
-	} else {
+		d.Set("read_version", "")
+		d.Set("last_applied_deltas", obj.appliedDeltas)
+		d.Set("result_warnings", obj.warnings.list())
+		d.Set("performance_warnings", obj.perfWarnings.list())
+		resetServerDrift(d)
+		err = obj.recomputeAfterWrite()
+		if err == nil {
+			err = obj.testAfterWrite()
+		}
+	}
+	if err != nil {
 		d.Partial(true)
 	}
 	return err
@@ -450,7 +1061,23 @@ func resourceRestAPIDelete(d *schema.ResourceData, meta interface{}) error {
 		log.Printf("resource_api_object.go: Delete routine called. Object built:\n%s\n", obj.toString())
 	}
 
-	err = obj.deleteObject()
+	action := d.Get("destroy_action").(string)
+	if d.Get("prevent_destroy_remote").(bool) && action != destroyActionAbandon {
+		return fmt.Errorf("'%s' at '%s' is protected by prevent_destroy_remote; set it to false and apply before destroying the object (destroy_action = %s)", d.Id(), d.Get("path").(string), action)
+	}
+
+	switch action {
+	case destroyActionAbandon:
+		log.Printf("[WARN] resource_api_object.go: Removing '%s' from state without deleting it (destroy_action = %s)", d.Id(), action)
+		return nil
+	case destroyActionArchive, destroyActionDisable:
+		err = obj.retireObject(action, d.Get("archive_lifecycle_state").(string))
+	default:
+		err = obj.deleteObject()
+		if err == nil && d.Get("verify_destroy").(bool) {
+			return obj.waitUntilDeleted(time.Duration(d.Get("destroy_timeout").(int)) * time.Second)
+		}
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "404") {
 			/* 404 means it doesn't exist. Call that good enough */
@@ -492,7 +1119,7 @@ Simple helper routine to build an api_object struct
 	results in a new object created
 */
 func makeAPIObject(d *schema.ResourceData, meta interface{}) (*APIObject, error) {
-	opts, err := buildAPIObjectOpts(d)
+	opts, err := buildAPIObjectOpts(d, meta.(*APIClient))
 	if err != nil {
 		return nil, err
 	}
@@ -511,7 +1138,7 @@ func makeAPIObject(d *schema.ResourceData, meta interface{}) (*APIObject, error)
 	return obj, err
 }
 
-func buildAPIObjectOpts(d *schema.ResourceData) (*apiObjectOpts, error) {
+func buildAPIObjectOpts(d *schema.ResourceData, client *APIClient) (*apiObjectOpts, error) {
 	opts := &apiObjectOpts{
 		path: d.Get("path").(string),
 	}
@@ -540,6 +1167,9 @@ func buildAPIObjectOpts(d *schema.ResourceData) (*apiObjectOpts, error) {
 	if v, ok := d.GetOk("update_path"); ok {
 		opts.putPath = v.(string)
 	}
+	if v, ok := d.GetOk("patch_path"); ok {
+		opts.patchPath = v.(string)
+	}
 	if v, ok := d.GetOk("create_method"); ok {
 		opts.createMethod = v.(string)
 	}
@@ -555,6 +1185,9 @@ func buildAPIObjectOpts(d *schema.ResourceData) (*apiObjectOpts, error) {
 	if v, ok := d.GetOk("update_data"); ok {
 		opts.updateData = v.(string)
 	}
+	if v, ok := d.GetOk("update_strategy"); ok {
+		opts.updateStrategy = v.(string)
+	}
 	if v, ok := d.GetOk("destroy_method"); ok {
 		opts.destroyMethod = v.(string)
 	}
@@ -567,43 +1200,92 @@ func buildAPIObjectOpts(d *schema.ResourceData) (*apiObjectOpts, error) {
 	if v, ok := d.GetOk("query_string"); ok {
 		opts.queryString = v.(string)
 	}
+	opts.queryString = joinQueryStrings(opts.queryString, encodeQueryParams(d.Get("query_params").(map[string]interface{})))
+	opts.queryStrings = make(map[string]string)
+	for _, operation := range []string{"create", "read", "update", "destroy"} {
+		if v, ok := d.GetOk(operation + "_query_string"); ok {
+			opts.queryStrings[operation] = v.(string)
+		}
+	}
+	opts.createPriority = d.Get("create_priority").(int)
 
 	readSearch := expandReadSearch(d.Get("read_search").(map[string]interface{}))
 	opts.readSearch = readSearch
+	opts.readResultsKey = d.Get("read_results_key").(string)
+	opts.wrapperKey = d.Get("response_wrapper_key").(string)
+	opts.requestWrapper = d.Get("request_wrapper_key").(string)
+	opts.readFieldPaths = expandReadSearch(d.Get("read_field_paths").(map[string]interface{}))
+	opts.listMergeKeys = expandReadSearch(d.Get("list_merge_keys").(map[string]interface{}))
+	opts.pathNamespaces = expandReadSearch(d.Get("path_namespaces").(map[string]interface{}))
+	opts.pagination = expandPagination(d.Get("pagination").([]interface{}))
+	opts.shallowRead = expandShallowRead(d.Get("shallow_read").([]interface{}))
+	opts.normalize = expandNormalizeRules(d.Get("normalize").([]interface{}))
+	opts.protectedPaths = expandStringList(d.Get("protected_fields").([]interface{}))
+	opts.updateOptions = expandStringList(d.Get("update_options").([]interface{}))
+	opts.precondition = d.Get("update_precondition").(string)
+	opts.recompute = d.Get("recompute_after_write").(bool)
+	opts.postCreate = expandPostCreateRequests(d.Get("post_create_requests").([]interface{}))
+	opts.testOnApply = d.Get("test_on_apply").(bool)
+	opts.upsert = d.Get("upsert").(bool)
+	opts.warnPartial = d.Get("warn_on_partial_error").(bool)
+	opts.copyKeys = expandStringList(d.Get("copy_keys").([]interface{}))
+	opts.retry = expandRetryPolicy(d.Get("retry").([]interface{}))
+	opts.readInclude = expandStringList(d.Get("read_include").([]interface{}))
+	opts.readExclude = expandStringList(d.Get("read_exclude").([]interface{}))
+	opts.singleton = d.Get("singleton").(bool)
+	opts.waitForTask = d.Get("wait_for_task").(bool)
+	opts.taskInterval = time.Duration(d.Get("task_poll_interval").(int)) * time.Second
+	opts.tasksPath = d.Get("tasks_path").(string)
+	opts.destroyOptions = expandStringList(d.Get("destroy_options").([]interface{}))
+	opts.tokenAudience = d.Get("token_audience").(string)
+	opts.tokenScopes = expandStringList(d.Get("token_scopes").([]interface{}))
+	opts.contentType = d.Get("content_type").(string)
+	opts.binaryFields = withConfiguredContents(expandBinaryFields(d.Get("binary_field").([]interface{})), d.GetRawConfig())
 
 	opts.data = d.Get("data").(string)
+	previousData, _ := d.GetChange("data")
+	opts.previousData = previousData.(string)
 	opts.debug = d.Get("debug").(bool)
 
-	// Filter ignored fields from the data at load time
-	// This ensures Terraform never sees server-managed fields even if they're in the config file
-	if v, ok := d.GetOk("ignore_changes_to"); ok {
-		ignoreList := []string{}
-		for _, s := range v.([]interface{}) {
-			ignoreList = append(ignoreList, s.(string))
-		}
+	representation, err := client.lookupRepresentation(d.Get("representation").(string))
+	if err != nil {
+		return nil, err
+	}
+	if err := setRepresentation(d, client); err != nil {
+		return nil, err
+	}
+	setMidpointMode(d, client)
+	if representation != nil && opts.updateStrategy == "" {
+		opts.updateStrategy = representation.updateStrategy
+	}
+
+	opts.managedFields = getManagedFields(d)
 
-		if len(ignoreList) > 0 && opts.data != "" {
+	// Filter ignored and unmanaged fields from the data at load time
+	// This ensures Terraform never sees server-managed fields even if they're in the config file
+	if ignoreList := getIgnoreList(d); len(ignoreList) > 0 || len(opts.managedFields) > 0 {
+		if opts.data != "" {
 			// Parse the JSON data
 			var dataMap map[string]interface{}
-			err := json.Unmarshal([]byte(opts.data), &dataMap)
+			err := unmarshalJSON([]byte(opts.data), &dataMap)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse data JSON for filtering: %v", err)
 			}
 
 			// Filter ignored fields
-			filteredData := filterIgnoredFields(dataMap, ignoreList)
+			filteredData := filterIgnoredFields(filterManagedFields(dataMap, opts.managedFields), ignoreList)
 
 			// Only re-serialize if filtering actually changed something
 			// This preserves original JSON formatting when no filtering is needed
-			originalJSON, _ := json.Marshal(dataMap)
-			filteredJSON, err := json.Marshal(filteredData)
+			originalJSON, _ := canonicalJSON(dataMap)
+			filteredJSON, err := canonicalJSON(filteredData)
 			if err != nil {
 				return nil, fmt.Errorf("failed to serialize filtered data: %v", err)
 			}
 
 			// Only update if something changed
-			if string(originalJSON) != string(filteredJSON) {
-				opts.data = string(filteredJSON)
+			if originalJSON != filteredJSON {
+				opts.data = filteredJSON
 				if opts.debug {
 					log.Printf("resource_api_object.go: Filtered ignored fields from config data")
 				}
@@ -614,41 +1296,92 @@ func buildAPIObjectOpts(d *schema.ResourceData) (*apiObjectOpts, error) {
 	return opts, nil
 }
 
-// getIgnoreList extracts the ignore_changes_to list from ResourceData
+// getIgnoreList extracts the ignore_changes_to list from ResourceData,
+// followed by the entries of read_exclude and of the resource's representation profile
 func getIgnoreList(d interface{}) []string {
 	ignoreList := []string{}
 
 	// Handle both *schema.ResourceData and *schema.ResourceDiff
-	var raw interface{}
+	var raw, excluded, profile interface{}
+	var midpointMode bool
 
 	switch v := d.(type) {
 	case *schema.ResourceData:
 		// Use Get instead of GetOk to get the value from config, not just state
 		raw = v.Get("ignore_changes_to")
+		excluded = v.Get("read_exclude")
+		profile = v.Get("representation_ignore_changes_to")
+		midpointMode, _ = v.Get("midpoint_mode").(bool)
 	case *schema.ResourceDiff:
 		raw = v.Get("ignore_changes_to")
+		excluded = v.Get("read_exclude")
+		profile = v.Get("representation_ignore_changes_to")
+		midpointMode, _ = v.Get("midpoint_mode").(bool)
 	default:
 		return ignoreList
 	}
 
-	// Check if raw is nil or not a list
-	if raw == nil {
-		return ignoreList
+	// Type assert to []interface{}, which also rules out a nil raw
+	if rawList, ok := raw.([]interface{}); ok {
+		for _, s := range rawList {
+			if str, ok := s.(string); ok {
+				ignoreList = append(ignoreList, str)
+			}
+		}
 	}
 
-	// Type assert to []interface{}
-	rawList, ok := raw.([]interface{})
-	if !ok {
-		return ignoreList
+	// Items left out of reads are not on the server as far as the provider knows
+	if excludedList, ok := excluded.([]interface{}); ok {
+		ignoreList = append(ignoreList, readExcludePatterns(expandStringList(excludedList))...)
 	}
 
-	for _, s := range rawList {
-		if str, ok := s.(string); ok {
-			ignoreList = append(ignoreList, str)
-		}
+	// The representation profile's patterns, as resolved by setRepresentation
+	if profileList, ok := profile.([]interface{}); ok {
+		ignoreList = append(ignoreList, expandStringList(profileList)...)
+	}
+
+	// The server-managed items of midpoint_mode, as recorded by setMidpointMode
+	return append(ignoreList, midpointModeIgnoreList(midpointMode)...)
+}
+
+// getManagedFields extracts the managed_fields list from ResourceData,
+// followed by the fields of the resource's representation profile
+func getManagedFields(d interface{}) []string {
+	var managed, profile interface{}
+
+	// Handle both *schema.ResourceData and *schema.ResourceDiff
+	switch v := d.(type) {
+	case *schema.ResourceData:
+		managed = v.Get("managed_fields")
+		profile = v.Get("representation_fields")
+	case *schema.ResourceDiff:
+		managed = v.Get("managed_fields")
+		profile = v.Get("representation_fields")
+	default:
+		return nil
+	}
+
+	fields := []string{}
+	if managedList, ok := managed.([]interface{}); ok {
+		fields = append(fields, expandStringList(managedList)...)
 	}
 
-	return ignoreList
+	// The representation profile's fields, as resolved by setRepresentation
+	if profileList, ok := profile.([]interface{}); ok {
+		fields = append(fields, expandStringList(profileList)...)
+	}
+
+	return fields
+}
+
+/* readExcludePatterns turns the item paths of read_exclude into ignore list entries, at the top and below a wrapping key */
+func readExcludePatterns(paths []string) []string {
+	patterns := make([]string, 0, 2*len(paths))
+	for _, path := range paths {
+		dotted := strings.ReplaceAll(strings.Trim(path, "/"), "/", ".")
+		patterns = append(patterns, dotted, "*."+dotted)
+	}
+	return patterns
 }
 
 // suppressDiffForIgnoredFields compares old (state) vs new (config) JSON,
@@ -669,21 +1402,31 @@ func suppressDiffForIgnoredFields(k, old, new string, d *schema.ResourceData) bo
 		return false
 	}
 
+	// Key order, whitespace and how numbers are written are not changes
+	if sameCanonicalJSON(old, new) {
+		return true
+	}
+
 	// Parse old (state) and new (config) JSON
 	var oldData, newData map[string]interface{}
 
-	if err := json.Unmarshal([]byte(old), &oldData); err != nil {
+	if err := unmarshalJSON([]byte(old), &oldData); err != nil {
 		// Can't parse old state - don't suppress (let Terraform show the diff)
 		log.Printf("resource_api_object.go: DiffSuppressFunc: failed to parse old state: %v", err)
 		return false
 	}
 
-	if err := json.Unmarshal([]byte(new), &newData); err != nil {
+	if err := unmarshalJSON([]byte(new), &newData); err != nil {
 		// Can't parse new config - don't suppress
 		log.Printf("resource_api_object.go: DiffSuppressFunc: failed to parse new config: %v", err)
 		return false
 	}
 
+	// Only the managed fields are compared
+	managedFields := getManagedFields(d)
+	oldData = filterManagedFields(oldData, managedFields)
+	newData = filterManagedFields(newData, managedFields)
+
 	// If there's an ignore list, filter both old and new before comparing
 	if len(ignoreList) > 0 {
 		oldData = filterIgnoredFields(oldData, ignoreList)
@@ -694,6 +1437,12 @@ func suppressDiffForIgnoredFields(k, old, new string, d *schema.ResourceData) bo
 	// If they're equal after parsing, suppress the diff
 	result := reflect.DeepEqual(oldData, newData)
 
+	// Values that only differ in representation (see normalize) are equal too
+	if rules := expandNormalizeRules(d.Get("normalize").([]interface{})); !result && rules != nil {
+		_, changed := getDelta(oldData, newData, nil, rules)
+		result = !changed
+	}
+
 	if debug && len(ignoreList) > 0 {
 		log.Printf("resource_api_object.go: DiffSuppressFunc returning %v (suppress=%v)", result, result)
 	}
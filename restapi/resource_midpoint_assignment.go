@@ -0,0 +1,246 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceMidpointAssignment() *schema.Resource {
+	return &schema.Resource{
+		Create:      resourceMidpointAssignmentCreate,
+		Read:        resourceMidpointAssignmentRead,
+		Update:      resourceMidpointAssignmentUpdate,
+		Delete:      resourceMidpointAssignmentDelete,
+		Description: "A single assignment of a midPoint object, such as a role of a user. Only this assignment is added, modified and deleted with itemDeltas, so several configurations can assign roles to the same object without owning its JSON.",
+		Importer: &schema.ResourceImporter{
+			State: resourceMidpointAssignmentImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"object_path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/users`. The API path of the objects of the type of the assigned object, such as `/roles` to assign a meta-role to a role.",
+				Optional:    true,
+				Default:     "/users",
+				ForceNew:    true,
+			},
+			"object_oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the object the assignment is on, such as the user.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"target_oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the assigned role, org, service or archetype.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"target_type": {
+				Type:        schema.TypeString,
+				Description: "The type of the target, such as `OrgType` or `ArchetypeType`. Default: `RoleType`",
+				Optional:    true,
+				Default:     "RoleType",
+				ForceNew:    true,
+			},
+			"relation": {
+				Type:        schema.TypeString,
+				Description: "The relation of the assignment, such as `manager`. Not set means the default relation.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "The description of the assignment.",
+				Optional:    true,
+			},
+			"activation": activationSchema(),
+			"container_id": {
+				Type:        schema.TypeString,
+				Description: "The container id (@id) midPoint gave the assignment.",
+				Computed:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the object on the server.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+/* midpointAssignmentObject returns the APIObject of the object the assignment is on */
+func midpointAssignmentObject(d *schema.ResourceData, meta interface{}) (*APIObject, error) {
+	return NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:  d.Get("object_path").(string),
+		id:    d.Get("object_oid").(string),
+		debug: d.Get("debug").(bool),
+	})
+}
+
+/*
+findMidpointAssignment returns the assignment of the object read into obj that
+
+	has the target and relation of the resource, or nil when there is none.
+*/
+func findMidpointAssignment(d *schema.ResourceData, obj *APIObject) map[string]interface{} {
+	values, _ := asValueList(midpointObjectOf(obj.apiData)["assignment"])
+	for _, value := range values {
+		hash, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if stringAtKey(hash, "targetRef/oid") == d.Get("target_oid").(string) && refQName(stringAtKey(hash, "targetRef/relation")) == refQName(d.Get("relation").(string)) {
+			return hash
+		}
+	}
+	return nil
+}
+
+func buildMidpointAssignment(d *schema.ResourceData) map[string]interface{} {
+	targetRef := map[string]interface{}{"oid": d.Get("target_oid").(string), "type": d.Get("target_type").(string)}
+	setIfNotEmpty(targetRef, "relation", d.Get("relation").(string))
+	assignment := map[string]interface{}{"targetRef": targetRef}
+	setIfNotEmpty(assignment, "description", d.Get("description").(string))
+	if activation := buildActivation(d.Get("activation").([]interface{})); len(activation) > 0 {
+		assignment["activation"] = activation
+	}
+	return assignment
+}
+
+/* midpointAssignmentID is the id of the resource: object oid, target oid and relation */
+func midpointAssignmentID(d *schema.ResourceData) string {
+	relation := refQName(d.Get("relation").(string))
+	if relation == "" {
+		relation = "default"
+	}
+	return fmt.Sprintf("%s/%s/%s", d.Get("object_oid").(string), d.Get("target_oid").(string), relation)
+}
+
+func resourceMidpointAssignmentCreate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointAssignmentObject(d, meta)
+	if err != nil {
+		return err
+	}
+
+	err = obj.patchWithConflictRetry(func() error {
+		if obj.id == "" {
+			return fmt.Errorf("the object '%s' to assign '%s' to does not exist at '%s'", d.Get("object_oid").(string), d.Get("target_oid").(string), d.Get("object_path").(string))
+		}
+		if findMidpointAssignment(d, obj) != nil {
+			return fmt.Errorf("the object '%s' already has an assignment to '%s' with this relation; import it with the id '%s'", obj.id, d.Get("target_oid").(string), midpointAssignmentID(d))
+		}
+		return obj.sendMidpointPatch("add", "assignment", []interface{}{buildMidpointAssignment(d)})
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(midpointAssignmentID(d))
+	return resourceMidpointAssignmentRead(d, meta)
+}
+
+func resourceMidpointAssignmentRead(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointAssignmentObject(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+
+	assignment := findMidpointAssignment(d, obj)
+	if obj.id == "" || assignment == nil {
+		log.Printf("resource_midpoint_assignment.go: The assignment '%s' is gone. Removing from state.", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	id, _ := containerIDString(assignment)
+	d.Set("container_id", id)
+	d.Set("target_type", refQName(stringAtKey(assignment, "targetRef/type")))
+	d.Set("description", scalarStringAt(assignment, "description"))
+	return d.Set("activation", flattenActivation(assignment["activation"]))
+}
+
+func resourceMidpointAssignmentUpdate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointAssignmentObject(d, meta)
+	if err != nil {
+		return err
+	}
+
+	err = obj.patchWithConflictRetry(func() error {
+		current := findMidpointAssignment(d, obj)
+		if obj.id == "" || current == nil {
+			return fmt.Errorf("the assignment '%s' no longer exists on the server", d.Id())
+		}
+		id, ok := containerIDString(current)
+		if !ok {
+			return fmt.Errorf("the assignment '%s' has no container id to modify it with", d.Id())
+		}
+
+		desired := buildMidpointAssignment(d)
+		itemDeltas := make([]interface{}, 0)
+		for _, key := range []string{"description", "activation"} {
+			path := fmt.Sprintf("assignment/[%s]/%s", id, key)
+			if value, ok := desired[key]; ok {
+				itemDeltas = append(itemDeltas, obj.buildItemDelta("replace", path, value))
+			} else if _, ok := current[key]; ok {
+				itemDeltas = append(itemDeltas, obj.buildItemDelta("delete", path, nil))
+			}
+		}
+		if len(itemDeltas) == 0 {
+			return nil
+		}
+		return obj.sendObjectModification(itemDeltas)
+	})
+	if err != nil {
+		return err
+	}
+	return resourceMidpointAssignmentRead(d, meta)
+}
+
+func resourceMidpointAssignmentDelete(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointAssignmentObject(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return obj.patchWithConflictRetry(func() error {
+		current := findMidpointAssignment(d, obj)
+		if obj.id == "" || current == nil {
+			/* Already gone */
+			return nil
+		}
+		return obj.sendMidpointPatch("delete", "assignment", []interface{}{containerValueToDelete(current)})
+	})
+}
+
+/*
+resourceMidpointAssignmentImport accepts the id of the resource,
+
+	<object oid>/<target oid>/<relation>, optionally preceded by the
+	object_path of objects that are not users, such as /roles/<oid>/<oid>/default.
+*/
+func resourceMidpointAssignmentImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), "/")
+	if len(parts) < 3 || len(parts) > 3 && !strings.HasPrefix(d.Id(), "/") {
+		return nil, fmt.Errorf("invalid id '%s': expected '<object oid>/<target oid>/<relation>', optionally preceded by the object_path such as '/roles/'", d.Id())
+	}
+	if len(parts) > 3 {
+		d.Set("object_path", strings.Join(parts[:len(parts)-3], "/"))
+		parts = parts[len(parts)-3:]
+	}
+	relation := parts[2]
+	if relation == "default" {
+		relation = ""
+	}
+	d.Set("object_oid", parts[0])
+	d.Set("target_oid", parts[1])
+	d.Set("relation", relation)
+	d.SetId(midpointAssignmentID(d))
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,72 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointAssignment(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Failed to create API client: %s", err)
+	}
+	user := `{"user": {"oid": "user-1", "name": "jsmith", "assignment": [{"@id": 1, "targetRef": {"oid": "role-1", "type": "c:RoleType"}}]}}`
+	if _, err := client.sendRequest("POST", "/users", user); err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Failed to create the user: %s", err)
+	}
+
+	/* Two configurations assign roles to the same user */
+	first := schema.TestResourceDataRaw(t, resourceMidpointAssignment().Schema, map[string]interface{}{"object_oid": "user-1", "target_oid": "role-2"})
+	if err := resourceMidpointAssignmentCreate(first, client); err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Create failed: %s", err)
+	}
+	second := schema.TestResourceDataRaw(t, resourceMidpointAssignment().Schema, map[string]interface{}{"object_oid": "user-1", "target_oid": "org-1", "target_type": "OrgType", "relation": "manager"})
+	if err := resourceMidpointAssignmentCreate(second, client); err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Create failed: %s", err)
+	}
+	if first.Id() != "user-1/role-2/default" || second.Id() != "user-1/org-1/manager" {
+		t.Fatalf("resource_midpoint_assignment_test.go: Unexpected ids '%s' and '%s'", first.Id(), second.Id())
+	}
+
+	duplicate := schema.TestResourceDataRaw(t, resourceMidpointAssignment().Schema, map[string]interface{}{"object_oid": "user-1", "target_oid": "role-1"})
+	if err := resourceMidpointAssignmentCreate(duplicate, client); err == nil || !strings.Contains(err.Error(), "import it with the id 'user-1/role-1/default'") {
+		t.Fatalf("resource_midpoint_assignment_test.go: Expected an existing assignment to be refused, got %v", err)
+	}
+
+	if err := resourceMidpointAssignmentDelete(first, client); err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Delete failed: %s", err)
+	}
+	result, _ := client.sendRequest("GET", "/users/user-1", "")
+	if strings.Contains(result, "role-2") || !strings.Contains(result, "role-1") || !strings.Contains(result, "org-1") {
+		t.Fatalf("resource_midpoint_assignment_test.go: Expected only the deleted assignment to be gone: %s", result)
+	}
+	if err := resourceMidpointAssignmentRead(first, client); err != nil || first.Id() != "" {
+		t.Fatalf("resource_midpoint_assignment_test.go: Expected the deleted assignment to be removed from state, got '%s' (%v)", first.Id(), err)
+	}
+
+	/* The existing assignment is imported and modified by its container id */
+	imported := schema.TestResourceDataRaw(t, resourceMidpointAssignment().Schema, map[string]interface{}{})
+	imported.SetId("/users/user-1/role-1/default")
+	if _, err := resourceMidpointAssignmentImport(imported, client); err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Import failed: %s", err)
+	}
+	if err := resourceMidpointAssignmentRead(imported, client); err != nil || imported.Id() != "user-1/role-1/default" || imported.Get("container_id") != "1" || imported.Get("target_type") != "RoleType" {
+		t.Fatalf("resource_midpoint_assignment_test.go: Unexpected state after the import: %v (%v)", imported.State(), err)
+	}
+
+	config := map[string]interface{}{"object_oid": "user-1", "target_oid": "role-1", "description": "Granted by ticket 42"}
+	updated := schema.TestResourceDataRaw(t, resourceMidpointAssignment().Schema, config)
+	updated.SetId(imported.Id())
+	if err := resourceMidpointAssignmentUpdate(updated, client); err != nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Update failed: %s", err)
+	}
+	if updated.Get("description") != "Granted by ticket 42" {
+		t.Fatalf("resource_midpoint_assignment_test.go: Expected the description to be set, got %v", updated.State())
+	}
+
+	if _, err := resourceMidpointAssignmentImport(schema.TestResourceDataRaw(t, resourceMidpointAssignment().Schema, map[string]interface{}{}), client); err == nil {
+		t.Fatalf("resource_midpoint_assignment_test.go: Expected an empty import id to be rejected")
+	}
+}
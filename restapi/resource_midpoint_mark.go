@@ -0,0 +1,102 @@
+package restapi
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var midpointMark = &midpointType{
+	wrapper:       "mark",
+	path:          "/marks",
+	managedFields: []string{"name", "description", "display.label", "display.color", "policyRule", "assignment.targetRef"},
+	listMergeKeys: map[string]string{"policyRule": "name", "assignment": "targetRef/oid"},
+	keepValue:     keepNonArchetypeAssignment,
+	build:         buildMidpointMark,
+	flatten:       flattenMidpointMark,
+}
+
+func resourceMidpointMark() *schema.Resource {
+	ruleSchema := policyRuleSchema()
+	ruleSchema["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The name of the policy rule, which matches it to the rule on the server.",
+		Required:    true,
+	}
+
+	return midpointMark.resource("A midPoint object or event mark (MarkType) with typed attributes, as used by simulations and compliance policies. The resource sends only the modified items as midPoint itemDeltas.", map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the mark.",
+			Required:    true,
+		},
+		"description": {
+			Type:        schema.TypeString,
+			Description: "The description of the mark.",
+			Optional:    true,
+		},
+		"display_label": {
+			Type:        schema.TypeString,
+			Description: "The label midPoint shows for the mark.",
+			Optional:    true,
+		},
+		"display_color": {
+			Type:        schema.TypeString,
+			Description: "The color midPoint shows the mark in, such as `#00a65a`.",
+			Optional:    true,
+		},
+		"policy_rule": {
+			Type:        schema.TypeList,
+			Description: "The policy rules that apply the mark, matched to the rules on the server by their name.",
+			Optional:    true,
+			Elem:        &schema.Resource{Schema: ruleSchema},
+		},
+		"archetype_oids": {
+			Type:        schema.TypeList,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The oids of the archetypes of the mark, such as the built-in object mark or event mark archetype. Each is sent as an assignment; the other assignments of the mark are left untouched.",
+			Optional:    true,
+		},
+	})
+}
+
+func buildMidpointMark(d *schema.ResourceData) map[string]interface{} {
+	mark := map[string]interface{}{"name": d.Get("name").(string)}
+	setIfNotEmpty(mark, "description", d.Get("description").(string))
+	display := make(map[string]interface{})
+	setIfNotEmpty(display, "label", d.Get("display_label").(string))
+	setIfNotEmpty(display, "color", d.Get("display_color").(string))
+	if len(display) > 0 {
+		mark["display"] = display
+	}
+
+	rules := make([]interface{}, 0)
+	for _, block := range d.Get("policy_rule").([]interface{}) {
+		rules = append(rules, buildPolicyRule(block.(map[string]interface{})))
+	}
+	if len(rules) > 0 {
+		mark["policyRule"] = rules
+	}
+
+	if assignments := buildArchetypeAssignments(d.Get("archetype_oids").([]interface{})); len(assignments) > 0 {
+		mark["assignment"] = assignments
+	}
+	return mark
+}
+
+func flattenMidpointMark(d *schema.ResourceData, mark map[string]interface{}) error {
+	d.Set("name", scalarStringAt(mark, "name"))
+	d.Set("description", scalarStringAt(mark, "description"))
+	display, _ := mark["display"].(map[string]interface{})
+	d.Set("display_label", scalarStringAt(display, "label"))
+	d.Set("display_color", scalarStringAt(display, "color"))
+
+	values, _ := asValueList(mark["policyRule"])
+	rules := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if hash, ok := value.(map[string]interface{}); ok {
+			rules = append(rules, flattenPolicyRule(hash))
+		}
+	}
+	d.Set("policy_rule", rules)
+
+	return d.Set("archetype_oids", flattenArchetypeOids(mark["assignment"]))
+}
@@ -0,0 +1,61 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointMark(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "mark/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_mark_test.go: Failed to create API client: %s", err)
+	}
+
+	config := map[string]interface{}{
+		"oid":            "mark-1",
+		"name":           "Orphaned account",
+		"display_label":  "Orphaned",
+		"archetype_oids": []interface{}{"00000000-0000-0000-0000-000000000700"},
+		"policy_rule": []interface{}{map[string]interface{}{
+			"name":               "orphaned",
+			"policy_constraints": `{"situation": [{"situation": "unmatched"}]}`,
+		}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceMidpointMark().Schema, config)
+	if err := midpointMark.create(d, client); err != nil {
+		t.Fatalf("resource_midpoint_mark_test.go: Create failed: %s", err)
+	}
+	created, _ := client.sendRequest("GET", "/marks/mark-1", "")
+	for _, expected := range []string{`"display":{"label":"Orphaned"}`, `"policyRule":[{"name":"orphaned","policyConstraints":{"situation":[{"situation":"unmatched"}]}}]`} {
+		if !strings.Contains(created, expected) {
+			t.Fatalf("resource_midpoint_mark_test.go: Expected %s in the created mark %s", expected, created)
+		}
+	}
+
+	/* midPoint gives the containers ids */
+	server := `{"mark": {"oid": "mark-1", "name": {"orig": "Orphaned account", "norm": "orphaned account"}, "display": {"label": {"orig": "Orphaned", "norm": "orphaned"}},
+		"policyRule": [{"@id": 1, "name": "orphaned", "policyConstraints": {"situation": [{"@id": 2, "situation": "unmatched"}]}}],
+		"assignment": [{"@id": 3, "targetRef": {"oid": "00000000-0000-0000-0000-000000000700", "type": "c:ArchetypeType"}}]}}`
+	if _, err := client.sendRequest("PUT", "/marks/mark-1", server); err != nil {
+		t.Fatalf("resource_midpoint_mark_test.go: Put failed: %s", err)
+	}
+	if err := midpointMark.read(d, client); err != nil {
+		t.Fatalf("resource_midpoint_mark_test.go: Read failed: %s", err)
+	}
+	if d.Get("policy_rule.0.policy_constraints") != `{"situation":[{"situation":"unmatched"}]}` || d.Get("display_label") != "Orphaned" || d.Get("archetype_oids.#") != 1 {
+		t.Fatalf("resource_midpoint_mark_test.go: Unexpected attributes after the read: %v", d.State())
+	}
+
+	config["description"] = "Accounts without an owner"
+	d = schema.TestResourceDataRaw(t, resourceMidpointMark().Schema, config)
+	d.SetId("mark-1")
+	if err := midpointMark.update(d, client); err != nil {
+		t.Fatalf("resource_midpoint_mark_test.go: Update failed: %s", err)
+	}
+	deltas := strings.Join(expandStringList(d.Get("last_applied_deltas").([]interface{})), "\n")
+	if !strings.Contains(deltas, `"path":"description"`) || strings.Contains(deltas, "policyRule") || strings.Contains(deltas, "assignment") || strings.Contains(deltas, "display") {
+		t.Fatalf("resource_midpoint_mark_test.go: Expected only the description to change:\n%s", deltas)
+	}
+}
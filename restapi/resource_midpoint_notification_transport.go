@@ -0,0 +1,303 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/* The kinds of message transports, the items of messageTransportConfiguration */
+var notificationTransportTypes = []string{"mail", "sms", "file", "customTransport"}
+
+/* Values of server/transportSecurity of mail transports */
+var mailTransportSecurities = []string{"none", "starttlsEnabled", "starttlsRequired", "ssl"}
+
+func resourceMidpointNotificationTransport() *schema.Resource {
+	attributes := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the transport, which identifies it in the system configuration and is used by notifiers to select it.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"type": {
+			Type:         schema.TypeString,
+			Description:  "One of `mail`, `sms`, `file` or `customTransport`. Default: `mail`",
+			Optional:     true,
+			Default:      "mail",
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(notificationTransportTypes, false),
+		},
+		"mail_server": {
+			Type:        schema.TypeList,
+			Description: "The SMTP server of a mail transport.",
+			Optional:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"host": {
+						Type:        schema.TypeString,
+						Description: "The host name of the SMTP server.",
+						Required:    true,
+					},
+					"port": {
+						Type:        schema.TypeInt,
+						Description: "The port of the SMTP server. Not set uses the default port of transport_security.",
+						Optional:    true,
+					},
+					"username": {
+						Type:        schema.TypeString,
+						Description: "The user name to authenticate to the SMTP server with.",
+						Optional:    true,
+					},
+					"password": {
+						Type:        schema.TypeString,
+						Description: "The password to authenticate to the SMTP server with. midPoint stores it encrypted, so it is only sent when it changes in the configuration.",
+						Optional:    true,
+						Sensitive:   true,
+					},
+					"transport_security": {
+						Type:         schema.TypeString,
+						Description:  "One of `none`, `starttlsEnabled`, `starttlsRequired` or `ssl`.",
+						Optional:     true,
+						ValidateFunc: validation.StringInSlice(mailTransportSecurities, false),
+					},
+				},
+			},
+		},
+		"default_from": {
+			Type:        schema.TypeString,
+			Description: "The sender address of the messages of a mail transport.",
+			Optional:    true,
+		},
+		"redirect_to_file": {
+			Type:        schema.TypeString,
+			Description: "A file on the midPoint server the messages are written to instead of being sent, as for test environments.",
+			Optional:    true,
+		},
+		"configuration": {
+			Type:             schema.TypeString,
+			Description:      "Valid JSON object with the other items of the transport in midPoint's representation, such as the `method` and `urlExpression` of an SMS transport.",
+			Optional:         true,
+			ValidateFunc:     validation.StringIsJSON,
+			DiffSuppressFunc: suppressEquivalentJSON,
+		},
+	}
+
+	return &schema.Resource{
+		Create:      resourceMidpointNotificationTransportCreate,
+		Read:        resourceMidpointNotificationTransportRead,
+		Update:      resourceMidpointNotificationTransportUpdate,
+		Delete:      resourceMidpointNotificationTransportDelete,
+		Description: "A message transport of midPoint notifications, such as an SMTP mail server, kept in the messageTransportConfiguration of the system configuration. Only this transport is added, modified and deleted with itemDeltas.",
+		Importer: &schema.ResourceImporter{
+			State: resourceMidpointNotificationTransportImport,
+		},
+		Schema: systemConfigurationAttributes(attributes, "transport"),
+	}
+}
+
+/* notificationTransportPath returns the path of the transports of the type of the resource */
+func notificationTransportPath(d *schema.ResourceData) string {
+	return "messageTransportConfiguration/" + d.Get("type").(string)
+}
+
+/* The items of a transport the typed attributes set */
+var notificationTransportItems = []string{"defaultFrom", "redirectToFile", "server"}
+
+/* buildMidpointNotificationTransport returns the transport container value of the attributes */
+func buildMidpointNotificationTransport(d *schema.ResourceData) (map[string]interface{}, error) {
+	transport := make(map[string]interface{})
+	if configuration := d.Get("configuration").(string); configuration != "" {
+		if err := json.Unmarshal([]byte(configuration), &transport); err != nil {
+			return nil, fmt.Errorf("configuration of the transport '%s' is not a JSON object: %v", d.Get("name").(string), err)
+		}
+	}
+	transport["name"] = d.Get("name").(string)
+	setIfNotEmpty(transport, "defaultFrom", d.Get("default_from").(string))
+	setIfNotEmpty(transport, "redirectToFile", d.Get("redirect_to_file").(string))
+
+	if servers := d.Get("mail_server").([]interface{}); len(servers) > 0 && servers[0] != nil {
+		block := servers[0].(map[string]interface{})
+		server := map[string]interface{}{"host": block["host"]}
+		if port := block["port"].(int); port != 0 {
+			server["port"] = port
+		}
+		setIfNotEmpty(server, "username", block["username"].(string))
+		setIfNotEmpty(server, "transportSecurity", block["transport_security"].(string))
+		if password := block["password"].(string); password != "" {
+			server["password"] = map[string]interface{}{"clearValue": password}
+		}
+		transport["server"] = []interface{}{server}
+	}
+	return transport, nil
+}
+
+func resourceMidpointNotificationTransportCreate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+	transport, err := buildMidpointNotificationTransport(d)
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+
+	err = obj.patchWithConflictRetry(func() error {
+		if obj.id == "" {
+			return fmt.Errorf("the system configuration '%s' does not exist at '%s'", d.Get("system_configuration_oid").(string), d.Get("system_configuration_path").(string))
+		}
+		if findNamedValue(obj, notificationTransportPath(d), name) != nil {
+			return fmt.Errorf("the system configuration already has a %s transport named '%s'; import it with the id '%s/%s'", d.Get("type").(string), name, d.Get("type").(string), name)
+		}
+		return obj.sendMidpointPatch("add", notificationTransportPath(d), []interface{}{transport})
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("type").(string), name))
+	return resourceMidpointNotificationTransportRead(d, meta)
+}
+
+func resourceMidpointNotificationTransportRead(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+
+	transport := findNamedValue(obj, notificationTransportPath(d), d.Get("name").(string))
+	if obj.id == "" || transport == nil {
+		log.Printf("resource_midpoint_notification_transport.go: The transport '%s' is gone. Removing from state.", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("default_from", scalarStringAt(transport, "defaultFrom"))
+	d.Set("redirect_to_file", scalarStringAt(transport, "redirectToFile"))
+
+	servers := make([]interface{}, 0)
+	if values, _ := asValueList(transport["server"]); len(values) > 0 {
+		if server, ok := values[0].(map[string]interface{}); ok {
+			servers = append(servers, map[string]interface{}{
+				"host":               scalarStringAt(server, "host"),
+				"port":               integerAt(server, "port"),
+				"username":           scalarStringAt(server, "username"),
+				"transport_security": scalarStringAt(server, "transportSecurity"),
+				/* midPoint only returns the password encrypted */
+				"password": d.Get("mail_server.0.password").(string),
+			})
+		}
+	}
+	d.Set("mail_server", servers)
+
+	/* configuration holds the items it was given, as they are now on the server */
+	if configuration := d.Get("configuration").(string); configuration != "" {
+		var keys map[string]interface{}
+		json.Unmarshal([]byte(configuration), &keys)
+		current := make(map[string]interface{}, len(keys))
+		for key := range keys {
+			if value, ok := transport[key]; ok {
+				current[key] = withoutContainerIDs(value)
+			}
+		}
+		b, _ := json.Marshal(current)
+		d.Set("configuration", string(b))
+	}
+
+	id, _ := containerIDString(transport)
+	return d.Set("container_id", id)
+}
+
+func resourceMidpointNotificationTransportUpdate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+	desired, err := buildMidpointNotificationTransport(d)
+	if err != nil {
+		return err
+	}
+
+	/* The items of the configuration before and after the change */
+	keys := append([]string{}, notificationTransportItems...)
+	oldConfiguration, newConfiguration := d.GetChange("configuration")
+	for _, configuration := range []interface{}{oldConfiguration, newConfiguration} {
+		var items map[string]interface{}
+		json.Unmarshal([]byte(configuration.(string)), &items)
+		for key := range items {
+			if indexOf(keys, key) < 0 && key != "name" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys[len(notificationTransportItems):])
+
+	err = obj.patchWithConflictRetry(func() error {
+		current := findNamedValue(obj, notificationTransportPath(d), d.Get("name").(string))
+		if obj.id == "" || current == nil {
+			return fmt.Errorf("the transport '%s' no longer exists on the server", d.Id())
+		}
+		valuePath, ok := containerValuePath(notificationTransportPath(d), current)
+		if !ok {
+			return fmt.Errorf("the transport '%s' has no container id to modify it with", d.Id())
+		}
+
+		/* The password is kept in the encrypted form midPoint returns unless it changes */
+		if !d.HasChange("mail_server.0.password") {
+			currentServers, _ := asValueList(current["server"])
+			desiredServers, _ := desired["server"].([]interface{})
+			if len(currentServers) > 0 && len(desiredServers) > 0 {
+				if password, ok := currentServers[0].(map[string]interface{})["password"]; ok {
+					desiredServers[0].(map[string]interface{})["password"] = password
+				}
+			}
+		}
+
+		itemDeltas := itemDeltasOf(obj, valuePath, keys, current, desired)
+		if len(itemDeltas) == 0 {
+			return nil
+		}
+		return obj.sendObjectModification(itemDeltas)
+	})
+	if err != nil {
+		return err
+	}
+	return resourceMidpointNotificationTransportRead(d, meta)
+}
+
+func resourceMidpointNotificationTransportDelete(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return obj.patchWithConflictRetry(func() error {
+		current := findNamedValue(obj, notificationTransportPath(d), d.Get("name").(string))
+		if obj.id == "" || current == nil {
+			/* Already gone */
+			return nil
+		}
+		return obj.sendMidpointPatch("delete", notificationTransportPath(d), []interface{}{containerValueToDelete(current)})
+	})
+}
+
+/* resourceMidpointNotificationTransportImport accepts the id of the resource, <type>/<name> */
+func resourceMidpointNotificationTransportImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	for _, transportType := range notificationTransportTypes {
+		if name := d.Id(); len(name) > len(transportType)+1 && name[:len(transportType)+1] == transportType+"/" {
+			d.Set("type", transportType)
+			d.Set("name", name[len(transportType)+1:])
+			return []*schema.ResourceData{d}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid id '%s': expected '<type>/<name>' with one of the types %v", d.Id(), notificationTransportTypes)
+}
@@ -0,0 +1,72 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointNotificationTransport(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "systemConfiguration/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Failed to create API client: %s", err)
+	}
+	configuration := `{"systemConfiguration": {"oid": "` + midpointSystemConfigurationOID + `", "name": "SystemConfiguration"}}`
+	if _, err := client.sendRequest("POST", "/systemConfigurations", configuration); err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Failed to create the system configuration: %s", err)
+	}
+
+	config := map[string]interface{}{
+		"name":         "smtp",
+		"default_from": "midpoint@example.com",
+		"mail_server":  []interface{}{map[string]interface{}{"host": "smtp.example.com", "port": 587, "username": "midpoint", "password": "secret", "transport_security": "starttlsRequired"}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceMidpointNotificationTransport().Schema, config)
+	if err := resourceMidpointNotificationTransportCreate(d, client); err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "mail/smtp" || d.Get("mail_server.0.port") != 587 || d.Get("mail_server.0.password") != "secret" {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Unexpected state after the create: %v", d.State())
+	}
+
+	/* midPoint encrypts the password and gives the containers ids */
+	server := `{"systemConfiguration": {"oid": "` + midpointSystemConfigurationOID + `", "name": "SystemConfiguration", "messageTransportConfiguration": {"mail": [
+		{"@id": 4, "name": "smtp", "defaultFrom": "midpoint@example.com", "server": [{"@id": 5, "host": "smtp.example.com", "port": 587, "username": "midpoint", "transportSecurity": "starttlsRequired", "password": {"encryptedData": {"cipherData": "abc"}}}]},
+		{"@id": 6, "name": "other", "defaultFrom": "other@example.com"}]}}}`
+	if _, err := client.sendRequest("PUT", "/systemConfigurations/"+midpointSystemConfigurationOID, server); err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Put failed: %s", err)
+	}
+
+	/* A changed sender keeps the encrypted password, which is only sent when its configuration changes */
+	config["default_from"] = "noreply@example.com"
+	config["mail_server"] = []interface{}{map[string]interface{}{"host": "smtp.example.com", "port": 587, "username": "midpoint", "transport_security": "starttlsRequired"}}
+	updated := schema.TestResourceDataRaw(t, resourceMidpointNotificationTransport().Schema, config)
+	updated.SetId(d.Id())
+	if err := resourceMidpointNotificationTransportUpdate(updated, client); err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Update failed: %s", err)
+	}
+	result, _ := client.sendRequest("GET", "/systemConfigurations/"+midpointSystemConfigurationOID, "")
+	for _, expected := range []string{`"defaultFrom":"noreply@example.com"`, `"cipherData":"abc"`, `"@id":5`, `"defaultFrom":"other@example.com"`} {
+		if !strings.Contains(result, expected) {
+			t.Fatalf("resource_midpoint_notification_transport_test.go: Expected %s in %s", expected, result)
+		}
+	}
+
+	imported := schema.TestResourceDataRaw(t, resourceMidpointNotificationTransport().Schema, map[string]interface{}{})
+	imported.SetId("mail/other")
+	if _, err := resourceMidpointNotificationTransportImport(imported, client); err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Import failed: %s", err)
+	}
+	if err := resourceMidpointNotificationTransportRead(imported, client); err != nil || imported.Get("default_from") != "other@example.com" || imported.Get("container_id") != "6" {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Unexpected state after the import: %v (%v)", imported.State(), err)
+	}
+
+	if err := resourceMidpointNotificationTransportDelete(updated, client); err != nil {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Delete failed: %s", err)
+	}
+	result, _ = client.sendRequest("GET", "/systemConfigurations/"+midpointSystemConfigurationOID, "")
+	if strings.Contains(result, `"smtp"`) || !strings.Contains(result, `"other"`) {
+		t.Fatalf("resource_midpoint_notification_transport_test.go: Expected only the deleted transport to be gone: %s", result)
+	}
+}
@@ -0,0 +1,144 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceMidpointPolicy() *schema.Resource {
+	attributes := policyRuleSchema()
+	attributes["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The name of the global policy rule, which identifies it in the system configuration.",
+		Required:    true,
+		ForceNew:    true,
+	}
+	attributes["focus_type"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The type of the objects the rule applies to, such as `UserType`. Not set applies it to all objects.",
+		Optional:    true,
+	}
+
+	return &schema.Resource{
+		Create:      resourceMidpointPolicyCreate,
+		Read:        resourceMidpointPolicyRead,
+		Update:      resourceMidpointPolicyUpdate,
+		Delete:      resourceMidpointPolicyDelete,
+		Description: "A global policy rule of midPoint, kept in the system configuration. Only this rule is added, modified and deleted with itemDeltas, so the rest of the system configuration is left untouched.",
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Schema: systemConfigurationAttributes(attributes, "rule"),
+	}
+}
+
+func buildMidpointPolicy(d *schema.ResourceData) map[string]interface{} {
+	rule := buildPolicyRule(map[string]interface{}{
+		"name":               d.Get("name"),
+		"description":        d.Get("description"),
+		"policy_constraints": d.Get("policy_constraints"),
+		"policy_actions":     d.Get("policy_actions"),
+	})
+	if focusType := d.Get("focus_type").(string); focusType != "" {
+		rule["focusSelector"] = map[string]interface{}{"type": focusType}
+	}
+	return rule
+}
+
+func resourceMidpointPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+
+	err = obj.patchWithConflictRetry(func() error {
+		if obj.id == "" {
+			return fmt.Errorf("the system configuration '%s' does not exist at '%s'", d.Get("system_configuration_oid").(string), d.Get("system_configuration_path").(string))
+		}
+		if findNamedValue(obj, "globalPolicyRule", name) != nil {
+			return fmt.Errorf("the system configuration already has a global policy rule named '%s'; import it with the id '%s'", name, name)
+		}
+		return obj.sendMidpointPatch("add", "globalPolicyRule", []interface{}{buildMidpointPolicy(d)})
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(name)
+	return resourceMidpointPolicyRead(d, meta)
+}
+
+func resourceMidpointPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+
+	rule := findNamedValue(obj, "globalPolicyRule", d.Id())
+	if obj.id == "" || rule == nil {
+		log.Printf("resource_midpoint_policy.go: The global policy rule '%s' is gone. Removing from state.", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	for attribute, value := range flattenPolicyRule(rule) {
+		d.Set(attribute, value)
+	}
+	focusType := ""
+	if selector, ok := rule["focusSelector"].(map[string]interface{}); ok {
+		focusType = refQName(scalarStringAt(selector, "type"))
+	}
+	d.Set("focus_type", focusType)
+	id, _ := containerIDString(rule)
+	return d.Set("container_id", id)
+}
+
+func resourceMidpointPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+
+	err = obj.patchWithConflictRetry(func() error {
+		current := findNamedValue(obj, "globalPolicyRule", d.Id())
+		if obj.id == "" || current == nil {
+			return fmt.Errorf("the global policy rule '%s' no longer exists on the server", d.Id())
+		}
+		valuePath, ok := containerValuePath("globalPolicyRule", current)
+		if !ok {
+			return fmt.Errorf("the global policy rule '%s' has no container id to modify it with", d.Id())
+		}
+
+		itemDeltas := itemDeltasOf(obj, valuePath, []string{"description", "focusSelector", "policyConstraints", "policyActions"}, current, buildMidpointPolicy(d))
+		if len(itemDeltas) == 0 {
+			return nil
+		}
+		return obj.sendObjectModification(itemDeltas)
+	})
+	if err != nil {
+		return err
+	}
+	return resourceMidpointPolicyRead(d, meta)
+}
+
+func resourceMidpointPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointSystemConfiguration(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return obj.patchWithConflictRetry(func() error {
+		current := findNamedValue(obj, "globalPolicyRule", d.Id())
+		if obj.id == "" || current == nil {
+			/* Already gone */
+			return nil
+		}
+		return obj.sendMidpointPatch("delete", "globalPolicyRule", []interface{}{containerValueToDelete(current)})
+	})
+}
@@ -0,0 +1,62 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointPolicy(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "systemConfiguration/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_policy_test.go: Failed to create API client: %s", err)
+	}
+	configuration := `{"systemConfiguration": {"oid": "` + midpointSystemConfigurationOID + `", "name": "SystemConfiguration", "globalPolicyRule": [{"@id": 7, "name": "existing", "policyConstraints": {"assignment": [{"@id": 8}]}}]}}`
+	if _, err := client.sendRequest("POST", "/systemConfigurations", configuration); err != nil {
+		t.Fatalf("resource_midpoint_policy_test.go: Failed to create the system configuration: %s", err)
+	}
+
+	config := map[string]interface{}{
+		"name":               "sod",
+		"focus_type":         "UserType",
+		"policy_constraints": `{"exclusion": [{"targetRef": {"oid": "role-2", "type": "RoleType"}}]}`,
+		"policy_actions":     `{"enforcement": {}}`,
+	}
+	d := schema.TestResourceDataRaw(t, resourceMidpointPolicy().Schema, config)
+	if err := resourceMidpointPolicyCreate(d, client); err != nil {
+		t.Fatalf("resource_midpoint_policy_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "sod" || d.Get("focus_type") != "UserType" {
+		t.Fatalf("resource_midpoint_policy_test.go: Unexpected state after the create: %v", d.State())
+	}
+
+	duplicate := schema.TestResourceDataRaw(t, resourceMidpointPolicy().Schema, map[string]interface{}{"name": "existing", "policy_constraints": "{}"})
+	if err := resourceMidpointPolicyCreate(duplicate, client); err == nil || !strings.Contains(err.Error(), "import it") {
+		t.Fatalf("resource_midpoint_policy_test.go: Expected an existing rule to be refused, got %v", err)
+	}
+
+	/* The existing rule is imported by its name and modified by its container id */
+	imported := schema.TestResourceDataRaw(t, resourceMidpointPolicy().Schema, map[string]interface{}{})
+	imported.SetId("existing")
+	if err := resourceMidpointPolicyRead(imported, client); err != nil || imported.Get("container_id") != "7" || imported.Get("policy_constraints") != `{"assignment":[{}]}` {
+		t.Fatalf("resource_midpoint_policy_test.go: Unexpected state after the import: %v (%v)", imported.State(), err)
+	}
+	updated := schema.TestResourceDataRaw(t, resourceMidpointPolicy().Schema, map[string]interface{}{"name": "existing", "description": "Watch assignments", "policy_constraints": `{"assignment": [{}]}`})
+	updated.SetId("existing")
+	if err := resourceMidpointPolicyUpdate(updated, client); err != nil {
+		t.Fatalf("resource_midpoint_policy_test.go: Update failed: %s", err)
+	}
+	result, _ := client.sendRequest("GET", "/systemConfigurations/"+midpointSystemConfigurationOID, "")
+	if !strings.Contains(result, `"description":"Watch assignments"`) || !strings.Contains(result, `"@id":8`) {
+		t.Fatalf("resource_midpoint_policy_test.go: Expected only the description to be modified: %s", result)
+	}
+
+	if err := resourceMidpointPolicyDelete(d, client); err != nil {
+		t.Fatalf("resource_midpoint_policy_test.go: Delete failed: %s", err)
+	}
+	result, _ = client.sendRequest("GET", "/systemConfigurations/"+midpointSystemConfigurationOID, "")
+	if strings.Contains(result, `"sod"`) || !strings.Contains(result, `"existing"`) {
+		t.Fatalf("resource_midpoint_policy_test.go: Expected only the deleted rule to be gone: %s", result)
+	}
+}
@@ -0,0 +1,358 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* The oids of midPoint's built-in Import task archetype and administrator */
+const (
+	midpointImportTaskArchetypeOID = "00000000-0000-0000-0000-000000000503"
+	midpointAdministratorOID       = "00000000-0000-0000-0000-000000000002"
+)
+
+/* How often a task is read while waiting for it to complete; tests shorten it */
+var taskPollInterval = 5 * time.Second
+
+func resourceMidpointResourceImportTask() *schema.Resource {
+	return &schema.Resource{
+		Create:      resourceMidpointResourceImportTaskCreate,
+		Read:        resourceMidpointResourceImportTaskRead,
+		Update:      resourceMidpointResourceImportTaskRead,
+		Delete:      resourceMidpointResourceImportTaskDelete,
+		Description: "Creates and launches a midPoint task that imports the objects of a resource, such as right after the resource is provisioned, and optionally waits for it to complete. Changing what is imported replaces the task, which runs the import again.",
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/tasks`. The API path of the tasks on the midPoint server.",
+				Optional:    true,
+				Default:     "/tasks",
+				ForceNew:    true,
+			},
+			"oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the task. A random oid is generated when not set.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the task. Defaults to a name made of the resource oid and the object class or kind.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"resource_oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the resource to import from.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"object_class": {
+				Type:         schema.TypeString,
+				Description:  "The object class to import, such as `AccountObjectClass` or `ri:inetOrgPerson`.",
+				Optional:     true,
+				ForceNew:     true,
+				AtLeastOneOf: []string{"object_class", "kind"},
+			},
+			"kind": {
+				Type:        schema.TypeString,
+				Description: "The kind of the objects to import, such as `account`, as defined in the schemaHandling of the resource.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"intent": {
+				Type:         schema.TypeString,
+				Description:  "The intent of the objects of `kind` to import, such as `default`.",
+				Optional:     true,
+				ForceNew:     true,
+				RequiredWith: []string{"kind"},
+			},
+			"owner_oid": {
+				Type:        schema.TypeString,
+				Description: fmt.Sprintf("Defaults to `%s` (administrator). The oid of the user the task runs as.", midpointAdministratorOID),
+				Optional:    true,
+				Default:     midpointAdministratorOID,
+				ForceNew:    true,
+			},
+			"wait_for_completion": {
+				Type:        schema.TypeBool,
+				Description: "Whether to wait for the import to complete when the task is created, failing when it does not succeed or takes longer than the create timeout.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the object on the server.",
+				Optional:    true,
+			},
+			"execution_state": {
+				Type:        schema.TypeString,
+				Description: "The execution state of the task, such as `running` or `closed`.",
+				Computed:    true,
+			},
+			"result_status": {
+				Type:        schema.TypeString,
+				Description: "The status of the result of the task, such as `success` or `fatal_error`.",
+				Computed:    true,
+			},
+			"result_message": {
+				Type:        schema.TypeString,
+				Description: "The message of the result of the task, such as the error it failed with.",
+				Computed:    true,
+			},
+			"progress": {
+				Type:        schema.TypeInt,
+				Description: "The number of shadows the import processed.",
+				Computed:    true,
+			},
+			"imported_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of shadows imported successfully.",
+				Computed:    true,
+			},
+			"failed_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of shadows whose import failed.",
+				Computed:    true,
+			},
+			"skipped_count": {
+				Type:        schema.TypeInt,
+				Description: "The number of shadows the import skipped.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* buildMidpointResourceImportTask returns the task of the attributes, which runs as soon as it is created */
+func buildMidpointResourceImportTask(d *schema.ResourceData, oid string) map[string]interface{} {
+	objects := map[string]interface{}{
+		"resourceRef": map[string]interface{}{"oid": d.Get("resource_oid").(string), "type": "ResourceType"},
+	}
+	what := d.Get("kind").(string)
+	if objectClass := d.Get("object_class").(string); objectClass != "" {
+		if !strings.Contains(objectClass, ":") {
+			objectClass = "ri:" + objectClass
+		}
+		objects["objectclass"] = objectClass
+		what = refQName(objectClass)
+	}
+	setIfNotEmpty(objects, "kind", d.Get("kind").(string))
+	setIfNotEmpty(objects, "intent", d.Get("intent").(string))
+
+	name := d.Get("name").(string)
+	if name == "" {
+		name = fmt.Sprintf("Import %s from %s", what, d.Get("resource_oid").(string))
+	}
+
+	return map[string]interface{}{"task": map[string]interface{}{
+		"oid":             oid,
+		"name":            name,
+		"ownerRef":        map[string]interface{}{"oid": d.Get("owner_oid").(string), "type": "UserType"},
+		"executionState":  "runnable",
+		"schedulingState": "ready",
+		"assignment":      buildArchetypeAssignments([]interface{}{midpointImportTaskArchetypeOID}),
+		"activity": map[string]interface{}{
+			"work": map[string]interface{}{"import": map[string]interface{}{"resourceObjects": objects}},
+		},
+	}}
+}
+
+/*
+taskItemCounts returns the numbers of items a task processed by outcome
+
+	(success, failure and skip), from the committed progress of its activity.
+*/
+func taskItemCounts(task map[string]interface{}) map[string]int {
+	counts := make(map[string]int)
+	committed, err := GetObjectAtKey(task, "activityState/activity/progress/committed", false)
+	if err != nil {
+		return counts
+	}
+	values, _ := asValueList(committed)
+	for _, value := range values {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		outcome := scalarStringAt(entry, "outcome")
+		if qualified, ok := entry["outcome"].(map[string]interface{}); ok {
+			/* A QualifiedItemProcessingOutcomeType */
+			outcome = scalarStringAt(qualified, "outcome")
+		}
+		counts[outcome] += integerAt(entry, "count")
+	}
+	return counts
+}
+
+/* taskFinished reports whether a task is no longer going to run */
+func taskFinished(task map[string]interface{}) bool {
+	for _, state := range []string{scalarStringAt(task, "executionState"), scalarStringAt(task, "schedulingState")} {
+		if state == "closed" || state == "suspended" {
+			return true
+		}
+	}
+	return false
+}
+
+/* midpointTaskObject returns the APIObject of the task of the resource */
+func midpointTaskObject(d *schema.ResourceData, meta interface{}) (*APIObject, error) {
+	return NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:        d.Get("path").(string),
+		id:          d.Id(),
+		idAttribute: "task/oid",
+		debug:       d.Get("debug").(bool),
+	})
+}
+
+func resourceMidpointResourceImportTaskCreate(d *schema.ResourceData, meta interface{}) error {
+	oid := d.Get("oid").(string)
+	if oid == "" {
+		var err error
+		if oid, err = generateOID(); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(buildMidpointResourceImportTask(d, oid))
+	if err != nil {
+		return err
+	}
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:        d.Get("path").(string),
+		id:          oid,
+		idAttribute: "task/oid",
+		debug:       d.Get("debug").(bool),
+		data:        string(data),
+	})
+	if err != nil {
+		return err
+	}
+	if err := obj.createObject(); err != nil {
+		return err
+	}
+	d.SetId(oid)
+
+	if d.Get("wait_for_completion").(bool) {
+		if err := waitForMidpointTask(d, meta, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return err
+		}
+	}
+	return resourceMidpointResourceImportTaskRead(d, meta)
+}
+
+/* waitForMidpointTask reads the task of the resource until it is closed or suspended, failing unless it succeeded */
+func waitForMidpointTask(d *schema.ResourceData, meta interface{}, timeout time.Duration) error {
+	obj, err := midpointTaskObject(d, meta)
+	if err != nil {
+		return err
+	}
+	return pollMidpointTask(obj, timeout, taskPollInterval)
+}
+
+/*
+pollMidpointTask reads the task of obj every interval until it is closed or
+
+	suspended. A task
+
+	that did not succeed fails with its result and the failed steps of it.
+*/
+func pollMidpointTask(obj *APIObject, timeout time.Duration, interval time.Duration) error {
+	oid := obj.id
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := obj.readObject(); err != nil {
+			return err
+		}
+		if obj.id == "" {
+			return fmt.Errorf("the task '%s' was deleted while waiting for it to complete", oid)
+		}
+
+		task := midpointObjectOf(obj.apiData)
+		if taskFinished(task) {
+			if !taskHealthy(task) {
+				status, message := taskResult(task)
+				msg := fmt.Sprintf("the task '%s' ended %s with the result %s: %s", oid, scalarStringAt(task, "executionState"), status, message)
+				if result, ok := task["result"].(map[string]interface{}); ok {
+					if steps := failedOperationSteps(result, "  "); len(steps) > 0 {
+						msg += "\n" + strings.Join(steps, "\n")
+					}
+				}
+				return errors.New(msg)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the task '%s' did not complete within %s; it is still %s", oid, timeout, scalarStringAt(task, "executionState"))
+		}
+		if obj.debug {
+			log.Printf("resource_midpoint_resource_import_task.go: Waiting for the task '%s', which is %s with progress %d", oid, scalarStringAt(task, "executionState"), integerAt(task, "progress"))
+		}
+		time.Sleep(interval)
+	}
+}
+
+func resourceMidpointResourceImportTaskRead(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointTaskObject(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+	if obj.id == "" {
+		log.Printf("resource_midpoint_resource_import_task.go: The task '%s' is gone. Removing from state.", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	task := midpointObjectOf(obj.apiData)
+	objects, _ := GetObjectAtKey(task, "activity/work/import/resourceObjects", false)
+	if objects, ok := objects.(map[string]interface{}); ok {
+		d.Set("resource_oid", stringAtKey(objects, "resourceRef/oid"))
+		d.Set("kind", scalarStringAt(objects, "kind"))
+		d.Set("intent", scalarStringAt(objects, "intent"))
+		/* The object class is kept as configured, with or without its prefix */
+		if objectClass := scalarStringAt(objects, "objectclass"); refQName(objectClass) != refQName(d.Get("object_class").(string)) {
+			d.Set("object_class", refQName(objectClass))
+		}
+	}
+	status, message := taskResult(task)
+	counts := taskItemCounts(task)
+
+	d.Set("oid", d.Id())
+	d.Set("name", scalarStringAt(task, "name"))
+	d.Set("owner_oid", stringAtKey(task, "ownerRef/oid"))
+	d.Set("execution_state", scalarStringAt(task, "executionState"))
+	d.Set("result_status", status)
+	d.Set("result_message", message)
+	d.Set("progress", integerAt(task, "progress"))
+	d.Set("imported_count", counts["success"])
+	d.Set("failed_count", counts["failure"])
+	return d.Set("skipped_count", counts["skip"])
+}
+
+func resourceMidpointResourceImportTaskDelete(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointTaskObject(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := obj.deleteObject(); err != nil && !strings.Contains(err.Error(), "404") {
+		return err
+	}
+	return nil
+}
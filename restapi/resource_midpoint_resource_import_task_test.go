@@ -0,0 +1,87 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointResourceImportTask(t *testing.T) {
+	taskPollInterval = 10 * time.Millisecond
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "task/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceMidpointResourceImportTask().Schema, map[string]interface{}{
+		"oid":          "task-1",
+		"resource_oid": "resource-1",
+		"object_class": "AccountObjectClass",
+	})
+	if err := resourceMidpointResourceImportTaskCreate(d, client); err != nil {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Create failed: %s", err)
+	}
+	created, _ := client.sendRequest("GET", "/tasks/task-1", "")
+	for _, expected := range []string{
+		`"resourceObjects":{"objectclass":"ri:AccountObjectClass","resourceRef":{"oid":"resource-1","type":"ResourceType"}}`,
+		`"targetRef":{"oid":"00000000-0000-0000-0000-000000000503","type":"ArchetypeType"}`,
+		`"executionState":"runnable"`,
+		`"name":"Import AccountObjectClass from resource-1"`,
+	} {
+		if !strings.Contains(created, expected) {
+			t.Fatalf("resource_midpoint_resource_import_task_test.go: Expected %s in the task, got %s", expected, created)
+		}
+	}
+	if d.Get("execution_state") != "runnable" || d.Get("object_class") != "AccountObjectClass" || d.Get("owner_oid") != midpointAdministratorOID {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Unexpected attributes %v", d.State())
+	}
+
+	/* The task does not complete in memory */
+	if err := waitForMidpointTask(d, client, 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "did not complete within") {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Expected waiting to time out, got %v", err)
+	}
+
+	/* midPoint closes the task and counts the shadows */
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		client.sendRequest("PATCH", "/tasks/task-1", `{"objectModification": {"itemDelta": [
+			{"modificationType": "replace", "path": "executionState", "value": "closed"},
+			{"modificationType": "replace", "path": "resultStatus", "value": "success"},
+			{"modificationType": "replace", "path": "progress", "value": 7},
+			{"modificationType": "replace", "path": "activityState", "value": {"activity": {"progress": {"committed": [
+				{"outcome": {"outcome": "success"}, "count": 5}, {"outcome": {"outcome": "failure"}, "count": 1}, {"outcome": "skip", "count": 1}]}}}}]}}`)
+	}()
+	if err := waitForMidpointTask(d, client, time.Second); err != nil {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Waiting failed: %s", err)
+	}
+	if err := resourceMidpointResourceImportTaskRead(d, client); err != nil {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Read failed: %s", err)
+	}
+	if d.Get("execution_state") != "closed" || d.Get("progress") != 7 || d.Get("imported_count") != 5 || d.Get("failed_count") != 1 || d.Get("skipped_count") != 1 {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Unexpected attributes %v", d.State())
+	}
+
+	/* A task that fails makes waiting fail with its result */
+	failed := schema.TestResourceDataRaw(t, resourceMidpointResourceImportTask().Schema, map[string]interface{}{"resource_oid": "resource-1", "kind": "account", "intent": "default"})
+	if err := resourceMidpointResourceImportTaskCreate(failed, client); err != nil {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Create failed: %s", err)
+	}
+	if failed.Get("name") != "Import account from resource-1" {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Unexpected name '%s'", failed.Get("name"))
+	}
+	client.sendRequest("PATCH", "/tasks/"+failed.Id(), `{"objectModification": {"itemDelta": [
+		{"modificationType": "replace", "path": "executionState", "value": "suspended"},
+		{"modificationType": "replace", "path": "result", "value": {"status": "fatal_error", "message": "Connection refused"}}]}}`)
+	if err := waitForMidpointTask(failed, client, time.Second); err == nil || !strings.Contains(err.Error(), "ended suspended with the result fatal_error: Connection refused") {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Expected waiting for a failed task to fail, got %v", err)
+	}
+
+	if err := resourceMidpointResourceImportTaskDelete(d, client); err != nil {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Delete failed: %s", err)
+	}
+	if err := resourceMidpointResourceImportTaskRead(d, client); err != nil || d.Id() != "" {
+		t.Fatalf("resource_midpoint_resource_import_task_test.go: Expected the deleted task to leave the state, got %v", err)
+	}
+}
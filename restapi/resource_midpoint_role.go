@@ -0,0 +1,174 @@
+package restapi
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/* Values of authorization/decision */
+var authorizationDecisions = []string{"allow", "deny"}
+
+var midpointRole = &midpointType{
+	wrapper:       "role",
+	path:          "/roles",
+	managedFields: []string{"name", "displayName", "description", "inducement.targetRef", "authorization", "assignment.targetRef"},
+	listMergeKeys: map[string]string{"inducement": "targetRef/oid", "assignment": "targetRef/oid"},
+	keepValue:     keepNonArchetypeAssignment,
+	build:         buildMidpointRole,
+	flatten:       flattenMidpointRole,
+}
+
+func resourceMidpointRole() *schema.Resource {
+	return midpointRole.resource("A midPoint role (RoleType) with typed attributes. The resource builds the role's JSON and sends only the modified items as midPoint itemDeltas; items of the role it has no attribute for, such as those set by midPoint itself, are left untouched.", map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the role.",
+			Required:    true,
+		},
+		"display_name": {
+			Type:        schema.TypeString,
+			Description: "The display name of the role.",
+			Optional:    true,
+		},
+		"description": {
+			Type:        schema.TypeString,
+			Description: "The description of the role.",
+			Optional:    true,
+		},
+		"inducement": targetRefsSchema("The inducements of the role, matched to the inducements on the server by their target, so that adding or removing one only adds or deletes that inducement. Inducements without a target, such as constructions, are left untouched.", "The oid of the induced role, org, service or archetype."),
+		"authorization": {
+			Type:        schema.TypeList,
+			Description: "The authorizations of the role. They are managed as a whole: authorizations on the server that are not listed here are deleted.",
+			Optional:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Description: "The name of the authorization.",
+						Optional:    true,
+					},
+					"description": {
+						Type:        schema.TypeString,
+						Description: "The description of the authorization.",
+						Optional:    true,
+					},
+					"decision": {
+						Type:         schema.TypeString,
+						Description:  "One of `allow` or `deny`. Default: `allow`",
+						Optional:     true,
+						Default:      "allow",
+						ValidateFunc: validation.StringInSlice(authorizationDecisions, false),
+					},
+					"actions": {
+						Type:        schema.TypeList,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+						Description: "The action URIs the authorization applies to, such as `http://midpoint.evolveum.com/xml/ns/public/security/authorization-model-3#read`.",
+						Required:    true,
+						MinItems:    1,
+					},
+					"object_types": {
+						Type:        schema.TypeList,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+						Description: "The types of the objects the authorization applies to, such as `UserType`. Not set applies it to all objects.",
+						Optional:    true,
+					},
+				},
+			},
+		},
+		"archetype_oids": {
+			Type:        schema.TypeList,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The oids of the archetypes of the role. midPoint sets archetypeRef from archetype assignments, so each is sent as an assignment; the other assignments of the role are left untouched.",
+			Optional:    true,
+		},
+	})
+}
+
+func buildMidpointRole(d *schema.ResourceData) map[string]interface{} {
+	role := map[string]interface{}{"name": d.Get("name").(string)}
+	setIfNotEmpty(role, "displayName", d.Get("display_name").(string))
+	setIfNotEmpty(role, "description", d.Get("description").(string))
+	if inducements := buildTargetRefs(d.Get("inducement").([]interface{})); len(inducements) > 0 {
+		role["inducement"] = inducements
+	}
+
+	authorizations := make([]interface{}, 0)
+	for _, a := range d.Get("authorization").([]interface{}) {
+		a := a.(map[string]interface{})
+		authorization := map[string]interface{}{"decision": a["decision"], "action": a["actions"]}
+		setIfNotEmpty(authorization, "name", a["name"].(string))
+		setIfNotEmpty(authorization, "description", a["description"].(string))
+		if types := a["object_types"].([]interface{}); len(types) > 0 {
+			selectors := make([]interface{}, 0, len(types))
+			for _, objectType := range types {
+				selectors = append(selectors, map[string]interface{}{"type": objectType})
+			}
+			authorization["object"] = selectors
+		}
+		authorizations = append(authorizations, authorization)
+	}
+	if len(authorizations) > 0 {
+		role["authorization"] = authorizations
+	}
+
+	if assignments := buildArchetypeAssignments(d.Get("archetype_oids").([]interface{})); len(assignments) > 0 {
+		role["assignment"] = assignments
+	}
+	return role
+}
+
+func flattenMidpointRole(d *schema.ResourceData, role map[string]interface{}) error {
+	d.Set("name", scalarStringAt(role, "name"))
+	d.Set("display_name", scalarStringAt(role, "displayName"))
+	d.Set("description", scalarStringAt(role, "description"))
+	d.Set("inducement", flattenTargetRefs(role["inducement"], nil))
+
+	values, _ := asValueList(role["authorization"])
+	authorizations := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		hash, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		decision := scalarStringAt(hash, "decision")
+		if decision == "" {
+			decision = "allow"
+		}
+		actions, _ := asScalarList(hash["action"])
+		objectTypes := make([]string, 0)
+		selectors, _ := asValueList(hash["object"])
+		for _, selector := range selectors {
+			if selector, ok := selector.(map[string]interface{}); ok {
+				if objectType := scalarStringAt(selector, "type"); objectType != "" {
+					objectTypes = append(objectTypes, refQName(objectType))
+				}
+			}
+		}
+		authorizations = append(authorizations, map[string]interface{}{
+			"name":         scalarStringAt(hash, "name"),
+			"description":  scalarStringAt(hash, "description"),
+			"decision":     decision,
+			"actions":      actions,
+			"object_types": objectTypes,
+		})
+	}
+	d.Set("authorization", authorizations)
+
+	return d.Set("archetype_oids", flattenArchetypeOids(role["assignment"]))
+}
+
+/* asScalarList returns the text of every value of a single or multi-value item */
+func asScalarList(value interface{}) ([]string, bool) {
+	strs := make([]string, 0)
+	switch v := value.(type) {
+	case nil:
+		return strs, false
+	case []interface{}:
+		for _, elem := range v {
+			strs = append(strs, scalarString(elem))
+		}
+	default:
+		strs = append(strs, scalarString(v))
+	}
+	return strs, true
+}
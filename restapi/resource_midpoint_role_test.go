@@ -0,0 +1,99 @@
+package restapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointRole(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "role/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_role_test.go: Failed to create API client: %s", err)
+	}
+
+	read := "http://midpoint.evolveum.com/xml/ns/public/security/authorization-model-3#read"
+	config := map[string]interface{}{
+		"oid":          "role-oid",
+		"name":         "auditor",
+		"display_name": "Auditor",
+		"inducement":   []interface{}{map[string]interface{}{"target_oid": "role-1"}, map[string]interface{}{"target_oid": "role-2"}},
+		"authorization": []interface{}{
+			map[string]interface{}{"name": "read-users", "actions": []interface{}{read}, "object_types": []interface{}{"UserType"}},
+		},
+		"archetype_oids": []interface{}{"archetype-1"},
+	}
+	d := schema.TestResourceDataRaw(t, resourceMidpointRole().Schema, config)
+	if err := midpointRole.create(d, client); err != nil {
+		t.Fatalf("resource_midpoint_role_test.go: Create failed: %s", err)
+	}
+	if d.Id() != "role-oid" {
+		t.Fatalf("resource_midpoint_role_test.go: Expected the configured oid, got '%s'", d.Id())
+	}
+
+	created, _ := client.sendRequest("GET", "/roles/role-oid", "")
+	for _, expected := range []string{`"authorization":[{"action":["` + read + `"],"decision":"allow","name":"read-users","object":[{"type":"UserType"}]}]`, `"targetRef":{"oid":"archetype-1","type":"ArchetypeType"}`} {
+		if !strings.Contains(created, expected) {
+			t.Fatalf("resource_midpoint_role_test.go: Expected %s in the created role %s", expected, created)
+		}
+	}
+
+	/* midPoint adds ids, qualifies types and writes a single action without the list */
+	server := `{"role": {"oid": "role-oid", "name": {"orig": "auditor", "norm": "auditor"}, "displayName": {"orig": "Auditor", "norm": "auditor"},
+		"inducement": [
+			{"@id": 1, "targetRef": {"oid": "role-1", "type": "c:RoleType"}},
+			{"@id": 2, "targetRef": {"oid": "role-2", "type": "c:RoleType"}},
+			{"@id": 3, "construction": {"resourceRef": {"oid": "resource-1"}}}
+		],
+		"authorization": [{"@id": 4, "name": "read-users", "decision": "allow", "action": "` + read + `", "object": [{"@id": 5, "type": "c:UserType"}]}],
+		"assignment": [
+			{"@id": 6, "targetRef": {"oid": "archetype-1", "type": "c:ArchetypeType"}},
+			{"@id": 7, "targetRef": {"oid": "meta-role", "type": "c:RoleType"}}
+		],
+		"archetypeRef": {"oid": "archetype-1", "type": "c:ArchetypeType"}}}`
+	if _, err := client.sendRequest("PUT", "/roles/role-oid", server); err != nil {
+		t.Fatalf("resource_midpoint_role_test.go: Put failed: %s", err)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceMidpointRole().Schema, map[string]interface{}{})
+	d.SetId("role-oid")
+	if err := midpointRole.read(d, client); err != nil {
+		t.Fatalf("resource_midpoint_role_test.go: Read failed: %s", err)
+	}
+	expectedAuthorizations := []interface{}{map[string]interface{}{
+		"name": "read-users", "description": "", "decision": "allow", "actions": []interface{}{read}, "object_types": []interface{}{"UserType"},
+	}}
+	if !reflect.DeepEqual(d.Get("authorization"), expectedAuthorizations) {
+		t.Fatalf("resource_midpoint_role_test.go: Unexpected authorizations %v", d.Get("authorization"))
+	}
+	if !reflect.DeepEqual(d.Get("archetype_oids"), []interface{}{"archetype-1"}) || d.Get("inducement.#") != 2 || d.Get("display_name") != "Auditor" {
+		t.Fatalf("resource_midpoint_role_test.go: Unexpected attributes after the read: %v", d.State())
+	}
+
+	/* Only the changed items are sent */
+	config["description"] = "Reads everything"
+	config["inducement"] = []interface{}{map[string]interface{}{"target_oid": "role-2"}}
+	d = schema.TestResourceDataRaw(t, resourceMidpointRole().Schema, config)
+	d.SetId("role-oid")
+	if err := midpointRole.update(d, client); err != nil {
+		t.Fatalf("resource_midpoint_role_test.go: Update failed: %s", err)
+	}
+	deltas := strings.Join(expandStringList(d.Get("last_applied_deltas").([]interface{})), "\n")
+	for _, expected := range []string{`"modificationType":"add","path":"description","value":"Reads everything"`, `"modificationType":"delete","path":"inducement","value":[{"@id":1}]`} {
+		if !strings.Contains(deltas, expected) {
+			t.Fatalf("resource_midpoint_role_test.go: Expected %s in the deltas:\n%s", expected, deltas)
+		}
+	}
+	if strings.Contains(deltas, "authorization") || strings.Contains(deltas, "assignment") || strings.Contains(deltas, "displayName") {
+		t.Fatalf("resource_midpoint_role_test.go: Expected only the description and the removed inducement to change:\n%s", deltas)
+	}
+
+	updated, _ := client.sendRequest("GET", "/roles/role-oid", "")
+	for _, expected := range []string{`"construction"`, `"meta-role"`, `"archetypeRef"`} {
+		if !strings.Contains(updated, expected) {
+			t.Fatalf("resource_midpoint_role_test.go: Expected %s to be left untouched in %s", expected, updated)
+		}
+	}
+}
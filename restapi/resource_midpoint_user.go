@@ -0,0 +1,269 @@
+package restapi
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/* Values of activation/administrativeStatus */
+var administrativeStatuses = []string{"enabled", "disabled", "archived"}
+
+var midpointUser = &midpointType{
+	wrapper:       "user",
+	path:          "/users",
+	managedFields: []string{"name", "givenName", "familyName", "emailAddress", "activation.administrativeStatus", "activation.validFrom", "activation.validTo", "assignment.targetRef", "extension"},
+	listMergeKeys: map[string]string{"assignment": "targetRef/oid"},
+	build:         buildMidpointUser,
+	flatten:       flattenMidpointUser,
+}
+
+func resourceMidpointUser() *schema.Resource {
+	return midpointUser.resource("A midPoint user (UserType) with typed attributes. The resource builds the user's JSON and sends only the modified items as midPoint itemDeltas; items of the user it has no attribute for, such as those set by midPoint itself, are left untouched.", map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name (username) of the user.",
+			Required:    true,
+		},
+		"given_name": {
+			Type:        schema.TypeString,
+			Description: "The given name of the user.",
+			Optional:    true,
+		},
+		"family_name": {
+			Type:        schema.TypeString,
+			Description: "The family name of the user.",
+			Optional:    true,
+		},
+		"email": {
+			Type:        schema.TypeString,
+			Description: "The email address of the user.",
+			Optional:    true,
+		},
+		"activation": activationSchema(),
+		"assignment": targetRefsSchema("The assignments of the user, matched to the assignments on the server by their target, so that adding or removing one only adds or deletes that assignment.", "The oid of the assigned role, org, service or archetype."),
+		"extension": {
+			Type:        schema.TypeMap,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Items of the schema extension of the user by name, such as `costCenter`. Each item is modified on its own.",
+			Optional:    true,
+		},
+		"extension_namespace": {
+			Type:        schema.TypeString,
+			Description: "The namespace of the schema extension items in `extension`, used to qualify their paths in itemDeltas.",
+			Optional:    true,
+		},
+	})
+}
+
+/* activationSchema is the activation block of typed midPoint resources */
+func activationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: "The activation of the object.",
+		Optional:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"administrative_status": {
+					Type:         schema.TypeString,
+					Description:  "One of `enabled`, `disabled` or `archived`. Not set lets midPoint compute the status.",
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice(administrativeStatuses, false),
+				},
+				"valid_from": {
+					Type:             schema.TypeString,
+					DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool { return sameInstant(old, new) },
+					Description:      "The RFC 3339 timestamp the object becomes valid at.",
+					Optional:         true,
+					ValidateFunc:     validation.IsRFC3339Time,
+				},
+				"valid_to": {
+					Type:             schema.TypeString,
+					DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool { return sameInstant(old, new) },
+					Description:      "The RFC 3339 timestamp the object stops being valid at.",
+					Optional:         true,
+					ValidateFunc:     validation.IsRFC3339Time,
+				},
+			},
+		},
+	}
+}
+
+func buildMidpointUser(d *schema.ResourceData) map[string]interface{} {
+	user := map[string]interface{}{"name": d.Get("name").(string)}
+	setIfNotEmpty(user, "givenName", d.Get("given_name").(string))
+	setIfNotEmpty(user, "familyName", d.Get("family_name").(string))
+	setIfNotEmpty(user, "emailAddress", d.Get("email").(string))
+	if activation := buildActivation(d.Get("activation").([]interface{})); len(activation) > 0 {
+		user["activation"] = activation
+	}
+
+	if assignments := buildTargetRefs(d.Get("assignment").([]interface{})); len(assignments) > 0 {
+		user["assignment"] = assignments
+	}
+
+	if extension := buildExtension(d); len(extension) > 0 {
+		user["extension"] = extension
+	}
+	return user
+}
+
+func flattenMidpointUser(d *schema.ResourceData, user map[string]interface{}) error {
+	d.Set("name", scalarStringAt(user, "name"))
+	d.Set("given_name", scalarStringAt(user, "givenName"))
+	d.Set("family_name", scalarStringAt(user, "familyName"))
+	d.Set("email", scalarStringAt(user, "emailAddress"))
+	d.Set("activation", flattenActivation(user["activation"]))
+
+	d.Set("assignment", flattenTargetRefs(user["assignment"], nil))
+
+	return d.Set("extension", flattenExtension(user["extension"]))
+}
+
+/* targetRefsSchema is a list of assignments or inducements of typed midPoint resources */
+func targetRefsSchema(description string, targetDescription string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Description: description,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"target_oid": {
+					Type:        schema.TypeString,
+					Description: targetDescription,
+					Required:    true,
+				},
+				"target_type": {
+					Type:        schema.TypeString,
+					Description: "The type of the target, such as `OrgType` or `ArchetypeType`. Default: `RoleType`",
+					Optional:    true,
+					Default:     "RoleType",
+				},
+				"relation": {
+					Type:        schema.TypeString,
+					Description: "The relation of the assignment, such as `manager`. Not set means the default relation.",
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+/* buildTargetRefs returns the assignment or inducement container values of targetRefsSchema blocks */
+func buildTargetRefs(blocks []interface{}) []interface{} {
+	values := make([]interface{}, 0, len(blocks))
+	for _, block := range blocks {
+		block := block.(map[string]interface{})
+		targetRef := map[string]interface{}{"oid": block["target_oid"], "type": block["target_type"]}
+		setIfNotEmpty(targetRef, "relation", block["relation"].(string))
+		values = append(values, map[string]interface{}{"targetRef": targetRef})
+	}
+	return values
+}
+
+/*
+flattenTargetRefs returns the targetRefsSchema blocks of assignment or
+
+	inducement container values, leaving out the values without a target
+	and those keep rejects.
+*/
+func flattenTargetRefs(value interface{}, keep func(targetRef map[string]interface{}) bool) []interface{} {
+	values, _ := asValueList(value)
+	blocks := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		hash, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		targetRef, ok := hash["targetRef"].(map[string]interface{})
+		if !ok || keep != nil && !keep(targetRef) {
+			/* Policy rules, constructions and the like have no attribute */
+			continue
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"target_oid":  stringAtKey(targetRef, "oid"),
+			"target_type": refQName(stringAtKey(targetRef, "type")),
+			"relation":    refQName(stringAtKey(targetRef, "relation")),
+		})
+	}
+	return blocks
+}
+
+/* buildActivation returns the activation container of an activation block */
+func buildActivation(blocks []interface{}) map[string]interface{} {
+	activation := make(map[string]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return activation
+	}
+	block := blocks[0].(map[string]interface{})
+	setIfNotEmpty(activation, "administrativeStatus", block["administrative_status"].(string))
+	setIfNotEmpty(activation, "validFrom", block["valid_from"].(string))
+	setIfNotEmpty(activation, "validTo", block["valid_to"].(string))
+	return activation
+}
+
+/* flattenActivation returns the activation block of an activation container, leaving out the computed items */
+func flattenActivation(value interface{}) []interface{} {
+	activation, ok := value.(map[string]interface{})
+	if !ok {
+		return []interface{}{}
+	}
+	block := map[string]interface{}{
+		"administrative_status": scalarStringAt(activation, "administrativeStatus"),
+		"valid_from":            scalarStringAt(activation, "validFrom"),
+		"valid_to":              scalarStringAt(activation, "validTo"),
+	}
+	if block["administrative_status"] == "" && block["valid_from"] == "" && block["valid_to"] == "" {
+		return []interface{}{}
+	}
+	return []interface{}{block}
+}
+
+/* buildExtension returns the extension container of the extension attributes */
+func buildExtension(d *schema.ResourceData) map[string]interface{} {
+	extension := make(map[string]interface{})
+	for name, value := range d.Get("extension").(map[string]interface{}) {
+		extension[name] = value
+	}
+	if ns, ok := d.GetOk("extension_namespace"); ok && len(extension) > 0 {
+		extension["@ns"] = ns
+	}
+	return extension
+}
+
+/* flattenExtension returns the extension attribute of an extension container */
+func flattenExtension(value interface{}) map[string]interface{} {
+	flattened := make(map[string]interface{})
+	extension, ok := value.(map[string]interface{})
+	if !ok {
+		return flattened
+	}
+	for name, item := range extension {
+		if name == "@ns" {
+			continue
+		}
+		/* Items are known by their local name */
+		if i := strings.LastIndexByte(name, ':'); i >= 0 {
+			name = name[i+1:]
+		}
+		flattened[name] = scalarString(item)
+	}
+	return flattened
+}
+
+func setIfNotEmpty(hash map[string]interface{}, key string, value string) {
+	if value != "" {
+		hash[key] = value
+	}
+}
+
+/* scalarStringAt returns the text of the scalar at key, or "" when it is not set */
+func scalarStringAt(hash map[string]interface{}, key string) string {
+	value, ok := hash[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return scalarString(value)
+}
@@ -0,0 +1,127 @@
+package restapi
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* The item of a user holding its password, which restapi_midpoint_user leaves alone */
+const midpointPasswordPath = "credentials/password"
+
+func resourceMidpointUserPassword() *schema.Resource {
+	return &schema.Resource{
+		Create:      resourceMidpointUserPasswordUpdate,
+		Read:        resourceMidpointUserPasswordRead,
+		Update:      resourceMidpointUserPasswordUpdate,
+		Delete:      resourceMidpointUserPasswordDelete,
+		Description: "Sets the password of a midPoint user with a credentials itemDelta. The password is managed apart from restapi_midpoint_user, which has no attribute for credentials, so rotating it does not show up as a change of the user. midPoint only returns passwords encrypted, so the password is only sent when it changes in the configuration. Destroying the resource leaves the password of the user as it is.",
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `/users`. The API path of the users on the midPoint server.",
+				Optional:    true,
+				Default:     "/users",
+				ForceNew:    true,
+			},
+			"user_oid": {
+				Type:        schema.TypeString,
+				Description: "The oid of the user whose password is set.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Description: "The password of the user, sent as the clear value midPoint encrypts or hashes according to its security policy.",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"force_change": {
+				Type:        schema.TypeBool,
+				Description: "Whether the user must change the password at the next login.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the object on the server.",
+				Optional:    true,
+			},
+			"last_changed": {
+				Type:        schema.TypeString,
+				Description: "When the password was last set, by this resource or otherwise, as midPoint recorded it in the metadata of the password.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* midpointUserPasswordObject returns the APIObject of the user whose password the resource sets */
+func midpointUserPasswordObject(d *schema.ResourceData, meta interface{}) (*APIObject, error) {
+	return NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:  d.Get("path").(string),
+		id:    d.Get("user_oid").(string),
+		debug: d.Get("debug").(bool),
+	})
+}
+
+func resourceMidpointUserPasswordUpdate(d *schema.ResourceData, meta interface{}) error {
+	obj, err := midpointUserPasswordObject(d, meta)
+	if err != nil {
+		return err
+	}
+
+	itemDeltas := make([]interface{}, 0)
+	if d.IsNewResource() || d.HasChange("password") {
+		itemDeltas = append(itemDeltas, obj.buildItemDelta("replace", midpointPasswordPath+"/value", map[string]interface{}{"clearValue": d.Get("password").(string)}))
+	}
+	if d.IsNewResource() || d.HasChange("force_change") {
+		itemDeltas = append(itemDeltas, obj.buildItemDelta("replace", midpointPasswordPath+"/forceChange", d.Get("force_change").(bool)))
+	}
+	if len(itemDeltas) > 0 {
+		if err := obj.sendObjectModification(itemDeltas); err != nil {
+			return err
+		}
+	}
+
+	d.SetId(d.Get("user_oid").(string))
+	return resourceMidpointUserPasswordRead(d, meta)
+}
+
+func resourceMidpointUserPasswordRead(d *schema.ResourceData, meta interface{}) error {
+	if d.Get("user_oid").(string) == "" {
+		/* Imported by the oid of the user */
+		d.Set("user_oid", d.Id())
+	}
+	obj, err := midpointUserPasswordObject(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return err
+	}
+	if obj.id == "" {
+		log.Printf("resource_midpoint_user_password.go: The user '%s' is gone. Removing from state.", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	/* midPoint only returns the password encrypted or hashed, so the one in the state is kept */
+	password, _ := GetObjectAtKey(midpointObjectOf(obj.apiData), midpointPasswordPath, false)
+	hash, _ := password.(map[string]interface{})
+	lastChanged := stringAtKey(hash, "metadata/modifyTimestamp")
+	if lastChanged == "" {
+		lastChanged = stringAtKey(hash, "metadata/createTimestamp")
+	}
+	d.Set("force_change", scalarStringAt(hash, "forceChange") == "true")
+	return d.Set("last_changed", lastChanged)
+}
+
+/* resourceMidpointUserPasswordDelete only forgets the password, which the user keeps so that it is not locked out */
+func resourceMidpointUserPasswordDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("resource_midpoint_user_password.go: Leaving the password of the user '%s' as it is on the server.", d.Id())
+	return nil
+}
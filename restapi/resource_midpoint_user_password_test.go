@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointUserPassword(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Failed to create API client: %s", err)
+	}
+	if _, err := client.sendRequest("POST", "/users", `{"user": {"oid": "user-1", "name": "jdoe"}}`); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Failed to create the user: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceMidpointUserPassword().Schema, map[string]interface{}{
+		"user_oid":     "user-1",
+		"password":     "s3cret",
+		"force_change": true,
+	})
+	if err := resourceMidpointUserPasswordUpdate(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Create failed: %s", err)
+	}
+	user, _ := client.sendRequest("GET", "/users/user-1", "")
+	for _, expected := range []string{`"clearValue":"s3cret"`, `"forceChange":true`, `"name":"jdoe"`} {
+		if !strings.Contains(user, expected) {
+			t.Fatalf("resource_midpoint_user_password_test.go: Expected %s in the user, got %s", expected, user)
+		}
+	}
+	if d.Id() != "user-1" || d.Get("force_change") != true {
+		t.Fatalf("resource_midpoint_user_password_test.go: Unexpected state after the create: %v", d.State())
+	}
+
+	/* midPoint only returns the password encrypted, which keeps the one in the state */
+	server := `{"user": {"oid": "user-1", "name": "jdoe", "credentials": {"password": {"value": {"encryptedData": {"cipherData": "abc"}}, "metadata": {"modifyTimestamp": "2024-05-01T10:00:00Z"}}}}}`
+	if _, err := client.sendRequest("PUT", "/users/user-1", server); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Put failed: %s", err)
+	}
+	if err := resourceMidpointUserPasswordRead(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Read failed: %s", err)
+	}
+	if d.Get("password") != "s3cret" || d.Get("force_change") != false || d.Get("last_changed") != "2024-05-01T10:00:00Z" {
+		t.Fatalf("resource_midpoint_user_password_test.go: Unexpected state after the read: %v", d.State())
+	}
+
+	/* Deleting the resource leaves the password on the user */
+	if err := resourceMidpointUserPasswordDelete(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Delete failed: %s", err)
+	}
+	if user, _ := client.sendRequest("GET", "/users/user-1", ""); !strings.Contains(user, `"cipherData":"abc"`) {
+		t.Fatalf("resource_midpoint_user_password_test.go: Expected the password to be kept, got %s", user)
+	}
+
+	/* A password imported by the oid of its user */
+	imported := schema.TestResourceDataRaw(t, resourceMidpointUserPassword().Schema, map[string]interface{}{})
+	imported.SetId("user-1")
+	if err := resourceMidpointUserPasswordRead(imported, client); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Import failed: %s", err)
+	}
+	if imported.Get("user_oid") != "user-1" {
+		t.Fatalf("resource_midpoint_user_password_test.go: Unexpected state after the import: %v", imported.State())
+	}
+
+	if _, err := client.sendRequest("DELETE", "/users/user-1", ""); err != nil {
+		t.Fatalf("resource_midpoint_user_password_test.go: Failed to delete the user: %s", err)
+	}
+	if err := resourceMidpointUserPasswordRead(d, client); err != nil || d.Id() != "" {
+		t.Fatalf("resource_midpoint_user_password_test.go: Expected the password of a deleted user to be removed from state, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package restapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMidpointUser(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "user/oid", mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("resource_midpoint_user_test.go: Failed to create API client: %s", err)
+	}
+
+	config := map[string]interface{}{
+		"name":       "jsmith",
+		"given_name": "John",
+		"email":      "jsmith@example.com",
+		"activation": []interface{}{map[string]interface{}{"administrative_status": "enabled"}},
+		"assignment": []interface{}{
+			map[string]interface{}{"target_oid": "role-1"},
+			map[string]interface{}{"target_oid": "org-1", "target_type": "OrgType", "relation": "manager"},
+		},
+		"extension":           map[string]interface{}{"costCenter": "CC-1"},
+		"extension_namespace": "http://example.com/xml/ns/ext",
+	}
+	d := schema.TestResourceDataRaw(t, resourceMidpointUser().Schema, config)
+	if err := midpointUser.create(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_test.go: Create failed: %s", err)
+	}
+	oid := d.Id()
+	if len(oid) != 36 || d.Get("oid").(string) != oid {
+		t.Fatalf("resource_midpoint_user_test.go: Expected a generated oid, got '%s'", oid)
+	}
+
+	created, _ := client.sendRequest("GET", "/users/"+oid, "")
+	for _, expected := range []string{`"emailAddress":"jsmith@example.com"`, `"targetRef":{"oid":"org-1","relation":"manager","type":"OrgType"}`, `"extension":{"@ns":"http://example.com/xml/ns/ext","costCenter":"CC-1"}`} {
+		if !strings.Contains(created, expected) {
+			t.Fatalf("resource_midpoint_user_test.go: Expected %s in the created user %s", expected, created)
+		}
+	}
+
+	/* midPoint adds items of its own and writes some values in another form */
+	server := `{"user": {"oid": "` + oid + `", "name": {"orig": "jsmith", "norm": "jsmith"}, "givenName": "John", "emailAddress": "jsmith@example.com",
+		"activation": {"administrativeStatus": "enabled", "effectiveStatus": "enabled"},
+		"assignment": [
+			{"@id": 1, "targetRef": {"oid": "role-1", "type": "c:RoleType", "relation": "org:default"}, "metadata": {"createTimestamp": "2026-01-01T00:00:00Z"}},
+			{"@id": 2, "targetRef": {"oid": "org-1", "type": "c:OrgType", "relation": "org:manager"}},
+			{"@id": 3, "policyRule": {"name": "sod"}}
+		],
+		"extension": {"@ns": "http://example.com/xml/ns/ext", "costCenter": "CC-1"},
+		"roleMembershipRef": [{"oid": "role-1"}]}}`
+	if _, err := client.sendRequest("PUT", "/users/"+oid, server); err != nil {
+		t.Fatalf("resource_midpoint_user_test.go: Put failed: %s", err)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceMidpointUser().Schema, map[string]interface{}{})
+	d.SetId(oid)
+	if err := midpointUser.read(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_test.go: Read failed: %s", err)
+	}
+	if d.Get("name") != "jsmith" || d.Get("activation.0.administrative_status") != "enabled" || d.Get("extension.costCenter") != "CC-1" {
+		t.Fatalf("resource_midpoint_user_test.go: Unexpected attributes after the read: %v", d.State())
+	}
+	expectedAssignments := []interface{}{
+		map[string]interface{}{"target_oid": "role-1", "target_type": "RoleType", "relation": ""},
+		map[string]interface{}{"target_oid": "org-1", "target_type": "OrgType", "relation": "manager"},
+	}
+	if !reflect.DeepEqual(d.Get("assignment"), expectedAssignments) {
+		t.Fatalf("resource_midpoint_user_test.go: Unexpected assignments %v", d.Get("assignment"))
+	}
+
+	/* Only the changed items are sent */
+	config["email"] = "john.smith@example.com"
+	config["assignment"] = []interface{}{map[string]interface{}{"target_oid": "role-1"}}
+	d = schema.TestResourceDataRaw(t, resourceMidpointUser().Schema, config)
+	d.SetId(oid)
+	if err := midpointUser.update(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_test.go: Update failed: %s", err)
+	}
+	deltas := strings.Join(expandStringList(d.Get("last_applied_deltas").([]interface{})), "\n")
+	for _, expected := range []string{`"modificationType":"replace","path":"emailAddress","value":"john.smith@example.com"`, `"modificationType":"delete","path":"assignment"`} {
+		if !strings.Contains(deltas, expected) {
+			t.Fatalf("resource_midpoint_user_test.go: Expected %s in the deltas:\n%s", expected, deltas)
+		}
+	}
+	if strings.Contains(deltas, "role-1") || strings.Contains(deltas, "name") || strings.Contains(deltas, "activation") || strings.Contains(deltas, "extension") {
+		t.Fatalf("resource_midpoint_user_test.go: Expected only the email and the removed assignment to change:\n%s", deltas)
+	}
+
+	updated, _ := client.sendRequest("GET", "/users/"+oid, "")
+	for _, expected := range []string{`"policyRule":{"name":"sod"}`, `"roleMembershipRef"`, `"effectiveStatus":"enabled"`} {
+		if !strings.Contains(updated, expected) {
+			t.Fatalf("resource_midpoint_user_test.go: Expected %s to be left untouched in %s", expected, updated)
+		}
+	}
+
+	if err := midpointUser.delete(d, client); err != nil {
+		t.Fatalf("resource_midpoint_user_test.go: Delete failed: %s", err)
+	}
+	if err := midpointUser.read(d, client); err != nil || d.Id() != "" {
+		t.Fatalf("resource_midpoint_user_test.go: Expected the user to be gone, got '%s' (%v)", d.Id(), err)
+	}
+}
@@ -0,0 +1,362 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceRestAPIObjectSet() *schema.Resource {
+	return &schema.Resource{
+		Create:      resourceObjectSetCreate,
+		Read:        resourceObjectSetRead,
+		Update:      resourceObjectSetUpdate,
+		Delete:      resourceObjectSetDelete,
+		Description: "Manages many similar midPoint objects at one path, such as a catalog of business roles, as one resource: objects missing on the server are created, changed ones are patched with midPoint deltas and removed ones are deleted. A refresh reads every object of the set with a few searches instead of one request per object, which is far faster than thousands of `restapi_object` resources. Objects without an oid in their data are created with an oid generated by the provider.",
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The API path of the objects on top of the base URL set in the provider, such as `/roles`. Objects are searched at `path/search`.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"objects": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The objects of the set as JSON, such as `{\"role\": {\"name\": \"Finance\"}}`, by a key that identifies them in the configuration. Only the items an object sets are compared with the server; an object that drifted shows up as a change of its entry.",
+				Required:    true,
+			},
+			"ignore_changes_to": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateIgnorePattern},
+				Description: "Patterns of fields of the objects that are not compared with the server, like `ignore_changes_to` of `restapi_object`.",
+				Optional:    true,
+			},
+			"page_size": {
+				Type:         schema.TypeInt,
+				Description:  "The number of objects read per search request. Default: 100",
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while working with the objects on the server.",
+				Optional:    true,
+			},
+			"ids": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The oid of every object by its key in `objects`.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/* objectSetMember returns the APIObject of the object of the set with the oid */
+func objectSetMember(d *schema.ResourceData, meta interface{}, oid string, data string) (*APIObject, error) {
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:           d.Get("path").(string),
+		id:             oid,
+		data:           data,
+		updateStrategy: updateStrategyMidpointPatch,
+		normalize:      expandNormalizeRules(nil),
+		debug:          d.Get("debug").(bool),
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj.ignoreChangesTo = append(getIgnoreList(d), midpointModeIgnoreList(meta.(*APIClient).midpointMode)...)
+	return obj, nil
+}
+
+/* createObjectSetMember creates the object of an entry of the set, returning its oid */
+func createObjectSetMember(d *schema.ResourceData, meta interface{}, key string, data string) (string, error) {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+		return "", fmt.Errorf("the object '%s' is not a JSON object: %v", key, err)
+	}
+	oid := stringAtKey(midpointObjectOf(parsed), "oid")
+
+	obj, err := objectSetMember(d, meta, oid, data)
+	if err != nil {
+		return "", fmt.Errorf("the object '%s' is invalid: %v", key, err)
+	}
+	if err := obj.useGeneratedOID(oid); err != nil {
+		return "", err
+	}
+	if err := obj.createObject(); err != nil {
+		return "", fmt.Errorf("failed to create the object '%s': %v", key, err)
+	}
+	return obj.id, nil
+}
+
+/*
+applyObjectSet creates, updates and deletes the objects of the set so the
+
+	server has the objects in desired, starting from the entries in applied.
+	applied and ids are kept up to date as changes are made, so a failure
+	records the objects that were changed before it. With delta_list_path set
+	on the provider, the updates are sent in one request after the others,
+	and only the objects whose delta succeeded are recorded as applied.
+*/
+func applyObjectSet(d *schema.ResourceData, meta interface{}, applied map[string]interface{}, desired map[string]interface{}, ids map[string]interface{}) error {
+	deltaListPath := meta.(*APIClient).deltaListPath
+	batch := make([]objectDelta, 0)
+	batchKeys := make(map[string]string)
+
+	for _, key := range sortedKeys(applied) {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		if oid, _ := ids[key].(string); oid != "" {
+			obj, err := objectSetMember(d, meta, oid, "")
+			if err != nil {
+				return err
+			}
+			if err := obj.deleteObject(); err != nil && !strings.Contains(err.Error(), "unexpected response code '404'") {
+				return fmt.Errorf("failed to delete the object '%s': %v", key, err)
+			}
+		}
+		delete(applied, key)
+		delete(ids, key)
+	}
+
+	for _, key := range sortedKeys(desired) {
+		data := desired[key].(string)
+		oid, _ := ids[key].(string)
+		switch {
+		case oid == "":
+			created, err := createObjectSetMember(d, meta, key, data)
+			if err != nil {
+				return err
+			}
+			ids[key] = created
+		case applied[key] != data:
+			obj, err := objectSetMember(d, meta, oid, data)
+			if err != nil {
+				return fmt.Errorf("the object '%s' is invalid: %v", key, err)
+			}
+			if deltaListPath != "" {
+				if obj.objectType == "" {
+					return fmt.Errorf("cannot send the object '%s' to delta_list_path: no midPoint object type is known for '%s'", key, d.Get("path").(string))
+				}
+				if err := obj.readObject(); err != nil {
+					return fmt.Errorf("failed to read the object '%s' for its update: %v", key, err)
+				}
+				batch = append(batch, objectDelta{obj: obj, objectType: obj.objectType})
+				batchKeys[oid] = key
+				continue
+			}
+			if err := obj.updateObject(); err != nil {
+				return fmt.Errorf("failed to update the object '%s': %v", key, err)
+			}
+		default:
+			continue
+		}
+		applied[key] = data
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+	failures, err := sendObjectDeltaList(meta.(*APIClient), deltaListPath, batch)
+	if err != nil {
+		return fmt.Errorf("failed to update the objects with '%s': %v", deltaListPath, err)
+	}
+	messages := make([]string, 0)
+	for _, delta := range batch {
+		key := batchKeys[delta.obj.id]
+		if message, ok := failures[delta.obj.id]; ok {
+			messages = append(messages, fmt.Sprintf("failed to update the object '%s': %s", key, message))
+			continue
+		}
+		applied[key] = desired[key]
+	}
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+func resourceObjectSetCreate(d *schema.ResourceData, meta interface{}) error {
+	applied := make(map[string]interface{})
+	ids := make(map[string]interface{})
+	err := applyObjectSet(d, meta, applied, d.Get("objects").(map[string]interface{}), ids)
+
+	/* The objects created before a failure are kept in state */
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("objects", applied)
+	d.Set("ids", ids)
+	return err
+}
+
+func resourceObjectSetUpdate(d *schema.ResourceData, meta interface{}) error {
+	old, desired := d.GetChange("objects")
+	applied := make(map[string]interface{})
+	for key, value := range old.(map[string]interface{}) {
+		applied[key] = value
+	}
+	ids := make(map[string]interface{})
+	for key, value := range d.Get("ids").(map[string]interface{}) {
+		ids[key] = value
+	}
+
+	err := applyObjectSet(d, meta, applied, desired.(map[string]interface{}), ids)
+	if err != nil {
+		d.Partial(true)
+	}
+	d.Set("objects", applied)
+	d.Set("ids", ids)
+	return err
+}
+
+func resourceObjectSetRead(d *schema.ResourceData, meta interface{}) error {
+	ids := d.Get("ids").(map[string]interface{})
+	objects := d.Get("objects").(map[string]interface{})
+	debug := d.Get("debug").(bool)
+
+	found, err := searchObjectSetMembers(d, meta, ids)
+	if err != nil {
+		return err
+	}
+
+	ignoreList := append(getIgnoreList(d), midpointModeIgnoreList(meta.(*APIClient).midpointMode)...)
+	rules := expandNormalizeRules(nil)
+	for key, oid := range ids {
+		actual, ok := found[oid.(string)]
+		if !ok {
+			/* Deleted on the server; it is created again */
+			log.Printf("resource_object_set.go: The object '%s' (%s) was not found, removing it from state", key, oid)
+			delete(objects, key)
+			delete(ids, key)
+			continue
+		}
+
+		var recorded map[string]interface{}
+		if err := unmarshalJSON([]byte(objects[key].(string)), &recorded); err != nil {
+			continue
+		}
+		configured := midpointObjectOf(recorded)
+		current, _ := configuredPart(configured, actual).(map[string]interface{})
+		if _, changed := getDelta(configured, current, ignoreList, rules); changed {
+			if debug {
+				log.Printf("resource_object_set.go: The object '%s' (%s) drifted on the server", key, oid)
+			}
+			encoded, err := canonicalJSON(withWrapperOf(recorded, current))
+			if err != nil {
+				return err
+			}
+			objects[key] = encoded
+		}
+	}
+
+	d.Set("objects", objects)
+	return d.Set("ids", ids)
+}
+
+func resourceObjectSetDelete(d *schema.ResourceData, meta interface{}) error {
+	return applyObjectSet(d, meta, d.Get("objects").(map[string]interface{}), map[string]interface{}{}, d.Get("ids").(map[string]interface{}))
+}
+
+/*
+searchObjectSetMembers reads the objects with the oids in ids with inOid
+
+	searches of page_size oids each, returning them unwrapped by oid.
+*/
+func searchObjectSetMembers(d *schema.ResourceData, meta interface{}, ids map[string]interface{}) (map[string]map[string]interface{}, error) {
+	oids := make([]string, 0, len(ids))
+	for _, oid := range ids {
+		oids = append(oids, oid.(string))
+	}
+	sort.Strings(oids)
+
+	path := d.Get("path").(string)
+	obj, err := NewAPIObject(meta.(*APIClient), &apiObjectOpts{
+		path:       path,
+		searchPath: path + "/search",
+		debug:      d.Get("debug").(bool),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]map[string]interface{}, len(oids))
+	pageSize := d.Get("page_size").(int)
+	for start := 0; start < len(oids); start += pageSize {
+		end := start + pageSize
+		if end > len(oids) {
+			end = len(oids)
+		}
+		search, _ := json.Marshal(map[string]interface{}{"query": map[string]interface{}{
+			"filter": map[string]interface{}{"inOid": map[string]interface{}{"value": oids[start:end]}},
+			"paging": map[string]interface{}{"maxSize": end - start},
+		}})
+		records, err := obj.listObjects("POST", "", "object/object", string(search))
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if hash, ok := record.(map[string]interface{}); ok {
+				object := midpointObjectOf(hash)
+				found[stringAtKey(object, "oid")] = object
+			}
+		}
+	}
+	return found, nil
+}
+
+/*
+configuredPart returns the parts of actual that recorded sets: the keys of
+
+	maps that recorded has, recursively, and the elements of lists of the
+	same length. Lists of other lengths are returned whole.
+*/
+func configuredPart(recorded interface{}, actual interface{}) interface{} {
+	switch r := recorded.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+		part := make(map[string]interface{}, len(r))
+		for key, value := range r {
+			if actualValue, ok := a[key]; ok {
+				part[key] = configuredPart(value, actualValue)
+			}
+		}
+		return part
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(r) {
+			return actual
+		}
+		part := make([]interface{}, len(a))
+		for i := range a {
+			part[i] = configuredPart(r[i], a[i])
+		}
+		return part
+	}
+	return actual
+}
+
+/* withWrapperOf wraps object in the key naming the type of recorded, if recorded has one */
+func withWrapperOf(recorded map[string]interface{}, object map[string]interface{}) map[string]interface{} {
+	if len(recorded) == 1 {
+		for wrapper, inner := range recorded {
+			if _, ok := inner.(map[string]interface{}); ok {
+				return map[string]interface{}{wrapper: object}
+			}
+		}
+	}
+	return object
+}
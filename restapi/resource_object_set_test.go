@@ -0,0 +1,151 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestObjectSet(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", mode: clientModeMemory, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("resource_object_set_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPIObjectSet().Schema, map[string]interface{}{
+		"path":      "/roles",
+		"page_size": 1,
+		"objects": map[string]interface{}{
+			"finance": `{"oid": "role-1", "name": "Finance"}`,
+			"sales":   `{"name": "Sales", "description": "Sales team"}`,
+		},
+	})
+	if err := resourceObjectSetCreate(d, client); err != nil {
+		t.Fatalf("resource_object_set_test.go: Create failed: %s", err)
+	}
+	ids := d.Get("ids").(map[string]interface{})
+	if ids["finance"] != "role-1" || ids["sales"] == "" || ids["sales"] == nil {
+		t.Fatalf("resource_object_set_test.go: Expected the configured and a generated oid, got %v", ids)
+	}
+	salesOID := ids["sales"].(string)
+
+	/* Reading without changes keeps the configured objects */
+	if err := resourceObjectSetRead(d, client); err != nil {
+		t.Fatalf("resource_object_set_test.go: Read failed: %s", err)
+	}
+	if d.Get("objects.sales") != `{"name": "Sales", "description": "Sales team"}` {
+		t.Fatalf("resource_object_set_test.go: Expected no drift, got %v", d.Get("objects"))
+	}
+
+	/* A change on the server shows up as drift of its entry */
+	if _, err := client.sendRequest("PUT", "/roles/"+salesOID, `{"oid": "`+salesOID+`", "name": "Sales", "description": "Changed by hand"}`); err != nil {
+		t.Fatalf("resource_object_set_test.go: Failed to change the object: %s", err)
+	}
+	if err := resourceObjectSetRead(d, client); err != nil {
+		t.Fatalf("resource_object_set_test.go: Read failed: %s", err)
+	}
+	if !strings.Contains(d.Get("objects.sales").(string), "Changed by hand") || d.Get("objects.finance") != `{"oid": "role-1", "name": "Finance"}` {
+		t.Fatalf("resource_object_set_test.go: Expected only the changed object to drift, got %v", d.Get("objects"))
+	}
+
+	/* A deleted object is removed from state */
+	if _, err := client.sendRequest("DELETE", "/roles/role-1", ""); err != nil {
+		t.Fatalf("resource_object_set_test.go: Failed to delete the object: %s", err)
+	}
+	if err := resourceObjectSetRead(d, client); err != nil {
+		t.Fatalf("resource_object_set_test.go: Read failed: %s", err)
+	}
+	if _, ok := d.Get("ids").(map[string]interface{})["finance"]; ok {
+		t.Fatalf("resource_object_set_test.go: Expected the deleted object to be removed from state, got %v", d.Get("ids"))
+	}
+
+	/* Apply the configuration: recreate, patch and delete */
+	applied := d.Get("objects").(map[string]interface{})
+	ids = d.Get("ids").(map[string]interface{})
+	desired := map[string]interface{}{
+		"finance": `{"oid": "role-1", "name": "Finance"}`,
+		"sales":   `{"name": "Sales", "description": "Sales team"}`,
+	}
+	if err := applyObjectSet(d, client, applied, desired, ids); err != nil {
+		t.Fatalf("resource_object_set_test.go: Apply failed: %s", err)
+	}
+	if ids["finance"] != "role-1" || ids["sales"] != salesOID {
+		t.Fatalf("resource_object_set_test.go: Expected the oids to be kept, got %v", ids)
+	}
+	found, err := searchObjectSetMembers(d, client, ids)
+	if err != nil || len(found) != 2 || found[salesOID]["description"] != "Sales team" {
+		t.Fatalf("resource_object_set_test.go: Expected both objects as configured, got %v, %v", err, found)
+	}
+
+	if err := applyObjectSet(d, client, applied, map[string]interface{}{"sales": desired["sales"]}, ids); err != nil {
+		t.Fatalf("resource_object_set_test.go: Apply failed: %s", err)
+	}
+	if found, _ := searchObjectSetMembers(d, client, map[string]interface{}{"finance": "role-1"}); len(found) != 0 || len(ids) != 1 {
+		t.Fatalf("resource_object_set_test.go: Expected the removed object to be deleted, got %v and %v", found, ids)
+	}
+}
+
+func TestObjectSetDeltaList(t *testing.T) {
+	var requests []string
+	var sent map[string]interface{}
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET":
+			oid := strings.TrimPrefix(r.URL.Path, "/roles/")
+			w.Write([]byte(fmt.Sprintf(`{"role": {"oid": "%s", "name": "old %s"}}`, oid, oid)))
+		case r.URL.Path == "/rpc/executeChanges":
+			json.NewDecoder(r.Body).Decode(&sent)
+			/* role-3 has no result, so nothing tells it was applied */
+			w.Write([]byte(`{"objectDeltaOperationList": {"deltaOperation": [
+				{"objectDelta": {"oid": "role-1"}, "executionResult": {"status": "success"}},
+				{"objectDelta": {"oid": "role-2"}, "executionResult": {"status": "fatal_error", "message": "denied"}}
+			]}}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid", deltaListPath: "/rpc/executeChanges"})
+	if err != nil {
+		t.Fatalf("resource_object_set_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPIObjectSet().Schema, map[string]interface{}{"path": "/roles"})
+
+	ids := map[string]interface{}{"finance": "role-1", "sales": "role-2", "hr": "role-3"}
+	applied := map[string]interface{}{
+		"finance": `{"role": {"oid": "role-1", "name": "old role-1"}}`,
+		"sales":   `{"role": {"oid": "role-2", "name": "old role-2"}}`,
+		"hr":      `{"role": {"oid": "role-3", "name": "old role-3"}}`,
+	}
+	desired := map[string]interface{}{
+		"finance": `{"role": {"oid": "role-1", "name": "Finance"}}`,
+		"sales":   `{"role": {"oid": "role-2", "name": "Sales"}}`,
+		"hr":      `{"role": {"oid": "role-3", "name": "HR"}}`,
+	}
+	err = applyObjectSet(d, client, applied, desired, ids)
+	if err == nil || !strings.Contains(err.Error(), "'sales': fatal_error: denied") || !strings.Contains(err.Error(), "'hr': no result in the response") {
+		t.Fatalf("resource_object_set_test.go: Expected the failed and the missing delta to be reported, got %v", err)
+	}
+
+	expected := []string{"GET /roles/role-1", "GET /roles/role-3", "GET /roles/role-2", "POST /rpc/executeChanges"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("resource_object_set_test.go: Expected all updates in one request: expected %v, got %v", expected, requests)
+	}
+	deltas := sent["objectDeltaList"].(map[string]interface{})["delta"].([]interface{})
+	if len(deltas) != 3 || deltas[0].(map[string]interface{})["objectType"] != "RoleType" {
+		t.Fatalf("resource_object_set_test.go: Unexpected object delta list %v", sent)
+	}
+
+	/* Only the object whose delta succeeded is recorded as applied */
+	if applied["finance"] != desired["finance"] || applied["sales"] == desired["sales"] || applied["hr"] == desired["hr"] {
+		t.Fatalf("resource_object_set_test.go: Expected only 'finance' to be applied, got %v", applied)
+	}
+}
@@ -0,0 +1,156 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceRawRequest() *schema.Resource {
+	return &schema.Resource{
+		Create:      resourceRawRequestCreate,
+		Read:        resourceRawRequestRead,
+		Update:      resourceRawRequestUpdate,
+		Delete:      resourceRawRequestDelete,
+		Description: "Sends a request to the API server when created, and optionally another when destroyed, for endpoints that do not map to objects, such as midPoint's RPC endpoints to clear caches or log out every session. The request is sent again when `triggers` or the request changes. Nothing is read back.",
+
+		Schema: map[string]*schema.Schema{
+			"method": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `POST`. The HTTP method of the request.",
+				Optional:    true,
+				Default:     "POST",
+				ForceNew:    true,
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The API path of the request on top of the base URL set in the provider, such as `/rpc/executeScript`.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"body": {
+				Type:        schema.TypeString,
+				Description: "The body of the request.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"expected_status": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeInt, ValidateFunc: validation.IntBetween(100, 599)},
+				Description: "The status codes the request succeeds with, such as `[200, 204]`. Not set accepts every 2xx code.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values that send the request again when they change, such as the version of the configuration the request applies.",
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"destroy_method": {
+				Type:        schema.TypeString,
+				Description: "Defaults to `POST`. The HTTP method of the request sent on destroy.",
+				Optional:    true,
+				Default:     "POST",
+			},
+			"destroy_path": {
+				Type:        schema.TypeString,
+				Description: "The API path of a request sent when the resource is destroyed. Not set sends nothing on destroy.",
+				Optional:    true,
+			},
+			"destroy_body": {
+				Type:        schema.TypeString,
+				Description: "The body of the request sent on destroy.",
+				Optional:    true,
+			},
+			"destroy_expected_status": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeInt, ValidateFunc: validation.IntBetween(100, 599)},
+				Description: "The status codes the request sent on destroy succeeds with. Not set accepts every 2xx code.",
+				Optional:    true,
+			},
+			"debug": {
+				Type:        schema.TypeBool,
+				Description: "Whether to emit verbose debug output while sending the requests.",
+				Optional:    true,
+			},
+			"status_code": {
+				Type:        schema.TypeInt,
+				Description: "The status code of the response to the request.",
+				Computed:    true,
+			},
+			"response": {
+				Type:        schema.TypeString,
+				Description: "The body of the response to the request.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+/*
+sendRawRequest sends a request of the resource, failing unless the response
+
+	has one of the expected status codes, or a 2xx code when none is expected.
+*/
+func sendRawRequest(client *APIClient, method string, path string, body string, expected []interface{}, debug bool) (string, int, error) {
+	if debug {
+		log.Printf("resource_raw_request.go: Sending %s %s: %s", method, path, body)
+	}
+	response, _, status, err := client.sendRequestReturningStatus(method, path, body, "", nil)
+	if status == 0 {
+		return "", 0, err
+	}
+
+	accepted := status >= 200 && status < 300
+	if len(expected) > 0 {
+		codes := make([]string, 0, len(expected))
+		accepted = false
+		for _, code := range expected {
+			codes = append(codes, strconv.Itoa(code.(int)))
+			accepted = accepted || code.(int) == status
+		}
+		if !accepted {
+			return response, status, fmt.Errorf("%s %s answered with the status %d instead of %s: %s", method, path, status, strings.Join(codes, ", "), response)
+		}
+	}
+	if !accepted {
+		return response, status, err
+	}
+	return response, status, nil
+}
+
+func resourceRawRequestCreate(d *schema.ResourceData, meta interface{}) error {
+	response, status, err := sendRawRequest(meta.(*APIClient), d.Get("method").(string), d.Get("path").(string), d.Get("body").(string), d.Get("expected_status").([]interface{}), d.Get("debug").(bool))
+	if err != nil {
+		return err
+	}
+	d.SetId(time.Now().UTC().Format(time.RFC3339Nano))
+	d.Set("status_code", status)
+	return d.Set("response", response)
+}
+
+func resourceRawRequestRead(d *schema.ResourceData, meta interface{}) error {
+	/* A request sent is not read back */
+	return nil
+}
+
+func resourceRawRequestUpdate(d *schema.ResourceData, meta interface{}) error {
+	/* Only the destroy request can change in place, and it is only sent on destroy */
+	return nil
+}
+
+func resourceRawRequestDelete(d *schema.ResourceData, meta interface{}) error {
+	path := d.Get("destroy_path").(string)
+	if path == "" {
+		return nil
+	}
+	_, _, err := sendRawRequest(meta.(*APIClient), d.Get("destroy_method").(string), path, d.Get("destroy_body").(string), d.Get("destroy_expected_status").([]interface{}), d.Get("debug").(bool))
+	return err
+}
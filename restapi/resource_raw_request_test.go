@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceRawRequest(t *testing.T) {
+	var requests []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		switch r.URL.Path {
+		case "/rpc/clearCaches":
+			w.Write([]byte(`{"status": "success"}`))
+		case "/rpc/logoutAll":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("resource_raw_request_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRawRequest().Schema, map[string]interface{}{
+		"path":           "/rpc/clearCaches",
+		"body":           `{"scope": "all"}`,
+		"destroy_path":   "/rpc/logoutAll",
+		"destroy_method": "DELETE",
+	})
+	if err := resourceRawRequestCreate(d, client); err != nil {
+		t.Fatalf("resource_raw_request_test.go: Create failed: %s", err)
+	}
+	if d.Id() == "" || d.Get("status_code") != 200 || d.Get("response") != `{"status": "success"}` {
+		t.Fatalf("resource_raw_request_test.go: Unexpected state after the request: %v", d.State())
+	}
+	if err := resourceRawRequestDelete(d, client); err != nil {
+		t.Fatalf("resource_raw_request_test.go: Delete failed: %s", err)
+	}
+	expected := "POST /rpc/clearCaches {\"scope\": \"all\"}\nDELETE /rpc/logoutAll "
+	if strings.Join(requests, "\n") != expected {
+		t.Fatalf("resource_raw_request_test.go: Expected the create and destroy requests, got %v", requests)
+	}
+
+	/* The expected status decides, also for other than 2xx codes */
+	for _, test := range []struct {
+		path     string
+		expected []interface{}
+		fails    bool
+	}{
+		{"/rpc/logoutAll", []interface{}{204}, true},
+		{"/rpc/logoutAll", []interface{}{202, 204}, false},
+		{"/rpc/missing", []interface{}{404}, false},
+		{"/rpc/missing", nil, true},
+	} {
+		d := schema.TestResourceDataRaw(t, resourceRawRequest().Schema, map[string]interface{}{
+			"path":            test.path,
+			"expected_status": test.expected,
+		})
+		if err := resourceRawRequestCreate(d, client); (err != nil) != test.fails {
+			t.Fatalf("resource_raw_request_test.go: Expected the request to %s with %v to fail: %t, got %v", test.path, test.expected, test.fails, err)
+		}
+	}
+}
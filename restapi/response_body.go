@@ -0,0 +1,41 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+readResponseBody reads a response body into a string without the copy a
+
+	conversion from bytes makes, which halves the memory a search returning
+	tens of thousands of objects takes. With a limit, a body of more than
+	limit bytes fails instead of being read whole.
+*/
+func readResponseBody(body io.Reader, contentLength int64, limit int64) (string, error) {
+	var buffer strings.Builder
+	if contentLength > 0 && (limit <= 0 || contentLength <= limit) {
+		buffer.Grow(int(contentLength))
+	}
+	if limit <= 0 {
+		_, err := io.Copy(&buffer, body)
+		return buffer.String(), err
+	}
+	read, err := io.Copy(&buffer, io.LimitReader(body, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if read > limit {
+		return "", fmt.Errorf("the response is larger than max_response_size (%d bytes); narrow the search, page it or raise max_response_size", limit)
+	}
+	return buffer.String(), nil
+}
+
+/* decodeJSON decodes a large response body as it is read, without copying it to bytes first. Like unmarshalJSON, it keeps numbers as json.Number */
+func decodeJSON(body string, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,43 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		objects := make([]string, 0)
+		for i := 0; i < 100; i++ {
+			objects = append(objects, fmt.Sprintf(`{"oid": "user-%d"}`, i))
+		}
+		if r.URL.Path == "/users/search" {
+			w.Write([]byte(`{"object": {"object": [` + strings.Join(objects, ",") + `]}}`))
+			return
+		}
+		w.Write([]byte(`{"oid": "user-1"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, maxResponseSize: 1000})
+	if err != nil {
+		t.Fatalf("response_body_test.go: Failed to create API client: %s", err)
+	}
+	if body, err := client.sendRequest("GET", "/users/user-1", ""); err != nil || body != `{"oid": "user-1"}` {
+		t.Fatalf("response_body_test.go: Expected the small response, got %s, %v", body, err)
+	}
+	if _, err := client.sendRequest("POST", "/users/search", "{}"); err == nil || !strings.Contains(err.Error(), "POST /users/search: the response is larger than max_response_size (1000 bytes)") {
+		t.Fatalf("response_body_test.go: Expected the large response to fail, got %v", err)
+	}
+
+	/* Unlimited */
+	client.maxResponseSize = 0
+	obj := &APIObject{apiClient: client, searchPath: "/users/search"}
+	records, err := obj.listObjects("POST", "", "object/object", "{}")
+	if err != nil || len(records) != 100 {
+		t.Fatalf("response_body_test.go: Expected all objects of the search, got %d, %v", len(records), err)
+	}
+}
@@ -0,0 +1,96 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+/*
+unwrapResponse returns the object at response_wrapper_key of a response. When
+
+	data holds the object below a single key, such as midPoint's
+	{"user": {...}}, the object is returned below the same key, so it is
+	compared with data as it is, without whatever else the envelope holds.
+*/
+func (obj *APIObject) unwrapResponse(response map[string]interface{}) (map[string]interface{}, error) {
+	value, err := GetObjectAtKey(response, obj.wrapperKey, obj.debug)
+	if err != nil {
+		return nil, fmt.Errorf("the response has no object at response_wrapper_key '%s': %v", obj.wrapperKey, err)
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the value at response_wrapper_key '%s' of the response is not an object", obj.wrapperKey)
+	}
+	if obj.debug {
+		log.Printf("response_wrapper.go: Unwrapped the response at '%s'", obj.wrapperKey)
+	}
+	if wrapper := obj.dataWrapper(); wrapper != "" {
+		return map[string]interface{}{wrapper: object}, nil
+	}
+	return object, nil
+}
+
+/* dataWrapper returns the key data holds the object below, as in {"user": {...}}, or an empty string */
+func (obj *APIObject) dataWrapper() string {
+	/* With request_wrapper_key, data is the bare object */
+	if obj.requestWrapper != "" || len(obj.data) != 1 {
+		return ""
+	}
+	for key, value := range obj.data {
+		if _, ok := value.(map[string]interface{}); ok {
+			return key
+		}
+	}
+	return ""
+}
+
+/*
+patchWrapper returns the key midPoint deltas are computed below. With
+
+	response_wrapper_key, responses are unwrapped like data, so it is the
+	key data is wrapped in. Otherwise it is guessed: the key data and the
+	object read both hold as their only key.
+*/
+func (obj *APIObject) patchWrapper() string {
+	if obj.wrapperKey != "" {
+		return obj.dataWrapper()
+	}
+	if len(obj.data) != 1 || len(obj.apiData) != 1 {
+		return ""
+	}
+	wrapper := obj.dataWrapper()
+	if _, ok := obj.apiData[wrapper].(map[string]interface{}); !ok {
+		return ""
+	}
+	return wrapper
+}
+
+/*
+wrapRequest puts an object to write below the '/'-delimited request_wrapper_key,
+
+	so {"name": "jdoe"} is sent as {"user": {"name": "jdoe"}} for "user".
+*/
+func (obj *APIObject) wrapRequest(object interface{}) interface{} {
+	if obj.requestWrapper == "" {
+		return object
+	}
+	parts := strings.Split(obj.requestWrapper, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] != "" {
+			object = map[string]interface{}{parts[i]: object}
+		}
+	}
+	return object
+}
+
+/* requestPointer returns the JSON pointer of request_wrapper_key, which JSON Patch paths start with */
+func (obj *APIObject) requestPointer() string {
+	pointer := ""
+	for _, part := range strings.Split(obj.requestWrapper, "/") {
+		if part != "" {
+			pointer += "/" + escapeJSONPointer(part)
+		}
+	}
+	return pointer
+}
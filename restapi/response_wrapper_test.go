@@ -0,0 +1,124 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResponseWrapperKey(t *testing.T) {
+	obj := &APIObject{wrapperKey: "result/items/0", data: map[string]interface{}{"name": "jsmith"}}
+	response := map[string]interface{}{"result": map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "jsmith"}}, "count": 1}}
+	if unwrapped, err := obj.unwrapResponse(response); err != nil || len(unwrapped) != 1 || unwrapped["name"] != "jsmith" {
+		t.Fatalf("response_wrapper_test.go: Expected the first item of the results, got %v, %v", unwrapped, err)
+	}
+	if _, err := obj.unwrapResponse(map[string]interface{}{"error": "not found"}); err == nil {
+		t.Fatal("response_wrapper_test.go: Expected a response without the wrapper key to fail")
+	}
+
+	/* The envelope has more than one key, so the wrapper cannot be guessed */
+	var patches []string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"@ns": "http://prism.evolveum.com/xml/ns/public/types-3", "object": {"oid": "user-1", "name": "jsmith", "fullName": "J Smith"}}`))
+		case "PATCH":
+			body, _ := io.ReadAll(r.Body)
+			patches = append(patches, string(body))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", updateMethod: "PATCH"})
+	if err != nil {
+		t.Fatalf("response_wrapper_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":                 "/users",
+		"data":                 `{"user": {"oid": "user-1", "name": "jsmith", "fullName": "John Smith"}}`,
+		"response_wrapper_key": "object",
+	})
+	d.SetId("user-1")
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("response_wrapper_test.go: Read failed: %s", err)
+	}
+	if changed := d.Get("changed_keys").([]interface{}); len(changed) != 1 || changed[0] != "user.fullName" {
+		t.Fatalf("response_wrapper_test.go: Expected only fullName to differ below the user key, got %v", changed)
+	}
+	d.Set("data", `{"user": {"oid": "user-1", "name": "jsmith", "fullName": "John Smith"}}`)
+	if err := resourceRestAPIUpdate(d, client); err != nil {
+		t.Fatalf("response_wrapper_test.go: Update failed: %s", err)
+	}
+	if len(patches) != 1 || !strings.Contains(patches[0], `"path":"fullName"`) {
+		t.Fatalf("response_wrapper_test.go: Expected fullName to be patched below the wrapper, got %v", patches)
+	}
+}
+
+func TestRequestWrapperKey(t *testing.T) {
+	var writes []string
+	stored := ""
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"@ns": "http://prism.evolveum.com/xml/ns/public/types-3", "user": ` + stored + `}`))
+		default:
+			body, _ := io.ReadAll(r.Body)
+			writes = append(writes, r.Method+" "+string(body))
+			if r.Method != "PATCH" {
+				stored = `{"oid": "user-1", "name": "jdoe"}`
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("response_wrapper_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(strategy string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":                "/users",
+			"data":                `{"oid": "user-1", "name": "jdoe"}`,
+			"request_wrapper_key": "user",
+			"update_strategy":     strategy,
+		})
+	}
+
+	d := newResourceData(updateStrategyPut)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("response_wrapper_test.go: Create failed: %s", err)
+	}
+	if err := resourceRestAPIRead(d, client); err != nil || d.Get("data") != `{"name":"jdoe","oid":"user-1"}` || len(d.Get("changed_keys").([]interface{})) != 0 {
+		t.Fatalf("response_wrapper_test.go: Expected the bare object in state without changes, got %v, '%s' and %v", err, d.Get("data"), d.Get("changed_keys"))
+	}
+	d.Set("data", `{"oid": "user-1", "name": "jdoe", "fullName": "John Doe"}`)
+	if err := resourceRestAPIUpdate(d, client); err != nil {
+		t.Fatalf("response_wrapper_test.go: Update failed: %s", err)
+	}
+
+	d = newResourceData(updateStrategyJSONPatch)
+	d.SetId("user-1")
+	d.Set("data", `{"oid": "user-1", "name": "jdoe", "fullName": "John Doe"}`)
+	if err := resourceRestAPIUpdate(d, client); err != nil {
+		t.Fatalf("response_wrapper_test.go: Update failed: %s", err)
+	}
+
+	expected := []string{
+		`POST {"user":{"name":"jdoe","oid":"user-1"}}`,
+		`PUT {"user":{"fullName":"John Doe","name":"jdoe","oid":"user-1"}}`,
+		`PATCH [{"op":"add","path":"/user/fullName","value":"John Doe"}]`,
+	}
+	if strings.Join(writes, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("response_wrapper_test.go: Expected the writes wrapped in the user key, got %v", writes)
+	}
+}
@@ -0,0 +1,67 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+/* The statuses of midPoint operation results that warn_on_partial_error reports as warnings */
+var warningOperationResults = []string{"partial_error", "handled_error", "warning"}
+
+/* resultWarnings collects the operation results reported as warnings by the requests of an object */
+type resultWarnings struct {
+	lock     sync.Mutex
+	warnings []string
+}
+
+func (w *resultWarnings) add(warning string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.warnings = append(w.warnings, warning)
+}
+
+/* list returns the warnings collected so far */
+func (w *resultWarnings) list() []string {
+	if w == nil {
+		return []string{}
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]string{}, w.warnings...)
+}
+
+/*
+withResultWarnings returns a copy of the client that reports midPoint operation
+
+	results with a partial_error, handled_error or warning status as warnings
+	collected in the returned resultWarnings, instead of failing the request.
+	midPoint sends them with codes such as 240 and 250, or as errors.
+*/
+func (client *APIClient) withResultWarnings() (*APIClient, *resultWarnings) {
+	warningClient := *client
+	warningClient.resultWarnings = &resultWarnings{}
+	return &warningClient, warningClient.resultWarnings
+}
+
+/*
+resultAsWarning checks the response of a request for an operation result to
+
+	report as a warning, returning the error of the request, or nil when it
+	failed only with such a result.
+*/
+func (client *APIClient) resultAsWarning(method string, path string, body string, status int, err error) error {
+	if client.resultWarnings == nil || status == 0 {
+		return err
+	}
+	result := operationResultOf(body)
+	resultStatus := scalarStringAt(result, "status")
+	if !containsString(warningOperationResults, resultStatus) {
+		return err
+	}
+
+	warning := fmt.Sprintf("%s %s returned %d: %s", method, path, status, describeErrorBody(body))
+	log.Printf("[WARN] result_warnings.go: %s", warning)
+	client.resultWarnings.add(warning)
+	return nil
+}
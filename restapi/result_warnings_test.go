@@ -0,0 +1,66 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWarnOnPartialError(t *testing.T) {
+	partialError := `{"object": {"operation": "executeChanges", "status": "partial_error", "message": "Could not provision the account on LDAP",
+		"partialResults": [{"operation": "provisioning", "status": "partial_error", "message": "Connection refused"}]}}`
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"user": {"oid": "user-1", "name": "jdoe"}}`))
+		case "POST":
+			/* The user was created, its account was not */
+			w.WriteHeader(250)
+			w.Write([]byte(partialError))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(partialError))
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", updateMethod: "PUT"})
+	if err != nil {
+		t.Fatalf("result_warnings_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(warn bool) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":                  "/users",
+			"data":                  `{"user": {"oid": "user-1", "name": "jdoe"}}`,
+			"warn_on_partial_error": warn,
+		})
+	}
+
+	d := newResourceData(true)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("result_warnings_test.go: Create failed: %s", err)
+	}
+	warnings := d.Get("result_warnings").([]interface{})
+	if len(warnings) != 1 || !strings.Contains(warnings[0].(string), "POST /users returned 250: executeChanges: partial_error: Could not provision") || !strings.Contains(warnings[0].(string), "Connection refused") {
+		t.Fatalf("result_warnings_test.go: Expected the partial error as a warning, got %v", warnings)
+	}
+
+	d.Set("data", `{"user": {"oid": "user-1", "name": "jdoe", "fullName": "John Doe"}}`)
+	if err := resourceRestAPIUpdate(d, client); err != nil {
+		t.Fatalf("result_warnings_test.go: Expected the partial error of the update to be a warning, got %s", err)
+	}
+	if warnings := d.Get("result_warnings").([]interface{}); len(warnings) != 1 || !strings.Contains(warnings[0].(string), "PUT /users/user-1 returned 500") {
+		t.Fatalf("result_warnings_test.go: Expected only the warning of the update, got %v", warnings)
+	}
+
+	/* Without warn_on_partial_error, the update fails */
+	d = newResourceData(false)
+	d.SetId("user-1")
+	d.Set("data", `{"user": {"oid": "user-1", "name": "jdoe", "fullName": "John Doe"}}`)
+	if err := resourceRestAPIUpdate(d, client); err == nil || !strings.Contains(err.Error(), "unexpected response code '500'") {
+		t.Fatalf("result_warnings_test.go: Expected the update to fail, got %v", err)
+	}
+}
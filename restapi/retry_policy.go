@@ -0,0 +1,122 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/*
+How a resource retries failed requests instead of the provider's
+
+	throttle_retries, concurrency_retries and update_conflict_retries.
+*/
+type retryPolicy struct {
+	maxRetries   int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+
+	/* Set for the create or update in progress; no retry waits past it */
+	deadline time.Time
+}
+
+func retrySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Retries of this object's requests, replacing the provider's `throttle_retries`, `concurrency_retries` and `update_conflict_retries`, e.g. many long retries for a heavyweight import endpoint or none for objects that should fail fast. Retries wait with exponential backoff, but never past the create or update timeout: a retry that would is not made and the last error is returned.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"max_retries": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "How many times a throttled request, a request failing with a midPoint concurrency error or an update failing with `409 Conflict` is retried.",
+				},
+				"initial_delay": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Seconds to wait before the first retry, doubling with every attempt. A `Retry-After` header of a throttled request takes precedence. Default: 1",
+				},
+				"max_delay": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      60,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "The longest wait between two attempts in seconds. Default: 60",
+				},
+			},
+		},
+	}
+}
+
+func expandRetryPolicy(v []interface{}) *retryPolicy {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	r := v[0].(map[string]interface{})
+	return &retryPolicy{
+		maxRetries:   r["max_retries"].(int),
+		initialDelay: time.Duration(r["initial_delay"].(int)) * time.Second,
+		maxDelay:     time.Duration(r["max_delay"].(int)) * time.Second,
+	}
+}
+
+/* withRetryPolicy returns a copy of the client retrying requests as policy says */
+func (client *APIClient) withRetryPolicy(policy *retryPolicy) *APIClient {
+	retryClient := *client
+	retryClient.retry = policy
+	retryClient.throttleRetries = policy.maxRetries
+	retryClient.concurrencyRetries = policy.maxRetries
+	retryClient.conflictRetries = policy.maxRetries
+	return &retryClient
+}
+
+/* retryDelay returns the wait before the retry after the attempt'th failure, in place of the provider's delay */
+func (client *APIClient) retryDelay(attempt int, delay time.Duration, retryAfter bool) time.Duration {
+	policy := client.retry
+	if policy == nil {
+		return delay
+	}
+	if !retryAfter {
+		delay = policy.initialDelay
+		for i := 0; i < attempt && delay < policy.maxDelay; i++ {
+			delay *= 2
+		}
+	}
+	if delay > policy.maxDelay {
+		return policy.maxDelay
+	}
+	return delay
+}
+
+/* retryExpired returns an error when waiting delay for a retry would pass the deadline of the operation */
+func (client *APIClient) retryExpired(delay time.Duration) error {
+	if client.retry == nil || client.retry.deadline.IsZero() || time.Now().Add(delay).Before(client.retry.deadline) {
+		return nil
+	}
+	return fmt.Errorf("not retrying in %s, which is past the timeout of the operation", delay)
+}
+
+/*
+retryUntil makes the retries of the object's requests end with timeout, the
+
+	timeout of the create or update that starts.
+*/
+func (obj *APIObject) retryUntil(timeout time.Duration) {
+	if obj.apiClient.retry == nil {
+		return
+	}
+	policy := *obj.apiClient.retry
+	policy.deadline = time.Now().Add(timeout)
+	obj.apiClient.retry = &policy
+	if obj.debug {
+		log.Printf("retry_policy.go: Retries of '%s' end at %s", obj.id, policy.deadline.Format(time.RFC3339))
+	}
+}
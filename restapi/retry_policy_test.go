@@ -0,0 +1,62 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	attempts := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id", throttleRetries: 0})
+	if err != nil {
+		t.Fatalf("retry_policy_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":  "/imports",
+		"data":  `{"id": "1"}`,
+		"retry": []interface{}{map[string]interface{}{"max_retries": 5, "initial_delay": 0, "max_delay": 1}},
+	})
+	obj, err := makeAPIObject(d, client)
+	if err != nil {
+		t.Fatalf("retry_policy_test.go: Failed to make the object: %s", err)
+	}
+	if obj.apiClient.conflictRetries != 5 || obj.apiClient.concurrencyRetries != 5 || client.retry != nil {
+		t.Fatalf("retry_policy_test.go: Expected the object's own retries, got %d and %d", obj.apiClient.conflictRetries, obj.apiClient.concurrencyRetries)
+	}
+	if err := obj.readObject(); err != nil || attempts != 3 {
+		t.Fatalf("retry_policy_test.go: Expected the throttled read to be retried, got %v after %d attempts", err, attempts)
+	}
+
+	/* The provider's retries are kept by other objects */
+	attempts = 0
+	if _, err := client.sendRequest("GET", "/imports/1", ""); err == nil || attempts != 1 {
+		t.Fatalf("retry_policy_test.go: Expected the provider not to retry, got %v after %d attempts", err, attempts)
+	}
+
+	/* No retry waits past the timeout */
+	attempts = 0
+	policy := &retryPolicy{maxRetries: 5, initialDelay: time.Minute, maxDelay: time.Minute}
+	obj.apiClient = client.withRetryPolicy(policy)
+	obj.retryUntil(time.Second)
+	if err := obj.readObject(); err == nil || !strings.Contains(err.Error(), "past the timeout") || attempts != 1 {
+		t.Fatalf("retry_policy_test.go: Expected the retry to be given up, got %v after %d attempts", err, attempts)
+	}
+	if !policy.deadline.IsZero() {
+		t.Fatal("retry_policy_test.go: Expected the deadline not to change the resource's policy")
+	}
+}
@@ -0,0 +1,35 @@
+package restapi
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/*
+customizeDiffServerDrift plans an update of an object that drifted on the
+
+	server when update_data_from_api is false. data is not refreshed from the
+	server then, so it does not show the drift; changed_keys, which is
+	recomputed by the update, does.
+*/
+func customizeDiffServerDrift(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || d.Get("update_data_from_api").(bool) || d.Get("ignore_all_server_changes").(bool) {
+		return nil
+	}
+	changed := expandStringList(d.Get("changed_keys").([]interface{}))
+	if len(changed) == 0 {
+		return nil
+	}
+	log.Printf("[WARN] server_drift.go: Planning an update of '%s', which drifted on the server at %s", d.Id(), strings.Join(changed, ", "))
+	return d.SetNewComputed("changed_keys")
+}
+
+/* resetServerDrift empties changed_keys after an update, which reverted the drift customizeDiffServerDrift planned */
+func resetServerDrift(d *schema.ResourceData) {
+	if !d.Get("update_data_from_api").(bool) {
+		d.Set("changed_keys", make([]string, 0))
+	}
+}
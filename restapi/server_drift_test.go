@@ -0,0 +1,56 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUpdateDataFromAPI(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"oid": "user-1", "name": "jsmith", "description": "Changed"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("server_drift_test.go: Failed to create API client: %s", err)
+	}
+
+	/* The configured data stays in state and the drift is only reported */
+	configured := "{\n  \"oid\": \"user-1\",\n  \"name\": \"jsmith\",\n  \"description\": \"Accountant\"\n}"
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":                 "/users",
+		"data":                 configured,
+		"update_data_from_api": false,
+	})
+	d.SetId("user-1")
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("server_drift_test.go: Read failed: %s", err)
+	}
+	if d.Get("data").(string) != configured {
+		t.Fatalf("server_drift_test.go: Expected the configured data in state, got %s", d.Get("data"))
+	}
+	if changed := d.Get("changed_keys").([]interface{}); len(changed) != 1 || changed[0] != "description" {
+		t.Fatalf("server_drift_test.go: Expected the drifted description in changed_keys, got %v", changed)
+	}
+	resetServerDrift(d)
+	if changed := d.Get("changed_keys").([]interface{}); len(changed) != 0 {
+		t.Fatalf("server_drift_test.go: Expected no changed_keys after the update, got %v", changed)
+	}
+
+	/* By default, the object read from the server is stored */
+	d = schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path": "/users",
+		"data": configured,
+	})
+	d.SetId("user-1")
+	if err := resourceRestAPIRead(d, client); err != nil {
+		t.Fatalf("server_drift_test.go: Read failed: %s", err)
+	}
+	if d.Get("data").(string) != `{"description":"Changed","name":"jsmith","oid":"user-1"}` {
+		t.Fatalf("server_drift_test.go: Expected the server's object in state, got %s", d.Get("data"))
+	}
+}
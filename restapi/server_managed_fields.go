@@ -0,0 +1,54 @@
+package restapi
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* The items midPoint maintains itself in the objects it returns */
+var midpointServerManagedFields = []string{
+	"@metadata",
+	"metadata",
+	"@ns",
+	"fetchResult",
+	"operationExecution",
+	"iteration",
+	"iterationToken",
+	"version",
+	"lastProvisioningTimestamp",
+	"linkRef",
+}
+
+/*
+setMidpointMode records the midpoint_mode of the provider of a resource in
+
+	state, where diff suppression, which is not given the client, finds it.
+*/
+func setMidpointMode(d *schema.ResourceData, client *APIClient) {
+	d.Set("midpoint_mode", client.midpointMode)
+}
+
+/* customizeDiffMidpointMode plans the midpoint_mode of the provider of a resource */
+func customizeDiffMidpointMode(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if meta == nil || d.Get("midpoint_mode").(bool) == meta.(*APIClient).midpointMode {
+		return nil
+	}
+	return d.SetNew("midpoint_mode", meta.(*APIClient).midpointMode)
+}
+
+/*
+midpointModeIgnoreList returns the ignore list entries midpoint_mode adds to
+
+	every resource when enabled: the server-managed items at any depth.
+*/
+func midpointModeIgnoreList(enabled bool) []string {
+	if !enabled {
+		return nil
+	}
+	patterns := make([]string, 0, len(midpointServerManagedFields))
+	for _, field := range midpointServerManagedFields {
+		patterns = append(patterns, "*."+field)
+	}
+	return patterns
+}
@@ -0,0 +1,80 @@
+package restapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestMidpointModeIgnoreList(t *testing.T) {
+	/* Aliased providers, one of them in midpoint_mode */
+	newClient := func(midpointMode bool) *APIClient {
+		t.Helper()
+		client, err := NewAPIClient(&apiClientOpt{uri: "http://127.0.0.1:1", timeout: 1, midpointMode: midpointMode})
+		if err != nil {
+			t.Fatalf("server_managed_fields_test.go: Failed to create API client: %s", err)
+		}
+		return client
+	}
+	plain, midpoint := newClient(false), newClient(true)
+
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":              "/users",
+		"data":              `{"user": {"name": "jdoe"}}`,
+		"ignore_changes_to": []interface{}{"user.description"},
+	})
+	if _, err := buildAPIObjectOpts(d, plain); err != nil {
+		t.Fatalf("server_managed_fields_test.go: Failed to build object options: %s", err)
+	}
+	if ignoreList := getIgnoreList(d); len(ignoreList) != 1 {
+		t.Fatalf("server_managed_fields_test.go: Expected only the configured entry without midpoint_mode, got %v", ignoreList)
+	}
+
+	if _, err := buildAPIObjectOpts(d, midpoint); err != nil {
+		t.Fatalf("server_managed_fields_test.go: Failed to build object options: %s", err)
+	}
+	ignoreList := getIgnoreList(d)
+	if len(ignoreList) != 1+len(midpointServerManagedFields) || ignoreList[0] != "user.description" {
+		t.Fatalf("server_managed_fields_test.go: Expected the server-managed items after the configured entry, got %v", ignoreList)
+	}
+
+	/* midpoint_mode is planned with the resource's own provider */
+	state := &terraform.InstanceState{ID: "1", Attributes: map[string]string{"id": "1", "path": "/users", "data": `{"user": {"name": "jdoe"}}`, "midpoint_mode": "true"}}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"path": "/users", "data": `{"user": {"name": "jdoe"}}`})
+	diff, err := resourceRestAPI().Diff(context.TODO(), state, config, plain)
+	if err != nil {
+		t.Fatalf("server_managed_fields_test.go: Diff failed: %s", err)
+	}
+	if attr := diff.Attributes["midpoint_mode"]; attr == nil || attr.New != "false" {
+		t.Fatalf("server_managed_fields_test.go: Expected midpoint_mode of the resource's provider to be planned, got %v", diff.Attributes)
+	}
+	if diff, err = resourceRestAPI().Diff(context.TODO(), state, config, midpoint); err != nil || diff != nil && diff.Attributes["midpoint_mode"] != nil {
+		t.Fatalf("server_managed_fields_test.go: Expected no change of midpoint_mode, got %v (%v)", diff, err)
+	}
+
+	recorded := map[string]interface{}{"user": map[string]interface{}{"name": "jdoe"}}
+	actual := map[string]interface{}{
+		"@ns": "http://prism.evolveum.com/xml/ns/public/types-3",
+		"user": map[string]interface{}{
+			"name":               "jdoe",
+			"description":        "set by hand",
+			"version":            "3",
+			"iteration":          0,
+			"iterationToken":     "",
+			"linkRef":            []interface{}{map[string]interface{}{"oid": "shadow-1"}},
+			"metadata":           map[string]interface{}{"createTimestamp": "2024-05-01T10:00:00Z"},
+			"operationExecution": []interface{}{map[string]interface{}{"@id": 1}},
+		},
+	}
+	if modified, changed := getDelta(recorded, actual, ignoreList, nil); changed {
+		t.Fatalf("server_managed_fields_test.go: Expected the server-managed items to be ignored, got %v", modified)
+	}
+
+	/* Other items are still compared */
+	actual["user"].(map[string]interface{})["givenName"] = "John"
+	if _, changed := getDelta(recorded, actual, ignoreList, nil); !changed {
+		t.Fatal("server_managed_fields_test.go: Expected other items to be compared in midpoint_mode")
+	}
+}
@@ -0,0 +1,131 @@
+package restapi
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/*
+shallowReadOpts describes a cheap request returning only the version of the
+
+	object, such as a HEAD request with an ETag. When the version is the one
+	recorded at the last full read, the object is not read again.
+*/
+type shallowReadOpts struct {
+	method     string
+	path       string
+	versionKey string
+}
+
+func shallowReadSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "A cheap request returning only the version of the object. A refresh sends it first and only reads the whole object when the version differs from `read_version`, so drift detection across many objects is fast. When the version is taken from a response body with `version_key`, the body is a projection of the object and the whole object is also read unless it has every field of `data` Terraform manages, with its value in state. Terraform does not tell providers whether a refresh is part of `terraform plan -refresh-only`, so this applies to every refresh of the resource; set it from a variable to only enable it in such runs.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"method": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "HEAD",
+					Description: "The method of the version request. Default: `HEAD`",
+				},
+				"path": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Defaults to `read_path`. The API path of the version request, such as a version-only endpoint. The string `{id}` will be replaced with the terraform ID of the object.",
+				},
+				"version_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The '/'-delimited location of the version in the response body, such as `object/version`. When not set, the `ETag` response header is used, or the `Last-Modified` header when there is no `ETag`.",
+				},
+			},
+		},
+	}
+}
+
+func expandShallowRead(v []interface{}) *shallowReadOpts {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	s := v[0].(map[string]interface{})
+	return &shallowReadOpts{
+		method:     s["method"].(string),
+		path:       s["path"].(string),
+		versionKey: s["version_key"].(string),
+	}
+}
+
+/*
+readVersion sends the shallow read request and returns the version of the
+
+	object, along with the response body when the version is taken from it.
+	An empty version means it could not be determined and the object has to
+	be read as a whole.
+*/
+func (obj *APIObject) readVersion() (string, map[string]interface{}, error) {
+	if obj.shallowRead == nil {
+		return "", nil, nil
+	}
+	if obj.id == "" {
+		return "", nil, fmt.Errorf("cannot read the version of an object unless the ID has been set")
+	}
+
+	path := obj.shallowRead.path
+	if path == "" {
+		path = obj.getPath
+	}
+
+	body, headers, err := obj.apiClient.sendRequestReturningHeaders(obj.shallowRead.method, strings.Replace(path, "{id}", obj.id, -1), "", "", nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if obj.shallowRead.versionKey == "" {
+		if etag := headers.Get("ETag"); etag != "" {
+			return etag, nil, nil
+		}
+		return headers.Get("Last-Modified"), nil, nil
+	}
+
+	var result map[string]interface{}
+	if err := unmarshalJSON([]byte(body), &result); err != nil {
+		return "", nil, fmt.Errorf("failed to parse the version response: %v", err)
+	}
+	version, err := GetStringAtKey(result, obj.shallowRead.versionKey, obj.debug)
+	if err != nil {
+		log.Printf("shallow_read.go: No version at '%s' for '%s': %v", obj.shallowRead.versionKey, obj.id, err)
+		return "", nil, nil
+	}
+	return version, result, nil
+}
+
+/*
+shallowReadCurrent tells whether the object in state is still current without
+
+	a full read: its version is recorded, the one read at the last full read,
+	and the projection of the object the shallow read returned, if any, has
+	every managed field of state with the value in state. A field the
+	projection leaves out may have drifted unseen, so it takes a full read.
+	Versions from the ETag or Last-Modified headers cover the whole object.
+*/
+func (obj *APIObject) shallowReadCurrent(version string, recorded string, projection map[string]interface{}, ignoreList []string) bool {
+	if version == "" || version != recorded {
+		return false
+	}
+	if projection == nil {
+		return true
+	}
+	stateData := filterIgnoredFields(obj.managedOnly(obj.data), ignoreList)
+	projected, _ := configuredPart(stateData, projection).(map[string]interface{})
+	if _, changed := getDelta(stateData, projected, ignoreList, obj.normalize); changed {
+		log.Printf("shallow_read.go: The version response of '%s' lacks or changed managed fields", obj.id)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestShallowRead(t *testing.T) {
+	etag := `"1"`
+	reads := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if r.Method == "GET" {
+			reads++
+			w.Write([]byte(`{"id": "1", "name": "jsmith"}`))
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id"})
+	if err != nil {
+		t.Fatalf("shallow_read_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":         "/users",
+		"data":         `{"id": "1", "name": "jsmith"}`,
+		"shallow_read": []interface{}{map[string]interface{}{"method": "HEAD"}},
+	})
+	d.SetId("1")
+
+	read := func(expected int, version string) {
+		t.Helper()
+		if err := resourceRestAPIRead(d, client); err != nil {
+			t.Fatalf("shallow_read_test.go: Read failed: %s", err)
+		}
+		if reads != expected {
+			t.Fatalf("shallow_read_test.go: Expected %d full reads, got %d", expected, reads)
+		}
+		if d.Get("read_version").(string) != version {
+			t.Fatalf("shallow_read_test.go: Expected read_version %s, got %s", version, d.Get("read_version"))
+		}
+	}
+
+	read(1, `"1"`)
+	/* The version did not change, so the object is not read again */
+	read(1, `"1"`)
+	etag = `"2"`
+	read(2, `"2"`)
+}
+
+func TestShallowReadProjection(t *testing.T) {
+	name := "jsmith"
+	projectName := false
+	reads := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/users/1/version" {
+			/* The version does not change, as with a change made in raw mode */
+			if projectName {
+				w.Write([]byte(fmt.Sprintf(`{"user": {"oid": "1", "name": "%s", "version": "3"}}`, name)))
+				return
+			}
+			w.Write([]byte(`{"user": {"oid": "1", "version": "3"}}`))
+			return
+		}
+		reads++
+		w.Write([]byte(fmt.Sprintf(`{"user": {"oid": "1", "name": "%s", "version": "3"}}`, name)))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid"})
+	if err != nil {
+		t.Fatalf("shallow_read_test.go: Failed to create API client: %s", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":              "/users",
+		"data":              `{"user": {"oid": "1", "name": "jsmith"}}`,
+		"ignore_changes_to": []interface{}{"user.version"},
+		"shallow_read": []interface{}{map[string]interface{}{
+			"method":      "GET",
+			"path":        "/users/{id}/version",
+			"version_key": "user/version",
+		}},
+	})
+	d.SetId("1")
+
+	/* A refresh-only plan runs the read and shows the drift it finds */
+	refresh := func(expected int) diag.Diagnostics {
+		t.Helper()
+		diags := resourceRestAPIReadContext(context.Background(), d, client)
+		if diags.HasError() {
+			t.Fatalf("shallow_read_test.go: Read failed: %v", diags)
+		}
+		if reads != expected {
+			t.Fatalf("shallow_read_test.go: Expected %d full reads, got %d", expected, reads)
+		}
+		return diags
+	}
+
+	refresh(1)
+
+	/* The projection lacks the managed name, so a change outside Terraform needs a full read */
+	name = "changed by hand"
+	diags := refresh(2)
+	if changed := d.Get("changed_keys").([]interface{}); !reflect.DeepEqual(changed, []interface{}{"user.name"}) || len(diags) == 0 {
+		t.Fatalf("shallow_read_test.go: Expected the drift of 'user.name' to show up, got %v, %v", changed, diags)
+	}
+
+	/* A projection with every managed field as in state spares the full read */
+	projectName = true
+	refresh(2)
+	name = "changed again"
+	refresh(3)
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSingleton(t *testing.T) {
+	const configPath = "/tenants/acme/settings"
+	var requests []string
+	stored := ""
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path != configPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(stored))
+		case "DELETE":
+			stored = ""
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			body, _ := io.ReadAll(r.Body)
+			stored = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "id", updateMethod: "PUT"})
+	if err != nil {
+		t.Fatalf("singleton_test.go: Failed to create API client: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+		"path":          configPath,
+		"data":          `{"theme": "dark"}`,
+		"singleton":     true,
+		"create_method": "PUT",
+	})
+
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("singleton_test.go: Create failed: %s", err)
+	}
+	if d.Id() != configPath {
+		t.Fatalf("singleton_test.go: Expected the singleton to be known by its path, got '%s'", d.Id())
+	}
+	d.Set("data", `{"theme": "light"}`)
+	if err := resourceRestAPIUpdate(d, client); err != nil {
+		t.Fatalf("singleton_test.go: Update failed: %s", err)
+	}
+	if err := resourceRestAPIRead(d, client); err != nil || d.Id() != configPath || !strings.Contains(stored, "light") {
+		t.Fatalf("singleton_test.go: Expected the updated singleton to be read, got %v, '%s' and '%s'", err, d.Id(), stored)
+	}
+	if err := resourceRestAPIDelete(d, client); err != nil || stored != "" {
+		t.Fatalf("singleton_test.go: Expected the singleton to be deleted, got %v and '%s'", err, stored)
+	}
+	for _, request := range requests {
+		if !strings.HasSuffix(request, " "+configPath) {
+			t.Fatalf("singleton_test.go: Expected every request to go to the literal path, got %v", requests)
+		}
+	}
+
+	/* Import by the literal path */
+	stored = `{"theme": "dark"}`
+	d = resourceRestAPI().TestResourceData()
+	d.SetId(configPath + "?singleton=true")
+	imported, err := resourceRestAPIImport(d, client)
+	if err != nil {
+		t.Fatalf("singleton_test.go: Import failed: %s", err)
+	}
+	d = imported[0]
+	if d.Id() != configPath || d.Get("path") != configPath || !d.Get("singleton").(bool) || d.Get("data") != `{"theme":"dark"}` {
+		t.Fatalf("singleton_test.go: Expected the singleton to be imported by its path, got %v", d.State())
+	}
+}
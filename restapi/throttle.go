@@ -0,0 +1,92 @@
+package restapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* Wait before the first retry of a throttled request; doubles with every attempt up to throttleMaxDelay */
+var throttleRetryDelay = time.Second
+
+var throttleMaxDelay = time.Minute
+
+/*
+throttleDeferral defers requests the server answered with 429 Too Many
+
+	Requests. Terraform keeps applying the other resources in parallel, so a
+	deferred request waits for its backoff and then until no other request
+	is in flight, which retries the deferred set once the rest of the apply
+	has drained instead of competing with it. A deferred request never waits
+	longer than throttleMaxDelay for the others.
+*/
+type throttleDeferral struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	inFlight int
+}
+
+func newThrottleDeferral() *throttleDeferral {
+	t := &throttleDeferral{}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+/* begin marks a request as in flight */
+func (t *throttleDeferral) begin() {
+	t.mu.Lock()
+	t.inFlight++
+	t.mu.Unlock()
+}
+
+/* end marks a request obtained with begin as finished */
+func (t *throttleDeferral) end() {
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+	t.cond.Broadcast()
+}
+
+/* wait sleeps for delay and then until no request is in flight */
+func (t *throttleDeferral) wait(delay time.Duration) {
+	time.Sleep(delay)
+
+	deadline := time.Now().Add(throttleMaxDelay)
+	timer := time.AfterFunc(throttleMaxDelay, t.cond.Broadcast)
+	defer timer.Stop()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.inFlight > 0 && time.Now().Before(deadline) {
+		t.cond.Wait()
+	}
+}
+
+/*
+throttleDelay returns how long to defer a request after its attempt'th 429,
+
+	honoring a Retry-After header given in seconds.
+*/
+func throttleDelay(attempt int, headers http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(headers.Get("Retry-After")); err == nil && seconds >= 0 {
+		if delay := time.Duration(seconds) * time.Second; delay < throttleMaxDelay {
+			return delay
+		}
+		return throttleMaxDelay
+	}
+	delay := throttleRetryDelay
+	for i := 0; i < attempt && delay < throttleMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > throttleMaxDelay {
+		return throttleMaxDelay
+	}
+	return delay
+}
+
+// isThrottledError reports whether err was caused by the server answering 429 Too Many Requests
+func isThrottledError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unexpected response code '429'")
+}
@@ -0,0 +1,86 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottleDeferral(t *testing.T) {
+	defer func(delay time.Duration) { throttleRetryDelay = delay }(throttleRetryDelay)
+	throttleRetryDelay = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}
+
+	throttled := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			record("slow done")
+		case "/throttled":
+			mu.Lock()
+			throttled++
+			n := throttled
+			mu.Unlock()
+			if n <= 2 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			record("throttled done")
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, throttleRetries: 2})
+	if err != nil {
+		t.Fatalf("throttle_test.go: Failed to create API client: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := client.sendRequest("GET", "/slow", ""); err != nil {
+			t.Errorf("throttle_test.go: Slow request failed: %s", err)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := client.sendRequest("GET", "/throttled", ""); err != nil {
+		t.Fatalf("throttle_test.go: Expected the throttled request to succeed when retried, got %s", err)
+	}
+	wg.Wait()
+
+	if len(events) != 2 || events[0] != "slow done" {
+		t.Fatalf("throttle_test.go: Expected the throttled request to be retried after the other request finished, got %v", events)
+	}
+
+	/* Once the retries are used up, the 429 is returned */
+	throttled = 0
+	client.throttleRetries = 1
+	if _, err := client.sendRequest("GET", "/throttled", ""); !isThrottledError(err) {
+		t.Fatalf("throttle_test.go: Expected a 429 error once the retries are used up, got %v", err)
+	}
+}
+
+func TestThrottleDelay(t *testing.T) {
+	if delay := throttleDelay(0, http.Header{"Retry-After": []string{"3"}}); delay != 3*time.Second {
+		t.Fatalf("throttle_test.go: Expected Retry-After to be honored, got %s", delay)
+	}
+	if delay := throttleDelay(2, nil); delay != 4*throttleRetryDelay {
+		t.Fatalf("throttle_test.go: Expected the delay to double with every attempt, got %s", delay)
+	}
+	if delay := throttleDelay(20, nil); delay != throttleMaxDelay {
+		t.Fatalf("throttle_test.go: Expected the delay to be capped at %s, got %s", throttleMaxDelay, delay)
+	}
+}
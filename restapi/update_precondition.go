@@ -0,0 +1,304 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/* What happens to an update whose update_precondition does not hold */
+const (
+	preconditionActionFail = "fail"
+	preconditionActionSkip = "skip"
+)
+
+var preconditionActions = []string{preconditionActionFail, preconditionActionSkip}
+
+/*
+preconditionError is returned by updateObject when update_precondition does
+
+	not hold for the object on the server, before any modification is sent.
+*/
+type preconditionError struct {
+	condition string
+	actual    string
+}
+
+func (e *preconditionError) Error() string {
+	return fmt.Sprintf("update_precondition '%s' does not hold for the object on the server (%s); no modification was sent", e.condition, e.actual)
+}
+
+/*
+A precondition is a disjunction (||) of conjunctions (&&) of comparisons.
+
+	A comparison is a JSONPath such as $.role.lifecycleState, optionally
+	negated with "!" or followed by == or != and a value.
+*/
+type preconditionComparison struct {
+	path     []string
+	negate   bool
+	operator string
+	value    interface{}
+}
+
+type precondition [][]preconditionComparison
+
+/*
+parsePrecondition parses an update_precondition such as
+
+	$.role.lifecycleState == "active" && !$.role.activation.effectiveStatus.
+	Values are JSON literals; a bare word is a string.
+*/
+func parsePrecondition(condition string) (precondition, error) {
+	tokens, err := tokenizePrecondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("update_precondition is empty")
+	}
+
+	parsed := precondition{}
+	conjunction := []preconditionComparison{}
+	for i := 0; i < len(tokens); {
+		comparison := preconditionComparison{}
+		if tokens[i] == "!" {
+			comparison.negate = true
+			i++
+		}
+		if i >= len(tokens) || isPreconditionOperator(tokens[i]) {
+			return nil, fmt.Errorf("update_precondition '%s': expected a path at position %d", condition, i+1)
+		}
+		comparison.path = splitJSONPath(tokens[i])
+		i++
+
+		if i < len(tokens) && (tokens[i] == "==" || tokens[i] == "!=") {
+			if comparison.negate {
+				return nil, fmt.Errorf("update_precondition '%s': '!' cannot be combined with '%s'; use the opposite operator", condition, tokens[i])
+			}
+			if i+1 >= len(tokens) || isPreconditionOperator(tokens[i+1]) {
+				return nil, fmt.Errorf("update_precondition '%s': '%s' needs a value", condition, tokens[i])
+			}
+			comparison.operator = tokens[i]
+			comparison.value = preconditionLiteral(tokens[i+1])
+			i += 2
+		}
+		conjunction = append(conjunction, comparison)
+
+		if i == len(tokens) {
+			break
+		}
+		switch tokens[i] {
+		case "&&":
+		case "||":
+			parsed = append(parsed, conjunction)
+			conjunction = []preconditionComparison{}
+		default:
+			return nil, fmt.Errorf("update_precondition '%s': expected '&&' or '||' before '%s'", condition, tokens[i])
+		}
+		i++
+		if i == len(tokens) {
+			return nil, fmt.Errorf("update_precondition '%s' ends with '%s'", condition, tokens[i-1])
+		}
+	}
+	return append(parsed, conjunction), nil
+}
+
+/* tokenizePrecondition splits a condition into paths, values, quoted strings and operators */
+func tokenizePrecondition(condition string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.HasPrefix(condition[i:], "==") || strings.HasPrefix(condition[i:], "!=") || strings.HasPrefix(condition[i:], "&&") || strings.HasPrefix(condition[i:], "||"):
+			tokens = append(tokens, condition[i:i+2])
+			i += 2
+		case c == '!':
+			tokens = append(tokens, "!")
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(condition) && condition[end] != c {
+				if condition[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(condition) {
+				return nil, fmt.Errorf("update_precondition '%s' has an unterminated string", condition)
+			}
+			/* Single quoted strings are read as JSON strings too */
+			tokens = append(tokens, `"`+strings.ReplaceAll(condition[i+1:end], `"`, `\"`)+`"`)
+			i = end + 1
+		default:
+			end := i
+			for end < len(condition) && !strings.ContainsRune(" \t\n!=&|\"'", rune(condition[end])) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("update_precondition '%s': unexpected '%c'", condition, c)
+			}
+			tokens = append(tokens, condition[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func isPreconditionOperator(token string) bool {
+	return token == "==" || token == "!=" || token == "&&" || token == "||" || token == "!"
+}
+
+/* preconditionLiteral decodes a JSON literal, or returns a bare word as a string */
+func preconditionLiteral(token string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(token), &value); err == nil {
+		return value
+	}
+	return token
+}
+
+/*
+splitJSONPath splits a JSONPath such as $.role.assignment[0].targetRef.oid
+
+	into its keys and list indexes. The leading "$." is optional.
+*/
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.ReplaceAll(strings.ReplaceAll(path, "[", "."), "]", "")
+	components := []string{}
+	for _, component := range strings.Split(path, ".") {
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+/*
+lookupJSONPath returns the value at the path components, and whether it is
+
+	present. A path that does not start at the root is looked up below the
+	single key midPoint wraps objects in, so lifecycleState finds
+	role.lifecycleState.
+*/
+func lookupJSONPath(data map[string]interface{}, components []string) (interface{}, bool) {
+	if len(components) > 0 {
+		if _, ok := data[components[0]]; !ok && len(data) == 1 {
+			for _, wrapped := range data {
+				if inner, ok := wrapped.(map[string]interface{}); ok {
+					data = inner
+				}
+			}
+		}
+	}
+
+	var value interface{} = data
+	for _, component := range components {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[component]
+			if !ok {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(component)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+/*
+evaluate reports whether the precondition holds for data and, when it does
+
+	not, describes the values it found.
+*/
+func (p precondition) evaluate(data map[string]interface{}) (bool, string) {
+	found := []string{}
+	for _, conjunction := range p {
+		holds := true
+		for _, comparison := range conjunction {
+			value, present := lookupJSONPath(data, comparison.path)
+			if !comparison.matches(value, present) {
+				holds = false
+				description := "absent"
+				if present {
+					b, _ := json.Marshal(value)
+					description = string(b)
+				}
+				found = append(found, fmt.Sprintf("%s is %s", strings.Join(comparison.path, "."), description))
+				break
+			}
+		}
+		if holds {
+			return true, ""
+		}
+	}
+	return false, strings.Join(found, ", ")
+}
+
+func (comparison preconditionComparison) matches(value interface{}, present bool) bool {
+	/* midPoint returns PolyStrings with their original value in orig */
+	if poly, ok := value.(map[string]interface{}); ok {
+		if orig, ok := poly["orig"]; ok {
+			value = orig
+		}
+	}
+
+	switch comparison.operator {
+	case "==":
+		return present && preconditionEqual(value, comparison.value)
+	case "!=":
+		return !present || !preconditionEqual(value, comparison.value)
+	}
+	truthy := present && value != nil && value != false && value != ""
+	return truthy != comparison.negate
+}
+
+/* preconditionEqual compares a value read from the server with a literal, numbers and strings by their text */
+func preconditionEqual(value interface{}, literal interface{}) bool {
+	if value == nil || literal == nil {
+		return value == literal
+	}
+	return fmt.Sprintf("%v", value) == fmt.Sprintf("%v", literal)
+}
+
+/* validatePrecondition rejects update_precondition values that do not parse */
+func validatePrecondition(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := parsePrecondition(val.(string)); err != nil {
+		errs = append(errs, err)
+	}
+	return warns, errs
+}
+
+/*
+checkPrecondition reads the object and returns a preconditionError if its
+
+	update_precondition does not hold for it.
+*/
+func (obj *APIObject) checkPrecondition() error {
+	parsed, err := parsePrecondition(obj.precondition)
+	if err != nil {
+		return err
+	}
+	if err := obj.readObject(); err != nil {
+		return fmt.Errorf("failed to read the object to check update_precondition: %v", err)
+	}
+	if obj.id == "" {
+		return &preconditionError{condition: obj.precondition, actual: "the object no longer exists"}
+	}
+	if holds, actual := parsed.evaluate(obj.apiData); !holds {
+		return &preconditionError{condition: obj.precondition, actual: actual}
+	}
+	return nil
+}
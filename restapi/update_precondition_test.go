@@ -0,0 +1,94 @@
+package restapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrecondition(t *testing.T) {
+	data := MapAny{"role": MapAny{
+		"name":           MapAny{"orig": "Auditor", "norm": "auditor"},
+		"lifecycleState": "active",
+		"riskLevel":      2.0,
+		"requestable":    false,
+		"assignment":     []interface{}{MapAny{"targetRef": MapAny{"oid": "archetype-1"}}},
+	}}
+
+	cases := map[string]bool{
+		`$.role.lifecycleState == "active"`:                       true,
+		`lifecycleState == active`:                                true,
+		`$.role.lifecycleState == 'draft'`:                        false,
+		`$.role.lifecycleState != draft`:                          true,
+		`$.role.name == Auditor`:                                  true,
+		`$.role.riskLevel == 2`:                                   true,
+		`$.role.assignment[0].targetRef.oid == archetype-1`:       true,
+		`$.role.assignment[1].targetRef.oid`:                      false,
+		`!$.role.requestable`:                                     true,
+		`$.role.requestable`:                                      false,
+		`$.role.description == null`:                              false,
+		`$.role.description != null`:                              true,
+		`lifecycleState == draft || lifecycleState == active`:     true,
+		`lifecycleState == active && !$.role.activation.disabled`: true,
+		`lifecycleState == active && riskLevel == 3`:              false,
+	}
+	for condition, expected := range cases {
+		parsed, err := parsePrecondition(condition)
+		if err != nil {
+			t.Fatalf("update_precondition_test.go: Failed to parse '%s': %s", condition, err)
+		}
+		if holds, _ := parsed.evaluate(data); holds != expected {
+			t.Fatalf("update_precondition_test.go: Expected '%s' to be %v", condition, expected)
+		}
+	}
+
+	for _, condition := range []string{"", "== active", "lifecycleState ==", "lifecycleState active", "a &&", `name == "x`, "!a == b"} {
+		if _, err := parsePrecondition(condition); err == nil {
+			t.Fatalf("update_precondition_test.go: Expected '%s' not to parse", condition)
+		}
+	}
+
+	parsed, _ := parsePrecondition("lifecycleState == draft || riskLevel == 3")
+	if _, actual := parsed.evaluate(data); actual != `lifecycleState is "active", riskLevel is 2` {
+		t.Fatalf("update_precondition_test.go: Unexpected description '%s'", actual)
+	}
+}
+
+func TestUpdatePrecondition(t *testing.T) {
+	client, err := NewAPIClient(&apiClientOpt{uri: "http://memory", timeout: 5, idAttribute: "role/oid", writeReturnsObject: true, mode: clientModeMemory})
+	if err != nil {
+		t.Fatalf("update_precondition_test.go: Failed to create API client: %s", err)
+	}
+
+	created, _ := NewAPIObject(client, &apiObjectOpts{path: "/roles", data: `{"role": {"oid": "r1", "name": "Auditor", "lifecycleState": "draft"}}`})
+	if err := created.createObject(); err != nil {
+		t.Fatalf("update_precondition_test.go: Create failed: %s", err)
+	}
+
+	updated, _ := NewAPIObject(client, &apiObjectOpts{
+		path:           "/roles",
+		id:             "r1",
+		updateStrategy: updateStrategyMidpointPatch,
+		precondition:   "lifecycleState == active",
+		data:           `{"role": {"oid": "r1", "name": "Auditor", "lifecycleState": "draft", "description": "changed"}}`,
+	})
+	err = updated.updateObject()
+	var failed *preconditionError
+	if !errors.As(err, &failed) || !strings.Contains(err.Error(), `lifecycleState is "draft"`) {
+		t.Fatalf("update_precondition_test.go: Expected the precondition to fail, got %v", err)
+	}
+	if err := created.readObject(); err != nil || created.apiData["role"].(map[string]interface{})["description"] != nil {
+		t.Fatalf("update_precondition_test.go: Expected no modification to be sent, got %v (%v)", created.apiData, err)
+	}
+
+	if _, err := client.sendRequest("PATCH", "/roles/r1", `{"objectModification": {"itemDelta": {"modificationType": "replace", "path": "lifecycleState", "value": "active"}}}`); err != nil {
+		t.Fatalf("update_precondition_test.go: Patch failed: %s", err)
+	}
+	updated.data["role"].(map[string]interface{})["lifecycleState"] = "active"
+	if err := updated.updateObject(); err != nil {
+		t.Fatalf("update_precondition_test.go: Expected the update to be sent once the role is active, got %s", err)
+	}
+	if updated.apiData["role"].(map[string]interface{})["description"] != "changed" {
+		t.Fatalf("update_precondition_test.go: Expected the update to be applied, got %v", updated.apiData)
+	}
+}
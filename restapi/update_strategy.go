@@ -0,0 +1,263 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+/* Supported values for update_strategy */
+const (
+	updateStrategyPut           = "put"
+	updateStrategyMidpointPatch = "midpoint-patch"
+	updateStrategyJSONPatch     = "json-patch"
+	updateStrategyMergePatch    = "merge-patch"
+)
+
+var updateStrategies = []string{
+	updateStrategyPut,
+	updateStrategyMidpointPatch,
+	updateStrategyJSONPatch,
+	updateStrategyMergePatch,
+}
+
+/* Content types sent with each patch-based strategy */
+const (
+	contentTypeJSONPatch  = "application/json-patch+json"
+	contentTypeMergePatch = "application/merge-patch+json"
+)
+
+/*
+resolveUpdateStrategy picks the update strategy for an object. An explicit
+
+	strategy always wins. Otherwise the legacy behavior is kept: an update_method
+	of PATCH means midPoint ObjectModificationType deltas, anything else is a
+	full replacement sent with update_method.
+*/
+func resolveUpdateStrategy(strategy string, updateMethod string) (string, error) {
+	if strategy == "" {
+		if strings.EqualFold(updateMethod, "PATCH") {
+			return updateStrategyMidpointPatch, nil
+		}
+		return updateStrategyPut, nil
+	}
+
+	for _, s := range updateStrategies {
+		if s == strategy {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown update_strategy '%s'; must be one of: %s", strategy, strings.Join(updateStrategies, ", "))
+}
+
+/*
+ * jsonPatchOperation is a single RFC 6902 operation
+ */
+type jsonPatchOperation struct {
+	Op    string
+	Path  string
+	Value interface{}
+}
+
+/* MarshalJSON keeps explicit null values for add/replace but omits value for remove */
+func (op jsonPatchOperation) MarshalJSON() ([]byte, error) {
+	out := map[string]interface{}{
+		"op":   op.Op,
+		"path": op.Path,
+	}
+	if op.Op != "remove" {
+		out["value"] = op.Value
+	}
+	return json.Marshal(out)
+}
+
+/*
+ * buildJSONPatch computes the RFC 6902 operations that transform current into desired.
+ * Nested objects are descended into; arrays and scalars are replaced as a whole.
+ * Keys matching the ignore list are neither replaced nor removed, and values
+ * equal under the normalization rules are not replaced. Only keys of managed,
+ * the data of the previous apply, are removed, so keys the server adds are kept.
+ */
+func buildJSONPatch(current, desired, managed map[string]interface{}, ignoreList []string, rules *normalizeRules) []jsonPatchOperation {
+	return appendJSONPatch(make([]jsonPatchOperation, 0), "", current, desired, managed, ignoreList, rules)
+}
+
+func appendJSONPatch(ops []jsonPatchOperation, prefix string, current, desired, managed map[string]interface{}, ignoreList []string, rules *normalizeRules) []jsonPatchOperation {
+	for _, key := range sortedKeys(desired) {
+		if matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		path := prefix + "/" + escapeJSONPointer(key)
+		desiredValue := desired[key]
+		currentValue, exists := current[key]
+
+		if !exists {
+			ops = append(ops, jsonPatchOperation{Op: "add", Path: path, Value: desiredValue})
+			continue
+		}
+
+		desiredMap, desiredIsMap := desiredValue.(map[string]interface{})
+		currentMap, currentIsMap := currentValue.(map[string]interface{})
+		if desiredIsMap && currentIsMap {
+			managedMap, _ := managed[key].(map[string]interface{})
+			ops = appendJSONPatch(ops, path, currentMap, desiredMap, managedMap, _descendIgnoreList(key, ignoreList), rules)
+		} else if !rules.equal(currentValue, desiredValue) {
+			ops = append(ops, jsonPatchOperation{Op: "replace", Path: path, Value: desiredValue})
+		}
+	}
+
+	for _, key := range sortedKeys(current) {
+		if _, exists := desired[key]; exists || !isManaged(managed, key) || matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		ops = append(ops, jsonPatchOperation{Op: "remove", Path: prefix + "/" + escapeJSONPointer(key)})
+	}
+
+	return ops
+}
+
+/*
+ * buildMergePatch computes the RFC 7386 merge patch document that transforms current into desired.
+ * Removed keys of managed are set to null; keys matching the ignore list, keys only the
+ * server has and values equal under the normalization rules are left out of the document.
+ */
+func buildMergePatch(current, desired, managed map[string]interface{}, ignoreList []string, rules *normalizeRules) map[string]interface{} {
+	patch := make(map[string]interface{})
+
+	for key, desiredValue := range desired {
+		if matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		currentValue, exists := current[key]
+
+		desiredMap, desiredIsMap := desiredValue.(map[string]interface{})
+		currentMap, currentIsMap := currentValue.(map[string]interface{})
+		if exists && desiredIsMap && currentIsMap {
+			managedMap, _ := managed[key].(map[string]interface{})
+			if sub := buildMergePatch(currentMap, desiredMap, managedMap, _descendIgnoreList(key, ignoreList), rules); len(sub) > 0 {
+				patch[key] = sub
+			}
+		} else if !exists || !rules.equal(currentValue, desiredValue) {
+			patch[key] = desiredValue
+		}
+	}
+
+	for key := range current {
+		if _, exists := desired[key]; exists || !isManaged(managed, key) || matchesIgnorePattern(key, ignoreList) {
+			continue
+		}
+		patch[key] = nil
+	}
+
+	return patch
+}
+
+/* isManaged reports whether key was in the data of the previous apply, which makes it removable */
+func isManaged(managed map[string]interface{}, key string) bool {
+	_, ok := managed[key]
+	return ok
+}
+
+/*
+managedData returns the data of the previous apply without the id attribute,
+
+	as the keys the patch strategies may remove. The server's other keys, such
+	as the id or metadata and versions it adds, are never removed.
+*/
+func (obj *APIObject) managedData() map[string]interface{} {
+	if obj.previousData == nil {
+		return nil
+	}
+	managed := copyMap(obj.previousData)
+	if obj.idAttribute != "" {
+		deleteObjectAtKey(managed, obj.idAttribute)
+	}
+	return managed
+}
+
+/* deleteObjectAtKey deletes the value at the '/'-delimited path of GetObjectAtKey */
+func deleteObjectAtKey(data map[string]interface{}, path string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := data[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		data = next
+	}
+	delete(data, parts[len(parts)-1])
+}
+
+/* escapeJSONPointer escapes a key for use as an RFC 6901 reference token */
+func escapeJSONPointer(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+func sortedKeys(hash map[string]interface{}) []string {
+	keys := GetKeys(hash)
+	sort.Strings(keys)
+	return keys
+}
+
+/*
+patchGenericObject sends the difference between apiData and data as a single
+
+	RFC 6902 JSON Patch or RFC 7386 JSON Merge Patch document, depending on
+	the object's update strategy.
+*/
+func (obj *APIObject) patchGenericObject() error {
+	desiredData := filterIgnoredFields(obj.managedOnly(obj.data), obj.ignoreChangesTo)
+	currentData := obj.managedOnly(obj.apiData)
+
+	var payload interface{}
+	var contentType string
+	var empty bool
+	modifications := make([]interface{}, 0)
+
+	switch obj.updateStrategy {
+	case updateStrategyJSONPatch:
+		ops := withoutProtectedRemovals(buildJSONPatch(currentData, desiredData, obj.managedData(), obj.ignoreChangesTo, obj.normalize), obj.protectedPaths)
+		/* The paths of the operations start below the envelope of the object */
+		for i := range ops {
+			ops[i].Path = obj.requestPointer() + ops[i].Path
+		}
+		payload, contentType, empty = ops, contentTypeJSONPatch, len(ops) == 0
+		for _, op := range ops {
+			modifications = append(modifications, op)
+		}
+	case updateStrategyMergePatch:
+		patch := withoutProtectedNulls(buildMergePatch(currentData, desiredData, obj.managedData(), obj.ignoreChangesTo, obj.normalize), "", obj.protectedPaths)
+		payload, contentType, empty = obj.wrapRequest(patch), contentTypeMergePatch, len(patch) == 0
+		modifications = append(modifications, patch)
+	default:
+		return fmt.Errorf("update strategy '%s' is not a generic patch strategy", obj.updateStrategy)
+	}
+
+	if empty {
+		if obj.debug {
+			log.Printf("api_object.go: No differences found, not sending %s request", obj.updateStrategy)
+		}
+		return nil
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s document to JSON: %v", obj.updateStrategy, err)
+	}
+
+	patchPath := obj.withQueryString(obj.patchPath, "update")
+	patchPath = withExecuteOptions(patchPath, obj.updateOptions)
+
+	resultString, err := obj.apiClient.sendRequestWithContentType("PATCH", strings.Replace(patchPath, "{id}", obj.id, -1), string(b), contentType)
+	if err != nil {
+		return err
+	}
+	obj.appliedDeltas, _ = marshalModifications(modifications)
+
+	if obj.apiClient.writeReturnsObject {
+		return obj.updateState(resultString)
+	}
+	return obj.readObject()
+}
@@ -0,0 +1,374 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveUpdateStrategy(t *testing.T) {
+	cases := []struct {
+		strategy string
+		method   string
+		expected string
+		wantErr  bool
+	}{
+		{"", "PUT", updateStrategyPut, false},
+		{"", "POST", updateStrategyPut, false},
+		{"", "PATCH", updateStrategyMidpointPatch, false},
+		{"json-patch", "PATCH", updateStrategyJSONPatch, false},
+		{"merge-patch", "PUT", updateStrategyMergePatch, false},
+		{"put", "PATCH", updateStrategyPut, false},
+		{"bogus", "PUT", "", true},
+	}
+
+	for _, c := range cases {
+		result, err := resolveUpdateStrategy(c.strategy, c.method)
+		if (err != nil) != c.wantErr {
+			t.Errorf("update_strategy_test.go: strategy='%s' method='%s': unexpected error state: %v", c.strategy, c.method, err)
+		}
+		if result != c.expected {
+			t.Errorf("update_strategy_test.go: strategy='%s' method='%s': expected '%s', got '%s'", c.strategy, c.method, c.expected, result)
+		}
+	}
+}
+
+func TestBuildJSONPatch(t *testing.T) {
+	current := MapAny{
+		"name":     "jsmith",
+		"title":    "Engineer",
+		"a/b":      "slash",
+		"metadata": MapAny{"modified": "yesterday"},
+		"address":  MapAny{"city": "Paris", "zip": "75001"},
+	}
+	desired := MapAny{
+		"name":    "jsmith",
+		"email":   nil,
+		"address": MapAny{"city": "Lyon"},
+	}
+
+	ops := buildJSONPatch(current, desired, current, []string{"metadata"}, nil)
+	expected := []jsonPatchOperation{
+		{Op: "replace", Path: "/address/city", Value: "Lyon"},
+		{Op: "remove", Path: "/address/zip"},
+		{Op: "add", Path: "/email", Value: nil},
+		{Op: "remove", Path: "/a~1b"},
+		{Op: "remove", Path: "/title"},
+	}
+	if !reflect.DeepEqual(expected, ops) {
+		t.Fatalf("update_strategy_test.go: Unexpected JSON Patch: expected %v but got %v", expected, ops)
+	}
+
+	b, _ := json.Marshal(ops)
+	var decoded []map[string]interface{}
+	_ = json.Unmarshal(b, &decoded)
+	if _, ok := decoded[2]["value"]; !ok {
+		t.Fatalf("update_strategy_test.go: Expected explicit null value on add operation, got %s", string(b))
+	}
+	if _, ok := decoded[3]["value"]; ok {
+		t.Fatalf("update_strategy_test.go: Expected no value on remove operation, got %s", string(b))
+	}
+
+	/* Representation-only differences are no changes once normalized */
+	rules := &normalizeRules{booleanStrings: true, numericStrings: true}
+	ops = buildJSONPatch(MapAny{"enabled": "true", "weight": "10", "a~b": MapAny{"c/d": 1}}, MapAny{"enabled": true, "weight": 10.0, "a~b": MapAny{"c/d": 2}}, nil, nil, rules)
+	expected = []jsonPatchOperation{
+		{Op: "replace", Path: "/a~0b/c~1d", Value: 2},
+	}
+	if !reflect.DeepEqual(expected, ops) {
+		t.Fatalf("update_strategy_test.go: Unexpected normalized JSON Patch: expected %v but got %v", expected, ops)
+	}
+}
+
+func TestBuildMergePatch(t *testing.T) {
+	current := MapAny{
+		"name":     "jsmith",
+		"title":    "Engineer",
+		"metadata": MapAny{"modified": "yesterday"},
+		"address":  MapAny{"city": "Paris", "zip": "75001"},
+		"roles":    []interface{}{"a", "b"},
+	}
+	desired := MapAny{
+		"name":    "jsmith",
+		"address": MapAny{"city": "Paris", "zip": "69001"},
+		"roles":   []interface{}{"a"},
+	}
+
+	patch := buildMergePatch(current, desired, current, []string{"metadata"}, nil)
+	expected := MapAny{
+		"title":   nil,
+		"address": MapAny{"zip": "69001"},
+		"roles":   []interface{}{"a"},
+	}
+	if !reflect.DeepEqual(expected, patch) {
+		t.Fatalf("update_strategy_test.go: Unexpected merge patch: expected %v but got %v", expected, patch)
+	}
+
+	if patch := buildMergePatch(current, current, current, nil, nil); len(patch) != 0 {
+		t.Fatalf("update_strategy_test.go: Expected empty merge patch for identical objects, got %v", patch)
+	}
+}
+
+func TestGenericPatchStrategies(t *testing.T) {
+	var lastMethod, lastContentType string
+	var lastBody []byte
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			lastMethod = r.Method
+			lastContentType = r.Header.Get("Content-Type")
+			lastBody, _ = io.ReadAll(r.Body)
+		}
+		w.Write([]byte(`{"id": "1", "name": "old"}`))
+	}))
+	defer svr.Close()
+
+	for strategy, contentType := range map[string]string{
+		updateStrategyJSONPatch:  contentTypeJSONPatch,
+		updateStrategyMergePatch: contentTypeMergePatch,
+	} {
+		t.Run(strategy, func(t *testing.T) {
+			client, err := NewAPIClient(&apiClientOpt{
+				uri:            svr.URL,
+				timeout:        5,
+				updateStrategy: strategy,
+				/* A provider-wide Content-Type must not win over the patch media type */
+				headers: map[string]string{"Content-Type": "application/json"},
+			})
+			if err != nil {
+				t.Fatalf("update_strategy_test.go: Failed to create API client: %s", err)
+			}
+
+			obj, err := NewAPIObject(client, &apiObjectOpts{
+				path: "/api/objects",
+				id:   "1",
+				data: `{"id": "1", "name": "new"}`,
+			})
+			if err != nil {
+				t.Fatalf("update_strategy_test.go: Failed to create API object: %s", err)
+			}
+
+			lastMethod, lastContentType, lastBody = "", "", nil
+			if err := obj.updateObject(); err != nil {
+				t.Fatalf("update_strategy_test.go: Update failed: %s", err)
+			}
+
+			if lastMethod != "PATCH" {
+				t.Fatalf("update_strategy_test.go: Expected a PATCH request, got '%s'", lastMethod)
+			}
+			if lastContentType != contentType {
+				t.Fatalf("update_strategy_test.go: Expected content type '%s', got '%s'", contentType, lastContentType)
+			}
+			if len(lastBody) == 0 {
+				t.Fatalf("update_strategy_test.go: Expected a patch document to be sent")
+			}
+		})
+	}
+}
+
+func TestPatchPath(t *testing.T) {
+	requests := make(map[string]string)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			requests[r.Method] = r.URL.Path
+		}
+		w.Write([]byte(`{"id": "1", "name": "old"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("update_strategy_test.go: Failed to create API client: %s", err)
+	}
+
+	for _, strategy := range []string{updateStrategyPut, updateStrategyJSONPatch} {
+		obj, err := NewAPIObject(client, &apiObjectOpts{
+			path:           "/api/objects",
+			patchPath:      "/api/objects/{id}/delta",
+			id:             "1",
+			updateStrategy: strategy,
+			data:           `{"id": "1", "name": "new"}`,
+		})
+		if err != nil {
+			t.Fatalf("update_strategy_test.go: Failed to create API object: %s", err)
+		}
+		if err := obj.updateObject(); err != nil {
+			t.Fatalf("update_strategy_test.go: Update with '%s' failed: %s", strategy, err)
+		}
+	}
+
+	expected := map[string]string{"PUT": "/api/objects/1", "PATCH": "/api/objects/1/delta"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("update_strategy_test.go: Expected updates to use update_path and patches patch_path: expected %v, got %v", expected, requests)
+	}
+
+	obj, _ := NewAPIObject(client, &apiObjectOpts{path: "/api/objects", putPath: "/api/objects/{id}/update"})
+	if obj.patchPath != "/api/objects/{id}/update" {
+		t.Fatalf("update_strategy_test.go: Expected patch_path to default to update_path, got '%s'", obj.patchPath)
+	}
+}
+
+func TestAppliedDeltas(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "1", "name": "old"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("update_strategy_test.go: Failed to create API client: %s", err)
+	}
+
+	expected := map[string][]string{
+		updateStrategyJSONPatch:     {`{"op":"replace","path":"/name","value":"new"}`},
+		updateStrategyMergePatch:    {`{"name":"new"}`},
+		updateStrategyMidpointPatch: {`{"modificationType":"replace","path":"name","value":"new"}`},
+		updateStrategyPut:           {},
+	}
+	for strategy, applied := range expected {
+		obj, err := NewAPIObject(client, &apiObjectOpts{
+			path:           "/api/objects",
+			id:             "1",
+			updateStrategy: strategy,
+			data:           `{"id": "1", "name": "new"}`,
+		})
+		if err != nil {
+			t.Fatalf("update_strategy_test.go: Failed to create API object: %s", err)
+		}
+		if err := obj.updateObject(); err != nil {
+			t.Fatalf("update_strategy_test.go: Update with '%s' failed: %s", strategy, err)
+		}
+		if !reflect.DeepEqual(obj.appliedDeltas, applied) {
+			t.Fatalf("update_strategy_test.go: Expected '%s' to record %v as applied, got %v", strategy, applied, obj.appliedDeltas)
+		}
+	}
+}
+
+func TestMidpointPatchDeltaList(t *testing.T) {
+	var requests []string
+	results := `{"objectDeltaOperationList": {"deltaOperation": {"objectDelta": {"oid": "1"}, "executionResult": {"status": "success"}}}}`
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			w.Write([]byte(results))
+			return
+		}
+		w.Write([]byte(`{"user": {"oid": "1", "name": "old"}}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "oid", deltaListPath: "/rpc/executeChanges"})
+	if err != nil {
+		t.Fatalf("update_strategy_test.go: Failed to create API client: %s", err)
+	}
+	obj, err := NewAPIObject(client, &apiObjectOpts{
+		path:           "/users",
+		id:             "1",
+		updateStrategy: updateStrategyMidpointPatch,
+		data:           `{"user": {"oid": "1", "name": "new"}}`,
+	})
+	if err != nil {
+		t.Fatalf("update_strategy_test.go: Failed to create API object: %s", err)
+	}
+
+	if err := obj.updateObject(); err != nil {
+		t.Fatalf("update_strategy_test.go: Update failed: %s", err)
+	}
+	expected := []string{"GET /users/1", "POST /rpc/executeChanges"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("update_strategy_test.go: Expected the update to be sent to delta_list_path: expected %v, got %v", expected, requests)
+	}
+	if !reflect.DeepEqual(obj.appliedDeltas, []string{`{"modificationType":"replace","path":"name","value":"new"}`}) {
+		t.Fatalf("update_strategy_test.go: Expected the delta to be recorded as applied, got %v", obj.appliedDeltas)
+	}
+
+	/* A response without the result of the object fails the update */
+	results = `{"objectDeltaOperationList": {"deltaOperation": []}}`
+	if err := obj.updateObject(); err == nil || !strings.Contains(err.Error(), "no result in the response") {
+		t.Fatalf("update_strategy_test.go: Expected a missing result to fail the update, got %v", err)
+	}
+}
+
+func TestExecuteOptions(t *testing.T) {
+	requests := make(map[string]string)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method] = r.URL.RequestURI()
+		w.Write([]byte(`{"id": "1", "name": "old"}`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5})
+	if err != nil {
+		t.Fatalf("update_strategy_test.go: Failed to create API client: %s", err)
+	}
+
+	obj, err := NewAPIObject(client, &apiObjectOpts{
+		path:           "/users",
+		id:             "1",
+		updateStrategy: updateStrategyMidpointPatch,
+		queryString:    "options=overwrite",
+		updateOptions:  []string{"raw", "reconcile"},
+		destroyOptions: []string{"force"},
+		data:           `{"id": "1", "name": "new"}`,
+	})
+	if err != nil {
+		t.Fatalf("update_strategy_test.go: Failed to create API object: %s", err)
+	}
+	if err := obj.updateObject(); err != nil {
+		t.Fatalf("update_strategy_test.go: Update failed: %s", err)
+	}
+	if err := obj.deleteObject(); err != nil {
+		t.Fatalf("update_strategy_test.go: Delete failed: %s", err)
+	}
+
+	expected := map[string]string{
+		"GET":    "/users/1?options=overwrite",
+		"PATCH":  "/users/1?options=raw&options=reconcile",
+		"DELETE": "/users/1?options=overwrite&options=force",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("update_strategy_test.go: Expected execute options only on modifications and deletes: expected %v, got %v", expected, requests)
+	}
+}
+
+func TestGenericPatchKeepsServerKeys(t *testing.T) {
+	var lastBody []byte
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			lastBody, _ = io.ReadAll(r.Body)
+		}
+		/* The server added version and metadata, which were never in data */
+		w.Write([]byte(`{"id": "1", "name": "old", "title": "Engineer", "version": "3", "metadata": {"modified": "today"}}`))
+	}))
+	defer svr.Close()
+
+	expected := map[string]string{
+		updateStrategyJSONPatch:  `[{"op":"replace","path":"/name","value":"new"},{"op":"remove","path":"/title"}]`,
+		updateStrategyMergePatch: `{"name":"new","title":null}`,
+	}
+	for strategy, body := range expected {
+		client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, updateStrategy: strategy, idAttribute: "id"})
+		if err != nil {
+			t.Fatalf("update_strategy_test.go: Failed to create API client: %s", err)
+		}
+		obj, err := NewAPIObject(client, &apiObjectOpts{
+			path:         "/api/objects",
+			id:           "1",
+			data:         `{"name": "new"}`,
+			previousData: `{"id": "1", "name": "old", "title": "Engineer"}`,
+		})
+		if err != nil {
+			t.Fatalf("update_strategy_test.go: Failed to create API object: %s", err)
+		}
+		if err := obj.updateObject(); err != nil {
+			t.Fatalf("update_strategy_test.go: Update with '%s' failed: %s", strategy, err)
+		}
+		if string(lastBody) != body {
+			t.Fatalf("update_strategy_test.go: Expected only the managed title to be removed with '%s', got %s", strategy, lastBody)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUpsert(t *testing.T) {
+	var writes []string
+	stored := ""
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if stored == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(stored))
+		default:
+			writes = append(writes, r.Method+" "+r.URL.RequestURI())
+			stored = `{"user": {"oid": "user-1", "name": "jsmith"}}`
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", updateMethod: "PATCH"})
+	if err != nil {
+		t.Fatalf("upsert_test.go: Failed to create API client: %s", err)
+	}
+	newResourceData := func(data string) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceRestAPI().Schema, map[string]interface{}{
+			"path":   "/users",
+			"data":   data,
+			"upsert": true,
+		})
+	}
+
+	d := newResourceData(`{"user": {"oid": "user-1", "name": "jsmith"}}`)
+	if err := resourceRestAPICreate(d, client); err != nil {
+		t.Fatalf("upsert_test.go: Create failed: %s", err)
+	}
+	d.Set("data", `{"user": {"oid": "user-1", "name": "jsmith", "fullName": "John Smith"}}`)
+	if err := resourceRestAPIUpdate(d, client); err != nil {
+		t.Fatalf("upsert_test.go: Update failed: %s", err)
+	}
+	expected := "PUT /users/user-1?options=overwrite\nPUT /users/user-1?options=overwrite"
+	if strings.Join(writes, "\n") != expected {
+		t.Fatalf("upsert_test.go: Expected the create and update to overwrite with PUT, got %v", writes)
+	}
+
+	if err := resourceRestAPICreate(newResourceData(`{"user": {"name": "jsmith"}}`), client); err == nil || !strings.Contains(err.Error(), "upsert needs the id") {
+		t.Fatalf("upsert_test.go: Expected the create without an id to fail, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package restapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/* The namespace midPoint objects are serialized in unless "@ns" says otherwise */
+const midpointCommonNamespace = "http://midpoint.evolveum.com/xml/ns/public/common/common-3"
+
+/* Root elements of the REST API's own documents, which are not in the common namespace */
+var midpointAPITypesRoots = map[string]bool{
+	"objectModification":    true,
+	"objectDeltaList":       true,
+	"executeScript":         true,
+	"policyItemsDefinition": true,
+}
+
+const midpointAPITypesNamespace = "http://midpoint.evolveum.com/xml/ns/public/common/api-types-3"
+
+/*
+jsonToXML converts a JSON document with a single root key, such as
+
+	{"user": {"name": "jsmith"}}, into midPoint XML. Keys starting with "@"
+	become attributes ("@ns" sets the namespace), "#text" becomes the text of
+	the element, lists become repeated elements and nulls are left out.
+	Numbers are written as they are in the JSON.
+*/
+func jsonToXML(data string) (string, error) {
+	var document map[string]interface{}
+	if err := unmarshalJSON([]byte(data), &document); err != nil {
+		return "", fmt.Errorf("failed to parse the JSON payload to convert to XML: %v", err)
+	}
+	if len(document) != 1 {
+		return "", fmt.Errorf("an XML payload needs a single root key such as 'user', got %d keys", len(document))
+	}
+
+	var buffer bytes.Buffer
+	enc := xml.NewEncoder(&buffer)
+	for name, value := range document {
+		root, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("the root key '%s' of an XML payload must hold an object", name)
+		}
+		if _, ok := root["@ns"]; !ok {
+			root = copyMap(root)
+			root["@ns"] = midpointCommonNamespace
+			if midpointAPITypesRoots[name] {
+				root["@ns"] = midpointAPITypesNamespace
+			}
+		}
+		if err := encodeXMLElement(enc, name, root); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func encodeXMLElement(enc *xml.Encoder, name string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, elem := range v {
+			if err := encodeXMLElement(enc, name, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		start := xml.StartElement{Name: xml.Name{Local: name}}
+		children := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			switch {
+			case key == "@ns":
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: xmlText(v[key])})
+			case strings.HasPrefix(key, "@"):
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key[1:]}, Value: xmlText(v[key])})
+			case key != "#text":
+				children = append(children, key)
+			}
+		}
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if text, ok := v["#text"]; ok {
+			if err := enc.EncodeToken(xml.CharData(xmlText(text))); err != nil {
+				return err
+			}
+		}
+		for _, key := range children {
+			if err := encodeXMLElement(enc, key, v[key]); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return enc.EncodeElement(xmlText(v), xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+/* xmlText returns the XML text of a JSON scalar */
+func xmlText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+/*
+xmlToJSON converts an XML response into the JSON document jsonToXML would
+
+	have turned into it, so responses are diffed the same way in both modes.
+	XML carries no types, so every value is a string, and a repeated element
+	is a list while a single one is not.
+*/
+func xmlToJSON(body string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return "", fmt.Errorf("the XML response has no root element")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse the XML response: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeXMLElement(dec, start)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse the XML response: %v", err)
+			}
+			b, err := json.Marshal(map[string]interface{}{start.Name.Local: value})
+			if err != nil {
+				return "", err
+			}
+			return string(b), nil
+		}
+	}
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		/* Namespaces are dropped, elements are known by their local name */
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		element["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := element[name].(type) {
+			case nil:
+				element[name] = child
+			case []interface{}:
+				element[name] = append(existing, child)
+			default:
+				element[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return trimmed, nil
+			}
+			if trimmed != "" {
+				element["#text"] = trimmed
+			}
+			return element, nil
+		}
+	}
+}
+
+/* isXMLBody reports whether a response body is an XML document rather than JSON */
+func isXMLBody(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(body), "<")
+}
@@ -0,0 +1,122 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONToXML(t *testing.T) {
+	res, err := jsonToXML(`{"user": {"@id": 5, "name": "jsmith", "active": true, "weight": 1.5, "description": null, "assignment": [{"targetRef": {"@oid": "r1"}}, {"targetRef": {"@oid": "r2"}}], "extension": {"@ns": "urn:ext", "code": "A&B"}}}`)
+	if err != nil {
+		t.Fatalf("xml_payload_test.go: %s", err)
+	}
+	expected := `<user id="5" xmlns="http://midpoint.evolveum.com/xml/ns/public/common/common-3"><active>true</active><assignment><targetRef oid="r1"></targetRef></assignment><assignment><targetRef oid="r2"></targetRef></assignment><extension xmlns="urn:ext"><code>A&amp;B</code></extension><name>jsmith</name><weight>1.5</weight></user>`
+	if res != expected {
+		t.Fatalf("xml_payload_test.go: Unexpected XML\nexpected: %s\n     got: %s", expected, res)
+	}
+
+	/* Numbers are written as in JSON, including integers a float64 cannot hold */
+	res, _ = jsonToXML(`{"user": {"employeeNumber": 1000000000, "big": 12345678901234567890}}`)
+	if !strings.Contains(res, "<big>12345678901234567890</big><employeeNumber>1000000000</employeeNumber>") {
+		t.Fatalf("xml_payload_test.go: Expected the numbers as written, got %s", res)
+	}
+
+	res, _ = jsonToXML(`{"objectModification": {"itemDelta": {"modificationType": "replace", "path": "name", "value": "x"}}}`)
+	if expected := `<objectModification xmlns="http://midpoint.evolveum.com/xml/ns/public/common/api-types-3">`; res[:len(expected)] != expected {
+		t.Fatalf("xml_payload_test.go: Expected modifications in the api-types namespace, got %s", res)
+	}
+
+	if _, err := jsonToXML(`{"user": {}, "role": {}}`); err == nil {
+		t.Fatalf("xml_payload_test.go: Expected a payload without a single root key to fail")
+	}
+}
+
+func TestXMLToJSON(t *testing.T) {
+	res, err := xmlToJSON(`<?xml version="1.0"?>
+<user xmlns="http://midpoint.evolveum.com/xml/ns/public/common/common-3" oid="1234" version="3">
+  <name>jsmith</name>
+  <assignment id="1"><targetRef oid="r1" type="RoleType"/></assignment>
+  <assignment id="2"><targetRef oid="r2" type="RoleType"/></assignment>
+  <note lang="en">Hello</note>
+</user>`)
+	if err != nil {
+		t.Fatalf("xml_payload_test.go: %s", err)
+	}
+
+	var decoded map[string]interface{}
+	json.Unmarshal([]byte(res), &decoded)
+	expected := MapAny{"user": MapAny{
+		"@oid":     "1234",
+		"@version": "3",
+		"name":     "jsmith",
+		"assignment": []interface{}{
+			MapAny{"@id": "1", "targetRef": MapAny{"@oid": "r1", "@type": "RoleType"}},
+			MapAny{"@id": "2", "targetRef": MapAny{"@oid": "r2", "@type": "RoleType"}},
+		},
+		"note": MapAny{"@lang": "en", "#text": "Hello"},
+	}}
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Fatalf("xml_payload_test.go: Unexpected JSON\nexpected: %v\n     got: %v", expected, decoded)
+	}
+}
+
+func TestXMLPayloads(t *testing.T) {
+	var lastContentType, lastAccept, lastBody string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lastContentType, lastAccept, lastBody = r.Header.Get("Content-Type"), r.Header.Get("Accept"), string(b)
+		if r.URL.Path == "/login" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>Please sign in</body></html>"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<user xmlns="http://midpoint.evolveum.com/xml/ns/public/common/common-3"><oid>1</oid><name>jsmith</name></user>`))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", writeReturnsObject: true})
+	if err != nil {
+		t.Fatalf("xml_payload_test.go: Failed to create API client: %s", err)
+	}
+
+	obj, err := NewAPIObject(client, &apiObjectOpts{path: "/users", contentType: "xml", data: `{"user": {"name": "jsmith"}}`})
+	if err != nil {
+		t.Fatalf("xml_payload_test.go: Failed to create API object: %s", err)
+	}
+	if client.payloadFormat != "" {
+		t.Fatalf("xml_payload_test.go: Expected the provider's client to keep sending JSON")
+	}
+
+	if err := obj.createObject(); err != nil {
+		t.Fatalf("xml_payload_test.go: Create failed: %s", err)
+	}
+	if lastContentType != "application/xml" || lastAccept != "application/xml" {
+		t.Fatalf("xml_payload_test.go: Expected XML content type and accept headers, got '%s' and '%s'", lastContentType, lastAccept)
+	}
+	if lastBody != `<user xmlns="http://midpoint.evolveum.com/xml/ns/public/common/common-3"><name>jsmith</name></user>` {
+		t.Fatalf("xml_payload_test.go: Unexpected request body %s", lastBody)
+	}
+	if obj.id != "1" || !reflect.DeepEqual(obj.apiData, MapAny{"user": MapAny{"oid": "1", "name": "jsmith"}}) {
+		t.Fatalf("xml_payload_test.go: Expected the XML response to be read as JSON, got id '%s' and %v", obj.id, obj.apiData)
+	}
+
+	/* Responses are expected in the payload format, unless expect_content_type says otherwise */
+	if _, err := client.withPayloadFormat("xml").sendRequest("GET", "/login", ""); err == nil || !strings.Contains(err.Error(), "expected 'application/xml'") {
+		t.Fatalf("xml_payload_test.go: Expected an HTML response to an XML request to be reported, got %v", err)
+	}
+	client.expectContentType = "text/html"
+	if _, err := client.withPayloadFormat("xml").sendRequest("GET", "/users/1", ""); err == nil || !strings.Contains(err.Error(), "expected 'text/html'") {
+		t.Fatalf("xml_payload_test.go: Expected expect_content_type to win over the payload format, got %v", err)
+	}
+	client.expectContentType = ""
+
+	if _, err := NewAPIObject(client, &apiObjectOpts{path: "/users", contentType: "toml"}); err == nil {
+		t.Fatalf("xml_payload_test.go: Expected an unknown content_type to fail")
+	}
+}
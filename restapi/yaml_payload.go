@@ -0,0 +1,136 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/* jsonToYAML converts a JSON request body into the same document in YAML, numbers as written */
+func jsonToYAML(data string) (string, error) {
+	var document interface{}
+	if err := unmarshalJSON([]byte(data), &document); err != nil {
+		return "", fmt.Errorf("failed to parse the JSON payload to convert to YAML: %v", err)
+	}
+	b, err := yaml.Marshal(withYAMLNumbers(document))
+	if err != nil {
+		return "", fmt.Errorf("failed to convert the payload to YAML: %v", err)
+	}
+	return string(b), nil
+}
+
+/* yamlNumber is a JSON number that is written to YAML exactly as it was written in JSON */
+type yamlNumber json.Number
+
+func (n yamlNumber) MarshalYAML() (interface{}, error) {
+	tag := "!!int"
+	if strings.ContainsAny(string(n), ".eE") {
+		tag = "!!float"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(n)}, nil
+}
+
+/* withYAMLNumbers returns a decoded JSON value with its numbers as yamlNumber */
+func withYAMLNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		return yamlNumber(v)
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			converted[key] = withYAMLNumbers(elem)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, elem := range v {
+			converted[i] = withYAMLNumbers(elem)
+		}
+		return converted
+	}
+	return value
+}
+
+/*
+yamlToJSON converts a YAML response into JSON. Scalars keep the type YAML
+
+	resolves them to, except timestamps, which are kept as written instead
+	of being reformatted, so they compare equal to the strings in data.
+	Numbers are kept as written too, so long identifiers are not rounded.
+*/
+func yamlToJSON(body string) (string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(body), &node); err != nil {
+		return "", fmt.Errorf("failed to parse the YAML response: %v", err)
+	}
+	if node.Kind == 0 {
+		return "{}", nil
+	}
+	value, err := yamlNodeValue(&node)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the YAML response: %v", err)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func yamlNodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return yamlNodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.MappingNode:
+		hash := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			hash[node.Content[i].Value] = value
+		}
+		return hash, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, elem := range node.Content {
+			value, err := yamlNodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!int", "!!float":
+		/* Numbers JSON can write are kept as written, like numbers in decoded JSON */
+		if json.Valid([]byte(node.Value)) {
+			return json.Number(node.Value), nil
+		}
+		fallthrough
+	case "!!bool":
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		/* Other numbers, such as 0x1F or 1_000, are float64 */
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		}
+		return value, nil
+	}
+	return node.Value, nil
+}
@@ -0,0 +1,81 @@
+package restapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestYAMLPayloads(t *testing.T) {
+	res, err := jsonToYAML(`{"user": {"name": "jsmith", "employeeNumber": "007", "active": true}}`)
+	if err != nil {
+		t.Fatalf("yaml_payload_test.go: %s", err)
+	}
+	expected := "user:\n    active: true\n    employeeNumber: \"007\"\n    name: jsmith\n"
+	if res != expected {
+		t.Fatalf("yaml_payload_test.go: Unexpected YAML\nexpected: %q\n     got: %q", expected, res)
+	}
+
+	/* Numbers are written as in JSON, including integers a float64 cannot hold */
+	res, err = jsonToYAML(`{"user": {"employeeNumber": 1000000000, "personalNumber": 12345678901234567890, "ratio": 1.50}}`)
+	if err != nil {
+		t.Fatalf("yaml_payload_test.go: %s", err)
+	}
+	expected = "user:\n    employeeNumber: 1000000000\n    personalNumber: 12345678901234567890\n    ratio: 1.50\n"
+	if res != expected {
+		t.Fatalf("yaml_payload_test.go: Unexpected YAML\nexpected: %q\n     got: %q", expected, res)
+	}
+	if res, err = yamlToJSON(res); err != nil || res != `{"user":{"employeeNumber":1000000000,"personalNumber":12345678901234567890,"ratio":1.50}}` {
+		t.Fatalf("yaml_payload_test.go: Expected the numbers to round-trip, got %s (%v)", res, err)
+	}
+
+	res, err = yamlToJSON("user:\n  oid: '1'\n  name: jsmith\n  validFrom: 2024-01-02T03:04:05.000Z\n  costCenter: 42\n  active: yes\n  roles: &roles [a, b]\n  copy: *roles\n  description: ~\n")
+	if err != nil {
+		t.Fatalf("yaml_payload_test.go: %s", err)
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal([]byte(res), &decoded)
+	expectedJSON := MapAny{"user": MapAny{
+		"oid":         "1",
+		"name":        "jsmith",
+		"validFrom":   "2024-01-02T03:04:05.000Z",
+		"costCenter":  42.0,
+		"active":      "yes",
+		"roles":       []interface{}{"a", "b"},
+		"copy":        []interface{}{"a", "b"},
+		"description": nil,
+	}}
+	if !reflect.DeepEqual(expectedJSON, decoded) {
+		t.Fatalf("yaml_payload_test.go: Unexpected JSON\nexpected: %v\n     got: %v", expectedJSON, decoded)
+	}
+
+	var lastContentType, lastAccept, lastBody string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lastContentType, lastAccept, lastBody = r.Header.Get("Content-Type"), r.Header.Get("Accept"), string(b)
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write([]byte("user:\n  oid: \"1\"\n  name: jsmith\n"))
+	}))
+	defer svr.Close()
+
+	client, err := NewAPIClient(&apiClientOpt{uri: svr.URL, timeout: 5, idAttribute: "user/oid", writeReturnsObject: true})
+	if err != nil {
+		t.Fatalf("yaml_payload_test.go: Failed to create API client: %s", err)
+	}
+	obj, err := NewAPIObject(client, &apiObjectOpts{path: "/users", contentType: "yaml", data: `{"user": {"name": "jsmith"}}`})
+	if err != nil {
+		t.Fatalf("yaml_payload_test.go: Failed to create API object: %s", err)
+	}
+	if err := obj.createObject(); err != nil {
+		t.Fatalf("yaml_payload_test.go: Create failed: %s", err)
+	}
+	if lastContentType != "application/yaml" || lastAccept != "application/yaml" || lastBody != "user:\n    name: jsmith\n" {
+		t.Fatalf("yaml_payload_test.go: Unexpected request with content type '%s', accept '%s' and body %q", lastContentType, lastAccept, lastBody)
+	}
+	if obj.id != "1" || !reflect.DeepEqual(obj.apiData, MapAny{"user": MapAny{"oid": "1", "name": "jsmith"}}) {
+		t.Fatalf("yaml_payload_test.go: Expected the YAML response to be read as JSON, got id '%s' and %v", obj.id, obj.apiData)
+	}
+}